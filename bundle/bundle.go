@@ -0,0 +1,87 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package bundle reads and writes campaign bundles: one zip archive holding
+// several Worldographer maps, the files their notes link to, and a manifest
+// that lists both.
+//
+// A bundle is an ordinary zip. manifest.json at its root names every map and
+// every linked file in it; the maps are stored under the names they were given,
+// byte for byte, and a file a note links to is stored under files/. A note
+// keeps the Filename it had when it was written, which is a path on the
+// author's machine, so the manifest records which entry holds the file each
+// Filename names.
+//
+// Reader and Writer work on a bundle as a whole. Open, ReadFile and ReadMap are
+// for the tools: they take a name that is either a file on disk or a map inside
+// a bundle, written as the bundle's path, a '#', and the map's name in it
+// (campaign.zip#maps/world.wxx).
+package bundle
+
+import (
+	"path"
+	"strings"
+)
+
+// ManifestName is the name of the manifest in a bundle.
+const ManifestName = "manifest.json"
+
+// Format is the manifest's format field, which marks a zip as a bundle.
+const Format = "wxx-bundle"
+
+// Version is the version of the bundle layout this package writes, and the
+// newest it reads.
+const Version = 1
+
+// Manifest_t is what manifest.json holds.
+type Manifest_t struct {
+	Format  string        `json:"format"`
+	Version int           `json:"version"`
+	Maps    []MapEntry_t  `json:"maps"`
+	Files   []FileEntry_t `json:"files,omitempty"`
+}
+
+// MapEntry_t is one map in a bundle.
+type MapEntry_t struct {
+	// Path is the map's name in the bundle.
+	Path string `json:"path"`
+	// App is the application version the map was written as.
+	App string `json:"app"`
+	// References are the Filenames the map's notes link to, each once.
+	References []string `json:"references,omitempty"`
+}
+
+// FileEntry_t is one file a note links to.
+type FileEntry_t struct {
+	// Path is the file's name in the bundle, under files/.
+	Path string `json:"path"`
+	// Filename is the file as the notes name it, Note_t.Filename.
+	Filename string `json:"filename"`
+}
+
+// Map returns the entry for the map named name, and whether there is one.
+func (m *Manifest_t) Map(name string) (MapEntry_t, bool) {
+	for _, e := range m.Maps {
+		if e.Path == name {
+			return e, true
+		}
+	}
+	return MapEntry_t{}, false
+}
+
+// File returns the name in the bundle of the file a note names filename, and
+// whether the bundle holds it.
+func (m *Manifest_t) File(filename string) (string, bool) {
+	for _, e := range m.Files {
+		if e.Filename == filename {
+			return e.Path, true
+		}
+	}
+	return "", false
+}
+
+// baseName returns the last element of filename, which may be a path from any
+// operating system.
+func baseName(filename string) string {
+	filename = strings.TrimRight(strings.ReplaceAll(filename, `\`, "/"), "/")
+	return path.Base(filename)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package bundle_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+const (
+	populatedFixture = "../testdata/w2025-populated.xml"
+	classicFixture   = "../testdata/blank-2017-1.77-1.0.wxx"
+)
+
+// linkedMap returns the populated fixture with its notes linking to a file by
+// a Windows path and to one by a relative path.
+func linkedMap(t *testing.T) *wxx.Map_t {
+	t.Helper()
+	m, err := xmlio.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	if len(m.Notes) < 2 {
+		t.Fatalf("%s: want 2 notes, got %d", populatedFixture, len(m.Notes))
+	}
+	m.Notes[0].Filename = `C:\Campaign\city.html`
+	m.Notes[1].Filename = "lore/city.html"
+	return m
+}
+
+// TestWriterReader asserts that a bundle written with maps and their linked
+// files reads back: the manifest lists them, a map added as data is stored byte
+// for byte, and each map and file reads back as it went in.
+func TestWriterReader(t *testing.T) {
+	m := linkedMap(t)
+	classic, err := os.ReadFile(classicFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	w := bundle.NewWriter(&buf)
+	if err := w.AddMap("maps/world.wxx", m, "2.06"); err != nil {
+		t.Fatalf("AddMap: %v", err)
+	}
+	if err := w.AddMapData("maps/classic.wxx", classic); err != nil {
+		t.Fatalf("AddMapData: %v", err)
+	}
+	if got, want := w.Missing(), []string{`C:\Campaign\city.html`, "lore/city.html"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing() = %q, want %q", got, want)
+	}
+	if err := w.AddFile(`C:\Campaign\city.html`, []byte("first")); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if err := w.AddFile("lore/city.html", []byte("second")); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	if err := w.AddMap("maps/world.wxx", m, "2.06"); !errors.Is(err, wxx.ErrInvalidBundleEntry) {
+		t.Errorf("AddMap of a name already used: error = %v, want %v", err, wxx.ErrInvalidBundleEntry)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	r, err := bundle.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	want := bundle.Manifest_t{
+		Format:  bundle.Format,
+		Version: bundle.Version,
+		Maps: []bundle.MapEntry_t{
+			{Path: "maps/world.wxx", App: "2.06", References: []string{`C:\Campaign\city.html`, "lore/city.html"}},
+			{Path: "maps/classic.wxx", App: "1.77"},
+		},
+		Files: []bundle.FileEntry_t{
+			{Path: "files/city.html", Filename: `C:\Campaign\city.html`},
+			{Path: "files/city-2.html", Filename: "lore/city.html"},
+		},
+	}
+	if !reflect.DeepEqual(r.Manifest, want) {
+		t.Errorf("Manifest = %+v, want %+v", r.Manifest, want)
+	}
+
+	got, err := r.ReadMap("maps/world.wxx")
+	if err != nil {
+		t.Fatalf("ReadMap: %v", err)
+	}
+	if got.Notes[0].Filename != m.Notes[0].Filename || got.Tiles.TilesWide != m.Tiles.TilesWide {
+		t.Errorf("ReadMap: the map read back differs from the map added")
+	}
+	stored, err := r.FS().Open("maps/classic.wxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := io.ReadAll(stored); err != nil || !bytes.Equal(data, classic) {
+		t.Errorf("maps/classic.wxx is not the data added (err %v)", err)
+	}
+	for filename, content := range map[string]string{`C:\Campaign\city.html`: "first", "lore/city.html": "second"} {
+		f, err := r.OpenFile(filename)
+		if err != nil {
+			t.Errorf("OpenFile(%s): %v", filename, err)
+			continue
+		}
+		if data, _ := io.ReadAll(f); string(data) != content {
+			t.Errorf("OpenFile(%s) = %q, want %q", filename, data, content)
+		}
+	}
+}
+
+// TestWriterMissingFile asserts that a bundle is not finished while a note
+// links to a file that is not in it.
+func TestWriterMissingFile(t *testing.T) {
+	var buf bytes.Buffer
+	w := bundle.NewWriter(&buf)
+	if err := w.AddMap("world.wxx", linkedMap(t), "2.06"); err != nil {
+		t.Fatalf("AddMap: %v", err)
+	}
+	if err := w.AddFile("lore/city.html", nil); err != nil {
+		t.Fatalf("AddFile: %v", err)
+	}
+	err := w.Close()
+	if !errors.Is(err, wxx.ErrMissingBundleFile) || !strings.Contains(err.Error(), `C:\Campaign\city.html`) {
+		t.Errorf("Close: error = %v, want %v naming the file", err, wxx.ErrMissingBundleFile)
+	}
+}
+
+// TestReaderRejects asserts that a zip is read as a bundle only with a manifest
+// this package can read.
+func TestReaderRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		manifest string // "" for none
+		want     error
+	}{
+		{"no manifest", "", wxx.ErrInvalidBundle},
+		{"not a bundle", `{"format":"other","version":1}`, wxx.ErrInvalidBundle},
+		{"newer", `{"format":"wxx-bundle","version":2}`, wxx.ErrUnsupportedBundleVersion},
+		{"lists a missing map", `{"format":"wxx-bundle","version":1,"maps":[{"path":"world.wxx"}]}`, wxx.ErrInvalidBundle},
+	} {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		if tc.manifest != "" {
+			f, _ := zw.Create(bundle.ManifestName)
+			_, _ = f.Write([]byte(tc.manifest))
+		}
+		_ = zw.Close()
+		if _, err := bundle.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())); !errors.Is(err, tc.want) {
+			t.Errorf("%s: error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+// TestCreateAndOpen asserts that Create bundles map files with the files their
+// notes link to, finding one by its base name beside the map, and that Open
+// reads a map from a file, from a bundle by name, and from a bundle of one map.
+func TestCreateAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	world := filepath.Join(dir, "world.wxx")
+	if err := xmlio.WriteFile(world, linkedMap(t), "2.06"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "lore"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"city.html": "first", "lore/city.html": "second"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	classic := filepath.Join(dir, "classic.wxx")
+	data, err := os.ReadFile(classicFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(classic, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	campaign := filepath.Join(dir, "campaign.zip")
+	if err := bundle.Create(campaign, world, classic); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	single := filepath.Join(dir, "single.zip")
+	if err := bundle.Create(single, classic); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	for _, name := range []string{classic, campaign + "#classic.wxx", single} {
+		got, err := bundle.ReadFile(name)
+		if err != nil {
+			t.Errorf("ReadFile(%s): %v", name, err)
+		} else if !bytes.Equal(got, data) {
+			t.Errorf("ReadFile(%s) is not the map file", name)
+		}
+	}
+	if m, err := bundle.ReadMap(campaign + "#world.wxx"); err != nil {
+		t.Errorf("ReadMap: %v", err)
+	} else if m.Notes[1].Filename != "lore/city.html" {
+		t.Errorf("ReadMap: note filename = %q", m.Notes[1].Filename)
+	}
+	if _, err := bundle.Open(campaign); err == nil || !strings.Contains(err.Error(), campaign+"#world.wxx") {
+		t.Errorf("Open of a bundle of two maps: error = %v, want one naming them", err)
+	}
+	if _, err := bundle.Open(campaign + "#missing.wxx"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open of a map not in the bundle: error = %v, want %v", err, os.ErrNotExist)
+	}
+
+	// a linked file that is nowhere fails the bundle, and leaves no file
+	if err := os.Remove(filepath.Join(dir, "city.html")); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(dir, "broken.zip")
+	if err := bundle.Create(broken, world); !errors.Is(err, wxx.ErrMissingBundleFile) {
+		t.Errorf("Create without a linked file: error = %v, want %v", err, wxx.ErrMissingBundleFile)
+	}
+	if _, err := os.Stat(broken); !os.IsNotExist(err) {
+		t.Errorf("Create without a linked file left %s behind", broken)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Create writes a bundle to path holding the map files maps, each under its
+// base name and byte for byte, and every file their notes link to.
+//
+// A note's Filename is looked for as it is, relative to the directory of its
+// map when it is not absolute, and then as its base name in that directory:
+// the path a note was given on its author's machine rarely exists anywhere
+// else, but the file is often kept beside the map. A file that is in neither
+// place fails the bundle with wxx.ErrMissingBundleFile, and path is removed.
+func Create(path string, maps ...string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
+
+	w := NewWriter(f)
+	dirs := map[string]string{} // where to look for each file a note names
+	for _, name := range maps {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		if err := w.AddMapData(filepath.Base(name), data); err != nil {
+			return err
+		}
+		for _, filename := range w.manifest.Maps[len(w.manifest.Maps)-1].References {
+			if _, ok := dirs[filename]; !ok {
+				dirs[filename] = filepath.Dir(name)
+			}
+		}
+	}
+	for _, filename := range w.Missing() {
+		data, err := readLinked(dirs[filename], filename)
+		if errors.Is(err, os.ErrNotExist) {
+			continue // Close names it
+		} else if err != nil {
+			return err
+		}
+		if err := w.AddFile(filename, data); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// readLinked reads the file a note in a map in dir names filename.
+func readLinked(dir, filename string) ([]byte, error) {
+	name := filepath.FromSlash(filename)
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	data, err := os.ReadFile(name)
+	if !errors.Is(err, os.ErrNotExist) {
+		return data, err
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, baseName(filename))); err == nil {
+		return data, nil
+	}
+	return nil, fmt.Errorf("%s: %w", filename, err)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// Separator joins a bundle's path and the name of a map in it, in the names
+// Open takes.
+const Separator = "#"
+
+// zipMagic opens every zip archive. A .wxx opens with gzip's magic instead, so
+// the two cannot be confused.
+var zipMagic = []byte("PK\x03\x04")
+
+// Open opens a map for reading. name is a file on disk, which may itself be a
+// bundle holding a single map, or a map inside a bundle, named by the bundle's
+// path, Separator, and the map's name in the bundle: campaign.zip#maps/world.wxx.
+// A file named name on disk is always opened as one, whatever its name holds.
+//
+// It is what the tools open their input with, so that each of them reads a map
+// from a bundle as readily as from a file. The caller closes the file.
+func Open(name string) (fs.File, error) {
+	f, err := os.Open(name)
+	if err == nil {
+		head := make([]byte, len(zipMagic))
+		n, _ := io.ReadFull(f, head)
+		if !bytes.Equal(head[:n], zipMagic) {
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			return f, nil
+		}
+		_ = f.Close()
+		return openIn(name, "")
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	i := strings.LastIndex(name, Separator)
+	if i == -1 {
+		return nil, err
+	}
+	return openIn(name[:i], name[i+len(Separator):])
+}
+
+// ReadFile reads the map name, which Open names, and returns the bytes the file
+// holds.
+func ReadFile(name string) ([]byte, error) {
+	f, err := Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return io.ReadAll(f)
+}
+
+// ReadMap reads and decodes the map name, which Open names, as xmlio.ReadFile
+// does.
+func ReadMap(name string, opts ...xmlio.DecoderOption) (*wxx.Map_t, error) {
+	return xmlio.ReadFS(opener{}, name, opts...)
+}
+
+// opener is the file system of the names Open takes, which ReadMap hands to
+// xmlio.ReadFS so that a map read from a bundle is decoded with the defaults
+// of a map read from disk.
+type opener struct{}
+
+func (opener) Open(name string) (fs.File, error) {
+	return Open(name)
+}
+
+// openIn opens the map name in the bundle at path. An empty name is the
+// bundle's only map.
+func openIn(path, name string) (fs.File, error) {
+	r, err := OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		if len(r.Manifest.Maps) != 1 {
+			_ = r.Close()
+			var names []string
+			for _, e := range r.Manifest.Maps {
+				names = append(names, path+Separator+e.Path)
+			}
+			return nil, fmt.Errorf("bundle: %s holds %d maps; name one: %s", path, len(names), strings.Join(names, ", "))
+		}
+		name = r.Manifest.Maps[0].Path
+	}
+	if _, ok := r.Manifest.Map(filepath.ToSlash(name)); !ok {
+		_ = r.Close()
+		return nil, fmt.Errorf("bundle: %s%s%s: %w", path, Separator, name, fs.ErrNotExist)
+	}
+	f, err := r.zr.Open(filepath.ToSlash(name))
+	if err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	return &bundledFile{File: f, r: r}, nil
+}
+
+// bundledFile is a map in a bundle, which closes the bundle when it is closed.
+type bundledFile struct {
+	fs.File
+	r *Reader
+}
+
+func (f *bundledFile) Close() error {
+	return errors.Join(f.File.Close(), f.r.Close())
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package bundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// Reader reads a bundle.
+type Reader struct {
+	// Manifest is the bundle's manifest. NewReader has checked that every map
+	// and file it lists is in the bundle.
+	Manifest Manifest_t
+
+	zr     *zip.Reader
+	closer io.Closer // the file OpenReader opened, nil otherwise
+}
+
+// OpenReader opens the bundle at path. The caller closes the Reader.
+func OpenReader(path string) (*Reader, error) {
+	zrc, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, errors.Join(wxx.ErrInvalidBundle, fmt.Errorf("bundle: %s: %w", path, err))
+	}
+	r, err := newReader(&zrc.Reader)
+	if err != nil {
+		_ = zrc.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.closer = zrc
+	return r, nil
+}
+
+// NewReader returns a Reader for the bundle held in r, which is size bytes long.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, errors.Join(wxx.ErrInvalidBundle, fmt.Errorf("bundle: %w", err))
+	}
+	return newReader(zr)
+}
+
+func newReader(zr *zip.Reader) (*Reader, error) {
+	data, err := fs.ReadFile(zr, ManifestName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: no %s", wxx.ErrInvalidBundle, ManifestName)
+	} else if err != nil {
+		return nil, errors.Join(wxx.ErrInvalidBundle, fmt.Errorf("%s: %w", ManifestName, err))
+	}
+	r := &Reader{zr: zr}
+	if err := json.Unmarshal(data, &r.Manifest); err != nil {
+		return nil, errors.Join(wxx.ErrInvalidBundle, fmt.Errorf("%s: %w", ManifestName, err))
+	}
+	if r.Manifest.Format != Format {
+		return nil, fmt.Errorf("%w: %s: format %q, want %q", wxx.ErrInvalidBundle, ManifestName, r.Manifest.Format, Format)
+	} else if r.Manifest.Version < 1 || r.Manifest.Version > Version {
+		return nil, fmt.Errorf("%w: %s: version %d, want 1 to %d", wxx.ErrUnsupportedBundleVersion, ManifestName, r.Manifest.Version, Version)
+	}
+	for _, e := range r.Manifest.Maps {
+		if err := r.exists(e.Path); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range r.Manifest.Files {
+		if err := r.exists(e.Path); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// exists returns an error if the manifest lists name and the bundle lacks it.
+func (r *Reader) exists(name string) error {
+	if _, err := fs.Stat(r.zr, name); err != nil {
+		return fmt.Errorf("%w: %s lists %s: %w", wxx.ErrInvalidBundle, ManifestName, name, err)
+	}
+	return nil
+}
+
+// FS returns the bundle as a file system, to read any entry in it.
+func (r *Reader) FS() fs.FS {
+	return r.zr
+}
+
+// ReadMap reads and decodes the map name, as xmlio.ReadFS does.
+func (r *Reader) ReadMap(name string, opts ...xmlio.DecoderOption) (*wxx.Map_t, error) {
+	if _, ok := r.Manifest.Map(name); !ok {
+		return nil, fmt.Errorf("bundle: %s: %w", name, fs.ErrNotExist)
+	}
+	return xmlio.ReadFS(r.zr, name, opts...)
+}
+
+// OpenFile opens the file the notes name filename.
+func (r *Reader) OpenFile(filename string) (fs.File, error) {
+	name, ok := r.Manifest.File(filename)
+	if !ok {
+		return nil, fmt.Errorf("bundle: %s: %w", filename, fs.ErrNotExist)
+	}
+	return r.zr.Open(name)
+}
+
+// Close closes the bundle, if OpenReader opened it.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
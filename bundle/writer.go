@@ -0,0 +1,188 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// Writer writes a bundle. Add the maps with AddMap or AddMapData and the files
+// their notes link to with AddFile, in any order, and then Close it, which
+// writes the manifest. Close fails if a note links to a file that was not
+// added, so a bundle that is written is complete.
+type Writer struct {
+	zw       *zip.Writer
+	manifest Manifest_t
+	names    map[string]bool // the entries written so far
+	modified time.Time
+}
+
+// NewWriter returns a Writer that writes a bundle to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		zw:       zip.NewWriter(w),
+		manifest: Manifest_t{Format: Format, Version: Version},
+		names:    map[string]bool{ManifestName: true},
+		modified: time.Now(),
+	}
+}
+
+// AddMap encodes m as the application version app and adds it to the bundle
+// as name. opts tune the encode as they tune xmlio.NewEncoder.
+func (w *Writer) AddMap(name string, m *wxx.Map_t, app string, opts ...xmlio.EncoderOption) error {
+	if err := w.checkName(name); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := xmlio.NewEncoder(app, opts...).Encode(&buf, m); err != nil {
+		return fmt.Errorf("bundle: %s: %w", name, err)
+	}
+	return w.addMap(name, buf.Bytes(), app, m)
+}
+
+// AddMapData adds data, a map as a file holds it, to the bundle as name. The map
+// is stored as it is, and decoded only to find the files its notes link to.
+func (w *Writer) AddMapData(name string, data []byte) error {
+	if err := w.checkName(name); err != nil {
+		return err
+	}
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithDefaultLimits()).Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("bundle: %s: %w", name, err)
+	}
+	return w.addMap(name, data, m.MetaData.Version.App.Raw, m)
+}
+
+// AddFile adds data to the bundle as the file the notes name filename.
+func (w *Writer) AddFile(filename string, data []byte) error {
+	if filename == "" {
+		return fmt.Errorf("bundle: %w: a file with no name", wxx.ErrInvalidBundleEntry)
+	} else if _, ok := w.manifest.File(filename); ok {
+		return fmt.Errorf("bundle: %w: %s is already in the bundle", wxx.ErrInvalidBundleEntry, filename)
+	}
+	name := w.fileName(filename)
+	if err := w.write(name, data, zip.Deflate); err != nil {
+		return err
+	}
+	w.manifest.Files = append(w.manifest.Files, FileEntry_t{Path: name, Filename: filename})
+	return nil
+}
+
+// Missing returns the files the notes of the maps added so far link to that
+// have not been added, sorted.
+func (w *Writer) Missing() []string {
+	var missing []string
+	seen := map[string]bool{}
+	for _, e := range w.manifest.Maps {
+		for _, filename := range e.References {
+			if _, ok := w.manifest.File(filename); !ok && !seen[filename] {
+				seen[filename] = true
+				missing = append(missing, filename)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Close writes the manifest and finishes the archive. It does not close the
+// underlying writer. If a note links to a file that was not added, Close
+// returns wxx.ErrMissingBundleFile and writes no manifest, so what was written
+// is not a bundle.
+func (w *Writer) Close() error {
+	if missing := w.Missing(); len(missing) != 0 {
+		return fmt.Errorf("bundle: %w: %s", wxx.ErrMissingBundleFile, strings.Join(missing, ", "))
+	}
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := w.write(ManifestName, append(data, '\n'), zip.Deflate); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// addMap writes data, the encoded m, as name, and lists it in the manifest
+// with the files m's notes link to.
+func (w *Writer) addMap(name string, data []byte, app string, m *wxx.Map_t) error {
+	// a .wxx is gzipped already, and deflating it again only costs time
+	method := zip.Deflate
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		method = zip.Store
+	}
+	if err := w.write(name, data, method); err != nil {
+		return err
+	}
+	w.manifest.Maps = append(w.manifest.Maps, MapEntry_t{Path: name, App: app, References: references(m)})
+	return nil
+}
+
+// checkName returns an error if name cannot be a map's name in the bundle.
+func (w *Writer) checkName(name string) error {
+	switch {
+	case !fs.ValidPath(name) || name == ".":
+		return fmt.Errorf("bundle: %w: %q is not a valid name", wxx.ErrInvalidBundleEntry, name)
+	case name == "files" || strings.HasPrefix(name, "files/"):
+		return fmt.Errorf("bundle: %w: %s: files/ holds the files notes link to", wxx.ErrInvalidBundleEntry, name)
+	case w.names[name]:
+		return fmt.Errorf("bundle: %w: %s is already in the bundle", wxx.ErrInvalidBundleEntry, name)
+	}
+	return nil
+}
+
+// fileName returns an unused name under files/ for the file the notes name
+// filename, made from its last element.
+func (w *Writer) fileName(filename string) string {
+	base := baseName(filename)
+	if base == "." || base == "/" || base == ".." {
+		base = "file"
+	}
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	name := "files/" + base
+	for n := 2; w.names[name]; n++ {
+		name = "files/" + stem + "-" + strconv.Itoa(n) + ext
+	}
+	return name
+}
+
+func (w *Writer) write(name string, data []byte, method uint16) error {
+	f, err := w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: method, Modified: w.modified})
+	if err != nil {
+		return errors.Join(wxx.ErrInvalidBundle, fmt.Errorf("bundle: %s: %w", name, err))
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("bundle: %s: %w", name, err)
+	}
+	w.names[name] = true
+	return nil
+}
+
+// references returns the Filenames m's notes link to, each once, in the order
+// the notes have them.
+func references(m *wxx.Map_t) []string {
+	var refs []string
+	seen := map[string]bool{}
+	for _, note := range m.Notes {
+		if note != nil && note.Filename != "" && !seen[note.Filename] {
+			seen[note.Filename] = true
+			refs = append(refs, note.Filename)
+		}
+	}
+	return refs
+}
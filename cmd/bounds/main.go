@@ -5,9 +5,11 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/maloquacious/wxx/bundle"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
@@ -18,19 +20,25 @@ func main() {
 		}
 		fmt.Printf("bounds:\t%s\n", arg)
 
-		fp, err := os.Open(arg)
+		fp, err := bundle.Open(arg)
 		if err != nil {
 			fmt.Printf("\t%v\n", err)
 			continue
 		}
 		defer fp.Close()
 
-		w, err := xmlio.NewDecoder().Decode(fp)
+		s, err := xmlio.Peek(fp)
 		if err != nil {
-			fmt.Printf("\t%v\n", err)
+			// point at the place in the document, the way a compiler would
+			var de *xmlio.DecodeError
+			if errors.As(err, &de) && de.XMLLine != 0 {
+				fmt.Printf("\t%s:%d:%d: %v\n", arg, de.XMLLine, de.XMLColumn, de.Err)
+			} else {
+				fmt.Printf("\t%v\n", err)
+			}
 			continue
 		}
-		fmt.Printf("\t%s: orientation %q: height %d: width %d\n", w.MetaData.Version, w.HexOrientation, w.Tiles.TilesHigh, w.Tiles.TilesWide)
+		fmt.Printf("\t%s: orientation %q: height %d: width %d\n", s.Version, s.HexOrientation, s.TilesHigh, s.TilesWide)
 	}
 
 }
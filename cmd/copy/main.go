@@ -5,19 +5,20 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
 func main() {
 	var showBuildInfo, showVersion, quiet bool
 	var inputFile, outputFile, debugUtf8XmlFile string
+	var backups int
 
 	// TODO: Future sprint - add flag to let user specify the data version in the copied file
 	flag.BoolVar(&showVersion, "version", false, "show version")
@@ -25,6 +26,7 @@ func main() {
 	flag.StringVar(&inputFile, "input", "", "input .wxx file (required)")
 	flag.StringVar(&outputFile, "output", "", "output .wxx file (required)")
 	flag.StringVar(&debugUtf8XmlFile, "debug-utf8", "", "write debug UTF-8 XML file alongside compressed UTF-16 .wxx file")
+	flag.IntVar(&backups, "backups", 0, "keep this many numbered backups of an output file that is replaced")
 	flag.BoolVar(&quiet, "quiet", false, "suppress output messages")
 	flag.Parse()
 
@@ -41,6 +43,7 @@ func main() {
 		_, _ = fmt.Fprintf(os.Stderr, "  -input file        input .wxx file (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -output file       output .wxx file (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -debug-utf8        write debug UTF-8 XML file alongside compressed UTF-16 .wxx file\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  -backups n         keep n numbered backups of an output file that is replaced\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -quiet             suppress output messages\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -version           show version\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -build-info        show version with build info\n")
@@ -58,15 +61,7 @@ func main() {
 	}
 
 	// Read the input file
-	fp, err := os.Open(inputFile)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "error opening %s: %v\n", inputFile, err)
-		os.Exit(2)
-	}
-	defer fp.Close()
-	var decoderDiagnostics xmlio.DecoderDiagnostics
-	joy := xmlio.NewDecoder(xmlio.WithDecoderDiagnostics(&decoderDiagnostics))
-	inputMap, err := joy.Decode(fp)
+	inputMap, err := bundle.ReadMap(inputFile)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "error reading %s: %v\n", inputFile, err)
 		os.Exit(2)
@@ -84,15 +79,7 @@ func main() {
 	// default target precisely because "what wrote the input" is not an answer to
 	// "what should I write" (issue #45): it is the right answer HERE, for copy, and
 	// it is copy's job to say so.
-	var encoderDiagnostics xmlio.EncoderDiagnostics
-	bah := xmlio.NewEncoder(inputMap.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
-	outputBuffer := &bytes.Buffer{}
-	err = bah.Encode(outputBuffer, inputMap)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "error encoding %s: %v\n", outputFile, err)
-		os.Exit(1)
-	}
-	err = os.WriteFile(outputFile, outputBuffer.Bytes(), 0644)
+	err = xmlio.WriteFile(outputFile, inputMap, inputMap.MetaData.Version.App.Raw, xmlio.WithBackups(backups))
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "error writing %s: %v\n", outputFile, err)
 		os.Exit(1)
@@ -5,19 +5,20 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
 func main() {
 	var showBuildInfo, showVersion, quiet bool
 	var inputFile, outputFile, debugUtf8XmlFile string
+	var backups int
 
 	// TODO: Future sprint - add flag to let user specify the data version in the copied file
 	flag.BoolVar(&showVersion, "version", false, "show version")
@@ -25,6 +26,7 @@ func main() {
 	flag.StringVar(&inputFile, "input", "", "input .wxx file (required)")
 	flag.StringVar(&outputFile, "output", "", "output .wxx file (required)")
 	flag.StringVar(&debugUtf8XmlFile, "debug-utf8", "", "write debug UTF-8 XML file alongside compressed UTF-16 .wxx file")
+	flag.IntVar(&backups, "backups", 0, "keep this many numbered backups of an output file that is replaced")
 	flag.BoolVar(&quiet, "quiet", false, "suppress output messages")
 	flag.Parse()
 
@@ -41,6 +43,7 @@ func main() {
 		_, _ = fmt.Fprintf(os.Stderr, "  -input file        input .wxx file (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -output file       output .wxx file (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -debug-utf8        write debug UTF-8 XML file alongside compressed UTF-16 .wxx file\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  -backups n         keep n numbered backups of an output file that is replaced\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -quiet             suppress output messages\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -version           show version\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -build-info        show version with build info\n")
@@ -58,13 +61,7 @@ func main() {
 	}
 
 	// Read the input file
-	fp, err := os.Open(inputFile)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "error opening %s: %v\n", inputFile, err)
-		os.Exit(2)
-	}
-	defer fp.Close()
-	input, err := xmlio.NewDecoder().Decode(fp)
+	input, err := bundle.ReadMap(inputFile)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "error reading %s: %v\n", inputFile, err)
 		os.Exit(2)
@@ -93,6 +90,7 @@ func main() {
 	dstWidth, dstHeight := rrow-lrow+1, bcol-tcol+1
 	fmt.Printf(" crop: %4d x %4d\n", dstWidth, dstHeight)
 	// allocate cells for the destination map
+	src := input.Tiles.Columns()
 	dst := make([][]*wxx.Tile_t, dstWidth)
 	for i := range dst {
 		dst[i] = make([]*wxx.Tile_t, dstHeight)
@@ -100,12 +98,14 @@ func main() {
 	// copy tiles within the crop area
 	for x := lrow; x <= rrow; x++ {
 		for y := tcol; y <= bcol; y++ {
-			dst[x-lrow][y-tcol] = input.Tiles.Tiles[x][y]
+			dst[x-lrow][y-tcol] = src[x][y]
 		}
 	}
-	// update the input
-	input.Tiles.TilesWide, input.Tiles.TilesHigh = dstWidth, dstHeight
-	input.Tiles.Tiles = dst
+	// update the input; this sets TilesWide and TilesHigh to the crop
+	if err := input.Tiles.SetColumns(dst); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "error cropping %s: %v\n", inputFile, err)
+		os.Exit(1)
+	}
 	fmt.Printf("input: %4d x %4d\n", input.Tiles.TilesWide, input.Tiles.TilesHigh)
 
 	// Write to the output file, as the application version the INPUT states.
@@ -115,18 +115,11 @@ func main() {
 	// decoder recorded and choosing to write that. A CLIENT may do that; the
 	// encoder may not do it for us, and has no default target (issue #45).
 	var encoderDiagnostics xmlio.EncoderDiagnostics
-	var encoderOptions []xmlio.EncoderOption
+	encoderOptions := []xmlio.EncoderOption{xmlio.WithBackups(backups)}
 	if debugUtf8XmlFile != "" {
 		encoderOptions = append(encoderOptions, xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
 	}
-	encoder := xmlio.NewEncoder(input.MetaData.Version.App.Raw, encoderOptions...)
-	outputBuffer := &bytes.Buffer{}
-	err = encoder.Encode(outputBuffer, input)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "error encoding %s: %v\n", outputFile, err)
-		os.Exit(1)
-	}
-	err = os.WriteFile(outputFile, outputBuffer.Bytes(), 0644)
+	err = xmlio.WriteFile(outputFile, input, input.MetaData.Version.App.Raw, encoderOptions...)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "error writing %s: %v\n", outputFile, err)
 		os.Exit(1)
@@ -4,7 +4,6 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
 	"log"
@@ -12,18 +11,21 @@ import (
 	"path/filepath"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
 func main() {
 	//var err error
 	var outputFile, debugUtf8File string
+	var backups int
 	var showBuildInfo, showVersion bool
 
 	flag.BoolVar(&showVersion, "version", false, "show version")
 	flag.BoolVar(&showBuildInfo, "build-info", false, "show version with build info")
 	flag.StringVar(&outputFile, "output", "", "name to write the resized file to")
 	flag.StringVar(&debugUtf8File, "debug-utf8", "", "optional name to write debug data to")
+	flag.IntVar(&backups, "backups", 0, "keep this many numbered backups of an output file that is replaced")
 	flag.Parse()
 
 	if showVersion {
@@ -52,6 +54,7 @@ func main() {
 		_, _ = fmt.Fprintf(os.Stderr, "usage: %s [options] input-file-name\n", os.Args[0])
 		_, _ = fmt.Fprintf(os.Stderr, "  -output     file   create .wxx file                   (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -debug-utf8 file   xcreate debug UTF-8 XML file       (optional)\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  -backups    int    backups of output to keep          (optional)\n")
 		os.Exit(2)
 	}
 
@@ -82,15 +85,10 @@ func main() {
 
 	// load the input file
 	log.Printf("%s: loading\n", inputFile)
-	fp, err := os.Open(inputFile)
-	if err != nil {
-		log.Fatalf("error: opening file: %v\n", err)
-	}
-	inputMap, err := xmlio.NewDecoder().Decode(fp)
+	inputMap, err := bundle.ReadMap(inputFile)
 	if err != nil {
 		log.Fatalf("error: loading Worldographer file: %v\n", err)
 	}
-	_ = fp.Close()
 
 	// import from the JSON files
 
@@ -104,11 +102,9 @@ func main() {
 	// provenance and names it as the target, which a CLIENT may do; the encoder may
 	// not do it for us, and has no default target (issue #45).
 	var encoderDiagnostics xmlio.EncoderDiagnostics
-	encoder := xmlio.NewEncoder(outputMap.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
-	outputBuffer := &bytes.Buffer{}
-	err = encoder.Encode(outputBuffer, outputMap)
+	err = xmlio.WriteFile(outputFile, outputMap, outputMap.MetaData.Version.App.Raw, xmlio.WithBackups(backups), xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
 	if err != nil {
-		log.Fatalf("error: encoding %s: %v\n", outputFile, err)
+		log.Fatalf("error: writing %s: %v\n", outputFile, err)
 	}
 	if debugUtf8File != "" {
 		err = os.WriteFile(debugUtf8File, encoderDiagnostics.Utf8Encoded, 0644)
@@ -117,9 +113,5 @@ func main() {
 		}
 		log.Printf("created %q\n", debugUtf8File)
 	}
-	err = os.WriteFile(outputFile, outputBuffer.Bytes(), 0644)
-	if err != nil {
-		log.Fatalf("error: writing %s: %v\n", outputFile, err)
-	}
 	log.Printf("created %q\n", outputFile)
 }
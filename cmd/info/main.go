@@ -5,36 +5,72 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"os"
 
+	"github.com/maloquacious/wxx/bundle"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
 func main() {
-	for n, arg := range os.Args {
-		if n == 0 {
-			continue
-		}
+	var decode bool
+	flag.BoolVar(&decode, "decode", false, "decode the whole map to show its codec and terrain")
+	flag.Parse()
 
+	for _, arg := range flag.Args() {
 		fmt.Printf("info:\t%s\n", arg)
-		fp, err := os.Open(arg)
-		if err != nil {
-			fmt.Printf("\t%v\n", err)
-			continue
-		}
-		defer fp.Close()
-
-		var decoderDiagnostics xmlio.DecoderDiagnostics
-		decoder := xmlio.NewDecoder(xmlio.WithDecoderDiagnostics(&decoderDiagnostics))
-		w, err := decoder.Decode(fp)
-		if err != nil {
-			fmt.Printf("\t%v\n", err)
-			continue
+		if decode {
+			showDecoded(arg)
+		} else {
+			showPeeked(arg)
 		}
-		fmt.Printf("\t%8s codec: %s\n", decoderDiagnostics.Schema, w.MetaData.Version)
-		fmt.Printf("\t%8d tiles high\n", w.Tiles.TilesHigh)
-		fmt.Printf("\t%8d tiles wide\n", w.Tiles.TilesWide)
-		fmt.Printf("\t%8d terrain tiles defined\n", len(w.TerrainMap.List))
+	}
+}
+
+// showPeeked prints what xmlio.Peek reads from the start of the map arg.
+func showPeeked(arg string) {
+	fp, err := bundle.Open(arg)
+	if err != nil {
+		fmt.Printf("\t%v\n", err)
+		return
+	}
+	defer fp.Close()
+
+	s, err := xmlio.Peek(fp)
+	if err != nil {
+		showError(arg, err)
+		return
+	}
+	fmt.Printf("\t%s\n", s.Version)
+	fmt.Printf("\t%8s orientation\n", s.HexOrientation)
+	fmt.Printf("\t%8s view level\n", s.ViewLevel)
+	fmt.Printf("\t%8d tiles high\n", s.TilesHigh)
+	fmt.Printf("\t%8d tiles wide\n", s.TilesWide)
+}
+
+// showDecoded decodes the whole map arg and prints the codec that read it and
+// the terrain it defines.
+func showDecoded(arg string) {
+	var decoderDiagnostics xmlio.DecoderDiagnostics
+	w, err := bundle.ReadMap(arg, xmlio.WithDecoderDiagnostics(&decoderDiagnostics))
+	if err != nil {
+		showError(arg, err)
+		return
+	}
+	fmt.Printf("\t%8s codec: %s\n", decoderDiagnostics.Schema, w.MetaData.Version)
+	fmt.Printf("\t%8d tiles high\n", w.Tiles.TilesHigh)
+	fmt.Printf("\t%8d tiles wide\n", w.Tiles.TilesWide)
+	fmt.Printf("\t%8d terrain tiles defined\n", len(w.TerrainMap.List))
+}
+
+// showError prints err, pointing at the place in the document the way a
+// compiler would when it has one.
+func showError(arg string, err error) {
+	var de *xmlio.DecodeError
+	if errors.As(err, &de) && de.XMLLine != 0 {
+		fmt.Printf("\t%s:%d:%d: %v\n", arg, de.XMLLine, de.XMLColumn, de.Err)
+	} else {
+		fmt.Printf("\t%v\n", err)
 	}
 }
@@ -5,7 +5,6 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
 	"log"
@@ -13,18 +12,21 @@ import (
 	"path/filepath"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
 func main() {
 	var err error
 	var outputFile, debugUtf8File string
+	var backups int
 	var showBuildInfo, showVersion bool
 
 	flag.BoolVar(&showVersion, "version", false, "show version")
 	flag.BoolVar(&showBuildInfo, "build-info", false, "show version with build info")
 	flag.StringVar(&outputFile, "output", "", "name to write the resized file to")
 	flag.StringVar(&debugUtf8File, "debug-utf8", "", "optional name to write debug data to")
+	flag.IntVar(&backups, "backups", 0, "keep this many numbered backups of an output file that is replaced")
 	flag.Parse()
 
 	if showVersion {
@@ -54,6 +56,7 @@ func main() {
 		_, _ = fmt.Fprintf(os.Stderr, "usage: %s [options] input-file-names\n", os.Args[0])
 		_, _ = fmt.Fprintf(os.Stderr, "  -output     file   create .wxx file                   (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -debug-utf8 file   xcreate debug UTF-8 XML file       (optional)\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  -backups    int    backups of output to keep          (optional)\n")
 		os.Exit(2)
 	}
 
@@ -90,16 +93,11 @@ func main() {
 	var inputMaps []*wxx.Map_t
 	for _, inputFile := range inputFiles {
 		log.Printf("%s: loading\n", inputFile)
-		fp, err := os.Open(inputFile)
-		if err != nil {
-			log.Fatalf("error: opening file: %v\n", err)
-		}
-		inputMap, err := xmlio.NewDecoder().Decode(fp)
+		inputMap, err := bundle.ReadMap(inputFile)
 		if err != nil {
 			log.Fatalf("error: loading Worldographer file: %v\n", err)
 		}
 		inputMaps = append(inputMaps, inputMap)
-		_ = fp.Close()
 	}
 
 	// all maps must have the same orientation and size and terrain map slots
@@ -150,16 +148,14 @@ func main() {
 		}
 		// merge terrain
 		for col := 0; col < inputMap.Tiles.TilesWide; col++ {
-			inputColumn := inputMap.Tiles.Tiles[col]
-			outputColumn := outputMap.Tiles.Tiles[col]
 			for row := 0; row < inputMap.Tiles.TilesHigh; row++ {
-				outputTile := outputColumn[row]
+				outputTile := outputMap.Tiles.Tile(col, row)
 				if terrainSlot[outputTile.Terrain] != "Blank" {
 					// don't overwrite non-blank tiles
 					log.Printf("%d, %d: skipping terrain %d\n", outputTile.Column, outputTile.Row, outputTile.Terrain)
 					continue
 				}
-				inputTile := inputColumn[row]
+				inputTile := inputMap.Tiles.Tile(col, row)
 				inputTerrainLabel := inputMap.TerrainMap.List[inputTile.Terrain].Label
 				slot, ok := terrainMap[inputTerrainLabel]
 				if !ok {
@@ -171,6 +167,7 @@ func main() {
 				outputTile.IsGMOnly = inputTile.IsGMOnly
 				outputTile.Resources = inputTile.Resources
 				outputTile.CustomBackgroundColor = inputTile.CustomBackgroundColor
+				outputMap.Tiles.SetTile(col, row, outputTile)
 			}
 		}
 		// merge features
@@ -195,11 +192,9 @@ func main() {
 	// preserves rather than answers: it took the first input's version before and
 	// it takes it now.
 	var encoderDiagnostics xmlio.EncoderDiagnostics
-	encoder := xmlio.NewEncoder(outputMap.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
-	outputBuffer := &bytes.Buffer{}
-	err = encoder.Encode(outputBuffer, outputMap)
+	err = xmlio.WriteFile(outputFile, outputMap, outputMap.MetaData.Version.App.Raw, xmlio.WithBackups(backups), xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
 	if err != nil {
-		log.Fatalf("error: encoding %s: %v\n", outputFile, err)
+		log.Fatalf("error: writing %s: %v\n", outputFile, err)
 	}
 	if debugUtf8File != "" {
 		err = os.WriteFile(debugUtf8File, encoderDiagnostics.Utf8Encoded, 0644)
@@ -208,9 +203,5 @@ func main() {
 		}
 		log.Printf("created %q\n", debugUtf8File)
 	}
-	err = os.WriteFile(outputFile, outputBuffer.Bytes(), 0644)
-	if err != nil {
-		log.Fatalf("error: writing %s: %v\n", outputFile, err)
-	}
 	log.Printf("created %q\n", outputFile)
 }
@@ -4,7 +4,6 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
 	"log"
@@ -12,13 +11,14 @@ import (
 	"path/filepath"
 
 	"github.com/maloquacious/wxx"
-	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/bundle"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
 func main() {
 	var err error
 	var inputFile, outputFile, debugUtf8File string
+	var backups int
 	var numberOfColumnsToAddToLeft int
 	var numberOfColumnsToAddToRight int
 	var numberOfRowsToAddToTop int
@@ -32,6 +32,7 @@ func main() {
 	flag.StringVar(&inputFile, "input", "", "name of Worldographer file to load and resize")
 	flag.StringVar(&outputFile, "output", "", "name to write the resized file to")
 	flag.StringVar(&debugUtf8File, "debug-utf8", "", "optional name to write debug data to")
+	flag.IntVar(&backups, "backups", 0, "keep this many numbered backups of an output file that is replaced")
 	flag.IntVar(&numberOfRowsToAddToTop, "top", 0, "number of rows to add to top (negative to crop)")
 	flag.IntVar(&numberOfRowsToAddToBottom, "bottom", 0, "number of rows to add to bottom (negative to crop)")
 	flag.IntVar(&numberOfColumnsToAddToLeft, "left", 0, "number of columns to add to left (negative to crop)")
@@ -70,6 +71,7 @@ func main() {
 		_, _ = fmt.Fprintf(os.Stderr, "  -input      file   load   .wxx file                   (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -output     file   create .wxx file                   (required)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -debug-utf8 file   xcreate debug UTF-8 XML file       (optional)\n")
+		_, _ = fmt.Fprintf(os.Stderr, "  -backups    int    backups of output to keep          (optional)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -top        int    number of rows    to add to top    (negative to crop)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -bottom     int    number of rows    to add to bottom (negative to crop)\n")
 		_, _ = fmt.Fprintf(os.Stderr, "  -left       int    number of columns to add to left   (negative to crop)\n")
@@ -106,20 +108,13 @@ func main() {
 	}
 
 	// load the input file
-	fp, err := os.Open(inputFile)
-	if err != nil {
-		log.Fatalf("error: opening file: %v\n", err)
-	}
-	defer func() {
-		_ = fp.Close()
-	}()
-
-	inputMap, err := xmlio.NewDecoder().Decode(fp)
+	inputMap, err := bundle.ReadMap(inputFile)
 	if err != nil {
 		log.Fatalf("error: loading Worldographer file: %v\n", err)
 	}
 	if showSizing {
-		log.Printf("input  %6d      x %6d\n", len(inputMap.Tiles.Tiles), len(inputMap.Tiles.Tiles[0]))
+		wide, high := inputMap.Tiles.Size()
+		log.Printf("input  %6d      x %6d\n", wide, high)
 	}
 
 	blankTerrainSlot, ok := inputMap.TerrainMap.Data["Blank"]
@@ -140,38 +135,33 @@ func main() {
 	if showSizing {
 		log.Printf("orientation %q\n", inputMap.HexOrientation)
 		log.Printf("map    %6d wide x %6d high\n", inputMap.Tiles.TilesWide, inputMap.Tiles.TilesHigh)
-		log.Printf("tiles  %6d      x %6d\n", len(inputMap.Tiles.Tiles), len(inputMap.Tiles.Tiles[0]))
+		wide, high := inputMap.Tiles.Size()
+		log.Printf("tiles  %6d      x %6d\n", wide, high)
 	}
 
 	// calculate the size of the resized map
 	height := inputMap.Tiles.TilesHigh + numberOfRowsToAddToTop + numberOfRowsToAddToBottom
 	width := inputMap.Tiles.TilesWide + numberOfColumnsToAddToLeft + numberOfColumnsToAddToRight
 	// allocate a new Tiles_t to hold the resized map
-	outputTiles := &wxx.Tiles_t{
-		ViewLevel: inputMap.Tiles.ViewLevel,
-		TilesHigh: height,
-		TilesWide: width,
-		Tiles:     make([][]*wxx.Tile_t, width),
-	}
-
 	// we can't make a tiny map
 	if height < 2 || width < 2 {
 		log.Fatalf("error: we can't create a map smaller than 2 x 2\n")
 	}
 
-	// fill it with blank tiles that have the new coordinates
+	// allocate a new Tiles_t to hold the resized map. the tiles work out their
+	// new coordinates from their position in it.
+	outputTiles := wxx.NewTiles(width, height, inputMap.Tiles.Orientation)
+	outputTiles.ViewLevel = inputMap.Tiles.ViewLevel
+
+	// fill it with blank tiles
 	for col := 0; col < width; col++ {
-		outputTiles.Tiles[col] = make([]*wxx.Tile_t, height)
 		for row := 0; row < height; row++ {
-			tile := &wxx.Tile_t{
-				Terrain: blankTerrainSlot,
-				Coords:  hexg.NewOddQCoord(col, row).ToCube(),
-			}
-			outputTiles.Tiles[col][row] = tile
+			outputTiles.SetTile(col, row, wxx.Tile_t{Terrain: blankTerrainSlot})
 		}
 	}
 	if showSizing {
-		log.Printf("output %6d      x %6d\n", len(outputTiles.Tiles), len(outputTiles.Tiles[0]))
+		wide, high := outputTiles.Size()
+		log.Printf("output %6d      x %6d\n", wide, high)
 	}
 
 	// determine the source region to copy from the input map
@@ -196,22 +186,20 @@ func main() {
 
 	// copy tiles from input to output
 	for col := startCol; col < endCol; col++ {
-		inputColumn := inputMap.Tiles.Tiles[col]
 		// calculate output column position: subtract start offset, add any left padding
 		outputCol := col - startCol
 		if numberOfColumnsToAddToLeft > 0 {
 			outputCol += numberOfColumnsToAddToLeft
 		}
-		outputColumn := outputTiles.Tiles[outputCol]
 
 		for row := startRow; row < endRow; row++ {
-			inputTile := inputColumn[row]
+			inputTile := inputMap.Tiles.Tile(col, row)
 			// calculate output row position: subtract start offset, add any top padding
 			outputRow := row - startRow
 			if numberOfRowsToAddToTop > 0 {
 				outputRow += numberOfRowsToAddToTop
 			}
-			outputTile := outputColumn[outputRow]
+			outputTile := outputTiles.Tile(outputCol, outputRow)
 
 			// copy tile attributes
 			outputTile.Terrain = inputTile.Terrain
@@ -219,6 +207,7 @@ func main() {
 			outputTile.IsIcy = inputTile.IsIcy
 			outputTile.IsGMOnly = inputTile.IsGMOnly
 			outputTile.Resources = inputTile.Resources
+			outputTiles.SetTile(outputCol, outputRow, outputTile)
 		}
 	}
 
@@ -277,11 +266,9 @@ func main() {
 	// decoder recorded and choosing to write that. A CLIENT may do that; the
 	// encoder may not do it for us, and has no default target (issue #45).
 	var encoderDiagnostics xmlio.EncoderDiagnostics
-	encoder := xmlio.NewEncoder(inputMap.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
-	outputBuffer := &bytes.Buffer{}
-	err = encoder.Encode(outputBuffer, inputMap)
+	err = xmlio.WriteFile(outputFile, inputMap, inputMap.MetaData.Version.App.Raw, xmlio.WithBackups(backups), xmlio.WithEncoderDiagnostics(&encoderDiagnostics))
 	if err != nil {
-		log.Fatalf("error: encoding %s: %v\n", outputFile, err)
+		log.Fatalf("error: writing %s: %v\n", outputFile, err)
 	}
 	if debugUtf8File != "" {
 		err = os.WriteFile(debugUtf8File, encoderDiagnostics.Utf8Encoded, 0644)
@@ -289,10 +276,6 @@ func main() {
 			log.Fatalf("error: writing %s: %v\n", debugUtf8File, err)
 		}
 	}
-	err = os.WriteFile(outputFile, outputBuffer.Bytes(), 0644)
-	if err != nil {
-		log.Fatalf("error: writing %s: %v\n", outputFile, err)
-	}
 
 	log.Printf("%s: resized to %s\n", inputFile, outputFile)
 }
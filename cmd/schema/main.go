@@ -11,6 +11,7 @@ import (
 	"flag"
 	"fmt"
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 	"io"
@@ -35,21 +36,28 @@ func main() {
 			fmt.Printf("\tnot a '.wxx' file\n")
 			continue
 		}
-		sb, err := os.Stat(arg)
+		fp, err := bundle.Open(arg)
 		if err != nil {
 			if os.IsNotExist(err) {
 				fmt.Printf("\tdoes not exist\n")
 			} else {
-				fmt.Printf("\tunable to stat\n")
+				fmt.Printf("\tunable to open\n")
 			}
 			continue
+		}
+		sb, err := fp.Stat()
+		if err != nil {
+			fmt.Printf("\tunable to stat\n")
+			_ = fp.Close()
+			continue
 		} else if sb.IsDir() {
 			fmt.Printf("\tis a folder\n")
 		} else if !sb.Mode().IsRegular() {
 			fmt.Printf("\tis not a file\n")
 		}
 		fmt.Printf("\t%8d bytes on disk\n", sb.Size())
-		input, err := os.ReadFile(arg)
+		input, err := io.ReadAll(fp)
+		_ = fp.Close()
 		if err != nil {
 			fmt.Printf("\tfailed to read\n")
 		}
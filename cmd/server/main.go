@@ -4,17 +4,18 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"html/template"
-	"math"
+	"log/slog"
 	"net/http"
 	"os"
-	"strings"
 	"time"
 
 	"github.com/maloquacious/wxx"
-	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/bundle"
+	"github.com/maloquacious/wxx/render/svg"
 	"github.com/maloquacious/wxx/xmlio"
 )
 
@@ -92,8 +93,8 @@ var htmlTemplate = `<!DOCTYPE html>
     </section>
     
     <section>
-        <h2>Hex Grid Preview (First 5x5)</h2>
-        <img src="/hex-grid.svg" alt="Hex Grid Preview" style="max-width: 100%; height: auto;">
+        <h2>Map</h2>
+        <img src="/map.svg" alt="Map" style="max-width: 100%; height: auto;">
     </section>
 </body>
 </html>`
@@ -102,6 +103,7 @@ func main() {
 	var host = flag.String("host", "localhost", "host to bind to")
 	var port = flag.String("port", "8081", "port to listen on")
 	var timeout = flag.Duration("timeout", 0, "automatically shutdown after this duration (e.g. 30s, 5m, 1h)")
+	var trace = flag.Bool("trace", false, "log the size and duration of each stage of loading the file")
 	flag.Parse()
 
 	if flag.NArg() != 1 {
@@ -113,15 +115,14 @@ func main() {
 
 	filename := flag.Arg(0)
 
-	// Load the Worldographer file
-	fp, err := os.Open(filename)
-	if err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "error opening file: %v\n", err)
-		os.Exit(1)
+	// Load the Worldographer file, which may be in a bundle. ReadMap limits the
+	// size of the map it will build, since the file may have come from anyone.
+	var opts []xmlio.DecoderOption
+	if *trace {
+		opts = append(opts, xmlio.WithDecoderTracer(xmlio.NewSlogTracer(slog.Default(), slog.LevelInfo)))
 	}
-	defer fp.Close()
-
-	worldMap, err = xmlio.NewDecoder().Decode(fp)
+	var err error
+	worldMap, err = bundle.ReadMap(filename, opts...)
 	if err != nil {
 		_, _ = fmt.Fprintf(os.Stderr, "error loading Worldographer file: %v\n", err)
 		os.Exit(1)
@@ -129,7 +130,7 @@ func main() {
 
 	// Setup HTTP server
 	http.HandleFunc("/", handleRoot)
-	http.HandleFunc("/hex-grid.svg", handleHexGridSVG)
+	http.HandleFunc("/map.svg", handleMapSVG)
 	http.HandleFunc("/shutdown", handleShutdown)
 	http.HandleFunc("/ping", handlePing)
 
@@ -196,10 +197,14 @@ func handleRoot(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func handleHexGridSVG(w http.ResponseWriter, r *http.Request) {
-	svg := generateHexGridSVG(worldMap)
+func handleMapSVG(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := svg.Render(&buf, worldMap); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "image/svg+xml")
-	_, _ = w.Write([]byte(svg))
+	_, _ = w.Write(buf.Bytes())
 }
 
 func handleShutdown(w http.ResponseWriter, r *http.Request) {
@@ -214,109 +219,3 @@ func handlePing(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain")
 	_, _ = w.Write([]byte("pong\n"))
 }
-
-func generateHexGridSVG(m *wxx.Map_t) string {
-	if m.Tiles == nil || len(m.Tiles.Tiles) == 0 {
-		return `<svg><text x="10" y="20">No tiles available</text></svg>`
-	}
-
-	// Limit to first 5 rows and columns
-	maxRows := 5
-	maxCols := 5
-	if m.RowsHigh < maxRows {
-		maxRows = m.RowsHigh
-	}
-	if m.ColumnsWide < maxCols {
-		maxCols = m.ColumnsWide
-	}
-
-	// Calculate hex layout parameters
-	hexWidth := m.HexWidth
-	hexHeight := m.HexHeight
-
-	// Calculate SVG dimensions with some padding
-	padding := 50.0
-	svgWidth := float64(maxCols)*hexWidth + padding*2
-	svgHeight := float64(maxRows)*hexHeight + padding*2
-
-	var svg strings.Builder
-	svg.WriteString(fmt.Sprintf(`<svg width="%.1f" height="%.1f" xmlns="http://www.w3.org/2000/svg">`, svgWidth, svgHeight))
-	svg.WriteString(`<style>
-	.hex { fill: none; stroke: #333; stroke-width: 1; }
-	.label { text-anchor: middle; font-family: monospace; font-size: 10px; fill: #000; }
-	</style>`)
-
-	// Determine if we have flat-top or pointy-top hexes
-	isFlat := m.GridOrientation == hexg.EvenQ || m.GridOrientation == hexg.OddQ
-
-	// Draw hexes
-	for row := 0; row < maxRows && row < len(m.Tiles.Tiles); row++ {
-		tileRow := m.Tiles.Tiles[row]
-		for col := 0; col < maxCols && col < len(tileRow); col++ {
-			if tile := tileRow[col]; tile != nil {
-				hexPoints := generateHexPath(row, col, hexWidth, hexHeight, isFlat, padding)
-				svg.WriteString(fmt.Sprintf(`<polygon class="hex" points="%s"/>`, hexPoints))
-
-				// Calculate center for label
-				centerX, centerY := calculateHexCenter(row, col, hexWidth, hexHeight, isFlat, padding)
-
-				// Generate labels: row,col on first line, cube coords on second line
-				cubeCoords := tile.Coords.String()
-				svg.WriteString(fmt.Sprintf(
-					`<text class="label" x="%.1f" y="%.1f">%d,%d</text>`,
-					centerX, centerY-5, row, col))
-				svg.WriteString(fmt.Sprintf(
-					`<text class="label" x="%.1f" y="%.1f">%s</text>`,
-					centerX, centerY+5, cubeCoords))
-			}
-		}
-	}
-
-	svg.WriteString(`</svg>`)
-	return svg.String()
-}
-
-func generateHexPath(row, col int, hexWidth, hexHeight float64, isFlat bool, padding float64) string {
-	centerX, centerY := calculateHexCenter(row, col, hexWidth, hexHeight, isFlat, padding)
-
-	var points []string
-	for i := 0; i < 6; i++ {
-		x, y := getHexCorner(centerX, centerY, hexWidth/2, hexHeight/2, i, isFlat)
-		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
-	}
-	return strings.Join(points, " ")
-}
-
-func calculateHexCenter(row, col int, hexWidth, hexHeight float64, isFlat bool, padding float64) (float64, float64) {
-	// Calculate position based on hex grid layout
-	var x, y float64
-
-	if isFlat { // flat-topped
-		x = hexWidth * 0.75 * float64(col)
-		y = hexHeight * (float64(row) + 0.5*float64(col%2))
-	} else { // pointy-topped
-		x = hexWidth * (float64(col) + 0.5*float64(row%2))
-		y = hexHeight * 0.75 * float64(row)
-	}
-
-	return x + padding, y + padding
-}
-
-func getHexCorner(centerX, centerY, radiusX, radiusY float64, corner int, isFlat bool) (float64, float64) {
-	// Calculate corner positions for hexagon
-	// For flat-top hexes, first corner is at top-right
-	// For pointy-top hexes, first corner is at top
-
-	var angleDeg float64
-	if isFlat {
-		angleDeg = 60.0*float64(corner) + 30.0 // Flat-top hex starts at 30 degrees
-	} else {
-		angleDeg = 60.0 * float64(corner) // Pointy-top hex starts at 0 degrees
-	}
-
-	angleRad := angleDeg * math.Pi / 180.0
-	x := centerX + radiusX*math.Cos(angleRad)
-	y := centerY + radiusY*math.Sin(angleRad)
-
-	return x, y
-}
@@ -0,0 +1,45 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maloquacious/wxx/bundle"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// newBundleCommand returns the `wxx bundle` subcommand.
+//
+// `wxx bundle -o <bundle> <wxx-file>...` writes a campaign bundle: a zip
+// holding each map, byte for byte under its base name, the files the maps'
+// notes link to, and a manifest listing them. A linked file is looked for as
+// the note names it and then beside its map; the bundle is not written if one
+// is in neither place.
+//
+// Every tool reads a map from a bundle, named campaign.zip#world.wxx, or by the
+// bundle's path alone when it holds one map.
+//
+// Required flags:
+//
+//	-o, --output   the bundle to write.
+func newBundleCommand(parent *ff.FlagSet) *ff.Command {
+	fs := ff.NewFlagSet("bundle").SetParent(parent)
+	output := fs.String('o', "output", "", "write the bundle to this file")
+
+	return &ff.Command{
+		Name:      "bundle",
+		Usage:     "wxx bundle -o <bundle> <wxx-file>...",
+		ShortHelp: "write Worldographer WXX files and the files their notes link to into a bundle",
+		Flags:     fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if *output == "" {
+				return fmt.Errorf("bundle: missing required --output flag")
+			} else if len(args) == 0 {
+				return fmt.Errorf("bundle: missing required <wxx-file> argument")
+			}
+			return bundle.Create(*output, args...)
+		},
+	}
+}
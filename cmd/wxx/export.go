@@ -10,8 +10,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
+	"github.com/maloquacious/wxx/geojson"
+	"github.com/maloquacious/wxx/jsonio"
+	"github.com/maloquacious/wxx/sqldump"
 	"github.com/peterbourgon/ff/v4"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
@@ -32,10 +37,28 @@ import (
 //
 //		--xml <file>     write the XML payload to <file> with original encoding
 //	                  preserved.
+//
+//		--json <file>    decode the map and write it to <file> as JSON, in the
+//		                 layout package jsonio documents. `wxx import --json`
+//		                 reads it back.
+//
+//		--sql <file>     decode the map and write it to <file> as SQL: the
+//		                 tables of package sqldump and the INSERTs that load
+//		                 the map into them, named by the input's base name.
+//
+//		--geojson <file> decode the map and write it to <file> as a GeoJSON
+//		                 FeatureCollection of its tiles, features, labels,
+//		                 notes and shapes.
+//
+// The payload exports need a .wxx file. The others decode the map as
+// xmlio.ReadFile does, so they read plain XML as readily.
 func newExportCommand(parent *ff.FlagSet) *ff.Command {
 	fs := ff.NewFlagSet("export").SetParent(parent)
 	rawOut := fs.String('r', "raw", "", "write the uncompress payload to this file with original encoding")
 	utf8Out := fs.String('u', "utf-8", "", "write the UTF-8 bytes of the XML payload to this file")
+	jsonOut := fs.StringLong("json", "", "write the map as JSON to this file")
+	sqlOut := fs.StringLong("sql", "", "write the map as SQL to this file")
+	geojsonOut := fs.StringLong("geojson", "", "write the map as GeoJSON to this file")
 
 	return &ff.Command{
 		Name:      "export",
@@ -51,30 +74,27 @@ func newExportCommand(parent *ff.FlagSet) *ff.Command {
 			default:
 				return fmt.Errorf("export: expected exactly one <wxx-file> argument, got %d", len(args))
 			}
-			return runExport(args[0], *rawOut, *utf8Out)
+			return runExport(args[0], *rawOut, *utf8Out, *jsonOut, *sqlOut, *geojsonOut)
 		},
 	}
 }
 
-func runExport(inputPath, rawContentOut, utf8Out string) error {
-	// 1. Read the file and verify gzip magic.
-	data, err := os.ReadFile(inputPath)
-	if err != nil {
-		return errors.Join(wxx.ErrRawReadFailed, fmt.Errorf("read %s: %w", inputPath, err))
-	}
-	if !(len(data) >= 2 && data[0] == 0x1F && data[1] == 0x8B) {
-		return fmt.Errorf("%s: %w", inputPath, wxx.ErrNotCompressed)
-	}
-
-	// 2. Uncompress.
-	gzr, err := gzip.NewReader(bytes.NewReader(data))
-	if err != nil {
-		return errors.Join(wxx.ErrGZipNewReaderFailed, fmt.Errorf("%s: %w", inputPath, err))
+func runExport(inputPath, rawContentOut, utf8Out, jsonOut, sqlOut, geojsonOut string) error {
+	// the raw and UTF-8 exports are of the payload of a .wxx file
+	var data []byte
+	if rawContentOut != "" || utf8Out != "" {
+		var err error
+		if data, err = readPayload(inputPath); err != nil {
+			return err
+		}
 	}
-	defer func() { _ = gzr.Close() }()
-	data, err = io.ReadAll(gzr)
-	if err != nil {
-		return errors.Join(wxx.ErrGUnZipFailed, fmt.Errorf("%s: %w", inputPath, err))
+	// the others decode the map, from whatever container holds it
+	var m *wxx.Map_t
+	if jsonOut != "" || sqlOut != "" || geojsonOut != "" {
+		var err error
+		if m, err = bundle.ReadMap(inputPath); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
 	}
 
 	createdFiles := 0
@@ -92,12 +112,58 @@ func runExport(inputPath, rawContentOut, utf8Out string) error {
 		createdFiles++
 	}
 
+	if jsonOut != "" {
+		if err := exportJSON(m, jsonOut); err != nil {
+			return fmt.Errorf("%s: %w", inputPath, err)
+		}
+		createdFiles++
+	}
+
+	if sqlOut != "" {
+		if err := exportSQL(m, filepath.Base(inputPath), sqlOut); err != nil {
+			return fmt.Errorf("%s: %w", inputPath, err)
+		}
+		createdFiles++
+	}
+
+	if geojsonOut != "" {
+		if err := exportGeoJSON(m, geojsonOut); err != nil {
+			return fmt.Errorf("%s: %w", inputPath, err)
+		}
+		createdFiles++
+	}
+
 	if createdFiles == 0 {
 		return fmt.Errorf("export: nothing to do; pass an output file format on the command line")
 	}
 	return nil
 }
 
+// readPayload reads the Worldographer file at inputPath, which may be in a
+// bundle, and returns its uncompressed payload.
+func readPayload(inputPath string) ([]byte, error) {
+	// 1. Read the file and verify gzip magic.
+	data, err := bundle.ReadFile(inputPath)
+	if err != nil {
+		return nil, errors.Join(wxx.ErrRawReadFailed, fmt.Errorf("read %s: %w", inputPath, err))
+	}
+	if !(len(data) >= 2 && data[0] == 0x1F && data[1] == 0x8B) {
+		return nil, fmt.Errorf("%s: %w", inputPath, wxx.ErrNotCompressed)
+	}
+
+	// 2. Uncompress.
+	gzr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Join(wxx.ErrGZipNewReaderFailed, fmt.Errorf("%s: %w", inputPath, err))
+	}
+	defer func() { _ = gzr.Close() }()
+	data, err = io.ReadAll(gzr)
+	if err != nil {
+		return nil, errors.Join(wxx.ErrGUnZipFailed, fmt.Errorf("%s: %w", inputPath, err))
+	}
+	return data, nil
+}
+
 // exportRawContent writes the raw content from a Worldographer file to
 // outputPath.
 func exportRawContent(data []byte, outputPath string) error {
@@ -109,6 +175,45 @@ func exportRawContent(data []byte, outputPath string) error {
 	return nil
 }
 
+// exportJSON writes the map m to outputPath as JSON.
+func exportJSON(m *wxx.Map_t, outputPath string) error {
+	out, err := jsonio.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, out, 0o644); err != nil {
+		return fmt.Errorf("export: write %s: %w", outputPath, err)
+	}
+	fmt.Printf("export: wrote JSON to %s (%d bytes)\n", outputPath, len(out))
+	return nil
+}
+
+// exportSQL writes the map m to outputPath as SQL, under the name name.
+func exportSQL(m *wxx.Map_t, name, outputPath string) error {
+	var out bytes.Buffer
+	if err := sqldump.Write(&out, m, name); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("export: write %s: %w", outputPath, err)
+	}
+	fmt.Printf("export: wrote SQL to %s (%d bytes)\n", outputPath, out.Len())
+	return nil
+}
+
+// exportGeoJSON writes the map m to outputPath as GeoJSON.
+func exportGeoJSON(m *wxx.Map_t, outputPath string) error {
+	out, err := geojson.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, out, 0o644); err != nil {
+		return fmt.Errorf("export: write %s: %w", outputPath, err)
+	}
+	fmt.Printf("export: wrote GeoJSON to %s (%d bytes)\n", outputPath, len(out))
+	return nil
+}
+
 // exportUTF8 converts the content of a Worldographer file from UTF16/BE
 // to UTF8 and writes it to outputPath.
 //
@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/maloquacious/wxx/jsonio"
+	"github.com/maloquacious/wxx/xmlio"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// newImportCommand returns the `wxx import` subcommand.
+//
+// `wxx import --json <json-file> -o <wxx-file>` reads a map written by
+// `wxx export --json` and writes it as a Worldographer file. The map is encoded
+// to the application version it states unless --app names another.
+//
+// Required flags:
+//
+//	--json <file>   the JSON map to read.
+//	-o, --output    the Worldographer file to write.
+//
+// Optional flags:
+//
+//	--app <version>   the application version to write, "1.77" or "2.06".
+func newImportCommand(parent *ff.FlagSet) *ff.Command {
+	fs := ff.NewFlagSet("import").SetParent(parent)
+	jsonIn := fs.StringLong("json", "", "read the map from this JSON file")
+	output := fs.String('o', "output", "", "write the Worldographer file to this file")
+	app := fs.StringLong("app", "", "write this application version instead of the map's")
+
+	return &ff.Command{
+		Name:      "import",
+		Usage:     "wxx import --json <json-file> -o <wxx-file>",
+		ShortHelp: "write a Worldographer WXX file from a map exported as JSON",
+		Flags:     fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if *jsonIn == "" {
+				return fmt.Errorf("import: missing required --json flag")
+			} else if *output == "" {
+				return fmt.Errorf("import: missing required --output flag")
+			} else if len(args) != 0 {
+				return fmt.Errorf("import: unexpected argument %q", args[0])
+			}
+			return runImport(ctx, *jsonIn, *output, *app)
+		},
+	}
+}
+
+func runImport(ctx context.Context, jsonIn, output, app string) error {
+	data, err := os.ReadFile(jsonIn)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	m, err := jsonio.Unmarshal(data)
+	if err != nil {
+		return fmt.Errorf("import: %s: %w", jsonIn, err)
+	}
+	if app == "" {
+		app = m.MetaData.Version.App.Raw
+	}
+	if err := xmlio.WriteFileContext(ctx, output, m, app); err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	fmt.Printf("import: wrote %s as %s\n", output, app)
+	return nil
+}
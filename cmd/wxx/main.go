@@ -9,7 +9,12 @@
 //
 // Subcommands:
 //
+//	bundle   write Worldographer WXX files and the files their notes link to into a bundle
 //	export   export content from a Worldographer WXX file
+//	import   write a Worldographer WXX file from a map exported as JSON
+//	render   draw a Worldographer WXX file as an image
+//	schema   write the schema of the JSON form of a map
+//	verify   check that a Worldographer WXX file survives decode and encode unchanged
 package main
 
 import (
@@ -46,7 +51,7 @@ func newRootCommand() *ff.Command {
 		ShortHelp: "tools for working with Worldographer WXX files",
 		Flags:     rootFlags,
 	}
-	rootCmd.Subcommands = append(rootCmd.Subcommands, newExportCommand(rootFlags))
+	rootCmd.Subcommands = append(rootCmd.Subcommands, newBundleCommand(rootFlags), newExportCommand(rootFlags), newImportCommand(rootFlags), newRenderCommand(rootFlags), newSchemaCommand(rootFlags), newVerifyCommand(rootFlags))
 	return rootCmd
 }
 
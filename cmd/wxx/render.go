@@ -0,0 +1,101 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/maloquacious/wxx/bundle"
+	"github.com/maloquacious/wxx/render/svg"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// newRenderCommand returns the `wxx render` subcommand.
+//
+// `wxx render --svg <file> <wxx-file>` reads a Worldographer file, which may be
+// in a bundle, and draws the whole map as an SVG image: the tiles, the terrain
+// on map layers, the shapes, features, labels and the grid, on the layers the
+// map shows. The grid and its numbers are drawn as the map's settings say
+// unless --grid or --numbers says otherwise.
+//
+// Required flags:
+//
+//	--svg <file>   write the image to <file>.
+//
+// Optional flags:
+//
+//	--gm                        draw what the map marks GM-only.
+//	--grid, --numbers           draw the grid and its numbers, or not with =false.
+//	--scale <n>                 pixels per map unit, 1 by default.
+//	--terrain-color <name=color>
+//	                            fill the terrain called name with color, any SVG
+//	                            color; repeat for each terrain.
+func newRenderCommand(parent *ff.FlagSet) *ff.Command {
+	fs := ff.NewFlagSet("render").SetParent(parent)
+	svgOut := fs.StringLong("svg", "", "draw the map as SVG to this file")
+	gmOnly := fs.BoolLong("gm", "draw what the map marks GM-only")
+	grid := fs.BoolLong("grid", "draw the hex grid, overriding the map's setting")
+	numbers := fs.BoolLong("numbers", "number the tiles, overriding the map's setting")
+	scale := fs.Float64Long("scale", 1, "pixels per map unit")
+	terrainColors := fs.StringListLong("terrain-color", "fill a terrain with a color, as name=color")
+
+	return &ff.Command{
+		Name:      "render",
+		Usage:     "wxx render --svg <file> [flags] <wxx-file>",
+		ShortHelp: "draw a Worldographer WXX file as an image",
+		Flags:     fs,
+		Exec: func(ctx context.Context, args []string) error {
+			switch len(args) {
+			case 0:
+				return fmt.Errorf("render: missing required <wxx-file> argument")
+			case 1:
+				// ok
+			default:
+				return fmt.Errorf("render: expected exactly one <wxx-file> argument, got %d", len(args))
+			}
+			if *svgOut == "" {
+				return fmt.Errorf("render: nothing to do; pass --svg")
+			}
+
+			opts := []svg.Option{svg.WithGMOnly(*gmOnly), svg.WithScale(*scale)}
+			if f, ok := fs.GetFlag("grid"); ok && f.IsSet() {
+				opts = append(opts, svg.WithGrid(*grid))
+			}
+			if f, ok := fs.GetFlag("numbers"); ok && f.IsSet() {
+				opts = append(opts, svg.WithGridNumbers(*numbers))
+			}
+			if len(*terrainColors) != 0 {
+				colors := map[string]string{}
+				for _, tc := range *terrainColors {
+					name, color, ok := strings.Cut(tc, "=")
+					if !ok || name == "" || color == "" {
+						return fmt.Errorf("render: --terrain-color %q: want name=color", tc)
+					}
+					colors[name] = color
+				}
+				opts = append(opts, svg.WithTerrainColors(colors))
+			}
+			return runRender(args[0], *svgOut, opts...)
+		},
+	}
+}
+
+func runRender(inputPath, svgOut string, opts ...svg.Option) error {
+	m, err := bundle.ReadMap(inputPath)
+	if err != nil {
+		return fmt.Errorf("render: %w", err)
+	}
+	var out bytes.Buffer
+	if err := svg.Render(&out, m, opts...); err != nil {
+		return fmt.Errorf("render: %s: %w", inputPath, err)
+	}
+	if err := os.WriteFile(svgOut, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("render: write %s: %w", svgOut, err)
+	}
+	fmt.Printf("render: wrote SVG to %s (%d bytes)\n", svgOut, out.Len())
+	return nil
+}
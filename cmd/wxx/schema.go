@@ -0,0 +1,54 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/maloquacious/wxx/jsonio"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// newSchemaCommand returns the `wxx schema` subcommand.
+//
+// `wxx schema --json-schema` writes the JSON Schema of the maps that
+// `wxx export --json` writes, generated from the Go types. The published copy is
+// schema/wxx-json-v1.schema.json.
+//
+// Optional flags:
+//
+//	-o, --output   write the schema to this file instead of standard output.
+func newSchemaCommand(parent *ff.FlagSet) *ff.Command {
+	fs := ff.NewFlagSet("schema").SetParent(parent)
+	jsonSchema := fs.BoolLong("json-schema", "write the JSON Schema of the JSON form of a map")
+	output := fs.String('o', "output", "", "write the schema to this file")
+
+	return &ff.Command{
+		Name:      "schema",
+		Usage:     "wxx schema --json-schema [-o <file>]",
+		ShortHelp: "write the schema of the JSON form of a map",
+		Flags:     fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if !*jsonSchema {
+				return fmt.Errorf("schema: nothing to do; pass --json-schema")
+			} else if len(args) != 0 {
+				return fmt.Errorf("schema: unexpected argument %q", args[0])
+			}
+			data, err := jsonio.Schema()
+			if err != nil {
+				return err
+			}
+			data = append(data, '\n')
+			if *output == "" {
+				_, err = os.Stdout.Write(data)
+				return err
+			}
+			if err := os.WriteFile(*output, data, 0o644); err != nil {
+				return fmt.Errorf("schema: write %s: %w", *output, err)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/bundle"
+	"github.com/maloquacious/wxx/xmlio"
+	"github.com/peterbourgon/ff/v4"
+)
+
+// newVerifyCommand returns the `wxx verify` subcommand.
+//
+// `wxx verify <wxx-file>` decodes a Worldographer file with
+// xmlio.WithPreserveFormatting, encodes it again to the release it states, and
+// reports whether the XML came back byte for byte. When it did not, it reports
+// where the first difference is and exits with an error.
+//
+// The XML document is compared, declaration included, after the gzip and
+// UTF-16 layers are taken off. Those layers are not compared: two gzip streams
+// of the same bytes need not be the same bytes. The declaration of a file that
+// was not UTF-16 is not compared either, since it names an encoding the encoder
+// does not write.
+//
+// It also lists what the codec did not understand: a schema newer than the
+// codec that read the file, and each attribute or element it has no field for.
+// Those are kept and written back, so they do not fail the verify.
+//
+// Optional flags:
+//
+//	--canonical   encode without the file's formatting, to see where the
+//	              encoder's own spelling first differs from the file's.
+func newVerifyCommand(parent *ff.FlagSet) *ff.Command {
+	fs := ff.NewFlagSet("verify").SetParent(parent)
+	canonical := fs.BoolLong("canonical", "compare the encoder's own spelling, without the file's formatting")
+
+	return &ff.Command{
+		Name:      "verify",
+		Usage:     "wxx verify [flags] <wxx-file>",
+		ShortHelp: "check that a Worldographer WXX file survives decode and encode unchanged",
+		Flags:     fs,
+		Exec: func(ctx context.Context, args []string) error {
+			switch len(args) {
+			case 0:
+				return fmt.Errorf("verify: missing required <wxx-file> argument")
+			case 1:
+				// ok
+			default:
+				return fmt.Errorf("verify: expected exactly one <wxx-file> argument, got %d", len(args))
+			}
+			return runVerify(args[0], *canonical)
+		},
+	}
+}
+
+func runVerify(inputPath string, canonical bool) error {
+	data, err := bundle.ReadFile(inputPath)
+	if err != nil {
+		return errors.Join(wxx.ErrRawReadFailed, fmt.Errorf("read %s: %w", inputPath, err))
+	}
+
+	var dd xmlio.DecoderDiagnostics
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithPreserveFormatting(), xmlio.WithDecoderDiagnostics(&dd)).Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("verify: %s: %w", inputPath, err)
+	}
+	if dd.FallbackSchema != "" {
+		fmt.Printf("verify: %s: schema %s is newer than any codec; read as schema %s\n", inputPath, m.MetaData.Worldographer.Schema, dd.FallbackSchema)
+	}
+	for _, u := range dd.UnknownContent {
+		fmt.Printf("verify: %s: unknown content: %s\n", inputPath, u)
+	}
+	if canonical {
+		m.Formatting = nil
+	}
+	var ed xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&ed)).Encode(&bytes.Buffer{}, m); err != nil {
+		return fmt.Errorf("verify: %s: %w", inputPath, err)
+	}
+
+	// skipped counts the lines of the declaration when it is not compared, so
+	// that line numbers are still the file's
+	read, written, skipped := dd.XMLData, ed.Utf8Encoded, bytes.Count(dd.XMLHeader, []byte{'\n'})
+	if dd.Encoding != "UTF-8" {
+		read = append(append([]byte{}, dd.XMLHeader...), dd.XMLData...)
+		written, skipped = ed.WithXmlHeader, 0
+	}
+	if bytes.Equal(read, written) {
+		fmt.Printf("verify: %s: ok, %d bytes of XML round-trip unchanged\n", inputPath, len(read))
+		return nil
+	}
+
+	at := 0
+	for at < len(read) && at < len(written) && read[at] == written[at] {
+		at++
+	}
+	line, column := position(read, at)
+	fmt.Printf("verify: %s: differs at line %d, column %d (byte %d of the XML)\n", inputPath, line+skipped, column, at)
+	fmt.Printf("  file:    %q\n", lineAt(read, at))
+	fmt.Printf("  encoder: %q\n", lineAt(written, at))
+	return fmt.Errorf("verify: %s: %w", inputPath, wxx.ErrRoundTripChanged)
+}
+
+// position returns the 1-based line and column of the byte at offset in doc,
+// counting columns in characters.
+func position(doc []byte, offset int) (line, column int) {
+	start := bytes.LastIndexByte(doc[:offset], '\n') + 1
+	return bytes.Count(doc[:offset], []byte{'\n'}) + 1, utf8.RuneCount(doc[start:offset]) + 1
+}
+
+// lineAt returns the line of doc that holds the byte at offset, cut down to
+// the 40 bytes either side of it.
+func lineAt(doc []byte, offset int) string {
+	offset = min(offset, len(doc))
+	start := bytes.LastIndexByte(doc[:offset], '\n') + 1
+	end := len(doc)
+	if i := bytes.IndexByte(doc[offset:], '\n'); i != -1 {
+		end = offset + i
+	}
+	start, end = max(start, offset-40), min(end, offset+40)
+	return string(doc[start:end])
+}
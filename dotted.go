@@ -18,9 +18,9 @@ import (
 // Dotted keeps the bytes it was given and parses the components only so that
 // two versions can be ordered.
 type Dotted struct {
-	Raw   string // verbatim, exactly as read or to be written
-	Major int
-	Minor int
+	Raw   string `json:"raw"` // verbatim, exactly as read or to be written
+	Major int    `json:"major"`
+	Minor int    `json:"minor"`
 }
 
 // ParseDotted parses an on-disk dotted version such as "2.06" or "1.73".
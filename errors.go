@@ -12,10 +12,14 @@ func (e Error) Error() string {
 
 const (
 	ErrAmbiguousAppCodec           = Error("ambiguous application version codec")
+	ErrCompressedTooLarge          = Error("compressed input too large")
+	ErrDecompressedTooLarge        = Error("decompressed input too large")
 	ErrFSError                     = Error("file-system")
 	ErrGUnZipFailed                = Error("gunzip failed")
 	ErrGZipFailed                  = Error("gzip failed")
 	ErrGZipNewReaderFailed         = Error("gzip new reader failed")
+	ErrInvalidBundle               = Error("invalid bundle")
+	ErrInvalidBundleEntry          = Error("invalid bundle entry")
 	ErrInvalidCodecDeclaration     = Error("invalid codec declaration")
 	ErrInvalidDottedComponent      = Error("invalid dotted version component")
 	ErrInvalidDottedComponentCount = Error("invalid dotted version component count")
@@ -23,20 +27,32 @@ const (
 	ErrInvalidEncodingHeader       = Error("invalid encoding header")
 	ErrInvalidGZip                 = Error("invalid gzip")
 	ErrInvalidHexOrientation       = Error("invalid hex orientation")
+	ErrInvalidJSONMap              = Error("invalid json map")
 	ErrInvalidMapMetadata          = Error("invalid <map> metadata")
+	ErrInvalidMapProjection        = Error("invalid map projection")
+	ErrInvalidRGBA                 = Error("invalid rgba")
+	ErrInvalidTerrainLocation      = Error("invalid terrain location")
 	ErrInvalidTerrainMapFieldCount = Error("invalid terrain map field count")
+	ErrInvalidTileFieldCount       = Error("invalid tile field count")
+	ErrInvalidTileGrid             = Error("invalid tile grid")
+	ErrInvalidTileValue            = Error("invalid tile value")
 	ErrInvalidUTF16                = Error("invalid utf-16")
 	ErrInvalidUTF8                 = Error("invalid utf-8")
 	ErrInvalidVersion              = Error("invalid version")
 	ErrInvalidXML                  = Error("invalid xml")
+	ErrInvalidXMLCharacter         = Error("character not allowed in xml")
 	ErrInvalidXMLHeader            = Error("invalid xml header")
+	ErrMalformedOutput             = Error("encoder output is not well-formed xml")
 	ErrMapNotClosed                = Error("<map> not closed")
+	ErrMissingBundleFile           = Error("missing bundle file")
 	ErrMissingBOM                  = Error("missing bom")
 	ErrMissingFinalByte            = Error("missing final byte")
 	ErrMissingMapElement           = Error("missing map element")
 	ErrMissingVersion              = Error("missing version")
 	ErrMissingWxxExtension         = Error("missing .wxx extension")
 	ErrMissingXMLHeader            = Error("missing xml header")
+	ErrNestingTooDeep              = Error("elements nested too deeply")
+	ErrNewerSchema                 = Error("schema is newer than any codec")
 	ErrNotBigEndianUTF16Encoded    = Error("not big-endian utf-16 encoded")
 	ErrNotCompressed               = Error("not compressed")
 	ErrNotExists                   = Error("not exists")
@@ -44,10 +60,16 @@ const (
 	ErrNotImplemented              = Error("not implemented")
 	ErrPipelineHalted              = Error("pipeline halted")
 	ErrRawReadFailed               = Error("raw read failed")
+	ErrRoundTripChanged            = Error("round trip changed the file")
+	ErrTooManyElements             = Error("too many elements")
+	ErrTooManyTiles                = Error("too many tiles")
 	ErrUnacceptedAppVersion        = Error("unaccepted application version")
+	ErrUnknownContent              = Error("unknown content")
 	ErrUnknownVersion              = Error("unknown version")
 	ErrUnknownXMLHeader            = Error("unknown xml header")
 	ErrUnmodeledStubLoss           = Error("target cannot express an unmodeled stub")
+	ErrUnsupportedBundleVersion    = Error("unsupported bundle version")
+	ErrUnsupportedJSONVersion      = Error("unsupported json version")
 	ErrUnsupportedMapMetadata      = Error("unsupported map metadata")
 	ErrUnsupportedMapVersion       = Error("unsupported map version")
 	ErrUnsupportedSchemaVersion    = Error("unsupported schema version")
@@ -0,0 +1,283 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package geojson writes a Worldographer map as a GeoJSON FeatureCollection
+// (RFC 7946) that GIS tools such as QGIS can open.
+//
+// Every tile is a hex Polygon, laid out by package hexg with the map's
+// HexWidth, HexHeight and grid orientation. Features, labels and notes are
+// Points at their locations, and each shape is a Polygon, if it is one, or a
+// LineString through its points. Each GeoJSON feature's "kind" property says
+// which of tile, feature, label, note and shape it is, so a tool can split the
+// collection into layers.
+//
+// The coordinates are the map's own, in the units of HexWidth and HexHeight,
+// with the top left corner of the map at 0,0 and y negated, so that north is up
+// as a GIS tool draws it. They are not longitudes and latitudes; a tool that
+// asks for a coordinate reference system should be given a planar one.
+package geojson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/hexg"
+)
+
+// FeatureCollection_t is a GeoJSON FeatureCollection.
+type FeatureCollection_t struct {
+	Type     string       `json:"type"` // "FeatureCollection"
+	Features []*Feature_t `json:"features"`
+}
+
+// Feature_t is a GeoJSON Feature.
+type Feature_t struct {
+	Type       string         `json:"type"` // "Feature"
+	Geometry   *Geometry_t    `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// Geometry_t is a GeoJSON Point, LineString or Polygon. Coordinates is a
+// position, an array of positions or an array of rings of positions, to match.
+type Geometry_t struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Position_t is a GeoJSON position, x and y.
+type Position_t [2]float64
+
+// Marshal returns the GeoJSON FeatureCollection for m.
+func Marshal(m *wxx.Map_t) ([]byte, error) {
+	fc, err := Collection(m)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(fc)
+}
+
+// Collection returns the GeoJSON FeatureCollection for m: its tiles, then its
+// features, labels, notes and shapes, each in the order the map holds them.
+func Collection(m *wxx.Map_t) (*FeatureCollection_t, error) {
+	if m == nil {
+		return nil, fmt.Errorf("geojson: nil map")
+	}
+	layout, err := m.Layout()
+	if err != nil {
+		return nil, fmt.Errorf("geojson: %w", err)
+	}
+	p := &projection{layout: layout}
+	p.scaleX, p.scaleY = m.LocationScale()
+
+	fc := &FeatureCollection_t{Type: "FeatureCollection", Features: []*Feature_t{}}
+	add := func(g *Geometry_t, properties map[string]any) {
+		fc.Features = append(fc.Features, &Feature_t{Type: "Feature", Geometry: g, Properties: properties})
+	}
+
+	if m.Tiles != nil {
+		terrains := map[int]string{}
+		if m.TerrainMap != nil {
+			for _, t := range m.TerrainMap.List {
+				if t != nil {
+					terrains[t.Index] = t.Label
+				}
+			}
+		}
+		wide, _ := m.Tiles.Size()
+		var column []wxx.Tile_t
+		for x := 0; x < wide; x++ {
+			column = m.Tiles.ColumnTiles(column[:0], x)
+			for y, t := range column {
+				h := m.TileCoord(x, y)
+				q, r, s := h.QRS()
+				col, row := m.TileColumnRow(x, y)
+				res := t.Resources
+				properties := map[string]any{
+					"kind":       "tile",
+					"column":     col,
+					"row":        row,
+					"q":          q,
+					"r":          r,
+					"s":          s,
+					"terrain":    terrains[t.Terrain],
+					"elevation":  number(t.Elevation),
+					"is_icy":     t.IsIcy,
+					"is_gm_only": t.IsGMOnly,
+					"animal":     res.Animal,
+					"brick":      res.Brick,
+					"crops":      res.Crops,
+					"gems":       res.Gems,
+					"lumber":     res.Lumber,
+					"metals":     res.Metals,
+					"rock":       res.Rock,
+				}
+				if c := color(t.CustomBackgroundColor); c != nil {
+					properties["background_color"] = c
+				}
+				add(p.hex(h), properties)
+			}
+		}
+	}
+
+	for _, f := range m.Features {
+		if f == nil || f.Location == nil {
+			continue
+		}
+		properties := map[string]any{
+			"kind":       "feature",
+			"type":       f.Type,
+			"uuid":       f.Uuid,
+			"map_layer":  f.MapLayer,
+			"view_level": f.Location.ViewLevel,
+			"is_gm_only": f.IsGMOnly,
+		}
+		if f.Label != nil && f.Label.InnerText != "" {
+			properties["label"] = f.Label.InnerText
+		}
+		add(p.point(f.Location.X, f.Location.Y), properties)
+	}
+
+	for _, l := range m.Labels {
+		if l == nil || l.Location == nil {
+			continue
+		}
+		add(p.point(l.Location.X, l.Location.Y), map[string]any{
+			"kind":       "label",
+			"text":       l.InnerText,
+			"style":      l.Style,
+			"map_layer":  l.MapLayer,
+			"view_level": l.Location.ViewLevel,
+			"color":      color(l.Color),
+			"is_gm_only": l.IsGMOnly,
+		})
+	}
+
+	for _, n := range m.Notes {
+		if n == nil {
+			continue
+		}
+		add(p.point(n.X, n.Y), map[string]any{
+			"kind":       "note",
+			"key":        n.Key,
+			"title":      n.Title,
+			"view_level": n.ViewLevel,
+			"color":      color(n.Color),
+			"is_gm_only": n.IsGMOnly,
+		})
+	}
+
+	for _, s := range m.Shapes {
+		if s == nil {
+			continue
+		}
+		g := p.shape(s)
+		if g == nil {
+			continue
+		}
+		add(g, map[string]any{
+			"kind":         "shape",
+			"type":         s.Type,
+			"map_layer":    s.MapLayer,
+			"stroke_type":  s.StrokeType,
+			"stroke_color": s.StrokeColor,
+			"stroke_width": number(s.StrokeWidth),
+			"opacity":      number(s.Opacity),
+			"view_level":   s.HighestViewLevel,
+			"tags":         s.Tags,
+			"is_gm_only":   s.IsGMOnly,
+		})
+	}
+
+	return fc, nil
+}
+
+// projection places tiles and locations in the collection's coordinates.
+type projection struct {
+	layout         hexg.Layout
+	scaleX, scaleY float64 // from location space to map units
+}
+
+// hex returns the Polygon of the tile at h.
+func (p *projection) hex(h hexg.CubeCoord) *Geometry_t {
+	var ring []Position_t
+	for _, corner := range p.layout.PolygonCorners(h) {
+		ring = append(ring, position(corner.XY()))
+	}
+	return &Geometry_t{Type: "Polygon", Coordinates: [][]Position_t{closeRing(ring)}}
+}
+
+// point returns the Point at x, y in location space.
+func (p *projection) point(x, y float64) *Geometry_t {
+	return &Geometry_t{Type: "Point", Coordinates: p.position(x, y)}
+}
+
+// shape returns the geometry of s: a Polygon if s is one and has the points to
+// make one, otherwise a LineString, and nil if s has too few points for that.
+func (p *projection) shape(s *wxx.Shape_t) *Geometry_t {
+	var line []Position_t
+	for _, pt := range s.Points {
+		if pt != nil {
+			line = append(line, p.position(pt.X, pt.Y))
+		}
+	}
+	switch {
+	case s.Type == "Polygon" && len(line) >= 3:
+		return &Geometry_t{Type: "Polygon", Coordinates: [][]Position_t{closeRing(line)}}
+	case len(line) >= 2:
+		return &Geometry_t{Type: "LineString", Coordinates: line}
+	}
+	return nil
+}
+
+// position returns the position of x, y in location space.
+func (p *projection) position(x, y float64) Position_t {
+	return position(x*p.scaleX, y*p.scaleY)
+}
+
+// position returns the position of x, y in map units, with y negated and both
+// rounded to a millionth of a unit.
+func position(x, y float64) Position_t {
+	x, y = math.Round(x*1e6)/1e6, -math.Round(y*1e6)/1e6
+	if y == 0 {
+		y = 0 // not -0
+	}
+	return Position_t{x, y}
+}
+
+// closeRing returns ring closed, its first position repeated at its end, and
+// wound counterclockwise, as RFC 7946 has an exterior ring.
+func closeRing(ring []Position_t) []Position_t {
+	area := 0.0
+	for i := range ring {
+		a, b := ring[i], ring[(i+1)%len(ring)]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	if area < 0 {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	if ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+	return ring
+}
+
+// number returns f, or nil for a float JSON cannot hold.
+func number(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
+
+// color returns the color as the file spells it, or nil for none.
+func color(c *wxx.RGBA_t) any {
+	if c == nil {
+		return nil
+	} else if c.Raw != "" {
+		return c.Raw
+	}
+	return fmt.Sprintf("%g,%g,%g,%g", c.R, c.G, c.B, c.A)
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package geojson_test
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/maloquacious/wxx/geojson"
+	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+const (
+	columnsFixture   = "../testdata/2025-2.06-13x11-941577-layers.wxx"
+	rowsFixture      = "../testdata/2017-1.77-1.0-rows-blank.wxx"
+	populatedFixture = "../testdata/w2025-populated.xml"
+)
+
+// TestCollection asserts that every tile of a COLUMNS map and of a ROWS map is a
+// closed hex, wound counterclockwise, and that the features Worldographer
+// placed at the centers of tiles are at the centers of their hexes.
+func TestCollection(t *testing.T) {
+	for _, fixture := range []string{columnsFixture, rowsFixture} {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			testCollection(t, fixture)
+		})
+	}
+}
+
+func testCollection(t *testing.T, fixture string) {
+	m, err := xmlio.ReadFile(fixture)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	fc, err := geojson.Collection(m)
+	if err != nil {
+		t.Fatalf("Collection: %v", err)
+	}
+
+	kinds := map[string]int{}
+	centers := map[[2]int]geojson.Position_t{}
+	for _, f := range fc.Features {
+		kinds[f.Properties["kind"].(string)]++
+		if f.Properties["kind"] != "tile" {
+			continue
+		}
+		ring := f.Geometry.Coordinates.([][]geojson.Position_t)[0]
+		if len(ring) != 7 || ring[0] != ring[6] {
+			t.Fatalf("tile: ring of %d positions, want 6 corners and the first again", len(ring))
+		}
+		var area float64
+		var center geojson.Position_t
+		for i := 0; i < 6; i++ {
+			area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+			center[0], center[1] = center[0]+ring[i][0]/6, center[1]+ring[i][1]/6
+		}
+		if area <= 0 {
+			t.Fatalf("tile: ring is wound clockwise")
+		}
+		centers[[2]int{f.Properties["column"].(int), f.Properties["row"].(int)}] = center
+	}
+	if got, want := kinds["tile"], m.Tiles.TilesWide*m.Tiles.TilesHigh; got != want {
+		t.Errorf("%d tiles, want %d", got, want)
+	}
+
+	// Worldographer places the fixture's features snapped to the centers of
+	// tiles
+	for _, f := range fc.Features {
+		if f.Properties["kind"] != "feature" {
+			continue
+		}
+		at, found := f.Geometry.Coordinates.(geojson.Position_t), false
+		for _, center := range centers {
+			found = found || math.Abs(at[0]-center[0]) < 1e-3 && math.Abs(at[1]-center[1]) < 1e-3
+		}
+		if !found {
+			t.Errorf("feature at %v is not at the center of a tile", at)
+		}
+	}
+	if got, want := kinds["feature"], len(m.Features); got != want || got == 0 {
+		t.Errorf("%d features, want the fixture's %d", got, want)
+	}
+}
+
+// TestTilePlacement asserts that the tile at [x][y] of the grid is in column x
+// of a COLUMNS map and row x of a ROWS map, and that its hex is drawn there.
+func TestTilePlacement(t *testing.T) {
+	for _, fixture := range []string{columnsFixture, rowsFixture} {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			m, err := xmlio.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			fc, err := geojson.Collection(m)
+			if err != nil {
+				t.Fatalf("Collection: %v", err)
+			}
+			_, high := m.Tiles.Size()
+			for i, f := range fc.Features[:m.Tiles.TilesWide*high] {
+				x, y := i/high, i%high
+				ring := f.Geometry.Coordinates.([][]geojson.Position_t)[0]
+				var center geojson.Position_t
+				for _, corner := range ring[:6] {
+					center[0], center[1] = center[0]+corner[0]/6, center[1]+corner[1]/6
+				}
+				// hexes a <tilerow> apart are three quarters of a hex apart
+				want, got := (0.5+0.75*float64(x))*m.HexWidth, center[0]
+				column, row := f.Properties["column"], f.Properties["row"]
+				if m.GridOrientation == hexg.OddR {
+					want, got = (0.5+0.75*float64(x))*m.HexHeight, -center[1]
+					column, row = row, column
+				}
+				if column != x || row != y {
+					t.Fatalf("tile [%d][%d] is at column %v, row %v", x, y, f.Properties["column"], f.Properties["row"])
+				}
+				if math.Abs(got-want) > 1e-6 {
+					t.Fatalf("tile [%d][%d] is drawn %g along the <tilerow> axis, want %g", x, y, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestShapes asserts that a Polygon shape is a closed Polygon and any other
+// shape a LineString, and that the collection is JSON a GIS tool reads.
+func TestShapes(t *testing.T) {
+	m, err := xmlio.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	data, err := geojson.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+			Properties map[string]any
+		}
+	}
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		t.Errorf("type = %q", fc.Type)
+	}
+	shapes := map[string]string{}
+	for _, f := range fc.Features {
+		if f.Properties["kind"] == "shape" {
+			shapes[f.Properties["type"].(string)] = f.Geometry.Type
+			if f.Geometry.Type == "Polygon" {
+				var rings [][][2]float64
+				if err := json.Unmarshal(f.Geometry.Coordinates, &rings); err != nil || len(rings) != 1 || rings[0][0] != rings[0][len(rings[0])-1] {
+					t.Errorf("shape polygon is not one closed ring: %s", f.Geometry.Coordinates)
+				}
+			}
+		}
+	}
+	if shapes["Polygon"] != "Polygon" || shapes["Path"] != "LineString" {
+		t.Errorf("shapes = %v, want Polygon as a Polygon and Path as a LineString", shapes)
+	}
+}
@@ -30,6 +30,11 @@ func NewFractionalCubeCoord(q_, r_, s_ float64) FractionalCubeCoord {
 	return FractionalCubeCoord{q: q_, r: r_, s: s_}
 }
 
+// QRS returns the q, r and s of the coordinate.
+func (a CubeCoord) QRS() (q, r, s int) {
+	return a.q, a.r, a.s
+}
+
 func (a CubeCoord) Add(b CubeCoord) CubeCoord {
 	return CubeCoord{q: a.q + b.q, r: a.r + b.r, s: a.s + b.s}
 }
@@ -4,6 +4,7 @@ package hexg
 
 import (
 	"log"
+	"math"
 	"testing"
 )
 
@@ -123,6 +124,23 @@ func Test_layout(t *testing.T) {
 	equal_hex("layout", h, pixel_to_cube_rounded(pointy, cube_to_pixel(pointy, h)))
 }
 
+func Test_layout_corners(t *testing.T) {
+	for _, layout := range []Layout{FlatLayout(NewPoint(10, 10), NewPoint(35, 71)), PointyLayout(NewPoint(10, 10), NewPoint(35, 71))} {
+		h := CubeCoord{3, 4, -7}
+		cx, cy := layout.HexToPixel(h).XY()
+		corners := layout.PolygonCorners(h)
+		if len(corners) != 6 {
+			t.Fatalf("corners: got %d, want 6", len(corners))
+		}
+		for i, corner := range corners {
+			x, y := corner.XY()
+			if d := math.Hypot(x-cx, y-cy); math.Abs(d-10) > 1e-9 {
+				t.Errorf("corner %d: %g from the center, want 10", i, d)
+			}
+		}
+	}
+}
+
 func Test_offset_roundtrip(t *testing.T) {
 	for q := -2; q < 3; q++ {
 		for r := -2; r < 3; r++ {
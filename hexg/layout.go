@@ -75,6 +75,30 @@ var layout_pointy = Orientation{math.Sqrt(3.0), math.Sqrt(3.0) / 2.0, 0.0, 3.0 /
 
 var layout_flat = Orientation{3.0 / 2.0, 0.0, math.Sqrt(3.0) / 2.0, math.Sqrt(3.0), 2.0 / 3.0, 0.0, -1.0 / 3.0, math.Sqrt(3.0) / 3.0, 0.0}
 
+// FlatLayout returns a layout of flat-topped hexes, the hexes of an OddQ grid.
+// A hex is 2 * size.x wide and sqrt(3) * size.y high, and the hex at (0,0,0)
+// is centered on origin.
+func FlatLayout(size, origin Point) Layout {
+	return Layout{orientation: layout_flat, size: size, origin: origin}
+}
+
+// PointyLayout returns a layout of pointy-topped hexes, the hexes of an OddR
+// grid. A hex is sqrt(3) * size.x wide and 2 * size.y high, and the hex at
+// (0,0,0) is centered on origin.
+func PointyLayout(size, origin Point) Layout {
+	return Layout{orientation: layout_pointy, size: size, origin: origin}
+}
+
+// HexToPixel returns the center of the hex.
+func (layout Layout) HexToPixel(h CubeCoord) Point {
+	return cube_to_pixel(layout, h)
+}
+
+// PolygonCorners returns the six corners of the hex.
+func (layout Layout) PolygonCorners(h CubeCoord) []Point {
+	return polygon_corners(layout, h)
+}
+
 func hex_corner_offset(layout Layout, corner int) Point {
 	angle := 2.0 * math.Pi * (layout.orientation.start_angle - float64(corner)) / 6.0
 	return Point{x: layout.size.x * math.Cos(angle), y: layout.size.y * math.Sin(angle)}
@@ -59,6 +59,11 @@ func (a OddQCoord) Equals(b OddQCoord) bool {
 	return a.col == b.col && a.row == b.row
 }
 
+// ColRow returns the column and row of the coordinate.
+func (a OddQCoord) ColRow() (col, row int) {
+	return a.col, a.row
+}
+
 // OddRCoord implements "odd-r," an offset coordinate with pointy top hexes and odd rows pushed right.
 type OddRCoord struct {
 	col int
@@ -73,6 +78,11 @@ func (a OddRCoord) Equals(b OddRCoord) bool {
 	return a.col == b.col && a.row == b.row
 }
 
+// ColRow returns the column and row of the coordinate.
+func (a OddRCoord) ColRow() (col, row int) {
+	return a.col, a.row
+}
+
 // Orientation_e is orientation for offset coordinates
 type Orientation_e int
 
@@ -10,3 +10,8 @@ type Point struct {
 func NewPoint(x_, y_ float64) Point {
 	return Point{x: x_, y: y_}
 }
+
+// XY returns the x and y of the point.
+func (p Point) XY() (x, y float64) {
+	return p.x, p.y
+}
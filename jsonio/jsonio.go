@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package jsonio reads and writes a Worldographer map as JSON.
+//
+// The JSON is a document with three members:
+//
+//	{"format": "wxx-json", "version": 1, "map": {...}}
+//
+// format marks the document as a map, and version is the version of the layout
+// of map, which this package raises when a change to it would mislead a reader
+// written against the last one. map is wxx.Map_t as its json tags name its
+// fields, with one exception: the tiles are written as "columns", an array
+// holding an array of tiles for each <tilerow>, in the order Tiles_t.ColumnTiles
+// returns them. A tile holds its terrain, elevation, flags, resources and
+// custom background color, and leaves out its row, column and cube coordinate,
+// which follow from where it is in the array.
+//
+// The JSON is lossless: a map decoded from a .wxx file, written as JSON and
+// read back, encodes to the file the original map encodes to. Colors keep the
+// spelling the file had them in, and the extras a codec kept are kept. What is
+// not written is the formatting xmlio.WithPreserveFormatting records, which
+// describes the file rather than the map; a map read back from JSON is encoded
+// in the encoder's own spelling.
+package jsonio
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/maloquacious/wxx"
+)
+
+// Format is the document's format field, which marks JSON as a map.
+const Format = "wxx-json"
+
+// Version is the version of the layout this package writes, and the newest it
+// reads.
+const Version = 1
+
+// Document_t is the JSON document.
+type Document_t struct {
+	Format  string     `json:"format"`
+	Version int        `json:"version"`
+	Map     *wxx.Map_t `json:"map"`
+}
+
+// Marshal returns the JSON document for m.
+func Marshal(m *wxx.Map_t) ([]byte, error) {
+	return MarshalIndent(m, "", "")
+}
+
+// MarshalIndent is like Marshal but indents the document as json.MarshalIndent
+// does.
+func MarshalIndent(m *wxx.Map_t, prefix, indent string) ([]byte, error) {
+	if m == nil {
+		return nil, fmt.Errorf("jsonio: %w: nil map", wxx.ErrInvalidJSONMap)
+	}
+	doc := Document_t{Format: Format, Version: Version, Map: m}
+	var data []byte
+	var err error
+	if prefix == "" && indent == "" {
+		data, err = json.Marshal(doc)
+	} else {
+		data, err = json.MarshalIndent(doc, prefix, indent)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jsonio: %w", err)
+	}
+	return data, nil
+}
+
+// Unmarshal returns the map in the JSON document data. It returns
+// wxx.ErrInvalidJSONMap if data is not a map document or holds a member the
+// layout does not name, and wxx.ErrUnsupportedJSONVersion if it was written in
+// a newer layout than this package reads.
+func Unmarshal(data []byte) (*wxx.Map_t, error) {
+	// the header is read on its own first, so a newer layout is reported as
+	// newer rather than as whatever it no longer agrees with
+	var header struct {
+		Format  string `json:"format"`
+		Version int    `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, errors.Join(wxx.ErrInvalidJSONMap, fmt.Errorf("jsonio: %w", err))
+	}
+	if header.Format != Format {
+		return nil, fmt.Errorf("jsonio: %w: format %q, want %q", wxx.ErrInvalidJSONMap, header.Format, Format)
+	} else if header.Version < 1 || header.Version > Version {
+		return nil, fmt.Errorf("jsonio: %w: version %d, want 1...%d", wxx.ErrUnsupportedJSONVersion, header.Version, Version)
+	}
+
+	var doc Document_t
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.Join(wxx.ErrInvalidJSONMap, fmt.Errorf("jsonio: %w", err))
+	} else if dec.More() {
+		return nil, fmt.Errorf("jsonio: %w: data after the document", wxx.ErrInvalidJSONMap)
+	} else if doc.Map == nil {
+		return nil, fmt.Errorf("jsonio: %w: no map", wxx.ErrInvalidJSONMap)
+	}
+	if doc.Map.Tiles != nil {
+		doc.Map.Tiles.Orientation = doc.Map.GridOrientation
+	}
+	return doc.Map, nil
+}
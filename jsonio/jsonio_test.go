@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package jsonio_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/jsonio"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// encode returns m encoded to the application version it was read as.
+func encode(t *testing.T, m *wxx.Map_t) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw).Encode(&buf, m); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// fixtures returns the maps in testdata.
+func fixtures(t *testing.T) []string {
+	t.Helper()
+	names, err := filepath.Glob("../testdata/*.wxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(names, "../testdata/w2025-populated.xml")
+}
+
+// TestRoundTrip asserts that every fixture survives JSON: the map read back
+// from the JSON encodes to the file the decoded map encodes to, and writes the
+// same JSON again.
+func TestRoundTrip(t *testing.T) {
+	for _, fixture := range fixtures(t) {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			m, err := xmlio.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			data, err := jsonio.Marshal(m)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			got, err := jsonio.Unmarshal(data)
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !bytes.Equal(encode(t, got), encode(t, m)) {
+				t.Errorf("the map read back from JSON encodes to another file")
+			}
+			if again, err := jsonio.Marshal(got); err != nil {
+				t.Errorf("Marshal of the map read back: %v", err)
+			} else if !bytes.Equal(again, data) {
+				t.Errorf("the map read back from JSON marshals to other JSON")
+			}
+			if got.Tiles.Orientation != m.Tiles.Orientation {
+				t.Errorf("Tiles.Orientation = %v, want %v", got.Tiles.Orientation, m.Tiles.Orientation)
+			}
+		})
+	}
+}
+
+// TestUnmarshalRejects asserts that only a map document in a layout this
+// package reads is read.
+func TestUnmarshalRejects(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data string
+		want error
+	}{
+		{"not json", `<map/>`, wxx.ErrInvalidJSONMap},
+		{"not a map", `{"format":"wxx-bundle","version":1,"map":{}}`, wxx.ErrInvalidJSONMap},
+		{"newer", `{"format":"wxx-json","version":2,"map":{"layers":[]}}`, wxx.ErrUnsupportedJSONVersion},
+		{"no map", `{"format":"wxx-json","version":1}`, wxx.ErrInvalidJSONMap},
+		{"unknown member", `{"format":"wxx-json","version":1,"map":{"layers":[]}}`, wxx.ErrInvalidJSONMap},
+		{"ragged tiles", `{"format":"wxx-json","version":1,"map":{"tiles":{"tilesWide":2,"tilesHigh":1,"columns":[[{"terrain":1}],[]]}}}`, wxx.ErrInvalidTileGrid},
+		{"columns past tilesWide", `{"format":"wxx-json","version":1,"map":{"tiles":{"tilesWide":1,"tilesHigh":1,"columns":[[{"terrain":1}],[{"terrain":1}]]}}}`, wxx.ErrInvalidTileGrid},
+		{"tiles past tilesHigh", `{"format":"wxx-json","version":1,"map":{"tiles":{"tilesWide":1,"tilesHigh":1,"columns":[[{"terrain":1},{"terrain":1}]]}}}`, wxx.ErrInvalidTileGrid},
+		{"negative size", `{"format":"wxx-json","version":1,"map":{"tiles":{"tilesWide":-1,"tilesHigh":-1}}}`, wxx.ErrInvalidTileGrid},
+	} {
+		if _, err := jsonio.Unmarshal([]byte(tc.data)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
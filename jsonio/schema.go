@@ -0,0 +1,175 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package jsonio
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/maloquacious/wxx"
+)
+
+// SchemaDialect is the JSON Schema dialect Schema is written in.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns the JSON Schema of the document Marshal writes. It is
+// generated from the Go types, by the rules encoding/json marshals them by, so
+// it describes what this build writes and no other:
+//
+//   - a struct is an object of its tagged fields, and no others; a field
+//     without omitempty or omitzero is required.
+//   - a named struct is a definition under $defs, named as the Go type is.
+//   - a pointer, slice or map may be null.
+//   - time.Time is a date-time string.
+//   - wxx.Tiles_t is the wxx.TilesJSON_t its MarshalJSON writes.
+func Schema() ([]byte, error) {
+	g := &schemaGen{defs: map[string]*definition{}}
+	root := map[string]any{
+		"$schema":     SchemaDialect,
+		"title":       "Worldographer map",
+		"description": fmt.Sprintf("A Worldographer map, as the %s document of version %d that package jsonio of github.com/maloquacious/wxx writes.", Format, Version),
+		"type":        "object",
+		"properties": map[string]any{
+			"format":  map[string]any{"const": Format},
+			"version": map[string]any{"const": Version},
+			"map":     g.schema(reflect.TypeFor[wxx.Map_t]()),
+		},
+		"required":             []string{"format", "version", "map"},
+		"additionalProperties": false,
+	}
+	if g.err != nil {
+		return nil, g.err
+	}
+	root["$defs"] = g.defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaGen generates the schemas of Go types, collecting the definitions of
+// the named structs as it meets them.
+type schemaGen struct {
+	defs map[string]*definition
+	err  error
+}
+
+// schemaNames names the definitions of the types that stand in for others.
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeFor[wxx.TilesJSON_t](): "Tiles_t",
+	reflect.TypeFor[wxx.TileJSON_t]():  "Tile_t",
+}
+
+func (g *schemaGen) schema(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[wxx.Tiles_t]():
+		t = reflect.TypeFor[wxx.TilesJSON_t]()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Slice:
+		return nullable(map[string]any{"type": "array", "items": g.schema(t.Elem())})
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			break
+		}
+		return nullable(map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.define(t)
+	}
+	g.fail(fmt.Errorf("jsonio: schema: %s has no JSON schema", t))
+	return map[string]any{}
+}
+
+// define returns a reference to the definition of the named struct t, making
+// the definition the first time t is met.
+func (g *schemaGen) define(t reflect.Type) map[string]any {
+	name, ok := schemaNames[t]
+	if !ok {
+		name = t.Name()
+	}
+	ref := map[string]any{"$ref": "#/$defs/" + name}
+	if def, ok := g.defs[name]; ok {
+		if def.t != t {
+			g.fail(fmt.Errorf("jsonio: schema: %s and %s are both named %s", def.t, t, name))
+		}
+		return ref
+	}
+	def := &definition{t: t}
+	g.defs[name] = def // before the fields, which may refer to t
+	def.schema = g.object(t)
+	return ref
+}
+
+// definition is a schema under $defs and the type it was made for, so that two
+// types of the same name are reported rather than merged.
+type definition struct {
+	t      reflect.Type
+	schema map[string]any
+}
+
+func (d *definition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.schema)
+}
+
+// object returns the schema of the struct t: its fields as encoding/json names
+// them, the ones it always writes required, and nothing else allowed.
+func (g *schemaGen) object(t reflect.Type) map[string]any {
+	properties, required := map[string]any{}, []string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		} else if f.Anonymous {
+			g.fail(fmt.Errorf("jsonio: schema: %s embeds %s", t, f.Type))
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		} else if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schema(f.Type)
+		omitted := false
+		for _, opt := range strings.Split(opts, ",") {
+			omitted = omitted || opt == "omitempty" || opt == "omitzero"
+		}
+		if !omitted {
+			required = append(required, name)
+		}
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func (g *schemaGen) fail(err error) {
+	if g.err == nil {
+		g.err = err
+	}
+}
+
+// nullable returns a schema that allows null as well as what s allows.
+func nullable(s map[string]any) map[string]any {
+	return map[string]any{"anyOf": []any{s, map[string]any{"type": "null"}}}
+}
@@ -0,0 +1,224 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package jsonio_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/jsonio"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// schemaFile is the published schema, which must be what Schema generates.
+const schemaFile = "../schema/wxx-json-v1.schema.json"
+
+// TestSchemaFile asserts that the published schema is the one this build
+// generates. Regenerate it with `wxx schema --json-schema -o` after a change to
+// the types.
+func TestSchemaFile(t *testing.T) {
+	want, err := jsonio.Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	got, err := os.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, append(want, '\n')) {
+		t.Errorf("%s is not the schema this build generates", schemaFile)
+	}
+}
+
+// TestSchemaValidates asserts that the JSON of every fixture, and of a tile
+// with every field set, is valid against the schema, and that a document with a
+// member the schema does not name is not.
+func TestSchemaValidates(t *testing.T) {
+	schema := loadSchema(t)
+	for _, fixture := range fixtures(t) {
+		m, err := xmlio.ReadFile(fixture)
+		if err != nil {
+			t.Fatalf("ReadFile(%s): %v", fixture, err)
+		}
+		for _, problem := range schema.validate(t, marshal(t, m)) {
+			t.Errorf("%s: %s", filepath.Base(fixture), problem)
+		}
+	}
+
+	m := &wxx.Map_t{Tiles: wxx.NewTiles(1, 1, hexg.OddQ)}
+	m.MetaData.Version.App = wxx.Dotted{Raw: "2.06", Major: 2, Minor: 6}
+	m.Tiles.SetTile(0, 0, wxx.Tile_t{
+		Terrain:               2,
+		Elevation:             -0.5,
+		IsIcy:                 true,
+		IsGMOnly:              true,
+		Resources:             wxx.Resources_t{Animal: 1, Brick: 2, Crops: 3, Gems: 4, Lumber: 5, Metals: 6, Rock: 7},
+		CustomBackgroundColor: &wxx.RGBA_t{R: 1, A: 1, Raw: "1,0,0,1"},
+	})
+	for _, problem := range schema.validate(t, marshal(t, m)) {
+		t.Errorf("tile with every field set: %s", problem)
+	}
+
+	doc := marshal(t, m)
+	doc = bytes.Replace(doc, []byte(`"terrain":2`), []byte(`"terrain":2,"height":1`), 1)
+	if problems := schema.validate(t, doc); len(problems) == 0 {
+		t.Errorf("a tile with a member the schema does not name is valid")
+	}
+}
+
+func marshal(t *testing.T, m *wxx.Map_t) []byte {
+	t.Helper()
+	data, err := jsonio.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return data
+}
+
+// testSchema validates documents against a schema in the subset of JSON Schema
+// that Schema writes: $ref, anyOf, const, type, properties, required,
+// additionalProperties, items and minimum. format is an annotation, and is not
+// checked.
+type testSchema struct {
+	root map[string]any
+	defs map[string]any
+}
+
+func loadSchema(t *testing.T) testSchema {
+	t.Helper()
+	data, err := jsonio.Schema()
+	if err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("Schema: %v", err)
+	}
+	defs, _ := root["$defs"].(map[string]any)
+	return testSchema{root: root, defs: defs}
+}
+
+// validate returns what is wrong with the document data, if anything.
+func (s testSchema) validate(t *testing.T, data []byte) []string {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("document: %v", err)
+	}
+	var problems []string
+	s.check(s.root, doc, "$", &problems)
+	return problems
+}
+
+func (s testSchema) check(schema map[string]any, v any, at string, problems *[]string) {
+	fail := func(format string, args ...any) {
+		*problems = append(*problems, at+": "+fmt.Sprintf(format, args...))
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		def, ok := s.defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if !ok {
+			fail("no definition %s", ref)
+			return
+		}
+		s.check(def, v, at, problems)
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			var subProblems []string
+			if s.check(sub.(map[string]any), v, at, &subProblems); len(subProblems) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("matches none of anyOf")
+		}
+	}
+	if c, ok := schema["const"]; ok && fmt.Sprint(c) != fmt.Sprint(v) {
+		fail("%v, want %v", v, c)
+	}
+	if typ, ok := schema["type"].(string); ok && !isType(v, typ) {
+		fail("%T is not %s", v, typ)
+		return
+	}
+	if minimum, ok := schema["minimum"].(float64); ok {
+		if n, ok := v.(json.Number); ok {
+			if f, _ := n.Float64(); f < minimum {
+				fail("%v is less than %v", n, minimum)
+			}
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		for _, name := range asStrings(schema["required"]) {
+			if _, ok := v[name]; !ok {
+				fail("missing %q", name)
+			}
+		}
+		for name, member := range v {
+			if sub, ok := properties[name].(map[string]any); ok {
+				s.check(sub, member, at+"."+name, problems)
+			} else if sub, ok := schema["additionalProperties"].(map[string]any); ok {
+				s.check(sub, member, at+"."+name, problems)
+			} else if schema["additionalProperties"] == false {
+				fail("unexpected %q", name)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				s.check(items, item, fmt.Sprintf("%s[%d]", at, i), problems)
+			}
+		}
+	}
+}
+
+func isType(v any, typ string) bool {
+	switch typ {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if ok {
+			_, err := n.Int64()
+			ok = err == nil
+		}
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	}
+	return false
+}
+
+func asStrings(v any) []string {
+	var out []string
+	list, _ := v.([]any)
+	for _, s := range list {
+		out = append(out, s.(string))
+	}
+	return out
+}
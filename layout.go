@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package wxx
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/maloquacious/wxx/hexg"
+)
+
+// LocationHexSize is the width and height of a hex in location space, the
+// space Worldographer places features, labels, notes, shapes and extra terrain
+// in, whatever HexWidth and HexHeight are.
+const LocationHexSize = 300.0
+
+// Layout returns the layout of the map's tiles, in the units of HexWidth and
+// HexHeight, with y pointing down and the box around the hex of the tile at 0,0
+// starting at the origin. The hexes of a COLUMNS map are flat-topped and those of a ROWS map
+// pointy-topped, each HexWidth wide and HexHeight high.
+func (m *Map_t) Layout() (hexg.Layout, error) {
+	if !(m.HexWidth > 0 && m.HexHeight > 0) {
+		return hexg.Layout{}, fmt.Errorf("%w: hex size %gx%g", ErrInvalidMapMetadata, m.HexWidth, m.HexHeight)
+	}
+	origin := hexg.NewPoint(m.HexWidth/2, m.HexHeight/2)
+	switch m.GridOrientation {
+	case hexg.OddQ:
+		return hexg.FlatLayout(hexg.NewPoint(m.HexWidth/2, m.HexHeight/math.Sqrt(3)), origin), nil
+	case hexg.OddR:
+		return hexg.PointyLayout(hexg.NewPoint(m.HexWidth/math.Sqrt(3), m.HexHeight/2), origin), nil
+	}
+	return hexg.Layout{}, fmt.Errorf("%w: %v", ErrInvalidHexOrientation, m.GridOrientation)
+}
+
+// TileColumnRow returns the column and row of the map that the tile at [x][y]
+// of its grid fills, with x and y as Tiles_t.Tile takes them: a <tilerow> is a
+// column of a COLUMNS map and a row of a ROWS map.
+func (m *Map_t) TileColumnRow(x, y int) (column, row int) {
+	if m.GridOrientation == hexg.OddR {
+		return y, x
+	}
+	return x, y
+}
+
+// TileCoord returns the cube coordinate, in the layout Layout returns, of the
+// hex that the tile at [x][y] of the grid fills. It is the zero coordinate
+// unless the map is OddQ or OddR. Unlike the Coords of a Tile_t, which the
+// decoders set with the column and row of a COLUMNS map swapped, it is where
+// the tile is drawn.
+func (m *Map_t) TileCoord(x, y int) hexg.CubeCoord {
+	column, row := m.TileColumnRow(x, y)
+	switch m.GridOrientation {
+	case hexg.OddQ:
+		return hexg.NewOddQCoord(column, row).ToCube()
+	case hexg.OddR:
+		return hexg.NewOddRCoord(column, row).ToCube()
+	}
+	return hexg.CubeCoord{}
+}
+
+// LocationScale returns the factors that take x and y in location space to the
+// units of Layout.
+func (m *Map_t) LocationScale() (scaleX, scaleY float64) {
+	return m.HexWidth / LocationHexSize, m.HexHeight / LocationHexSize
+}
@@ -88,7 +88,7 @@ type Map_t struct {
 	BlurTerrainBG *BlurTerrainBG_t `json:"blurTerrainBG,omitempty"`
 
 	// ExtraTerrain is a W2025 top-level element; nil means absent from the file.
-	// Its content is opaque -- see ExtraTerrain_t.
+	// It holds the terrain painted on map layers -- see ExtraTerrain_t.
 	ExtraTerrain *ExtraTerrain_t `json:"extraTerrain,omitempty"`
 
 	// TerrainMap assigns numbers to each terrain type.
@@ -113,6 +113,76 @@ type Map_t struct {
 	Informations *Informations_t `json:"informations"`
 
 	Configuration *Configuration_t `json:"configuration"`
+
+	// Extras holds the attributes and child elements of <map> that the codec
+	// that read it has no field for. See Extras_t.
+	Extras *Extras_t `json:"extras,omitempty"`
+
+	// Formatting is how the file this map was decoded from spelled what the
+	// encoder would spell differently. It is nil unless the map was decoded with
+	// xmlio.WithPreserveFormatting; see Formatting_t.
+	Formatting *Formatting_t `json:"-"`
+}
+
+// Formatting_t records the lexical form of a decoded file: the number spellings,
+// attribute order, and whitespace Worldographer wrote where this module's encoder
+// writes something else. An encoder that finds it on a map writes those tokens
+// back as the file had them wherever the map has not changed, so re-encoding an
+// untouched map reproduces the file it came from.
+//
+// Only the tokens that differ are kept, and a whole element only where its tokens
+// alone cannot give it back. Each is keyed by its position in the document, its
+// element path and the kind of token, and stores both the encoder's spelling of
+// the decoded map and the file's; a token whose encoding no longer matches the
+// first was edited, and is written as the map now says.
+type Formatting_t struct {
+	// App is the application version the forms were recorded against. They
+	// describe what that version's encoder writes, so they mean nothing to any
+	// other.
+	App string
+
+	Forms map[string]Form_t
+}
+
+// Extras_t holds what a decoder found on an element that its codec's schema
+// structs do not name: attributes, and child elements, that a newer build of
+// Worldographer writes and this module does not yet model. The encoder for the
+// same schema writes them back where it found them -- attributes after the ones
+// it knows, elements before the end tag -- so a tool that reads and rewrites a
+// file does not strip what it does not understand.
+//
+// Extras belong to the schema they were read against. An attribute unknown to
+// 1.06 means nothing to the classic format, and may mean something else to the
+// next schema, so an encoder for any other schema leaves them out and reports
+// them in xmlio's EncoderDiagnostics.Dropped instead.
+//
+// Every modeled element carries a nil *Extras_t unless it had something
+// unrecognised, which is the common case: a file written by a build the codec
+// knows has no extras at all.
+type Extras_t struct {
+	// Schema is the schema the element was read against, verbatim map/@schema
+	// ("1.06"); "" is the classic schema, which files state by stating none.
+	Schema string `json:"schema"`
+
+	// Attrs are the unrecognised attributes in the order the file had them.
+	Attrs []ExtraAttr_t `json:"attrs,omitempty"`
+
+	// Elements are the unrecognised child elements, each as the XML text of the
+	// whole element, in the order the file had them.
+	Elements []string `json:"elements,omitempty"`
+}
+
+// ExtraAttr_t is one unrecognised attribute. Value is the attribute's value with
+// its entities expanded, as the decoder read it; the encoder escapes it again.
+type ExtraAttr_t struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Form_t is one token of a decoded file in two spellings.
+type Form_t struct {
+	Canonical string // as the encoder writes the decoded map
+	Original  string // as the file had it
 }
 
 // BlurTerrainBG models the W2025 top-level <blurTerrainBG> element. It is
@@ -125,6 +195,8 @@ type BlurTerrainBG_t struct {
 	Randomness  float64 `json:"randomness,omitempty"`
 	BlurStart   float64 `json:"blurStart,omitempty"`
 	BlurEnd     float64 `json:"blurEnd,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Configuration_t struct {
@@ -161,6 +233,8 @@ type Feature_t struct {
 
 	Location *FeatureLocation_t `json:"location,omitempty"`
 	Label    *Label_t           `json:"label,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type FeatureConfig_t struct {
@@ -171,26 +245,57 @@ type FeatureLocation_t struct {
 	ViewLevel string  `json:"viewLevel,omitempty"`
 	X         float64 `json:"x,omitempty"`
 	Y         float64 `json:"y,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 // ExtraTerrain models the W2025 top-level <extraTerrain> element. It is a
 // pointer on Map_t so nil distinguishes absent from present-but-empty.
 //
-// The two tracked 2.06 fixtures show both shapes it takes:
-// 2025-2.06-13x11-941577-blank.wxx carries an EMPTY container (InnerXML is "\n",
-// the pretty-printer's newline), while 2025-2.06-13x11-941577-layers.wxx carries
-// 183 bytes of real content -- a <mapLayer name="Terrain Layer"> holding a
-// <terrainAndLocation> that binds one hex's terrain to that layer.
+// W2025 can paint terrain on map layers other than the tiles' own. Each
+// <mapLayer> child names a layer (one of Map_t.MapLayers) and lists the terrain
+// placed on it, one <terrainAndLocation> per hex. The two tracked 2.06 fixtures
+// show both shapes the element takes: 2025-2.06-13x11-941577-blank.wxx carries
+// an empty container, and 2025-2.06-13x11-941577-layers.wxx a "Terrain Layer"
+// holding one hex of "Classic/Flat Dead Soil".
 //
-// InnerXML captures whatever is between the tags VERBATIM, and that is the
-// element's entire modeling: nothing here understands a mapLayer or a
-// terrainAndLocation. The bytes round-trip 2025 -> 2025 intact, but the model
-// cannot answer a question about them, which is why encoding a populated
-// <extraTerrain> to a target that has no such element is an error rather than a
-// reported loss (#34 tracks modeling it; xmlio's downgradeLoss holds the
-// contract).
+// Classic has no such element: its terrain sits on one hard-coded layer, so a
+// downgrade drops these placements and reports them (see xmlio's
+// DroppedFeature_t).
 type ExtraTerrain_t struct {
-	InnerXML string `json:"innerXML,omitempty"`
+	MapLayers []*ExtraTerrainLayer_t `json:"mapLayers,omitempty"`
+}
+
+// ExtraTerrainLayer_t is one <mapLayer> inside <extraTerrain>: the terrain
+// placed on the map layer called Name.
+type ExtraTerrainLayer_t struct {
+	Name    string                  `json:"name,omitempty"`
+	Terrain []*TerrainAndLocation_t `json:"terrain,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
+}
+
+// TerrainAndLocation_t is one hex of terrain on an extra-terrain layer. It
+// carries the same values a tile does, but names its terrain rather than
+// indexing the terrain map, and places it by map coordinates rather than by
+// tile row and column.
+type TerrainAndLocation_t struct {
+	Terrain   string  `json:"terrain,omitempty"` // terrain name, a key of TerrainMap.Data
+	Elevation float64 `json:"elevation,omitempty"`
+	IsIcy     bool    `json:"isIcy,omitempty"`
+	IsGMOnly  bool    `json:"isGMOnly,omitempty"`
+
+	// Resources is the resources attribute verbatim. Every sample states "Z",
+	// the compressed form tiles use for "no resources"; the uncompressed
+	// spelling has not been seen, so it is carried rather than guessed at.
+	Resources string `json:"resources,omitempty"`
+
+	// X and Y locate the hex's center in map coordinates, the space feature and
+	// label locations use.
+	X float64 `json:"x,omitempty"`
+	Y float64 `json:"y,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type GridAndNumbering_t struct {
@@ -225,6 +330,8 @@ type GridAndNumbering_t struct {
 	NumberPosition              string  `json:"numberPosition,omitempty"`  // "BOTTOM" or ??
 	NumberPrePad                string  `json:"numberPrePad,omitempty"`    // "DOUBLE_ZERO" or ??
 	NumberSeparator             string  `json:"numberSeparator,omitempty"` // "." or free text?
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Information_t struct {
@@ -242,6 +349,12 @@ type Information_t struct {
 
 	Details   []*InformationDetail_t `json:"details,omitempty"`
 	InnerText string                 `json:"innerText,omitempty"`
+	// IsCDATA records that the file wrote InnerText as a CDATA section, as
+	// Worldographer writes the HTML of an entry. The encoders write it the same
+	// way, and any whitespace it ends with after the section.
+	IsCDATA bool `json:"isCDATA,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Informations_t struct {
@@ -262,7 +375,14 @@ type InformationDetail_t struct {
 	HolySymbol   string `json:"holySymbol,omitempty"`
 	Domains      string `json:"domains,omitempty"`
 
-	InnerText string `json:"innerText,omitempty"`
+	// Details are the entries nested in this one, as deep as the file nests
+	// them.
+	Details   []*InformationDetail_t `json:"details,omitempty"`
+	InnerText string                 `json:"innerText,omitempty"`
+	// IsCDATA is as for Information_t.
+	IsCDATA bool `json:"isCDATA,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Label_t struct {
@@ -285,6 +405,8 @@ type Label_t struct {
 
 	Location  *LabelLocation_t `json:"location,omitempty"`
 	InnerText string           `json:"innerText,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type LabelLocation_t struct {
@@ -292,6 +414,8 @@ type LabelLocation_t struct {
 	X         float64 `json:"x,omitempty"`
 	Y         float64 `json:"y,omitempty"`
 	Scale     float64 `json:"scale,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type LabelStyle_t struct {
@@ -310,6 +434,8 @@ type LabelStyle_t struct {
 	DropShadowColor  string  `json:"dropShadowColor,omitempty"`
 	DropShadowRadius float64 `json:"dropShadowRadius,omitempty"`
 	DropShadowSpread float64 `json:"dropShadowSpread,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type MapKey_t struct {
@@ -337,12 +463,16 @@ type MapKey_t struct {
 	EntryFontBold     bool    `json:"entryFontBold,omitempty"`
 	EntryFontItalic   bool    `json:"entryFontItalic,omitempty"`
 	EntryScale        float64 `json:"entryScale,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type MapLayer_t struct {
 	Name      string  `json:"name"`
 	IsVisible bool    `json:"isVisible"`
 	Opacity   float64 `json:"opacity,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Note_t struct {
@@ -361,12 +491,16 @@ type Note_t struct {
 
 	// notetext CDATA body
 	NoteText string `json:"notetext,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Point_t struct {
 	Type string  `json:"type,omitempty"`
 	X    float64 `json:"x,omitempty"`
 	Y    float64 `json:"y,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Projection_e int
@@ -378,20 +512,28 @@ const (
 )
 
 type Resources_t struct {
-	Animal int
-	Brick  int
-	Crops  int
-	Gems   int
-	Lumber int
-	Metals int
-	Rock   int
+	Animal int `json:"animal,omitempty"`
+	Brick  int `json:"brick,omitempty"`
+	Crops  int `json:"crops,omitempty"`
+	Gems   int `json:"gems,omitempty"`
+	Lumber int `json:"lumber,omitempty"`
+	Metals int `json:"metals,omitempty"`
+	Rock   int `json:"rock,omitempty"`
 }
 
+// RGBA_t is a Worldographer color. Color fields are pointers: nil is "no color",
+// which a file states as "null" or by leaving the attribute out, and an RGBA_t is
+// an explicit color, opaque black included.
 type RGBA_t struct {
-	R float64
-	G float64
-	B float64
-	A float64
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+	A float64 `json:"a"`
+
+	// Raw is the color as the file spelled it, "0.0,0.0,0.0,1.0" or "0,0,0,1", and
+	// "" for a color made in code. The encoders write it in place of the floats
+	// while it still parses to them, so an unedited color keeps its spelling.
+	Raw string `json:"raw,omitempty"`
 }
 
 type Shape_t struct {
@@ -437,6 +579,8 @@ type Shape_t struct {
 	Type                  string  `json:"type,omitempty"`
 
 	Points []*Point_t `json:"points,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type ShapeConfig_t struct {
@@ -476,6 +620,8 @@ type ShapeStyle_t struct {
 	// W2025 line rendering attributes (mirrors Shape_t.LineCap/LineJoin).
 	LineCap  string `json:"lineCap,omitempty"`
 	LineJoin string `json:"lineJoin,omitempty"`
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
 
 type Terrain_t struct {
@@ -501,23 +647,38 @@ type TextureConfig_t struct {
 	InnerText string `json:"innerText,omitempty"`
 }
 
+// Tile_t is one tile, as the accessors of Tiles_t return and take it.
 type Tile_t struct {
-	Coords                hexg.CubeCoord
-	Row                   int
-	Column                int
-	Terrain               int // lookup into TerrainMap
-	Elevation             float64
-	IsIcy                 bool
-	IsGMOnly              bool
-	Resources             Resources_t
-	CustomBackgroundColor *RGBA_t
+	Coords                hexg.CubeCoord `json:"-"` // follows from Row, Column and the orientation
+	Row                   int            `json:"row"`
+	Column                int            `json:"column"`
+	Terrain               int            `json:"terrain"` // lookup into TerrainMap
+	Elevation             float64        `json:"elevation,omitzero"`
+	IsIcy                 bool           `json:"isIcy,omitempty"`
+	IsGMOnly              bool           `json:"isGMOnly,omitempty"`
+	Resources             Resources_t    `json:"resources,omitzero"`
+	CustomBackgroundColor *RGBA_t        `json:"customBackgroundColor,omitempty"`
 }
 
+// Tiles_t is the <tiles> element and the grid of tiles it holds. The tiles are
+// kept in a contiguous store of compact values rather than as a Tile_t each; see
+// tiles.go for the accessors, which index the grid by [x][y] or by cube
+// coordinate.
 type Tiles_t struct {
 	ViewLevel string `json:"viewLevel,omitempty"`
 	TilesWide int    `json:"tilesWide,omitempty"` // number of columns of tiles (x)
 	TilesHigh int    `json:"tilesHigh,omitempty"` // number of rows of tiles    (y)
 
-	// Tiles is a two-dimensional array that is indexed by [col][row]
-	Tiles [][]*Tile_t `json:"tiles,omitempty"`
+	// Orientation is the map's grid orientation, which decides the cube
+	// coordinates of the tiles. The decoders copy it from the map.
+	Orientation hexg.Orientation_e `json:"-"`
+
+	// the tiles, one column after another: the tile at [x][y] is cells[x*high+y]
+	cells []tileCell
+	high  int
+	// colors holds the custom background colors; a cell's color is
+	// colors[color-1], and a color of 0 is none
+	colors []*RGBA_t
+
+	Extras *Extras_t `json:"extras,omitempty"`
 }
@@ -0,0 +1,123 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+)
+
+// palette colors terrain that WithTerrainColors does not. A terrain takes the
+// color of the first word its name contains, ignoring case, so "Flat Forest
+// Deciduous" is a forest and "Classic/Flat Beach" a beach; the more specific
+// words come first.
+var palette = []struct{ word, color string }{
+	{"blank", "#ffffff"},
+	{"border only", "#ffffff"},
+	{"ocean", "#2b5c8a"},
+	{"sea", "#2b5c8a"},
+	{"reef", "#3f8fa6"},
+	{"lake", "#4a86b8"},
+	{"water", "#4a86b8"},
+	{"river", "#4a86b8"},
+	{"volcano", "#5a3d33"},
+	{"mountain", "#8c8272"},
+	{"hill", "#b4a26b"},
+	{"jungle", "#2e6b2e"},
+	{"forest", "#3f7d3a"},
+	{"wood", "#3f7d3a"},
+	{"swamp", "#5c6b45"},
+	{"marsh", "#6f8a5a"},
+	{"desert", "#e0c98f"},
+	{"dune", "#e0c98f"},
+	{"beach", "#eedfae"},
+	{"sand", "#eedfae"},
+	{"glacier", "#dcebf2"},
+	{"snow", "#f2f5f7"},
+	{"ice", "#dcebf2"},
+	{"tundra", "#c9d3c5"},
+	{"dead", "#9a8468"},
+	{"badland", "#b98a5e"},
+	{"farm", "#c3cf7a"},
+	{"grass", "#a9c779"},
+	{"plain", "#b8cf86"},
+	{"flat", "#b8cf86"},
+}
+
+// unknownColor fills terrain whose name contains none of the palette's words.
+const unknownColor = "#d9d4c7"
+
+// terrainColor returns the fill of the terrain called name.
+func (r *renderer) terrainColor(name string) string {
+	if c, ok := r.opts.terrainColors[name]; ok {
+		return c
+	}
+	lower := strings.ToLower(name)
+	for _, p := range palette {
+		if strings.Contains(lower, p.word) {
+			return p.color
+		}
+	}
+	return unknownColor
+}
+
+// paint is an SVG color and the opacity that goes with it.
+type paint struct {
+	color   string
+	opacity float64
+}
+
+// attrs returns the attributes that paint an element's fill or stroke, named
+// by property, with p.
+func (p paint) attrs(property string) string {
+	if p.opacity >= 1 {
+		return fmt.Sprintf(` %s="%s"`, property, p.color)
+	}
+	return fmt.Sprintf(` %s="%s" %s-opacity="%s"`, property, p.color, property, num(math.Max(0, p.opacity)))
+}
+
+// rgba returns the paint of a Worldographer color, and false for no color.
+func rgba(c *wxx.RGBA_t) (paint, bool) {
+	if c == nil {
+		return paint{}, false
+	}
+	return paint{color: hexColor(c.R, c.G, c.B), opacity: c.A}, true
+}
+
+// parsePaint returns the paint of a color that a string attribute holds, either
+// as the components of an RGBA_t ("0.31,0.57,0.68,1.0") or as the "0x00000040"
+// of the grid, and false for "", "null" or anything else.
+func parsePaint(s string) (paint, bool) {
+	if hex, ok := strings.CutPrefix(s, "0x"); ok && len(hex) == 8 {
+		n, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return paint{}, false
+		}
+		return paint{color: "#" + hex[:6], opacity: float64(n&0xff) / 255}, true
+	}
+	fields := strings.Split(s, ",")
+	if len(fields) != 4 {
+		return paint{}, false
+	}
+	var v [4]float64
+	for i, field := range fields {
+		f, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return paint{}, false
+		}
+		v[i] = f
+	}
+	return paint{color: hexColor(v[0], v[1], v[2]), opacity: v[3]}, true
+}
+
+// hexColor returns the #rrggbb of components between 0 and 1.
+func hexColor(r, g, b float64) string {
+	component := func(f float64) int {
+		return int(math.Round(math.Max(0, math.Min(1, f)) * 255))
+	}
+	return fmt.Sprintf("#%02x%02x%02x", component(r), component(g), component(b))
+}
@@ -0,0 +1,586 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package svg draws a Worldographer map as an SVG image.
+//
+// Render draws every tile, filled by its terrain or its custom background
+// color, the terrain painted on map layers, the shapes, the features as
+// markers, the labels, and the grid and its numbering. Each is drawn on its map
+// layer: the layers are stacked as Worldographer lists them, the first on top,
+// a hidden layer is left out and the others are drawn at their opacity. The
+// tiles are on "Terrain Land" and the grid on "Grid"; anything on a layer the
+// map does not list is drawn above the layers.
+//
+// Content the map marks GM-only is left out unless WithGMOnly asks for it, so
+// that the default image is one to show the players.
+//
+// The image is drawn in the map's own units, those of HexWidth and HexHeight,
+// with y pointing down. Widths and font sizes the file states in location
+// space, the space Worldographer places features, labels and shapes in, are
+// scaled as the locations are; the grid's widths are pixels.
+package svg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/hexg"
+)
+
+const (
+	// terrainLayer and gridLayer are the map layers the tiles and the grid are
+	// drawn on, when the map lists them.
+	terrainLayer = "Terrain Land"
+	gridLayer    = "Grid"
+
+	// defaultLabelScale and defaultNumberSize are the font sizes, in location
+	// space, of a label and a grid number that state none.
+	defaultLabelScale = 25.0
+	defaultNumberSize = 20
+)
+
+// Option configures Render.
+type Option func(*options)
+
+type options struct {
+	terrainColors map[string]string
+	gmOnly        bool
+	grid          *bool
+	gridNumbers   *bool
+	scale         float64
+}
+
+// WithTerrainColors fills the terrain called name with colors[name], any SVG
+// color. Terrain it does not name is colored by a built-in palette.
+func WithTerrainColors(colors map[string]string) Option {
+	return func(o *options) {
+		o.terrainColors = colors
+	}
+}
+
+// WithGMOnly draws the tiles, terrain, features, labels and shapes the map
+// marks GM-only when show is true.
+func WithGMOnly(show bool) Option {
+	return func(o *options) {
+		o.gmOnly = show
+	}
+}
+
+// WithGrid draws the hex grid, or does not, overriding the map's ShowGrid.
+func WithGrid(show bool) Option {
+	return func(o *options) {
+		o.grid = &show
+	}
+}
+
+// WithGridNumbers numbers the tiles, or does not, overriding the map's
+// ShowGridNumbers.
+func WithGridNumbers(show bool) Option {
+	return func(o *options) {
+		o.gridNumbers = &show
+	}
+}
+
+// WithScale sets the pixels per map unit of the image's width and height. The
+// default is 1, a hex HexWidth pixels wide.
+func WithScale(pixels float64) Option {
+	return func(o *options) {
+		o.scale = pixels
+	}
+}
+
+// Render draws m as an SVG image to w.
+func Render(w io.Writer, m *wxx.Map_t, opts ...Option) error {
+	if m == nil {
+		return fmt.Errorf("svg: nil map")
+	}
+	layout, err := m.Layout()
+	if err != nil {
+		return fmt.Errorf("svg: %w", err)
+	}
+	r := &renderer{m: m, layout: layout, opts: options{scale: 1}}
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	if !(r.opts.scale > 0) {
+		return fmt.Errorf("svg: invalid scale %g", r.opts.scale)
+	}
+	r.scaleX, r.scaleY = m.LocationScale()
+	r.minX, r.minY, r.maxX, r.maxY = 0, 0, m.HexWidth, m.HexHeight
+	r.stackLayers()
+
+	r.drawTiles()
+	r.drawExtraTerrain()
+	r.drawShapes()
+	r.drawFeatures()
+	r.drawLabels()
+	r.drawGrid()
+
+	return r.write(w)
+}
+
+// renderer draws one map into the buffers of its layers.
+type renderer struct {
+	m              *wxx.Map_t
+	layout         hexg.Layout
+	opts           options
+	scaleX, scaleY float64 // from location space to map units
+
+	// layers are drawn first to last. base holds what belongs below every
+	// layer and top what belongs above them.
+	layers    []*layer
+	named     map[string]*layer
+	base, top *layer
+
+	// the box around the tiles
+	minX, minY, maxX, maxY float64
+}
+
+// layer is a map layer and the elements drawn on it.
+type layer struct {
+	name    string
+	visible bool
+	opacity float64
+	buf     bytes.Buffer
+}
+
+// stackLayers makes a layer for each of the map's layers, the last listed
+// first, between base and top.
+func (r *renderer) stackLayers() {
+	r.named = map[string]*layer{}
+	r.base = &layer{visible: true, opacity: 1}
+	r.layers = append(r.layers, r.base)
+	for i := len(r.m.MapLayers) - 1; i >= 0; i-- {
+		ml := r.m.MapLayers[i]
+		if ml == nil {
+			continue
+		} else if _, ok := r.named[ml.Name]; ok {
+			continue
+		}
+		l := &layer{name: ml.Name, visible: ml.IsVisible, opacity: ml.Opacity}
+		if l.opacity <= 0 || l.opacity > 1 {
+			l.opacity = 1 // a classic file states no opacity
+		}
+		r.named[ml.Name] = l
+		r.layers = append(r.layers, l)
+	}
+	r.top = &layer{visible: true, opacity: 1}
+	r.layers = append(r.layers, r.top)
+}
+
+// layer returns the layer called name, or fallback if the map lists none.
+func (r *renderer) layer(name string, fallback *layer) *layer {
+	if l, ok := r.named[name]; ok {
+		return l
+	}
+	return fallback
+}
+
+func (r *renderer) drawTiles() {
+	if r.m.Tiles == nil {
+		return
+	}
+	terrains := map[int]string{}
+	if r.m.TerrainMap != nil {
+		for _, t := range r.m.TerrainMap.List {
+			if t != nil {
+				terrains[t.Index] = t.Label
+			}
+		}
+	}
+	l := r.layer(terrainLayer, r.base)
+	wide, _ := r.m.Tiles.Size()
+	var column []wxx.Tile_t
+	for x := 0; x < wide; x++ {
+		column = r.m.Tiles.ColumnTiles(column[:0], x)
+		for y, t := range column {
+			corners := r.layout.PolygonCorners(r.m.TileCoord(x, y))
+			for _, c := range corners {
+				cx, cy := c.XY()
+				r.minX, r.minY = math.Min(r.minX, cx), math.Min(r.minY, cy)
+				r.maxX, r.maxY = math.Max(r.maxX, cx), math.Max(r.maxY, cy)
+			}
+			if t.IsGMOnly && !r.opts.gmOnly {
+				continue
+			}
+			fill := paint{color: r.terrainColor(terrains[t.Terrain]), opacity: 1}
+			if p, ok := rgba(t.CustomBackgroundColor); ok {
+				fill = p
+			}
+			fmt.Fprintf(&l.buf, "<polygon class=\"tile\" points=\"%s\"%s/>\n", points(corners), fill.attrs("fill"))
+		}
+	}
+}
+
+// drawExtraTerrain draws the terrain painted on map layers, a hex centered on
+// each location.
+func (r *renderer) drawExtraTerrain() {
+	if r.m.ExtraTerrain == nil {
+		return
+	}
+	origin := hexg.NewCubeCoord(0, 0, 0)
+	ox, oy := r.layout.HexToPixel(origin).XY()
+	hex := r.layout.PolygonCorners(origin)
+	for _, et := range r.m.ExtraTerrain.MapLayers {
+		if et == nil {
+			continue
+		}
+		l := r.layer(et.Name, r.top)
+		for _, t := range et.Terrain {
+			if t == nil || t.IsGMOnly && !r.opts.gmOnly {
+				continue
+			}
+			x, y := r.location(t.X, t.Y)
+			corners := make([]hexg.Point, len(hex))
+			for i, c := range hex {
+				cx, cy := c.XY()
+				corners[i] = hexg.NewPoint(cx-ox+x, cy-oy+y)
+			}
+			fill := paint{color: r.terrainColor(t.Terrain), opacity: 1}
+			fmt.Fprintf(&l.buf, "<polygon class=\"terrain\" points=\"%s\"%s/>\n", points(corners), fill.attrs("fill"))
+		}
+	}
+}
+
+// drawShapes draws each shape through its points: a Polygon closed and filled
+// with the color of its fill texture, if it has one, and any other shape as an
+// open line. A curved shape is drawn as a smooth curve through its points.
+func (r *renderer) drawShapes() {
+	for _, s := range r.m.Shapes {
+		if s == nil || s.IsGMOnly && !r.opts.gmOnly {
+			continue
+		}
+		var pts []hexg.Point
+		for _, p := range s.Points {
+			if p != nil {
+				pts = append(pts, hexg.NewPoint(r.location(p.X, p.Y)))
+			}
+		}
+		if len(pts) < 2 {
+			continue
+		}
+		closed := s.Type == "Polygon" && len(pts) >= 3
+
+		var attrs strings.Builder
+		if closed && s.FillTexture != "" && s.FillTexture != "null" {
+			attrs.WriteString(paint{color: r.terrainColor(s.FillTexture), opacity: 1}.attrs("fill"))
+			if s.FillRule == "EVEN_ODD" {
+				attrs.WriteString(` fill-rule="evenodd"`)
+			}
+		} else {
+			attrs.WriteString(` fill="none"`)
+		}
+		if stroke, ok := parsePaint(s.StrokeColor); ok {
+			width := s.StrokeWidth * (r.scaleX + r.scaleY) / 2
+			attrs.WriteString(stroke.attrs("stroke"))
+			fmt.Fprintf(&attrs, ` stroke-width="%s"`, num(width))
+			switch s.StrokeType {
+			case "DOTTED":
+				fmt.Fprintf(&attrs, ` stroke-dasharray="%s %s"`, num(width), num(2*width))
+			case "DASHED":
+				fmt.Fprintf(&attrs, ` stroke-dasharray="%s %s"`, num(4*width), num(2*width))
+			}
+			if s.LineCap != "" {
+				fmt.Fprintf(&attrs, ` stroke-linecap="%s"`, strings.ToLower(s.LineCap))
+			}
+			if s.LineJoin != "" {
+				fmt.Fprintf(&attrs, ` stroke-linejoin="%s"`, strings.ToLower(s.LineJoin))
+			}
+		}
+		if o := opacity(s.Opacity); o < 1 {
+			fmt.Fprintf(&attrs, ` opacity="%s"`, num(o))
+		}
+
+		l := r.layer(s.MapLayer, r.top)
+		fmt.Fprintf(&l.buf, "<path class=\"shape\" d=\"%s\"%s/>\n", pathData(pts, closed, s.IsCurve), attrs.String())
+	}
+}
+
+// drawFeatures draws a marker at each feature's location, titled with its
+// type, and its label if the map shows feature labels.
+func (r *renderer) drawFeatures() {
+	radius := math.Min(r.m.HexWidth, r.m.HexHeight) / 6
+	for _, f := range r.m.Features {
+		if f == nil || f.Location == nil || f.IsGMOnly && !r.opts.gmOnly {
+			continue
+		}
+		l := r.layer(f.MapLayer, r.top)
+		x, y := r.location(f.Location.X, f.Location.Y)
+		fill, ok := rgba(f.Color)
+		if !ok {
+			fill = paint{color: "#333333", opacity: 1}
+		}
+		ring, ok := rgba(f.RingColor)
+		if !ok {
+			ring = paint{color: "#ffffff", opacity: 1}
+		}
+		fmt.Fprintf(&l.buf, "<circle class=\"feature\" cx=\"%s\" cy=\"%s\" r=\"%s\"%s%s stroke-width=\"%s\"><title>%s</title></circle>\n",
+			num(x), num(y), num(radius), fill.attrs("fill"), ring.attrs("stroke"), num(radius/4), escape(f.Type))
+		if r.m.ShowFeatureLabels && f.Label != nil {
+			r.drawLabel(l, f.Label)
+		}
+	}
+}
+
+func (r *renderer) drawLabels() {
+	for _, label := range r.m.Labels {
+		if label != nil {
+			r.drawLabel(r.layer(label.MapLayer, r.top), label)
+		}
+	}
+}
+
+// drawLabel draws the text of label centered on its location, rotated about it
+// by its rotation, with its font, weight, style and color and the outline it
+// states.
+func (r *renderer) drawLabel(l *layer, label *wxx.Label_t) {
+	if label.Location == nil || strings.TrimSpace(label.InnerText) == "" || label.IsGMOnly && !r.opts.gmOnly {
+		return
+	}
+	x, y := r.location(label.Location.X, label.Location.Y)
+	size := label.Location.Scale
+	if size <= 0 {
+		size = defaultLabelScale
+	}
+	var attrs strings.Builder
+	if label.FontFace != "" {
+		fmt.Fprintf(&attrs, ` font-family="%s"`, escape(label.FontFace))
+	}
+	fmt.Fprintf(&attrs, ` font-size="%s"`, num(size*r.scaleY))
+	if label.IsBold {
+		attrs.WriteString(` font-weight="bold"`)
+	}
+	if label.IsItalic {
+		attrs.WriteString(` font-style="italic"`)
+	}
+	fill, ok := rgba(label.Color)
+	if !ok {
+		fill = paint{color: "#000000", opacity: 1}
+	}
+	attrs.WriteString(fill.attrs("fill"))
+	if outline, ok := rgba(label.OutlineColor); ok && label.OutlineSize > 0 {
+		fmt.Fprintf(&attrs, `%s stroke-width="%s" paint-order="stroke"`, outline.attrs("stroke"), num(label.OutlineSize*r.scaleY))
+	}
+	if label.Rotate != 0 {
+		fmt.Fprintf(&attrs, ` transform="rotate(%s %s %s)"`, num(label.Rotate), num(x), num(y))
+	}
+	fmt.Fprintf(&l.buf, "<text class=\"label\" x=\"%s\" y=\"%s\" text-anchor=\"middle\" dominant-baseline=\"central\"%s>%s</text>\n",
+		num(x), num(y), attrs.String(), escape(label.InnerText))
+}
+
+// drawGrid outlines every tile with the grid's first color and width, and
+// numbers them as the map's grid and numbering settings say.
+func (r *renderer) drawGrid() {
+	if r.m.Tiles == nil {
+		return
+	}
+	showGrid, showNumbers := r.m.ShowGrid, r.m.ShowGridNumbers
+	if r.opts.grid != nil {
+		showGrid = *r.opts.grid
+	}
+	if r.opts.gridNumbers != nil {
+		showNumbers = *r.opts.gridNumbers
+	}
+	if !showGrid && !showNumbers {
+		return
+	}
+	g := r.m.GridAndNumbering
+	if g == nil {
+		g = &wxx.GridAndNumbering_t{}
+	}
+	l := r.layer(gridLayer, r.top)
+
+	wide, high := r.m.Tiles.Size()
+	if showGrid {
+		line, ok := parsePaint(g.Color0)
+		if !ok {
+			line = paint{color: "#000000", opacity: 0.25}
+		}
+		width := g.Width0
+		if width <= 0 {
+			width = 1
+		}
+		var d strings.Builder
+		for x := 0; x < wide; x++ {
+			for y := 0; y < high; y++ {
+				d.WriteString(pathData(r.layout.PolygonCorners(r.m.TileCoord(x, y)), true, false))
+			}
+		}
+		fmt.Fprintf(&l.buf, "<path class=\"grid\" d=\"%s\" fill=\"none\"%s stroke-width=\"%s\" vector-effect=\"non-scaling-stroke\"/>\n",
+			d.String(), line.attrs("stroke"), num(width))
+	}
+
+	if showNumbers {
+		color, ok := parsePaint(g.NumberColor)
+		if !ok {
+			color = paint{color: "#000000", opacity: 1}
+		}
+		size := g.NumberSize
+		if size <= 0 {
+			size = defaultNumberSize
+		}
+		var attrs strings.Builder
+		if g.NumberFont != "" {
+			fmt.Fprintf(&attrs, ` font-family="%s"`, escape(g.NumberFont))
+		}
+		fmt.Fprintf(&attrs, ` font-size="%s"`, num(float64(size)*r.scaleY))
+		if strings.Contains(g.NumberStyle, "BOLD") {
+			attrs.WriteString(` font-weight="bold"`)
+		}
+		if strings.Contains(g.NumberStyle, "ITALIC") {
+			attrs.WriteString(` font-style="italic"`)
+		}
+		attrs.WriteString(color.attrs("fill"))
+		var offset float64
+		switch g.NumberPosition {
+		case "TOP":
+			offset = -0.3 * r.m.HexHeight
+		case "BOTTOM":
+			offset = 0.3 * r.m.HexHeight
+		}
+		fmt.Fprintf(&l.buf, "<g class=\"grid-numbers\" text-anchor=\"middle\" dominant-baseline=\"central\"%s>\n", attrs.String())
+		for x := 0; x < wide; x++ {
+			for y := 0; y < high; y++ {
+				cx, cy := r.layout.HexToPixel(r.m.TileCoord(x, y)).XY()
+				column, row := r.m.TileColumnRow(x, y)
+				fmt.Fprintf(&l.buf, "<text x=\"%s\" y=\"%s\">%s</text>\n", num(cx), num(cy+offset), escape(gridNumber(g, column, row)))
+			}
+		}
+		l.buf.WriteString("</g>\n")
+	}
+}
+
+// gridNumber returns the number of the tile at column and row, counted from the
+// first column and row, each padded to two digits for DOUBLE_ZERO, joined by
+// the separator in the order the numbering states.
+func gridNumber(g *wxx.GridAndNumbering_t, column, row int) string {
+	format := "%d"
+	if g.NumberPrePad == "DOUBLE_ZERO" {
+		format = "%02d"
+	}
+	c, r := fmt.Sprintf(format, column+g.NumberFirstCol), fmt.Sprintf(format, row+g.NumberFirstRow)
+	if g.NumberOrder == "ROW_COL" {
+		return r + g.NumberSeparator + c
+	}
+	return c + g.NumberSeparator + r
+}
+
+// write writes the image: the visible layers that have anything on them, each
+// a group at its opacity, in a viewBox around the tiles.
+func (r *renderer) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	width, height := r.maxX-r.minX, r.maxY-r.minY
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" viewBox=\"%s %s %s %s\" width=\"%s\" height=\"%s\">\n",
+		num(r.minX), num(r.minY), num(width), num(height), num(width*r.opts.scale), num(height*r.opts.scale))
+	for _, l := range r.layers {
+		if !l.visible || l.buf.Len() == 0 {
+			continue
+		}
+		bw.WriteString("<g class=\"layer\"")
+		if l.name != "" {
+			fmt.Fprintf(bw, " data-name=\"%s\"", escape(l.name))
+		}
+		if l.opacity < 1 {
+			fmt.Fprintf(bw, " opacity=\"%s\"", num(l.opacity))
+		}
+		bw.WriteString(">\n")
+		bw.Write(l.buf.Bytes())
+		bw.WriteString("</g>\n")
+	}
+	bw.WriteString("</svg>\n")
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("svg: %w", err)
+	}
+	return nil
+}
+
+// location returns the point in map units of x, y in location space.
+func (r *renderer) location(x, y float64) (float64, float64) {
+	return x * r.scaleX, y * r.scaleY
+}
+
+// opacity returns a shape's opacity between 0 and 1. Files state it both as a
+// fraction and as a percentage, and a classic file not at all.
+func opacity(o float64) float64 {
+	if o > 1 {
+		o /= 100
+	}
+	if o <= 0 || o > 1 {
+		return 1
+	}
+	return o
+}
+
+// points returns the points attribute of a polygon through pts.
+func points(pts []hexg.Point) string {
+	var b strings.Builder
+	for i, p := range pts {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		x, y := p.XY()
+		b.WriteString(num(x) + "," + num(y))
+	}
+	return b.String()
+}
+
+// pathData returns the path through pts, closed if closed, and a Catmull-Rom
+// curve rather than straight lines if curve.
+func pathData(pts []hexg.Point, closed, curve bool) string {
+	var b strings.Builder
+	x, y := pts[0].XY()
+	b.WriteString("M" + num(x) + " " + num(y))
+	if !curve {
+		for _, p := range pts[1:] {
+			x, y := p.XY()
+			b.WriteString("L" + num(x) + " " + num(y))
+		}
+	} else {
+		n := len(pts)
+		at := func(i int) (float64, float64) {
+			if closed {
+				return pts[(i+n)%n].XY()
+			}
+			return pts[max(0, min(n-1, i))].XY()
+		}
+		segments := n - 1
+		if closed {
+			segments = n
+		}
+		for i := 0; i < segments; i++ {
+			x0, y0 := at(i - 1)
+			x1, y1 := at(i)
+			x2, y2 := at(i + 1)
+			x3, y3 := at(i + 2)
+			b.WriteString("C" + num(x1+(x2-x0)/6) + " " + num(y1+(y2-y0)/6) +
+				" " + num(x2-(x3-x1)/6) + " " + num(y2-(y3-y1)/6) +
+				" " + num(x2) + " " + num(y2))
+		}
+	}
+	if closed {
+		b.WriteString("Z")
+	}
+	return b.String()
+}
+
+// num returns f rounded to a thousandth, as short as it can be spelled.
+func num(f float64) string {
+	f = math.Round(f*1000) / 1000
+	if f == 0 {
+		return "0" // not -0
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// escape returns s escaped for the text or an attribute of an element.
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
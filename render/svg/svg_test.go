@@ -0,0 +1,134 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package svg_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/render/svg"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+const (
+	columnsFixture   = "../../testdata/2025-2.06-13x11-941577-layers.wxx"
+	rowsFixture      = "../../testdata/2017-1.77-1.0-rows-blank.wxx"
+	populatedFixture = "../../testdata/w2025-populated.xml"
+)
+
+// render returns the image of m, after checking that it is well-formed XML.
+func render(t *testing.T, m *wxx.Map_t, opts ...svg.Option) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := svg.Render(&buf, m, opts...); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	d := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	for {
+		if _, err := d.Token(); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("the image is not well-formed: %v", err)
+		}
+	}
+	return buf.String()
+}
+
+// TestRender asserts that every tile and feature of a COLUMNS map and of a ROWS
+// map is drawn, that the tiles are filled by terrain unless they have a custom
+// background color, and that the grid is numbered when asked.
+func TestRender(t *testing.T) {
+	for _, fixture := range []string{columnsFixture, rowsFixture} {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			m, err := xmlio.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			tiles := m.Tiles.TilesWide * m.Tiles.TilesHigh
+			m.Tiles.SetTile(0, 1, wxx.Tile_t{CustomBackgroundColor: &wxx.RGBA_t{R: 1, A: 1}})
+			image := render(t, m, svg.WithTerrainColors(map[string]string{"Blank": "#123456"}), svg.WithGridNumbers(true))
+
+			if got := strings.Count(image, `class="tile"`); got != tiles {
+				t.Errorf("%d tiles, want %d", got, tiles)
+			}
+			if got := strings.Count(image, `class="feature"`); got != len(m.Features) || got == 0 {
+				t.Errorf("%d features, want the fixture's %d", got, len(m.Features))
+			}
+			if got := strings.Count(image, `fill="#ff0000"`); got != 1 {
+				t.Errorf("%d tiles of the custom background color, want 1", got)
+			}
+			if !strings.Contains(image, `fill="#123456"`) {
+				t.Errorf("the Blank tiles are not filled with the configured color")
+			}
+			// both fixtures number from 0,0 as COL_ROW with DOUBLE_ZERO and "."
+			if got := strings.Count(image, ">01.00</text>"); got != 1 {
+				t.Errorf("the tile at column 1, row 0 is numbered %d times, want once", got)
+			}
+		})
+	}
+}
+
+// TestRenderExtent asserts that the image of a COLUMNS map and of a ROWS map is
+// as wide as its ColumnsWide columns of hexes and as high as its RowsHigh rows.
+func TestRenderExtent(t *testing.T) {
+	for _, fixture := range []string{columnsFixture, rowsFixture} {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			m, err := xmlio.ReadFile(fixture)
+			if err != nil {
+				t.Fatalf("ReadFile: %v", err)
+			}
+			var minX, minY, width, height float64
+			if _, err := fmt.Sscanf(render(t, m), `<svg xmlns="http://www.w3.org/2000/svg" viewBox="%g %g %g %g"`, &minX, &minY, &width, &height); err != nil {
+				t.Fatalf("viewBox: %v", err)
+			}
+			// hexes overlap by a quarter along the axis they are laid out on,
+			// and every other one is offset by half a hex across it
+			cols, rows := float64(m.ColumnsWide), float64(m.RowsHigh)
+			wantWidth, wantHeight := m.HexWidth*(1+0.75*(cols-1)), m.HexHeight*(rows+0.5)
+			if m.GridOrientation == hexg.OddR {
+				wantWidth, wantHeight = m.HexWidth*(cols+0.5), m.HexHeight*(1+0.75*(rows-1))
+			}
+			if math.Abs(width-wantWidth) > 0.01 || math.Abs(height-wantHeight) > 0.01 {
+				t.Errorf("%d x %d hexes are %g x %g, want %g x %g", m.ColumnsWide, m.RowsHigh, width, height, wantWidth, wantHeight)
+			}
+		})
+	}
+}
+
+// TestRenderFilters asserts that GM-only content is drawn only when asked for
+// and that a hidden layer is left out.
+func TestRenderFilters(t *testing.T) {
+	m, err := xmlio.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if got := strings.Count(render(t, m), `class="shape"`); got != 1 {
+		t.Errorf("%d shapes, want the one that is not GM-only", got)
+	}
+	image := render(t, m, svg.WithGMOnly(true))
+	if got := strings.Count(image, `class="shape"`); got != 2 {
+		t.Errorf("WithGMOnly: %d shapes, want 2", got)
+	}
+	if !strings.Contains(image, `data-name="Above Terrain"`) {
+		t.Errorf("the shapes' layer is not drawn")
+	}
+
+	for _, l := range m.MapLayers {
+		if l.Name == "Above Terrain" {
+			l.IsVisible = false
+		}
+	}
+	image = render(t, m, svg.WithGMOnly(true))
+	if strings.Contains(image, `class="shape"`) || strings.Contains(image, `data-name="Above Terrain"`) {
+		t.Errorf("the hidden layer is drawn")
+	}
+}
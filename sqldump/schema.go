@@ -0,0 +1,182 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package sqldump
+
+// Schema creates the tables Write loads maps into, where they do not exist.
+//
+// Every table is keyed by map_name, the name a map was written under, so the
+// maps of a whole campaign can share one database. The other tables list a
+// map's contents in the order the map holds them, by a 0-based index: terrains
+// by their index in the terrain map, features, labels, shapes, notes and
+// informations by their position in the file. A tile is keyed by its column and
+// row, and indexed by its cube coordinate, which is 0,0,0 for a map whose grid
+// orientation has none.
+//
+// Colors are text, as the file spells them ("0.1,0.2,0.3,1.0"); booleans are
+// BOOLEAN, which SQLite stores as 0 and 1.
+const Schema = `CREATE TABLE IF NOT EXISTS maps (
+  map_name         TEXT PRIMARY KEY,
+  app_version      TEXT NOT NULL,
+  schema_version   TEXT,
+  app_release      TEXT,
+  map_type         TEXT,
+  hex_orientation  TEXT,
+  hex_width        REAL,
+  hex_height       REAL,
+  tiles_wide       INTEGER NOT NULL,
+  tiles_high       INTEGER NOT NULL,
+  columns_wide     INTEGER,
+  rows_high        INTEGER,
+  last_view_level  TEXT
+);
+
+CREATE TABLE IF NOT EXISTS terrains (
+  map_name         TEXT NOT NULL REFERENCES maps (map_name),
+  terrain_index    INTEGER NOT NULL,
+  label            TEXT NOT NULL,
+  PRIMARY KEY (map_name, terrain_index)
+);
+
+CREATE TABLE IF NOT EXISTS tiles (
+  map_name         TEXT NOT NULL REFERENCES maps (map_name),
+  column_index     INTEGER NOT NULL,
+  row_index        INTEGER NOT NULL,
+  q                INTEGER NOT NULL,
+  r                INTEGER NOT NULL,
+  s                INTEGER NOT NULL,
+  terrain_index    INTEGER NOT NULL,
+  elevation        REAL,
+  is_icy           BOOLEAN NOT NULL,
+  is_gm_only       BOOLEAN NOT NULL,
+  animal           INTEGER NOT NULL,
+  brick            INTEGER NOT NULL,
+  crops            INTEGER NOT NULL,
+  gems             INTEGER NOT NULL,
+  lumber           INTEGER NOT NULL,
+  metals           INTEGER NOT NULL,
+  rock             INTEGER NOT NULL,
+  background_color TEXT,
+  PRIMARY KEY (map_name, column_index, row_index)
+);
+
+CREATE INDEX IF NOT EXISTS tiles_cube ON tiles (map_name, q, r, s);
+
+CREATE TABLE IF NOT EXISTS features (
+  map_name         TEXT NOT NULL REFERENCES maps (map_name),
+  feature_index    INTEGER NOT NULL,
+  feature_type     TEXT,
+  uuid             TEXT,
+  map_layer        TEXT,
+  view_level       TEXT,
+  x                REAL,
+  y                REAL,
+  rotate           REAL,
+  scale            REAL,
+  is_gm_only       BOOLEAN NOT NULL,
+  label            TEXT,
+  PRIMARY KEY (map_name, feature_index)
+);
+
+CREATE TABLE IF NOT EXISTS labels (
+  map_name         TEXT NOT NULL REFERENCES maps (map_name),
+  label_index      INTEGER NOT NULL,
+  map_layer        TEXT,
+  style            TEXT,
+  font_face        TEXT,
+  color            TEXT,
+  view_level       TEXT,
+  x                REAL,
+  y                REAL,
+  scale            REAL,
+  rotate           REAL,
+  is_gm_only       BOOLEAN NOT NULL,
+  tags             TEXT,
+  label_text       TEXT,
+  PRIMARY KEY (map_name, label_index)
+);
+
+CREATE TABLE IF NOT EXISTS shapes (
+  map_name           TEXT NOT NULL REFERENCES maps (map_name),
+  shape_index        INTEGER NOT NULL,
+  shape_type         TEXT,
+  map_layer          TEXT,
+  stroke_type        TEXT,
+  stroke_color       TEXT,
+  stroke_width       REAL,
+  opacity            REAL,
+  fill_texture       TEXT,
+  is_curve           BOOLEAN NOT NULL,
+  is_gm_only         BOOLEAN NOT NULL,
+  highest_view_level TEXT,
+  tags               TEXT,
+  PRIMARY KEY (map_name, shape_index)
+);
+
+CREATE TABLE IF NOT EXISTS points (
+  map_name         TEXT NOT NULL,
+  shape_index      INTEGER NOT NULL,
+  point_index      INTEGER NOT NULL,
+  point_type       TEXT,
+  x                REAL,
+  y                REAL,
+  PRIMARY KEY (map_name, shape_index, point_index),
+  FOREIGN KEY (map_name, shape_index) REFERENCES shapes (map_name, shape_index)
+);
+
+CREATE TABLE IF NOT EXISTS notes (
+  map_name         TEXT NOT NULL REFERENCES maps (map_name),
+  note_index       INTEGER NOT NULL,
+  note_key         TEXT,
+  title            TEXT,
+  view_level       TEXT,
+  x                REAL,
+  y                REAL,
+  filename         TEXT,
+  parent           TEXT,
+  color            TEXT,
+  is_gm_only       BOOLEAN NOT NULL,
+  note_text        TEXT,
+  PRIMARY KEY (map_name, note_index)
+);
+
+CREATE TABLE IF NOT EXISTS informations (
+  map_name          TEXT NOT NULL REFERENCES maps (map_name),
+  information_index INTEGER NOT NULL,
+  uuid              TEXT,
+  information_type  TEXT,
+  title             TEXT,
+  rulers            TEXT,
+  government        TEXT,
+  cultures          TEXT,
+  language          TEXT,
+  religion_type     TEXT,
+  culture           TEXT,
+  holy_symbol       TEXT,
+  domains           TEXT,
+  information_text  TEXT,
+  PRIMARY KEY (map_name, information_index)
+);
+
+CREATE TABLE IF NOT EXISTS information_details (
+  map_name          TEXT NOT NULL,
+  information_index INTEGER NOT NULL,
+  detail_index      INTEGER NOT NULL,
+  uuid              TEXT,
+  detail_type       TEXT,
+  title             TEXT,
+  rulers            TEXT,
+  government        TEXT,
+  cultures          TEXT,
+  language          TEXT,
+  religion_type     TEXT,
+  culture           TEXT,
+  holy_symbol       TEXT,
+  domains           TEXT,
+  detail_text       TEXT,
+  PRIMARY KEY (map_name, information_index, detail_index),
+  FOREIGN KEY (map_name, information_index) REFERENCES informations (map_name, information_index)
+);
+`
+
+// tables are the tables of Schema, each after the tables it refers to.
+var tables = []string{"maps", "terrains", "tiles", "features", "labels", "shapes", "points", "notes", "informations", "information_details"}
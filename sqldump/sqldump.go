@@ -0,0 +1,265 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package sqldump writes the contents of a Worldographer map as SQL: the
+// normalized schema in Schema, and the INSERT statements that load a map into
+// it.
+//
+// The SQL is written to load into SQLite and PostgreSQL alike. It uses only
+// TEXT, INTEGER, REAL and BOOLEAN columns, string literals with their quotes
+// doubled and nothing escaped with a backslash, TRUE and FALSE, and multi-row
+// INSERTs, and it loads a map in a single transaction.
+//
+// A dump replaces the map of the same name, so loading the dumps of each map of
+// a campaign into one database, and loading one again after the map changed,
+// leaves one copy of each.
+package sqldump
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+)
+
+// batchRows is the most rows one INSERT statement carries.
+const batchRows = 256
+
+// Write writes the SQL that creates the tables of Schema, where they do not
+// exist, and loads m into them under the name name, to w.
+func Write(w io.Writer, m *wxx.Map_t, name string) error {
+	if m == nil {
+		return fmt.Errorf("sqldump: nil map")
+	}
+	d := &dump{w: bufio.NewWriter(w), name: name}
+	d.printf("%s\nBEGIN;\n\n", Schema)
+	for i := len(tables) - 1; i >= 0; i-- {
+		d.printf("DELETE FROM %s WHERE map_name = %s;\n", tables[i], literal(name))
+	}
+	d.printf("\n")
+
+	d.writeMap(m)
+	d.writeTerrains(m)
+	d.writeTiles(m)
+	d.writeFeatures(m)
+	d.writeLabels(m)
+	d.writeShapes(m)
+	d.writeNotes(m)
+	d.writeInformations(m)
+
+	d.flush()
+	d.printf("COMMIT;\n")
+	if d.err != nil {
+		return fmt.Errorf("sqldump: %w", d.err)
+	}
+	if err := d.w.Flush(); err != nil {
+		return fmt.Errorf("sqldump: %w", err)
+	}
+	return nil
+}
+
+// dump writes the statements of one map, batching the rows of each table into
+// multi-row INSERTs.
+type dump struct {
+	w    *bufio.Writer
+	name string
+	err  error
+
+	// the INSERT being written, and the rows it holds so far
+	table, columns string
+	rows           int
+}
+
+func (d *dump) printf(format string, args ...any) {
+	if d.err == nil {
+		_, d.err = fmt.Fprintf(d.w, format, args...)
+	}
+}
+
+// insert adds a row to table, whose columns are listed after map_name, which
+// every table starts with.
+func (d *dump) insert(table, columns string, values ...any) {
+	if d.rows == batchRows || d.table != table {
+		d.flush()
+	}
+	if d.rows == 0 {
+		d.table, d.columns = table, columns
+		d.printf("INSERT INTO %s (map_name, %s) VALUES\n  (", table, columns)
+	} else {
+		d.printf(",\n  (")
+	}
+	d.printf("%s", literal(d.name))
+	for _, v := range values {
+		d.printf(", %s", literal(v))
+	}
+	d.printf(")")
+	d.rows++
+}
+
+// flush ends the INSERT being written, if any.
+func (d *dump) flush() {
+	if d.rows != 0 {
+		d.printf(";\n\n")
+	}
+	d.table, d.rows = "", 0
+}
+
+func (d *dump) writeMap(m *wxx.Map_t) {
+	var schema any
+	if m.MetaData.Version.Schema != nil {
+		schema = m.MetaData.Version.Schema.Raw
+	}
+	var wide, high int
+	if m.Tiles != nil {
+		wide, high = m.Tiles.TilesWide, m.Tiles.TilesHigh
+	}
+	d.insert("maps", "app_version, schema_version, app_release, map_type, hex_orientation, hex_width, hex_height, tiles_wide, tiles_high, columns_wide, rows_high, last_view_level",
+		m.MetaData.Version.App.Raw, schema, m.MetaData.Worldographer.Release, m.Type, m.HexOrientation, m.HexWidth, m.HexHeight, wide, high, m.ColumnsWide, m.RowsHigh, m.LastViewLevel)
+}
+
+func (d *dump) writeTerrains(m *wxx.Map_t) {
+	if m.TerrainMap == nil {
+		return
+	}
+	for _, t := range m.TerrainMap.List {
+		if t != nil {
+			d.insert("terrains", "terrain_index, label", t.Index, t.Label)
+		}
+	}
+}
+
+func (d *dump) writeTiles(m *wxx.Map_t) {
+	if m.Tiles == nil {
+		return
+	}
+	wide, _ := m.Tiles.Size()
+	var column []wxx.Tile_t
+	for x := 0; x < wide; x++ {
+		column = m.Tiles.ColumnTiles(column[:0], x)
+		for y, t := range column {
+			col, row := m.TileColumnRow(x, y)
+			q, r, s := m.TileCoord(x, y).QRS()
+			res := t.Resources
+			d.insert("tiles", "column_index, row_index, q, r, s, terrain_index, elevation, is_icy, is_gm_only, animal, brick, crops, gems, lumber, metals, rock, background_color",
+				col, row, q, r, s, t.Terrain, t.Elevation, t.IsIcy, t.IsGMOnly, res.Animal, res.Brick, res.Crops, res.Gems, res.Lumber, res.Metals, res.Rock, t.CustomBackgroundColor)
+		}
+	}
+}
+
+func (d *dump) writeFeatures(m *wxx.Map_t) {
+	for i, f := range m.Features {
+		if f == nil {
+			continue
+		}
+		var viewLevel, x, y, label any
+		if f.Location != nil {
+			viewLevel, x, y = f.Location.ViewLevel, f.Location.X, f.Location.Y
+		}
+		if f.Label != nil {
+			label = f.Label.InnerText
+		}
+		d.insert("features", "feature_index, feature_type, uuid, map_layer, view_level, x, y, rotate, scale, is_gm_only, label",
+			i, f.Type, f.Uuid, f.MapLayer, viewLevel, x, y, f.Rotate, f.Scale, f.IsGMOnly, label)
+	}
+}
+
+func (d *dump) writeLabels(m *wxx.Map_t) {
+	for i, l := range m.Labels {
+		if l == nil {
+			continue
+		}
+		var viewLevel, x, y, scale any
+		if l.Location != nil {
+			viewLevel, x, y, scale = l.Location.ViewLevel, l.Location.X, l.Location.Y, l.Location.Scale
+		}
+		d.insert("labels", "label_index, map_layer, style, font_face, color, view_level, x, y, scale, rotate, is_gm_only, tags, label_text",
+			i, l.MapLayer, l.Style, l.FontFace, l.Color, viewLevel, x, y, scale, l.Rotate, l.IsGMOnly, l.Tags, l.InnerText)
+	}
+}
+
+func (d *dump) writeShapes(m *wxx.Map_t) {
+	for i, s := range m.Shapes {
+		if s == nil {
+			continue
+		}
+		d.insert("shapes", "shape_index, shape_type, map_layer, stroke_type, stroke_color, stroke_width, opacity, fill_texture, is_curve, is_gm_only, highest_view_level, tags",
+			i, s.Type, s.MapLayer, s.StrokeType, s.StrokeColor, s.StrokeWidth, s.Opacity, s.FillTexture, s.IsCurve, s.IsGMOnly, s.HighestViewLevel, s.Tags)
+	}
+	// the points follow all the shapes, so that each INSERT is of one table
+	for i, s := range m.Shapes {
+		if s == nil {
+			continue
+		}
+		for j, p := range s.Points {
+			if p != nil {
+				d.insert("points", "shape_index, point_index, point_type, x, y", i, j, p.Type, p.X, p.Y)
+			}
+		}
+	}
+}
+
+func (d *dump) writeNotes(m *wxx.Map_t) {
+	for i, n := range m.Notes {
+		if n != nil {
+			d.insert("notes", "note_index, note_key, title, view_level, x, y, filename, parent, color, is_gm_only, note_text",
+				i, n.Key, n.Title, n.ViewLevel, n.X, n.Y, n.Filename, n.Parent, n.Color, n.IsGMOnly, n.NoteText)
+		}
+	}
+}
+
+func (d *dump) writeInformations(m *wxx.Map_t) {
+	if m.Informations == nil {
+		return
+	}
+	for i, info := range m.Informations.Informations {
+		if info != nil {
+			d.insert("informations", "information_index, uuid, information_type, title, rulers, government, cultures, language, religion_type, culture, holy_symbol, domains, information_text",
+				i, info.Uuid, info.Type, info.Title, info.Rulers, info.Government, info.Cultures, info.Language, info.ReligionType, info.Culture, info.HolySymbol, info.Domains, info.InnerText)
+		}
+	}
+	for i, info := range m.Informations.Informations {
+		if info == nil {
+			continue
+		}
+		for j, detail := range info.Details {
+			if detail != nil {
+				d.insert("information_details", "information_index, detail_index, uuid, detail_type, title, rulers, government, cultures, language, religion_type, culture, holy_symbol, domains, detail_text",
+					i, j, detail.Uuid, detail.Type, detail.Title, detail.Rulers, detail.Government, detail.Cultures, detail.Language, detail.ReligionType, detail.Culture, detail.HolySymbol, detail.Domains, detail.InnerText)
+			}
+		}
+	}
+}
+
+// literal returns v as an SQL literal. A nil value or color, and a float that
+// SQL cannot spell, are NULL.
+func literal(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return "NULL"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case *wxx.RGBA_t:
+		if v == nil {
+			return "NULL"
+		} else if v.Raw != "" {
+			return literal(v.Raw)
+		}
+		return literal(fmt.Sprintf("%g,%g,%g,%g", v.R, v.G, v.B, v.A))
+	}
+	panic(fmt.Sprintf("sqldump: no literal for %T", v))
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package sqldump_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/sqldump"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+const populatedFixture = "../testdata/w2025-populated.xml"
+
+// statements returns the INSERTs of the dump of m by table, each as the number
+// of rows it carries.
+func statements(t *testing.T, m *wxx.Map_t, name string) (string, map[string][]int) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := sqldump.Write(&buf, m, name); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	inserts := map[string][]int{}
+	for _, stmt := range strings.Split(buf.String(), ";\n") {
+		stmt = strings.TrimSpace(stmt)
+		if table, ok := strings.CutPrefix(stmt, "INSERT INTO "); ok {
+			table, _, _ = strings.Cut(table, " ")
+			inserts[table] = append(inserts[table], strings.Count(stmt, "\n  ("))
+		}
+	}
+	return buf.String(), inserts
+}
+
+// TestWrite asserts that a dump creates the schema, replaces the map of its
+// name and loads a row for each of the map's contents, in one transaction.
+func TestWrite(t *testing.T) {
+	m, err := xmlio.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	sql, inserts := statements(t, m, "world.wxx")
+	if !strings.HasPrefix(sql, sqldump.Schema) || !strings.HasSuffix(sql, "COMMIT;\n") {
+		t.Errorf("the dump is not the schema followed by a transaction")
+	}
+	if !strings.Contains(sql, "DELETE FROM tiles WHERE map_name = 'world.wxx';\n") {
+		t.Errorf("the dump does not replace the map's tiles")
+	}
+
+	points := 0
+	for _, s := range m.Shapes {
+		points += len(s.Points)
+	}
+	for table, want := range map[string]int{
+		"maps":     1,
+		"terrains": len(m.TerrainMap.List),
+		"tiles":    m.Tiles.TilesWide * m.Tiles.TilesHigh,
+		"features": len(m.Features),
+		"labels":   len(m.Labels),
+		"shapes":   len(m.Shapes),
+		"points":   points,
+		"notes":    len(m.Notes),
+	} {
+		got := 0
+		for _, rows := range inserts[table] {
+			got += rows
+		}
+		if got != want {
+			t.Errorf("%s: %d rows, want %d", table, got, want)
+		}
+	}
+}
+
+// TestWriteValues asserts that the rows of a large table are split into
+// INSERTs of at most 256 rows, that tiles carry their cube coordinate, and
+// that values are written as both SQLite and PostgreSQL read them.
+func TestWriteValues(t *testing.T) {
+	m := &wxx.Map_t{GridOrientation: hexg.OddQ, Tiles: wxx.NewTiles(20, 20, hexg.OddQ)}
+	m.MetaData.Version.App = wxx.Dotted{Raw: "2.06", Major: 2, Minor: 6}
+	m.Tiles.SetTile(3, 2, wxx.Tile_t{Terrain: 4, Elevation: math.NaN(), IsIcy: true, CustomBackgroundColor: &wxx.RGBA_t{R: 0.5, A: 1}})
+	m.Notes = []*wxx.Note_t{{Title: "O'Brien's keep"}}
+
+	sql, inserts := statements(t, m, "it's.wxx")
+	if got, want := inserts["tiles"], []int{256, 144}; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("tiles: INSERTs of %v rows, want %v", got, want)
+	}
+	q, r, s := m.TileCoord(3, 2).QRS()
+	for _, want := range []string{
+		"DELETE FROM maps WHERE map_name = 'it''s.wxx';",
+		"('it''s.wxx', 3, 2, " + strconv.Itoa(q) + ", " + strconv.Itoa(r) + ", " + strconv.Itoa(s) + ", 4, NULL, TRUE, FALSE, 0, 0, 0, 0, 0, 0, 0, '0.5,0,0,1')",
+		"'O''Brien''s keep'",
+	} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("the dump has no %s", want)
+		}
+	}
+}
+
+// TestWriteTilePositions asserts that the tiles of a COLUMNS map span its
+// tilesWide columns and tilesHigh rows, and that the tile a <tilerow> starts
+// with is in the column of that row.
+func TestWriteTilePositions(t *testing.T) {
+	m, err := xmlio.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if m.GridOrientation != hexg.OddQ {
+		t.Fatalf("%s is not a COLUMNS map", populatedFixture)
+	}
+	sql, _ := statements(t, m, "world.wxx")
+	maxColumn, maxRow, tiles := -1, -1, false
+	for _, line := range strings.Split(sql, "\n") {
+		values, ok := strings.CutPrefix(line, "  ('world.wxx', ")
+		if !ok {
+			tiles = strings.HasPrefix(line, "INSERT INTO tiles ")
+			continue
+		} else if !tiles {
+			continue
+		}
+		var column, row, q, r, s int
+		if _, err := fmt.Sscanf(values, "%d, %d, %d, %d, %d,", &column, &row, &q, &r, &s); err != nil {
+			t.Fatalf("tile %q: %v", line, err)
+		}
+		maxColumn, maxRow = max(maxColumn, column), max(maxRow, row)
+		if column == 1 && row == 0 && (q != 1 || r != 0 || s != -1) {
+			t.Errorf("column 1, row 0 is at %d,%d,%d, want 1,0,-1", q, r, s)
+		}
+	}
+	if maxColumn != m.Tiles.TilesWide-1 || maxRow != m.Tiles.TilesHigh-1 {
+		t.Errorf("tiles span columns 0...%d and rows 0...%d, want 0...%d and 0...%d", maxColumn, maxRow, m.Tiles.TilesWide-1, m.Tiles.TilesHigh-1)
+	}
+}
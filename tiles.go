@@ -0,0 +1,355 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package wxx
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/maloquacious/wxx/hexg"
+)
+
+// tileCell is the compact form Tiles_t stores a tile in. It holds nothing that
+// can be worked out from where the cell is -- the row, the column, the cube
+// coordinate -- and no pointers, so a grid of millions of them is a single
+// allocation the garbage collector does not have to scan.
+//
+// A Tile_t is 128 bytes on the heap behind a pointer of its own; a cell is 24.
+type tileCell struct {
+	elevation float64
+	terrain   int32
+	color     uint32   // 1 + the index of the color in Tiles_t.colors, 0 for none
+	resources [7]uint8 // Animal, Brick, Crops, Gems, Lumber, Metals, Rock
+	flags     uint8
+}
+
+const (
+	tileIsIcy    uint8 = 1 << 0
+	tileIsGMOnly uint8 = 1 << 1
+)
+
+// NewTiles returns a wide x high grid of zero tiles for a map with the given
+// grid orientation.
+func NewTiles(wide, high int, orientation hexg.Orientation_e) *Tiles_t {
+	return &Tiles_t{
+		TilesWide:   wide,
+		TilesHigh:   high,
+		Orientation: orientation,
+		cells:       make([]tileCell, wide*high),
+		high:        high,
+	}
+}
+
+// Size returns the number of columns of tiles held and the number of tiles in
+// each. It is TilesWide x TilesHigh for a decoded map. A decoder that failed
+// part of the way through may hold fewer columns, and a caller that changes
+// TilesWide or TilesHigh without replacing the tiles holds a grid of another
+// size; the encoders reject a grid that does not cover TilesWide x TilesHigh.
+func (t *Tiles_t) Size() (wide, high int) {
+	if t.high == 0 {
+		return 0, 0
+	}
+	return len(t.cells) / t.high, t.high
+}
+
+// InBounds reports whether the grid holds a tile at [x][y].
+func (t *Tiles_t) InBounds(x, y int) bool {
+	wide, high := t.Size()
+	return 0 <= x && x < wide && 0 <= y && y < high
+}
+
+// Tile returns the tile at [x][y]: x is the <tilerow> the tile is written in,
+// 0...TilesWide, and y is its line within the row, 0...TilesHigh. Row, Column
+// and Coords are filled in as the decoders have always set them, Row from x and
+// Column from y. It panics if the grid holds no tile at [x][y].
+//
+// The returned CustomBackgroundColor is the one the grid holds, not a copy.
+func (t *Tiles_t) Tile(x, y int) Tile_t {
+	if !t.InBounds(x, y) {
+		panic("wxx: tile index out of range")
+	}
+	return t.expand(x, y, &t.cells[x*t.high+y])
+}
+
+// SetTile sets the tile at [x][y] to tile. Row, Column and Coords are ignored,
+// since they follow from x and y, and resources are clamped to 0...100. It
+// panics if the grid holds no tile at [x][y].
+func (t *Tiles_t) SetTile(x, y int, tile Tile_t) {
+	if !t.InBounds(x, y) {
+		panic("wxx: tile index out of range")
+	}
+	t.compact(&t.cells[x*t.high+y], tile)
+}
+
+// TileAt returns the tile whose cube coordinate is c, and whether the grid
+// holds one. Only OddQ and OddR grids have cube coordinates.
+func (t *Tiles_t) TileAt(c hexg.CubeCoord) (Tile_t, bool) {
+	x, y, ok := t.Position(c)
+	if !ok {
+		return Tile_t{}, false
+	}
+	return t.Tile(x, y), true
+}
+
+// SetTileAt sets the tile whose cube coordinate is c, as SetTile does, and
+// reports whether the grid holds one.
+func (t *Tiles_t) SetTileAt(c hexg.CubeCoord, tile Tile_t) bool {
+	x, y, ok := t.Position(c)
+	if ok {
+		t.SetTile(x, y, tile)
+	}
+	return ok
+}
+
+// Coords returns the cube coordinate of the tile at [x][y]. It is the zero
+// coordinate unless the grid is OddQ or OddR.
+func (t *Tiles_t) Coords(x, y int) hexg.CubeCoord {
+	switch t.Orientation {
+	case hexg.OddQ:
+		return hexg.NewOddQCoord(y, x).ToCube()
+	case hexg.OddR:
+		return hexg.NewOddRCoord(y, x).ToCube()
+	}
+	return hexg.CubeCoord{}
+}
+
+// Position returns the [x][y] of the tile whose cube coordinate is c, and
+// whether the grid holds one. It is the inverse of Coords.
+func (t *Tiles_t) Position(c hexg.CubeCoord) (x, y int, ok bool) {
+	switch t.Orientation {
+	case hexg.OddQ:
+		y, x = c.ToOddQ().ColRow()
+	case hexg.OddR:
+		y, x = c.ToOddR().ColRow()
+	default:
+		return 0, 0, false
+	}
+	return x, y, t.InBounds(x, y)
+}
+
+// ColumnTiles appends the tiles of column x, the tiles the x'th <tilerow> holds,
+// to dst and returns the extended slice. An encoder that reuses dst from column
+// to column reads the grid without allocating.
+func (t *Tiles_t) ColumnTiles(dst []Tile_t, x int) []Tile_t {
+	if !t.InBounds(x, 0) {
+		panic("wxx: tile column out of range")
+	}
+	for y, cells := 0, t.cells[x*t.high:(x+1)*t.high]; y < len(cells); y++ {
+		dst = append(dst, t.expand(x, y, &cells[y]))
+	}
+	return dst
+}
+
+// AddColumn adds a column to the right of the grid holding tiles, padded with
+// zero tiles or cut to the grid's height. The first column added to an empty
+// grid sets the height, to TilesHigh. The decoders build the grid this way, one
+// <tilerow> at a time.
+func (t *Tiles_t) AddColumn(tiles []Tile_t) {
+	if len(t.cells) == 0 {
+		t.high = t.TilesHigh
+	}
+	x, _ := t.Size()
+	n := len(t.cells)
+	t.cells = slices.Grow(t.cells, t.high)[:n+t.high]
+	clear(t.cells[n:])
+	for y := 0; y < t.high && y < len(tiles); y++ {
+		t.compact(&t.cells[x*t.high+y], tiles[y])
+	}
+}
+
+// Columns returns the grid as the [x][y] array of tiles Tiles_t used to hold,
+// for callers written against it. The tiles are copies, backed by one
+// allocation; changes to them reach the grid only through SetColumns.
+func (t *Tiles_t) Columns() [][]*Tile_t {
+	wide, high := t.Size()
+	tiles := make([]Tile_t, 0, wide*high)
+	columns := make([][]*Tile_t, wide)
+	for x := range columns {
+		tiles = t.ColumnTiles(tiles, x)
+		columns[x] = make([]*Tile_t, high)
+		for y := range columns[x] {
+			columns[x][y] = &tiles[x*high+y]
+		}
+	}
+	return columns
+}
+
+// SetColumns replaces the grid with the [x][y] array of tiles columns, and sets
+// TilesWide and TilesHigh to its size. Each column must hold as many tiles as
+// the first, or SetColumns returns ErrInvalidTileGrid and leaves the grid as it
+// was; a nil tile is stored as a zero tile.
+func (t *Tiles_t) SetColumns(columns [][]*Tile_t) error {
+	wide, high := len(columns), 0
+	if wide != 0 {
+		high = len(columns[0])
+	}
+	for x, column := range columns {
+		if len(column) != high {
+			return fmt.Errorf("%w: column %d holds %d tiles, want %d", ErrInvalidTileGrid, x, len(column), high)
+		}
+	}
+	t.TilesWide, t.TilesHigh = wide, high
+	t.cells, t.high, t.colors = make([]tileCell, wide*high), high, nil
+	for x, column := range columns {
+		for y, tile := range column {
+			if tile != nil {
+				t.SetTile(x, y, *tile)
+			}
+		}
+	}
+	return nil
+}
+
+// expand returns the Tile_t for the cell at [x][y].
+func (t *Tiles_t) expand(x, y int, cell *tileCell) Tile_t {
+	tile := Tile_t{
+		Coords:    t.Coords(x, y),
+		Row:       x,
+		Column:    y,
+		Terrain:   int(cell.terrain),
+		Elevation: cell.elevation,
+		IsIcy:     cell.flags&tileIsIcy != 0,
+		IsGMOnly:  cell.flags&tileIsGMOnly != 0,
+		Resources: Resources_t{
+			Animal: int(cell.resources[0]),
+			Brick:  int(cell.resources[1]),
+			Crops:  int(cell.resources[2]),
+			Gems:   int(cell.resources[3]),
+			Lumber: int(cell.resources[4]),
+			Metals: int(cell.resources[5]),
+			Rock:   int(cell.resources[6]),
+		},
+	}
+	if cell.color != 0 {
+		tile.CustomBackgroundColor = t.colors[cell.color-1]
+	}
+	return tile
+}
+
+// compact stores tile in cell. A cell that already has a color keeps its slot
+// in t.colors for the new one, so rewriting tiles does not grow the list.
+func (t *Tiles_t) compact(cell *tileCell, tile Tile_t) {
+	var flags uint8
+	if tile.IsIcy {
+		flags |= tileIsIcy
+	}
+	if tile.IsGMOnly {
+		flags |= tileIsGMOnly
+	}
+	color := cell.color
+	if tile.CustomBackgroundColor == nil {
+		if color != 0 {
+			t.colors[color-1] = nil
+		}
+		color = 0
+	} else if color != 0 {
+		t.colors[color-1] = tile.CustomBackgroundColor
+	} else {
+		t.colors = append(t.colors, tile.CustomBackgroundColor)
+		color = uint32(len(t.colors))
+	}
+	r := tile.Resources
+	*cell = tileCell{
+		elevation: tile.Elevation,
+		terrain:   int32(tile.Terrain),
+		color:     color,
+		resources: [7]uint8{resource(r.Animal), resource(r.Brick), resource(r.Crops), resource(r.Gems), resource(r.Lumber), resource(r.Metals), resource(r.Rock)},
+		flags:     flags,
+	}
+}
+
+// resource clamps a resource to 0...100, the range Worldographer allows.
+func resource(n int) uint8 {
+	return uint8(max(0, min(n, 100)))
+}
+
+// TilesJSON_t is the JSON form of Tiles_t, which MarshalJSON writes and
+// UnmarshalJSON reads: the attributes of <tiles>, and the grid as an array of
+// columns, each an array of tiles, in the order ColumnTiles returns them. It is
+// exported for package jsonio to describe in its schema.
+type TilesJSON_t struct {
+	ViewLevel string         `json:"viewLevel,omitempty"`
+	TilesWide int            `json:"tilesWide,omitempty"`
+	TilesHigh int            `json:"tilesHigh,omitempty"`
+	Columns   [][]TileJSON_t `json:"columns,omitempty"`
+	Extras    *Extras_t      `json:"extras,omitempty"`
+}
+
+// TileJSON_t is a tile in a column of TilesJSON_t. It leaves out what follows
+// from where it is, as tileCell does.
+type TileJSON_t struct {
+	Terrain               int         `json:"terrain"`
+	Elevation             float64     `json:"elevation,omitzero"`
+	IsIcy                 bool        `json:"isIcy,omitempty"`
+	IsGMOnly              bool        `json:"isGMOnly,omitempty"`
+	Resources             Resources_t `json:"resources,omitzero"`
+	CustomBackgroundColor *RGBA_t     `json:"customBackgroundColor,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The grid is written as
+// its columns of tiles, so the tiles a decoder read come back from
+// UnmarshalJSON as they were.
+func (t *Tiles_t) MarshalJSON() ([]byte, error) {
+	wide, high := t.Size()
+	v := TilesJSON_t{ViewLevel: t.ViewLevel, TilesWide: t.TilesWide, TilesHigh: t.TilesHigh, Extras: t.Extras}
+	if wide != 0 {
+		v.Columns = make([][]TileJSON_t, wide)
+		tiles := make([]TileJSON_t, wide*high)
+		for x := range v.Columns {
+			v.Columns[x] = tiles[x*high : (x+1)*high]
+			for y := range v.Columns[x] {
+				tile := t.expand(x, y, &t.cells[x*high+y])
+				v.Columns[x][y] = TileJSON_t{
+					Terrain:               tile.Terrain,
+					Elevation:             tile.Elevation,
+					IsIcy:                 tile.IsIcy,
+					IsGMOnly:              tile.IsGMOnly,
+					Resources:             tile.Resources,
+					CustomBackgroundColor: tile.CustomBackgroundColor,
+				}
+			}
+		}
+	}
+	return json.Marshal(v)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. There must be
+// tilesWide columns, each holding tilesHigh tiles, or UnmarshalJSON returns
+// ErrInvalidTileGrid. Orientation is not part of the JSON form; the caller sets
+// it from the map's GridOrientation, as the decoders do.
+func (t *Tiles_t) UnmarshalJSON(data []byte) error {
+	var v TilesJSON_t
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.TilesWide < 0 || v.TilesHigh < 0 {
+		return fmt.Errorf("%w: %d x %d tiles", ErrInvalidTileGrid, v.TilesWide, v.TilesHigh)
+	} else if len(v.Columns) != v.TilesWide {
+		return fmt.Errorf("%w: %d columns, want tilesWide %d", ErrInvalidTileGrid, len(v.Columns), v.TilesWide)
+	}
+	for x, column := range v.Columns {
+		if len(column) != v.TilesHigh {
+			return fmt.Errorf("%w: column %d holds %d tiles, want tilesHigh %d", ErrInvalidTileGrid, x, len(column), v.TilesHigh)
+		}
+	}
+	*t = Tiles_t{ViewLevel: v.ViewLevel, TilesWide: v.TilesWide, TilesHigh: v.TilesHigh, Orientation: t.Orientation, Extras: v.Extras}
+	if len(v.Columns) == 0 {
+		return nil
+	}
+	t.high = v.TilesHigh
+	t.cells = make([]tileCell, len(v.Columns)*t.high)
+	for x, column := range v.Columns {
+		for y, tile := range column {
+			t.compact(&t.cells[x*t.high+y], Tile_t{
+				Terrain:               tile.Terrain,
+				Elevation:             tile.Elevation,
+				IsIcy:                 tile.IsIcy,
+				IsGMOnly:              tile.IsGMOnly,
+				Resources:             tile.Resources,
+				CustomBackgroundColor: tile.CustomBackgroundColor,
+			})
+		}
+	}
+	return nil
+}
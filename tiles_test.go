@@ -0,0 +1,142 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package wxx
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/maloquacious/wxx/hexg"
+)
+
+// TestTileCellSize guards the size of the compact tile, which is the point of
+// storing tiles as cells.
+func TestTileCellSize(t *testing.T) {
+	if got := unsafe.Sizeof(tileCell{}); got != 24 {
+		t.Errorf("tileCell is %d bytes, want 24", got)
+	}
+}
+
+// TestTilesSetTile checks that a tile set at [x][y] reads back with every field
+// it was given, and with the position fields the decoders have always set.
+func TestTilesSetTile(t *testing.T) {
+	tiles := NewTiles(4, 3, hexg.OddQ)
+	red := &RGBA_t{R: 1, A: 1}
+	want := Tile_t{
+		Terrain:               7,
+		Elevation:             1250.5,
+		IsIcy:                 true,
+		IsGMOnly:              true,
+		Resources:             Resources_t{Animal: 1, Brick: 2, Crops: 3, Gems: 4, Lumber: 5, Metals: 6, Rock: 100},
+		CustomBackgroundColor: red,
+	}
+	tiles.SetTile(2, 1, want)
+	want.Row, want.Column, want.Coords = 2, 1, hexg.NewOddQCoord(1, 2).ToCube()
+	if got := tiles.Tile(2, 1); !reflect.DeepEqual(got, want) {
+		t.Errorf("Tile(2, 1) = %+v, want %+v", got, want)
+	}
+	if got := tiles.Tile(1, 2); got.Terrain != 0 || got.CustomBackgroundColor != nil {
+		t.Errorf("Tile(1, 2) = %+v, want a zero tile", got)
+	}
+
+	// rewriting a colored tile reuses its color slot
+	tiles.SetTile(2, 1, Tile_t{CustomBackgroundColor: &RGBA_t{B: 1, A: 1}})
+	tiles.SetTile(2, 1, Tile_t{})
+	if got := len(tiles.colors); got != 1 {
+		t.Errorf("%d colors held after rewriting one tile, want 1", got)
+	}
+
+	tiles.SetTile(0, 0, Tile_t{Resources: Resources_t{Animal: -5, Rock: 250}})
+	if got := tiles.Tile(0, 0).Resources; got.Animal != 0 || got.Rock != 100 {
+		t.Errorf("resources -5 and 250 read back as %d and %d, want 0 and 100", got.Animal, got.Rock)
+	}
+}
+
+// TestTilesTileAt checks that every tile is found by its cube coordinate, for
+// both orientations, and that a coordinate off the grid finds nothing.
+func TestTilesTileAt(t *testing.T) {
+	for _, orientation := range []hexg.Orientation_e{hexg.OddQ, hexg.OddR} {
+		tiles := NewTiles(5, 4, orientation)
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 4; y++ {
+				tiles.SetTile(x, y, Tile_t{Terrain: 10*x + y})
+			}
+		}
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 4; y++ {
+				c := tiles.Tile(x, y).Coords
+				tile, ok := tiles.TileAt(c)
+				if !ok || tile.Terrain != 10*x+y {
+					t.Errorf("%v: TileAt(%v) = %d, %v, want the tile at [%d][%d]", orientation, c, tile.Terrain, ok, x, y)
+				}
+			}
+		}
+		if _, ok := tiles.TileAt(tiles.Coords(5, 0)); ok {
+			t.Errorf("%v: TileAt found a tile off the grid", orientation)
+		}
+	}
+}
+
+// TestTilesColumns checks that the [x][y] compatibility path reads and writes
+// the same grid the accessors do.
+func TestTilesColumns(t *testing.T) {
+	tiles := NewTiles(3, 2, hexg.OddQ)
+	for x := 0; x < 3; x++ {
+		for y := 0; y < 2; y++ {
+			tiles.SetTile(x, y, Tile_t{Terrain: 10*x + y})
+		}
+	}
+	columns := tiles.Columns()
+	if len(columns) != 3 || len(columns[0]) != 2 || columns[2][1].Terrain != 21 {
+		t.Fatalf("Columns() = %d x %d, [2][1] = %d, want 3 x 2, 21", len(columns), len(columns[0]), columns[2][1].Terrain)
+	}
+	columns[2][1].Terrain = 99
+	if got := tiles.Tile(2, 1).Terrain; got != 21 {
+		t.Errorf("changing a copy changed the grid: Tile(2, 1).Terrain = %d, want 21", got)
+	}
+
+	if err := tiles.SetColumns(columns[1:]); err != nil {
+		t.Fatalf("SetColumns: %v", err)
+	}
+	if tiles.TilesWide != 2 || tiles.TilesHigh != 2 {
+		t.Errorf("SetColumns: TilesWide x TilesHigh = %d x %d, want 2 x 2", tiles.TilesWide, tiles.TilesHigh)
+	}
+	if got := tiles.Tile(1, 1).Terrain; got != 99 {
+		t.Errorf("SetColumns: Tile(1, 1).Terrain = %d, want 99", got)
+	}
+
+	// a short column and a long one are both refused, and the grid kept
+	for _, bad := range [][][]*Tile_t{
+		{columns[0], columns[1][:1]},
+		{columns[0], append(columns[1], &Tile_t{})},
+	} {
+		if err := tiles.SetColumns(bad); !errors.Is(err, ErrInvalidTileGrid) {
+			t.Errorf("SetColumns(%d and %d tiles): err = %v, want ErrInvalidTileGrid", len(bad[0]), len(bad[1]), err)
+		}
+		if wide, high := tiles.Size(); wide != 2 || high != 2 || tiles.Tile(1, 1).Terrain != 99 {
+			t.Errorf("SetColumns(%d and %d tiles) changed the grid", len(bad[0]), len(bad[1]))
+		}
+	}
+}
+
+// TestTilesAddColumn checks that columns added one at a time, as the decoders
+// add them, are padded or cut to TilesHigh.
+func TestTilesAddColumn(t *testing.T) {
+	tiles := &Tiles_t{TilesWide: 2, TilesHigh: 3}
+	tiles.AddColumn([]Tile_t{{Terrain: 1}, {Terrain: 2}})
+	tiles.AddColumn([]Tile_t{{Terrain: 3}, {Terrain: 4}, {Terrain: 5}, {Terrain: 6}})
+	if wide, high := tiles.Size(); wide != 2 || high != 3 {
+		t.Fatalf("Size() = %d x %d, want 2 x 3", wide, high)
+	}
+	var got []int
+	for x := 0; x < 2; x++ {
+		for _, tile := range tiles.ColumnTiles(nil, x) {
+			got = append(got, tile.Terrain)
+		}
+	}
+	if want := []int{1, 2, 0, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("terrains = %v, want %v", got, want)
+	}
+}
@@ -20,7 +20,7 @@ type Version_t struct {
 	// App is map/@version, the application build that wrote the file: "1.73",
 	// "1.74" or "1.77" for classic, "2.06" for the W2025 baseline. Every
 	// supported file states it, so it is a value rather than a pointer.
-	App Dotted
+	App Dotted `json:"app"`
 
 	// Schema is map/@schema, the on-disk data format the file conforms to
 	// ("1.06" for the W2025 baseline).
@@ -29,7 +29,7 @@ type Version_t struct {
 	// implicit legacy (classic) schema, which states no @schema attribute at
 	// all. Classic 1.73, 1.74 and 1.77 share an identical element vocabulary,
 	// so the absence names a single schema rather than leaving a question open.
-	Schema *Dotted
+	Schema *Dotted `json:"schema"`
 }
 
 // String renders both axes for display: `app 2.06, schema 1.06` for a W2025
@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// containerVariants re-wraps the payload of a .wxx fixture every way the
+// auto-detecting decoder is expected to accept, keyed by a name for the subtest
+// and paired with the layers it should report.
+func containerVariants(t *testing.T, path string) []struct {
+	name     string
+	data     []byte
+	gzip     bool
+	encoding string
+	bom      bool
+} {
+	t.Helper()
+	wxxBytes, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	gzr, err := gzip.NewReader(bytes.NewReader(wxxBytes))
+	if err != nil {
+		t.Fatalf("gunzip %s: %v", path, err)
+	}
+	payload, err := io.ReadAll(gzr) // UTF-16BE with a BOM, as Worldographer writes it
+	if err != nil {
+		t.Fatalf("gunzip %s: %v", path, err)
+	}
+	utf8, err := io.ReadAll(transform.NewReader(bytes.NewReader(payload), unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()))
+	if err != nil {
+		t.Fatalf("utf-16 %s: %v", path, err)
+	}
+	encode := func(e unicode.Endianness, bom unicode.BOMPolicy) []byte {
+		out, err := io.ReadAll(transform.NewReader(bytes.NewReader(utf8), unicode.UTF16(e, bom).NewEncoder()))
+		if err != nil {
+			t.Fatalf("encode %s: %v", path, err)
+		}
+		return out
+	}
+	compress := func(data []byte) []byte {
+		var buf bytes.Buffer
+		gzw := gzip.NewWriter(&buf)
+		if _, err := gzw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		if err := gzw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+	littleEndian := encode(unicode.LittleEndian, unicode.UseBOM)
+
+	return []struct {
+		name     string
+		data     []byte
+		gzip     bool
+		encoding string
+		bom      bool
+	}{
+		{"wxx", wxxBytes, true, "UTF-16BE", true},
+		{"export --raw", payload, false, "UTF-16BE", true},
+		{"export --utf-8", utf8, false, "UTF-8", false},
+		{"utf-8 with bom", append([]byte{0xef, 0xbb, 0xbf}, utf8...), false, "UTF-8", true},
+		{"utf-16le", littleEndian, false, "UTF-16LE", true},
+		{"gzip utf-16le", compress(littleEndian), true, "UTF-16LE", true},
+		{"utf-16be without bom", encode(unicode.BigEndian, unicode.IgnoreBOM), false, "UTF-16BE", false},
+		{"gzip utf-8", compress(utf8), true, "UTF-8", false},
+	}
+}
+
+// TestAutoDetectAcceptsEveryContainer decodes the same map from every container
+// variant with WithAutoDetect and asserts each produces the Map_t the .wxx does,
+// and that the diagnostics name the layers that were found.
+func TestAutoDetectAcceptsEveryContainer(t *testing.T) {
+	for _, fixture := range []string{classicFixture, sample2025_206} {
+		want, err := decodeFile(t, fixture)
+		if err != nil {
+			t.Fatalf("decode %s: %v", fixture, err)
+		}
+		normalizeVolatile(want)
+
+		for _, tc := range containerVariants(t, fixture) {
+			t.Run(filepath.Base(fixture)+"/"+tc.name, func(t *testing.T) {
+				var d xmlio.DecoderDiagnostics
+				got, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithDecoderDiagnostics(&d)).Decode(bytes.NewReader(tc.data))
+				if err != nil {
+					t.Fatalf("auto-detect decode: %v", err)
+				}
+				normalizeVolatile(got)
+				assertSameMap(t, want, got)
+
+				if d.Gzip != tc.gzip {
+					t.Errorf("Diagnostics.Gzip = %v, want %v", d.Gzip, tc.gzip)
+				}
+				if d.Encoding != tc.encoding {
+					t.Errorf("Diagnostics.Encoding = %q, want %q", d.Encoding, tc.encoding)
+				}
+				if d.BOM != tc.bom {
+					t.Errorf("Diagnostics.BOM = %v, want %v", d.BOM, tc.bom)
+				}
+				if !bytes.HasPrefix(d.XMLHeader, []byte("<?xml")) {
+					t.Errorf("Diagnostics.XMLHeader = %q, want the removed declaration", d.XMLHeader)
+				}
+			})
+		}
+	}
+}
+
+// TestReadFileAutoDetects reads plain UTF-8 XML, whose declaration is not one
+// Worldographer writes, through ReadFile with no options.
+func TestReadFileAutoDetects(t *testing.T) {
+	m, err := xmlio.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", populatedFixture, err)
+	}
+	if got, want := len(m.Notes), 2; got != want {
+		t.Errorf("len(Notes) = %d, want %d", got, want)
+	}
+}
+
+// TestAutoDetectOverriddenByLaterOptions asserts that a fixed-format option after
+// WithAutoDetect turns detection off: a little-endian payload is then rejected
+// exactly as the default decoder rejects it.
+func TestAutoDetectOverriddenByLaterOptions(t *testing.T) {
+	var littleEndian []byte
+	for _, tc := range containerVariants(t, classicFixture) {
+		if tc.name == "utf-16le" {
+			littleEndian = tc.data
+		}
+	}
+
+	_, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithSkipUncompress()).Decode(bytes.NewReader(littleEndian))
+	if !errors.Is(err, wxx.ErrNotBigEndianUTF16Encoded) {
+		t.Errorf("decode error = %v, want it to wrap %v", err, wxx.ErrNotBigEndianUTF16Encoded)
+	}
+}
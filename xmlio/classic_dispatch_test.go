@@ -18,9 +18,9 @@ import (
 // wantMajor/wantMinor are the components MetaData.Version.App parses the on-disk
 // version into ("1.77" -> {1, 77}). Every classic fixture states no @schema, so
 // the identity's Schema is nil throughout -- that absence is the implicit legacy
-// schema, and it is what selects the classic codec on the way back out. All
-// classic fixtures are COLUMNS orientation (verified), so they can be re-encoded
-// without hitting the documented classic ROWS-encode gap.
+// schema, and it is what selects the classic codec on the way back out. The
+// rows-blank sample is the one ROWS map, so the classic encode is exercised in
+// both orientations.
 var classicSamples = []struct {
 	name      string
 	path      string
@@ -29,6 +29,7 @@ var classicSamples = []struct {
 	wantMinor int    // parsed MetaData.Version.App.Minor
 }{
 	{"1.77-columns-blank", "../testdata/2017-1.77-1.0-columns-blank.wxx", "1.77", 1, 77},
+	{"1.77-rows-blank", "../testdata/2017-1.77-1.0-rows-blank.wxx", "1.77", 1, 77},
 	{"1.77-blank", "../testdata/blank-2017-1.77-1.0.wxx", "1.77", 1, 77},
 	{"1.74-blank", "../testdata/blank-2017-1.74-1.0.wxx", "1.74", 1, 74},
 	{"1.73-blank", "../testdata/blank-2017-1.73-1.0.wxx", "1.73", 1, 73},
@@ -137,3 +137,47 @@ func codecFor(app string) (codec.Codec, error) {
 	}
 	return nil, errors.Join(wxx.ErrUnsupportedMapVersion, fmt.Errorf("version %q: not a supported application version", app))
 }
+
+// decoderFor resolves the codec that reads a file stating map/@schema schema.
+//
+// A codec whose declared schema is the file's, compared as dotted ordinals, is
+// the codec that reads it. A file stating a minor schema no codec declares is
+// read by the nearest older codec of the same major, and fallback is that
+// codec's schema. A minor bump is taken to add to the format
+// rather than change it, and what it adds the older codec keeps as extras and
+// Decode reports as unknown content, so the file is read rather than refused for
+// being mostly what the codec knows. A different major schema, or one older than
+// every codec's, is not guessed at.
+//
+// Classic is not chosen here. Its files state no schema, and "" is not a schema
+// to compare; the decoder routes them on map/@version instead.
+func decoderFor(schema string) (c codec.Codec, fallback string, err error) {
+	want, err := wxx.ParseDotted(schema)
+	if err != nil {
+		return nil, "", errors.Join(wxx.ErrInvalidMapMetadata, fmt.Errorf("schema %q: %w", schema, err))
+	}
+	var nearest codec.Codec
+	var nearestSchema wxx.Dotted
+	for _, c := range codecs() {
+		declared := c.AcceptedApps().Schema
+		if declared == "" {
+			continue
+		}
+		have, err := wxx.ParseDotted(declared)
+		if err != nil {
+			// appver.Set_t.Verify does not parse the schema, so a codec could
+			// declare one that is not dotted; it reads nothing
+			continue
+		}
+		switch {
+		case have.Compare(want) == 0:
+			return c, "", nil
+		case have.Major == want.Major && have.Less(want) && (nearest == nil || nearestSchema.Less(have)):
+			nearest, nearestSchema = c, have
+		}
+	}
+	if nearest == nil {
+		return nil, "", errors.Join(wxx.ErrUnsupportedSchemaVersion, fmt.Errorf("schema %q: no codec reads it or an older minor schema", schema))
+	}
+	return nearest, nearestSchema.Raw, nil
+}
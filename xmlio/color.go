@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import (
+	"github.com/maloquacious/wxx"
+)
+
+// foldBlack sets every color in m that is explicit opaque black to nil, "no
+// color". It is WithBlackAsNoColor's fold, applied once the codec has finished so
+// that neither codec needs to know about it.
+//
+// The colors are listed field by field rather than found by reflection, because
+// the list is also the answer to "which fields does the option touch?"; a color
+// field added to Map_t has to be added here.
+func foldBlack(m *wxx.Map_t) {
+	fold := func(colors ...**wxx.RGBA_t) {
+		for _, c := range colors {
+			if *c != nil && (*c).R == 0 && (*c).G == 0 && (*c).B == 0 && (*c).A == 1 {
+				*c = nil
+			}
+		}
+	}
+	foldLabel := func(l *wxx.Label_t) {
+		if l != nil {
+			fold(&l.Color, &l.OutlineColor, &l.BackgroundColor)
+		}
+	}
+
+	if m.Tiles != nil {
+		wide, high := m.Tiles.Size()
+		for x := 0; x < wide; x++ {
+			for y := 0; y < high; y++ {
+				if tile := m.Tiles.Tile(x, y); tile.CustomBackgroundColor != nil {
+					fold(&tile.CustomBackgroundColor)
+					m.Tiles.SetTile(x, y, tile)
+				}
+			}
+		}
+	}
+	if k := m.MapKey; k != nil {
+		fold(&k.BackgroundColor, &k.TitleFontColor, &k.ScaleFontColor, &k.EntryFontColor)
+	}
+	for _, f := range m.Features {
+		fold(&f.Color, &f.RingColor)
+		foldLabel(f.Label)
+	}
+	for _, l := range m.Labels {
+		foldLabel(l)
+	}
+	for _, n := range m.Notes {
+		fold(&n.Color)
+	}
+	if c := m.Configuration; c != nil {
+		if c.TextConfig != nil {
+			for _, s := range c.TextConfig.LabelStyles {
+				fold(&s.Color, &s.BackgroundColor, &s.OutlineColor)
+			}
+		}
+		if c.ShapeConfig != nil {
+			for _, s := range c.ShapeConfig.ShapeStyles {
+				fold(&s.StrokePaint, &s.FillPaint, &s.DsColor, &s.InsColor)
+			}
+		}
+	}
+}
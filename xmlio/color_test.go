@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// featureLine is the populated fixture's line for its labeled feature, whose
+// color is an explicit "0.0,0.0,0.0,1.0" and whose ring color is "null".
+const featureLine = 190
+
+// encodeColors decodes the populated fixture, with its feature line passed
+// through edit, and returns the decoded map and the UTF-8 XML it re-encodes to.
+func encodeColors(t *testing.T, edit func(string) string, opts ...xmlio.DecoderOption) (*wxx.Map_t, string) {
+	t.Helper()
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	lines := strings.Split(string(raw), "\n")
+	if !strings.HasPrefix(lines[featureLine-1], "<feature ") {
+		t.Fatalf("fixture line %d = %.40q, want the labeled <feature>", featureLine, lines[featureLine-1])
+	}
+	lines[featureLine-1] = edit(lines[featureLine-1])
+
+	m, err := xmlio.NewDecoder(append([]xmlio.DecoderOption{xmlio.WithAutoDetect()}, opts...)...).Decode(bytes.NewReader([]byte(strings.Join(lines, "\n"))))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var e xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&e)).Encode(&bytes.Buffer{}, m); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return m, string(e.Utf8Encoded)
+}
+
+// encodedFeature returns the start tag of the first <feature> in doc.
+func encodedFeature(t *testing.T, doc string) string {
+	t.Helper()
+	start := strings.Index(doc, "<feature ")
+	if start == -1 {
+		t.Fatal("no <feature> in the encoded map")
+	}
+	return doc[start : start+strings.Index(doc[start:], ">")]
+}
+
+// TestExplicitBlackIsAColor asserts that opaque black and "null" decode to the
+// two different states and are each written back as the file stated them.
+func TestExplicitBlackIsAColor(t *testing.T) {
+	m, doc := encodeColors(t, func(line string) string { return line })
+	if got, want := m.Features[0].Color, (&wxx.RGBA_t{A: 1, Raw: "0.0,0.0,0.0,1.0"}); got == nil || *got != *want {
+		t.Errorf("Features[0].Color = %+v, want %+v", got, want)
+	}
+	if got := m.Features[0].RingColor; got != nil {
+		t.Errorf("Features[0].RingColor = %+v, want nil for \"null\"", got)
+	}
+
+	feature := encodedFeature(t, doc)
+	for _, attr := range []string{` color="0.0,0.0,0.0,1.0"`, ` ringcolor="null"`} {
+		if !strings.Contains(feature, attr) {
+			t.Errorf("encoded feature does not contain %s\n%s", attr, feature)
+		}
+	}
+}
+
+// TestColorKeepsItsSpelling asserts that a color is written as the file spelled
+// it, and that a color changed after decoding is written from its values.
+func TestColorKeepsItsSpelling(t *testing.T) {
+	short := func(line string) string {
+		return strings.Replace(line, ` color="0.0,0.0,0.0,1.0"`, ` color="0,0,0,1"`, 1)
+	}
+
+	m, doc := encodeColors(t, short)
+	if got := m.Features[0].Color; got == nil || got.Raw != "0,0,0,1" {
+		t.Fatalf("Features[0].Color = %+v, want Raw \"0,0,0,1\"", got)
+	}
+	if feature := encodedFeature(t, doc); !strings.Contains(feature, ` color="0,0,0,1"`) {
+		t.Errorf("encoded feature lost the spelling \"0,0,0,1\"\n%s", feature)
+	}
+
+	// the stale Raw no longer describes the color, so it must not be written
+	m.Features[0].Color.R = 1
+	var e xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&e)).Encode(&bytes.Buffer{}, m); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if feature := encodedFeature(t, string(e.Utf8Encoded)); !strings.Contains(feature, ` color="1.0,0.0,0.0,1.0"`) {
+		t.Errorf("encoded feature does not carry the edited color\n%s", feature)
+	}
+}
+
+// TestBlackAsNoColor asserts that the opt-in fold reads explicit black as "no
+// color", however it is spelled, and that the encoder then writes it as "null".
+func TestBlackAsNoColor(t *testing.T) {
+	short := func(line string) string {
+		return strings.Replace(line, ` color="0.0,0.0,0.0,1.0"`, ` color="0,0,0,1"`, 1)
+	}
+	m, doc := encodeColors(t, short, xmlio.WithBlackAsNoColor())
+	if got := m.Features[0].Color; got != nil {
+		t.Errorf("Features[0].Color = %+v, want nil", got)
+	}
+	if got := m.Features[0].Label.Color; got != nil {
+		t.Errorf("Features[0].Label.Color = %+v, want nil", got)
+	}
+	if feature := encodedFeature(t, doc); !strings.Contains(feature, ` color="null"`) {
+		t.Errorf("encoded feature does not contain color=\"null\"\n%s", feature)
+	}
+}
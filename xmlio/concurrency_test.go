@@ -0,0 +1,195 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// syntheticMap returns the populated W2025 fixture with its tiles replaced by a
+// wide x high grid, for measuring the tile pipeline on a map the size of the
+// ones a batch regenerates. The tiles vary: some have every resource, and so
+// the long line form, and some have a background color.
+func syntheticMap(tb testing.TB, wide, high int) *wxx.Map_t {
+	tb.Helper()
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		tb.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(bytes.NewReader(raw))
+	if err != nil {
+		tb.Fatalf("decode %s: %v", populatedFixture, err)
+	}
+	tiles := wxx.NewTiles(wide, high, m.GridOrientation)
+	tiles.ViewLevel, tiles.Extras = m.Tiles.ViewLevel, m.Tiles.Extras
+	m.Tiles = tiles
+	for x := 0; x < wide; x++ {
+		for y := 0; y < high; y++ {
+			t := wxx.Tile_t{
+				Terrain:   (x + y) % 7,
+				Elevation: float64((x * y) % 2000),
+				IsIcy:     x%13 == 0,
+				IsGMOnly:  y%17 == 0,
+				Resources: wxx.Resources_t{Animal: (x * 3) % 101},
+			}
+			if (x+y)%5 == 0 {
+				t.Resources.Brick, t.Resources.Crops, t.Resources.Gems = 10, 20, 30
+				t.Resources.Lumber, t.Resources.Metals, t.Resources.Rock = 40, 50, 60
+			}
+			if (x*y)%11 == 0 {
+				t.CustomBackgroundColor = &wxx.RGBA_t{R: 0.25, G: 0.5, B: 0.75, A: 1}
+			}
+			m.Tiles.SetTile(x, y, t)
+		}
+	}
+	return m
+}
+
+// encodePlain encodes m to 2.06 as UTF-8 XML, neither gzipped nor UTF-16, so the
+// tiles are most of what is measured.
+func encodePlain(tb testing.TB, m *wxx.Map_t, opts ...xmlio.EncoderOption) []byte {
+	tb.Helper()
+	var buf bytes.Buffer
+	opts = append([]xmlio.EncoderOption{xmlio.WithGzipOutput(false), xmlio.WithUTF16BEOutput(false)}, opts...)
+	if err := xmlio.NewEncoder("2.06", opts...).Encode(&buf, m); err != nil {
+		tb.Fatalf("encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestConcurrentEncode asserts that an encode on several goroutines writes the
+// same bytes as a sequential one, for each codec.
+func TestConcurrentEncode(t *testing.T) {
+	m := syntheticMap(t, 60, 40)
+	for _, app := range []string{"2.06", classicTarget} {
+		var want, got bytes.Buffer
+		if err := xmlio.NewEncoder(app).Encode(&want, m); err != nil {
+			t.Fatalf("encode to %s: %v", app, err)
+		}
+		for _, n := range []int{2, 7, 0} {
+			got.Reset()
+			if err := xmlio.NewEncoder(app, xmlio.WithEncoderConcurrency(n)).Encode(&got, m); err != nil {
+				t.Fatalf("encode to %s on %d workers: %v", app, n, err)
+			}
+			if !bytes.Equal(got.Bytes(), want.Bytes()) {
+				t.Errorf("encode to %s on %d workers differs from a sequential encode", app, n)
+			}
+		}
+	}
+}
+
+// TestConcurrentDecode asserts that a decode on several goroutines builds the
+// same map as a sequential one, for each codec.
+func TestConcurrentDecode(t *testing.T) {
+	m := syntheticMap(t, 60, 40)
+	for _, app := range []string{"2.06", classicTarget} {
+		var doc bytes.Buffer
+		if err := xmlio.NewEncoder(app).Encode(&doc, m); err != nil {
+			t.Fatalf("encode to %s: %v", app, err)
+		}
+		want, err := xmlio.NewDecoder().Decode(bytes.NewReader(doc.Bytes()))
+		if err != nil {
+			t.Fatalf("decode %s: %v", app, err)
+		}
+		for _, n := range []int{2, 7, 0} {
+			got, err := xmlio.NewDecoder(xmlio.WithDecoderConcurrency(n)).Decode(bytes.NewReader(doc.Bytes()))
+			if err != nil {
+				t.Fatalf("decode %s on %d workers: %v", app, n, err)
+			}
+			// Created is the time of the decode, and the two may fall in different seconds
+			got.MetaData.Created = want.MetaData.Created
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("decode %s on %d workers differs from a sequential decode", app, n)
+			}
+		}
+	}
+}
+
+// TestConcurrentDecodeFaults asserts that warnings and errors come out of a
+// decode on several goroutines in document order: bad values in several rows
+// are reported as a sequential decode reports them, and a strict decode fails
+// on the first bad row even when a later one is parsed first.
+func TestConcurrentDecodeFaults(t *testing.T) {
+	doc := string(encodePlain(t, syntheticMap(t, 60, 40)))
+	// corrupt the first line of every seventh row, from the back of the map so
+	// the last row's fault is the one a worker is most likely to find first
+	rows := strings.Split(doc, "<tilerow>\n")
+	for x := len(rows) - 1; x > 0; x -= 7 {
+		rows[x] = "0\t1\t0\t0\t999\tZ" + rows[x][strings.Index(rows[x], "\n"):]
+	}
+	doc = strings.Join(rows, "<tilerow>\n")
+
+	decode := func(opts ...xmlio.DecoderOption) ([]xmlio.DecodeWarning, error) {
+		var d xmlio.DecoderDiagnostics
+		opts = append(opts, xmlio.WithAutoDetect(), xmlio.WithDecoderDiagnostics(&d))
+		_, err := xmlio.NewDecoder(opts...).Decode(strings.NewReader(doc))
+		return d.Warnings, err
+	}
+	want, err := decode(xmlio.WithLenientDecode())
+	if err != nil {
+		t.Fatalf("lenient decode: %v", err)
+	}
+	if len(want) < 8 {
+		t.Fatalf("lenient decode has %d warnings, want one per corrupted row", len(want))
+	}
+	_, wantErr := decode()
+	if wantErr == nil {
+		t.Fatal("strict decode succeeded")
+	}
+	for _, n := range []int{2, 7, 0} {
+		got, err := decode(xmlio.WithLenientDecode(), xmlio.WithDecoderConcurrency(n))
+		if err != nil {
+			t.Fatalf("lenient decode on %d workers: %v", n, err)
+		}
+		assertWarnings(t, got, want)
+		if _, err := decode(xmlio.WithDecoderConcurrency(n)); fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			t.Errorf("strict decode on %d workers: err = %v, want %v", n, err, wantErr)
+		}
+	}
+}
+
+// benchmarkWorkers are the settings the benchmarks compare: sequential, and one
+// goroutine per CPU.
+var benchmarkWorkers = []struct {
+	name string
+	n    int
+}{{"sequential", 1}, {"gomaxprocs", 0}}
+
+// BenchmarkEncodeTiles encodes a 1000x1000 map. Compare the sub-benchmarks for
+// the speedup WithEncoderConcurrency gives:
+//
+//	go test ./xmlio -run '^$' -bench Tiles -benchmem
+func BenchmarkEncodeTiles(b *testing.B) {
+	m := syntheticMap(b, 1000, 1000)
+	for _, w := range benchmarkWorkers {
+		b.Run(w.name, func(b *testing.B) {
+			for b.Loop() {
+				encodePlain(b, m, xmlio.WithEncoderConcurrency(w.n))
+			}
+		})
+	}
+}
+
+// BenchmarkDecodeTiles decodes a 1000x1000 map, sequentially and with
+// WithDecoderConcurrency.
+func BenchmarkDecodeTiles(b *testing.B) {
+	doc := encodePlain(b, syntheticMap(b, 1000, 1000))
+	for _, w := range benchmarkWorkers {
+		b.Run(w.name, func(b *testing.B) {
+			b.SetBytes(int64(len(doc)))
+			for b.Loop() {
+				if _, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithDecoderConcurrency(w.n)).Decode(bytes.NewReader(doc)); err != nil {
+					b.Fatalf("decode: %v", err)
+				}
+			}
+		})
+	}
+}
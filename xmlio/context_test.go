@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// countdownContext is a context that is cancelled once Err has been asked n
+// times, so a test can stop a decode or an encode partway through without a
+// race.
+type countdownContext struct {
+	context.Context
+	n atomic.Int64
+}
+
+func newCountdownContext(n int64) *countdownContext {
+	ctx := &countdownContext{Context: context.Background()}
+	ctx.n.Store(n)
+	return ctx
+}
+
+func (c *countdownContext) Err() error {
+	if c.n.Add(-1) < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+// TestContextCancelledBeforeStart asserts that a context that is already done
+// stops every entry point, and that nothing is written.
+func TestContextCancelledBeforeStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).DecodeContext(ctx, bytes.NewReader(raw)); !errors.Is(err, context.Canceled) {
+		t.Errorf("DecodeContext: error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := xmlio.ReadFileContext(ctx, populatedFixture); !errors.Is(err, context.Canceled) {
+		t.Errorf("ReadFileContext: error = %v, want %v", err, context.Canceled)
+	}
+
+	m := loadMap(t, populatedFixture)
+	var buf bytes.Buffer
+	if err := xmlio.NewEncoder("2.06").EncodeContext(ctx, &buf, m); !errors.Is(err, context.Canceled) || buf.Len() != 0 {
+		t.Errorf("EncodeContext: error = %v, %d bytes written, want %v and none", err, buf.Len(), context.Canceled)
+	}
+	path := filepath.Join(t.TempDir(), "cancelled.wxx")
+	if err := xmlio.WriteFileContext(ctx, path, m, "2.06"); !errors.Is(err, context.Canceled) {
+		t.Errorf("WriteFileContext: error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("WriteFileContext: the file exists after a cancelled write: %v", err)
+	}
+}
+
+// TestContextCancelledBetweenRows asserts that a decode and an encode check the
+// context for each tile row, so that a context done partway through a large map
+// stops it, on one goroutine or several. The map has 100 rows, and the context
+// gives out long before that, but after every stage of the pipeline has passed.
+func TestContextCancelledBetweenRows(t *testing.T) {
+	m := syntheticMap(t, 100, 20)
+	for _, app := range []string{"2.06", classicTarget} {
+		for _, workers := range []int{1, 4} {
+			var buf bytes.Buffer
+			if err := xmlio.NewEncoder(app, xmlio.WithEncoderConcurrency(workers)).EncodeContext(newCountdownContext(50), &buf, m); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s, %d workers: encode error = %v, want %v", app, workers, err, context.Canceled)
+			}
+
+			data, err := xmlio.MarshalXML(m, app)
+			if err != nil {
+				t.Fatalf("%s: marshal: %v", app, err)
+			}
+			dec := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithDecoderConcurrency(workers))
+			if _, err := dec.DecodeContext(newCountdownContext(50), bytes.NewReader(data)); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s, %d workers: decode error = %v, want %v", app, workers, err, context.Canceled)
+			}
+			if _, err := dec.DecodeContext(newCountdownContext(1000), bytes.NewReader(data)); err != nil {
+				t.Errorf("%s, %d workers: a context that outlasts the decode: %v", app, workers, err)
+			}
+		}
+	}
+}
@@ -270,8 +270,10 @@ func TestW2025CoverageMatrix(t *testing.T) {
 	if got, want := s2.Tiles.TilesHigh, s1.Tiles.TilesHigh; got != want {
 		t.Errorf("sample: Tiles.TilesHigh = %d, want %d", got, want)
 	}
-	if got, want := len(s2.Tiles.Tiles), len(s1.Tiles.Tiles); got != want {
-		t.Errorf("sample: len(Tiles.Tiles) = %d, want %d", got, want)
+	got, _ := s2.Tiles.Size()
+	want, _ := s1.Tiles.Size()
+	if got != want {
+		t.Errorf("sample: Tiles.Size() wide = %d, want %d", got, want)
 	}
 
 	// mapkey (implemented)
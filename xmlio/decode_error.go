@@ -0,0 +1,148 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
+)
+
+// DecodeError reports where in the document a decode failed. Decode returns one
+// whenever the codec could place the failure, so a caller can point a user at
+// the offending tile instead of printing a bare "strconv.Atoi: parsing" message:
+//
+//	var de *xmlio.DecodeError
+//	if errors.As(err, &de) {
+//		fmt.Printf("%s:%d:%d: %v\n", path, de.XMLLine, de.XMLColumn, de.Err)
+//	}
+//
+// Err wraps the wxx sentinel for the kind of failure (ErrInvalidTileValue,
+// ErrInvalidRGBA, ErrInvalidHexOrientation, ...), so errors.Is keeps working
+// through a DecodeError. A failure the XML parser reported wraps ErrInvalidXML.
+type DecodeError struct {
+	// Path is the element that failed, as a slash-separated path from the root:
+	// "map", "map/terrainmap", or "map/tiles/tilerow[17]". Indices are 1-based
+	// and count only the elements of that name.
+	Path string
+
+	// Line is the 1-based line within the element's data that failed, for
+	// elements such as <tilerow> whose text holds one record per line. Blank
+	// lines are not counted. It is 0 for elements without records.
+	Line int
+
+	// Field names the value that failed: a tile field such as "elevation" or
+	// "rgba", or a <map> attribute such as "hexOrientation". It is empty when
+	// the whole element failed.
+	Field string
+
+	// XMLLine and XMLColumn are the 1-based position in the decoded XML text,
+	// counting the XML header Decode removes. They are 0 when the position is
+	// not known. For a record inside an element's data the column is that of
+	// the start of its line.
+	XMLLine, XMLColumn int
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	var sb strings.Builder
+	if e.Path != "" {
+		sb.WriteString(e.Path)
+		sb.WriteString(": ")
+	}
+	if e.Line != 0 {
+		sb.WriteString(fmt.Sprintf("line %d: ", e.Line))
+	}
+	if e.Field != "" {
+		sb.WriteString(e.Field)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Err.Error())
+	if e.XMLLine != 0 {
+		sb.WriteString(fmt.Sprintf(" (xml line %d, column %d)", e.XMLLine, e.XMLColumn))
+	}
+	return sb.String()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeWarning records a bad value a decode with WithLenientDecode replaced
+// instead of failing on. Path, Line, and Field locate it as they would locate
+// the DecodeError a strict decode returns; Err is that error's cause.
+type DecodeWarning struct {
+	Path   string // element path, "map/tiles/tilerow[17]"
+	Line   int    // 1-based line within the element's data, 0 for none
+	Field  string // the value that was replaced, "brick" or "label.color"
+	Value  string // the value as the file stated it
+	Action string // what was used instead, "set to 100" or "dropped"
+	Err    error  // why the value was rejected; wraps a wxx sentinel
+}
+
+func (w DecodeWarning) String() string {
+	var sb strings.Builder
+	if w.Path != "" {
+		sb.WriteString(w.Path)
+		sb.WriteString(": ")
+	}
+	if w.Line != 0 {
+		sb.WriteString(fmt.Sprintf("line %d: ", w.Line))
+	}
+	if w.Field != "" {
+		sb.WriteString(w.Field)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(fmt.Sprintf("%q: %s", w.Value, w.Action))
+	return sb.String()
+}
+
+// asDecodeWarnings converts the codec's warnings to the exported type.
+func asDecodeWarnings(src []xmlstream.Warning) []DecodeWarning {
+	var warnings []DecodeWarning
+	for _, w := range src {
+		warnings = append(warnings, DecodeWarning{
+			Path:   w.Path,
+			Line:   w.Line,
+			Field:  w.Field,
+			Value:  w.Value,
+			Action: w.Action,
+			Err:    w.Err,
+		})
+	}
+	return warnings
+}
+
+// asDecodeError converts a codec's located error to a DecodeError. headerLines
+// is the number of lines in the XML header Decode removed before the codec saw
+// the document, which the codec's positions do not count.
+//
+// An error with no wxx sentinel in its chain came from the XML parser, and
+// gains ErrInvalidXML so that callers can tell malformed input from a value the
+// model rejected. Errors the codec did not locate are returned unchanged.
+func asDecodeError(err error, headerLines int) error {
+	var xe *xmlstream.Error
+	if !errors.As(err, &xe) {
+		return err
+	}
+	de := &DecodeError{
+		Path:      xe.Path,
+		Line:      xe.Line,
+		Field:     xe.Field,
+		XMLLine:   xe.XMLLine,
+		XMLColumn: xe.XMLColumn,
+		Err:       xe.Err,
+	}
+	if de.XMLLine != 0 {
+		de.XMLLine += headerLines
+	}
+	var sentinel wxx.Error
+	if !errors.As(de.Err, &sentinel) {
+		de.Err = errors.Join(wxx.ErrInvalidXML, de.Err)
+	}
+	return de
+}
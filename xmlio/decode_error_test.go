@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// decodeEdited decodes the populated fixture after edit has rewritten its text,
+// and returns the DecodeError the decode must fail with.
+func decodeEdited(t *testing.T, edit func(lines []string)) *xmlio.DecodeError {
+	t.Helper()
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	lines := strings.Split(string(raw), "\n")
+	edit(lines)
+	_, err = xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(bytes.NewReader([]byte(strings.Join(lines, "\n"))))
+	var de *xmlio.DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("decode error = %v, want a *xmlio.DecodeError", err)
+	}
+	return de
+}
+
+// TestDecodeErrorLocatesTileValue corrupts one tile and asserts that the error
+// names its <tilerow>, its line within the row, the field, and its line in the
+// document, counting the XML header the decoder strips.
+func TestDecodeErrorLocatesTileValue(t *testing.T) {
+	// line 30 of the fixture opens the second <tilerow>; 32 is its second tile
+	const xmlLine = 32
+	de := decodeEdited(t, func(lines []string) {
+		if lines[xmlLine-3] != "<tilerow>" {
+			t.Fatalf("fixture line %d = %q, want the second <tilerow>", xmlLine-2, lines[xmlLine-3])
+		}
+		lines[xmlLine-1] = "0\t1\t0\t0\t0\t101\t0\t0\t0\t0\t0" // brick out of range
+	})
+	if de.Path != "map/tiles/tilerow[2]" {
+		t.Errorf("Path = %q, want %q", de.Path, "map/tiles/tilerow[2]")
+	}
+	if de.Line != 2 {
+		t.Errorf("Line = %d, want 2", de.Line)
+	}
+	if de.Field != "brick" {
+		t.Errorf("Field = %q, want %q", de.Field, "brick")
+	}
+	if de.XMLLine != xmlLine || de.XMLColumn != 1 {
+		t.Errorf("XML position = %d:%d, want %d:1", de.XMLLine, de.XMLColumn, xmlLine)
+	}
+	if !errors.Is(de, wxx.ErrInvalidTileValue) {
+		t.Errorf("error = %v, want it to wrap %v", de, wxx.ErrInvalidTileValue)
+	}
+}
+
+// TestDecodeErrorLocatesMapAttribute asserts that a bad <map> attribute is
+// reported against the root element and the attribute's name.
+func TestDecodeErrorLocatesMapAttribute(t *testing.T) {
+	de := decodeEdited(t, func(lines []string) {
+		lines[3] = strings.Replace(lines[3], `hexOrientation="COLUMNS"`, `hexOrientation="DIAGONAL"`, 1)
+	})
+	if de.Path != "map" || de.Field != "hexOrientation" {
+		t.Errorf("location = %q %q, want %q %q", de.Path, de.Field, "map", "hexOrientation")
+	}
+	if de.XMLLine == 0 {
+		t.Errorf("XMLLine = 0, want the position of the <map> start tag")
+	}
+	if !errors.Is(de, wxx.ErrInvalidHexOrientation) {
+		t.Errorf("error = %v, want it to wrap %v", de, wxx.ErrInvalidHexOrientation)
+	}
+}
+
+// TestDecodeErrorMarksMalformedXML asserts that a failure from the XML parser is
+// located and wraps ErrInvalidXML.
+func TestDecodeErrorMarksMalformedXML(t *testing.T) {
+	de := decodeEdited(t, func(lines []string) {
+		lines[28] = "</tilerows>" // the first </tilerow>
+	})
+	if !strings.HasPrefix(de.Path, "map/tiles") {
+		t.Errorf("Path = %q, want it under map/tiles", de.Path)
+	}
+	if de.XMLLine != 29 {
+		t.Errorf("XMLLine = %d, want 29", de.XMLLine)
+	}
+	if !errors.Is(de, wxx.ErrInvalidXML) {
+		t.Errorf("error = %v, want it to wrap %v", de, wxx.ErrInvalidXML)
+	}
+}
+
+// TestDecodeErrorLocatesOversizedTileRow asserts that a <tilerow> with more
+// lines than tilesHigh is refused as an invalid grid, located at the first line
+// too many, by the classic and the current decoder alike.
+func TestDecodeErrorLocatesOversizedTileRow(t *testing.T) {
+	for _, fixture := range []string{classicFixture, populatedFixture} {
+		t.Run(filepath.Base(fixture), func(t *testing.T) {
+			raw, err := os.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var d xmlio.DecoderDiagnostics
+			m, err := decodeWith(raw, xmlio.WithDecoderDiagnostics(&d))
+			if err != nil {
+				t.Fatalf("decode %s: %v", fixture, err)
+			}
+			// repeat the first tile of the first <tilerow> of the UTF-8 text
+			text := d.Converted
+			if len(text) == 0 {
+				text = raw
+			}
+			start := bytes.Index(text, []byte("<tilerow>\n")) + len("<tilerow>\n")
+			end := start + bytes.IndexByte(text[start:], '\n') + 1
+			doc := append(append(append([]byte{}, text[:end]...), text[start:end]...), text[end:]...)
+
+			_, err = decodeWith(doc, xmlio.WithDefaultLimits())
+			var de *xmlio.DecodeError
+			if !errors.As(err, &de) || !errors.Is(err, wxx.ErrInvalidTileGrid) {
+				t.Fatalf("decode error = %v, want a *xmlio.DecodeError wrapping %v", err, wxx.ErrInvalidTileGrid)
+			}
+			if de.Path != "map/tiles/tilerow[1]" || de.Line != m.Tiles.TilesHigh+1 {
+				t.Errorf("location = %q line %d, want %q line %d", de.Path, de.Line, "map/tiles/tilerow[1]", m.Tiles.TilesHigh+1)
+			}
+		})
+	}
+}
@@ -3,17 +3,20 @@
 package xmlio
 
 import (
+	"bufio"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"encoding/xml"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/maloquacious/wxx"
 	"github.com/maloquacious/wxx/xmlio/internal/v0_77"
-	"github.com/maloquacious/wxx/xmlio/internal/v1_06"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
@@ -29,11 +32,25 @@ type Decoder struct {
 type DecoderOption func(*decoderOpts)
 
 type decoderOpts struct {
+	autoDetect      bool
 	compressedInput bool
 	utf16BeInput    bool
 	hasXmlHeader    bool
 	fixXmlHeader    bool
+	lenient         bool
+	blackAsNoColor  bool
+	preserve        bool
+	strictSchema    bool
+	workers         int
 	diagnostics     *DecoderDiagnostics
+	tracer          Tracer
+
+	// limits; 0 is none. See WithDefaultLimits.
+	maxCompressedSize   int64
+	maxDecompressedSize int64
+	maxTiles            int
+	maxElements         int
+	maxDepth            int
 }
 
 type DecoderDiagnostics struct {
@@ -41,9 +58,42 @@ type DecoderDiagnostics struct {
 	Uncompressed []byte // input after running gunzip
 	Converted    []byte // input after converting UTF-16 to UTF-8
 	XMLHeader    []byte // XML header that was removed
-	XMLData      []byte
+	XMLData      []byte // input after removing the XML header
 	MapElement   []byte
 	Schema       string
+
+	// The container layers the decoder found. With WithAutoDetect they are what
+	// it sniffed; otherwise they are the layers the options required.
+	Gzip     bool   // input was gzip compressed
+	Encoding string // character encoding: "UTF-16BE", "UTF-16LE", or "UTF-8"
+	BOM      bool   // input opened with a byte order mark
+
+	// Warnings lists the values WithLenientDecode repaired, in document order.
+	Warnings []DecodeWarning
+
+	// FallbackSchema is the schema of the codec that read a file stating a minor
+	// schema no codec declares ("1.06" for a "1.07" file), and "" when a codec
+	// declares the file's own.
+	FallbackSchema string
+
+	// UnknownContent lists the attributes and child elements the codec did not
+	// consume, in document order, one entry per name at each path. They are kept
+	// on the map as extras (see wxx.Extras_t). It is filled on every decode, not
+	// only a fallback one: a build can add to the format without a new schema.
+	UnknownContent []UnknownContent_t
+}
+
+// UnknownContent_t is one attribute or element name, at one path, that the codec
+// that read the file has no field for.
+type UnknownContent_t struct {
+	Path   string // "map/features/feature/@tint", or "map/weather" for an element
+	Field  string // the Extras field it is kept in, "Map_t.Features[].Extras"
+	Count  int    // the number of elements it was found on
+	Detail string // what was found, with the attribute's values
+}
+
+func (u UnknownContent_t) String() string {
+	return fmt.Sprintf("%s: %s", u.Path, u.Detail)
 }
 
 // NewDecoder returns a Decoder that implements the wxx.Decoder interface.
@@ -55,6 +105,7 @@ func NewDecoder(opts ...DecoderOption) *Decoder {
 			utf16BeInput:    true,
 			hasXmlHeader:    true,
 			fixXmlHeader:    true,
+			workers:         1,
 			diagnostics:     nil,
 		},
 	}
@@ -64,9 +115,24 @@ func NewDecoder(opts ...DecoderOption) *Decoder {
 	return d
 }
 
+// WithAutoDetect sniffs the container format instead of requiring one: gzip or
+// plain, UTF-16 big- or little-endian or UTF-8, each with or without a BOM, and
+// with or without an XML header. It accepts a .wxx file, the payload `wxx export
+// --raw` writes, the UTF-8 `wxx export --utf-8` writes, and files other tools
+// have transcoded. ReadFile uses it by default.
+//
+// Options are applied in order: WithSkipUncompress or WithUTF16BEInput after
+// WithAutoDetect turns detection off again and requires the layers they name.
+func WithAutoDetect() DecoderOption {
+	return func(o *decoderOpts) {
+		o.autoDetect = true
+	}
+}
+
 // WithSkipUncompress skips the step for running gunzip on the input.
 func WithSkipUncompress() DecoderOption { // expect gzip on input
 	return func(o *decoderOpts) {
+		o.autoDetect = false
 		o.compressedInput = false
 	}
 }
@@ -81,10 +147,110 @@ func WithDecoderDiagnostics(buf *DecoderDiagnostics) DecoderOption {
 // WithUTF16BEInput sets the flag for running the UTF16/BE to UTF8 conversion on the input.
 func WithUTF16BEInput(enabled bool) DecoderOption { // expect UTF-16/BE
 	return func(o *decoderOpts) {
+		o.autoDetect = false
 		o.utf16BeInput = enabled
 	}
 }
 
+// WithLenientDecode decodes a map that has bad values in it instead of rejecting
+// the whole map for the first one. Each bad value is replaced and the decode
+// goes on:
+//
+//   - a tile resource outside 0...100 is clamped into the range
+//   - a tile value that is not a number is set to 0
+//   - a compressed-resource sentinel other than Z is read as Z
+//   - a color that cannot be parsed is dropped, as if it had been left out
+//   - an unknown hexOrientation is set to COLUMNS and an unknown mapProjection
+//     to FLAT
+//
+// Every repair is listed in DecoderDiagnostics.Warnings, so a caller that wants
+// to know what was changed should pass WithDecoderDiagnostics as well. Faults in
+// the structure of the file still fail the decode: malformed XML, a tile line
+// with the wrong number of fields, and a <map> that does not say what wrote it.
+//
+// Worldographer opens some maps this decoder rejects; this is the way to rescue
+// them.
+func WithLenientDecode() DecoderOption {
+	return func(o *decoderOpts) {
+		o.lenient = true
+	}
+}
+
+// WithBlackAsNoColor decodes every explicit opaque black color,
+// "0.0,0.0,0.0,1.0" however it is spelled, as nil, "no color".
+//
+// Worldographer writes opaque black in places it means "no color" as well as in
+// places it means black, and the file does not say which. By default the decoder
+// keeps what the file states: "null" and a missing attribute are nil, and black
+// is an RGBA_t. This option is the older reading, for a caller that would rather
+// treat the two as one; the encoders then write those colors as "null" where the
+// attribute can be null, and as black where it cannot.
+func WithBlackAsNoColor() DecoderOption {
+	return func(o *decoderOpts) {
+		o.blackAsNoColor = true
+	}
+}
+
+// WithPreserveFormatting keeps the file's spelling of the map on the decoded
+// Map_t, in Map_t.Formatting, so that encoding it again reproduces the file byte
+// for byte where nothing has changed. Worldographer and this module's encoder
+// spell numbers, order attributes, and lay out whitespace differently, and
+// without this a decode and encode of an untouched file rewrites most of it --
+// which, in version control, looks the same as a change.
+//
+// Every Encoder honours the forms when it writes the application version they
+// were recorded for; clearing Map_t.Formatting turns them off. An edited value
+// is written as the encoder spells it, and the rest of its tag or tile row keeps
+// the file's spelling. Content the encoder cannot write at all is not brought
+// back: see EncoderDiagnostics.Dropped and the codec COVERAGE documents.
+//
+// The decoder encodes the map once more to find where the spellings differ, and
+// keeps both spellings of each token that does, so this costs a second pass and
+// memory in proportion to how differently the file was written.
+func WithPreserveFormatting() DecoderOption {
+	return func(o *decoderOpts) {
+		o.preserve = true
+	}
+}
+
+// WithStrictSchema fails a decode the codec did not fully understand, where by
+// default the map is returned and DecoderDiagnostics says what was not:
+//
+//   - a file stating a minor schema no codec declares fails with
+//     wxx.ErrNewerSchema instead of being read by the nearest older codec
+//   - a file with attributes or elements the codec has no field for fails with
+//     wxx.ErrUnknownContent, and the error lists them
+//
+// It is for a caller that would rather stop than carry content it cannot
+// vouch for, and it is the quickest way to find out that Worldographer has
+// changed its format. Note that the 2.06 fixtures are not all clean: label drop
+// shadows are unmodeled (see the v1_06 COVERAGE document).
+func WithStrictSchema() DecoderOption {
+	return func(o *decoderOpts) {
+		o.strictSchema = true
+	}
+}
+
+// WithDecoderConcurrency parses <tilerow> elements on up to n goroutines; n < 1
+// means one per CPU, runtime.GOMAXPROCS(0). The default is 1, which parses them
+// on the goroutine that calls Decode.
+//
+// The tiles are most of a large map's file and most of the time it takes to
+// decode, and each row can be parsed without the others. The XML itself is still
+// read by one goroutine, so the gain is bounded by how fast that is, and at most
+// n rows are held in flight, so memory does not grow with n beyond that. The
+// decoded map, the warnings of a lenient decode and the error of a failed one are
+// the same for any n.
+//
+// It is not WithConcurrency, shared with the Encoder, because an option is a
+// function of one type and a function can return only one type; see
+// WithEncoderConcurrency for the Encoder's.
+func WithDecoderConcurrency(n int) DecoderOption {
+	return func(o *decoderOpts) {
+		o.workers = xmlstream.Workers(n)
+	}
+}
+
 // WithFixXMLHeaderEncoding sets the flag for updating the encoding in the XML header.
 func WithFixXMLHeaderEncoding(enabled bool) DecoderOption {
 	return func(o *decoderOpts) {
@@ -93,9 +259,29 @@ func WithFixXMLHeaderEncoding(enabled bool) DecoderOption {
 }
 
 // Decode creates a Map_t from the input or returns an error.
+//
+// The input is decoded as a stream. Each layer of the container is a reader
+// wrapped around the one below it -- gunzip, then UTF-16 to UTF-8, then the XML
+// token stream -- and the codec fills the Map_t one <tilerow> at a time, so no
+// stage holds a copy of the file. Peak memory grows with the map being built,
+// not with the size of the input. The only stage buffered whole is the opening
+// <map ...> tag, which is read ahead to pick the codec.
+//
+// With WithDecoderDiagnostics, each stage is also copied into the diagnostics
+// buffers as it streams past. That is the one configuration in which the input
+// is held in memory, and it is meant for debugging.
 func (d *Decoder) Decode(r io.Reader) (*wxx.Map_t, error) {
+	return d.DecodeContext(context.Background(), r)
+}
+
+// DecodeContext is Decode, stopping when ctx is done. ctx is checked between the
+// stages of the pipeline and before each <tilerow>, and a decode it stops returns
+// ctx.Err(), which errors.Is matches to context.Canceled or
+// context.DeadlineExceeded. A read that is blocked is not interrupted: a reader
+// that can block for long, such as a network connection, should be given a
+// deadline of its own.
+func (d *Decoder) DecodeContext(ctx context.Context, r io.Reader) (*wxx.Map_t, error) {
 	// internal steps:
-	// * ReadFile
 	// * ReadCompressedXML
 	// * ReadUTF16XML
 	// * ReadUTF8XML
@@ -103,97 +289,213 @@ func (d *Decoder) Decode(r io.Reader) (*wxx.Map_t, error) {
 	// * * Verify root element is `map`
 	// * * Consume XML Header
 	// * * Read map metadata
-	// * * xml.Unmarshal
-	// * * Dispatch to version+schema specific Read
+	// * * Dispatch to version+schema specific DecodeReader
+	// * * Drain the input so the gzip checksum is verified
 	// * * Return Map_t
 
-	// read the entire input into memory
-	data, err := io.ReadAll(r)
-	if err != nil {
-		return nil, errors.Join(wxx.ErrRawReadFailed, err)
+	diagnostics := d.opts.diagnostics
+	var raw, uncompressed, converted, xmlData bytes.Buffer
+	if diagnostics != nil {
+		// publish whatever each stage captured, including on an early return
+		defer func() {
+			diagnostics.Raw = raw.Bytes()
+			diagnostics.Uncompressed = uncompressed.Bytes()
+			diagnostics.Converted = converted.Bytes()
+			diagnostics.XMLData = xmlData.Bytes()
+		}()
 	}
-	if d.opts.diagnostics != nil {
-		d.opts.diagnostics.Raw = bdup(data)
+
+	// the stages that stream are traced by readers in the stack, which report
+	// what passed through them when the decode is done
+	tracer := d.opts.tracer
+	var read, gunzipped, utf16 *traceReader
+	if tracer != nil {
+		defer func() {
+			traceStreams(tracer, read, gunzipped, utf16)
+		}()
 	}
 
-	if d.opts.compressedInput {
-		// Uncompress the input by running gunzip on it.
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// src is the top of the reader stack. Each stage tags its read errors with
+	// its own sentinel, because in a stream they surface wherever the next read
+	// happens to be rather than in the stage that failed.
+	var src io.Reader = r
+	if tracer != nil {
+		read = &traceReader{r: src}
+		src = read
+	}
+	src = &stageReader{r: src, sentinel: wxx.ErrRawReadFailed}
+	if d.opts.maxCompressedSize > 0 {
+		src = &sizeLimiter{r: src, max: d.opts.maxCompressedSize, sentinel: wxx.ErrCompressedTooLarge}
+	}
+	if diagnostics != nil {
+		src = io.TeeReader(src, &raw)
+	}
+
+	// compression: a fixed configuration requires gzip, auto-detection looks for it
+	compressed := d.opts.compressedInput
+	if d.opts.autoDetect || compressed {
+		br := bufio.NewReader(src)
+		src = br
 
 		// verify that the input is actually gzip data by looking for the magic number.
-		if !(len(data) >= 2 && data[0] == 0x1F && data[1] == 0x8B) {
+		magic, err := peek(br, 2)
+		if err != nil {
+			return nil, err
+		}
+		isGzip := len(magic) >= 2 && magic[0] == 0x1F && magic[1] == 0x8B
+		if d.opts.autoDetect {
+			compressed = isGzip
+		} else if !isGzip {
 			return nil, wxx.ErrNotCompressed
 		}
-
+	}
+	if diagnostics != nil {
+		diagnostics.Gzip = compressed
+	}
+	if compressed {
+		// Uncompress the input by running gunzip on it.
 		// Create a new gzip reader to process the source.
 		// This will return an error if the input is not gzip data.
-		gzr, err := gzip.NewReader(bytes.NewReader(data))
+		gzr, err := gzip.NewReader(src)
 		if err != nil {
 			return nil, errors.Join(wxx.ErrGZipNewReaderFailed, err)
 		}
 		defer func(gzr *gzip.Reader) {
 			_ = gzr.Close() // ignore errors closing this reader
 		}(gzr)
-		// Run gunzip on the input, returning any errors.
-		data, err = io.ReadAll(gzr)
-		if err != nil {
-			return nil, errors.Join(wxx.ErrGUnZipFailed, err)
+		src = &stageReader{r: gzr, sentinel: wxx.ErrGUnZipFailed}
+		if tracer != nil {
+			gunzipped = &traceReader{r: src}
+			src = gunzipped
 		}
-		if d.opts.diagnostics != nil {
-			d.opts.diagnostics.Uncompressed = bdup(data)
+		if diagnostics != nil {
+			src = io.TeeReader(src, &uncompressed)
 		}
 	}
 
-	if d.opts.utf16BeInput {
-		// decode UTF-16/BE into UTF-8
+	if d.opts.maxDecompressedSize > 0 {
+		src = &sizeLimiter{r: src, max: d.opts.maxDecompressedSize, sentinel: wxx.ErrDecompressedTooLarge}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		// verify the BOM for UTF-16/BE
-		if bytes.HasPrefix(data, []byte{0xfe, 0xff}) {
-			// as expected
-		} else if bytes.HasPrefix(data, []byte{0xff, 0xfe}) {
-			return nil, wxx.ErrNotBigEndianUTF16Encoded
-		} else {
-			return nil, wxx.ErrMissingBOM
-		}
+	// character encoding: the BOM, or the lack of one, names it
+	encoding, hasBOM := encodingUTF8, false
+	if d.opts.autoDetect || d.opts.utf16BeInput {
+		br := bufio.NewReader(src)
+		src = br
 
-		utf16Encoding := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
-		data, err = io.ReadAll(transform.NewReader(bytes.NewReader(data), utf16Encoding.NewDecoder()))
+		head, err := peek(br, 4)
 		if err != nil {
-			return nil, errors.Join(wxx.ErrInvalidUTF16, err)
+			return nil, err
+		}
+		encoding, hasBOM = sniffEncoding(head)
+		if d.opts.autoDetect && encoding == encodingUTF8 && hasBOM {
+			// the UTF-8 BOM is not XML; drop it before the header check sees it
+			if _, err := br.Discard(len(utf8BOM)); err != nil {
+				return nil, err
+			}
+		} else if !d.opts.autoDetect {
+			// verify the BOM for UTF-16/BE
+			if encoding == encodingUTF16BE && hasBOM {
+				// as expected
+			} else if encoding == encodingUTF16LE && hasBOM {
+				return nil, wxx.ErrNotBigEndianUTF16Encoded
+			} else {
+				return nil, wxx.ErrMissingBOM
+			}
+		}
+	}
+	if diagnostics != nil {
+		diagnostics.Encoding, diagnostics.BOM = encoding, hasBOM
+	}
+	if encoding == encodingUTF16BE || encoding == encodingUTF16LE {
+		// decode UTF-16 into UTF-8. ExpectBOM consumes the BOM when there is one.
+		endianness, bomPolicy := unicode.BigEndian, unicode.IgnoreBOM
+		if encoding == encodingUTF16LE {
+			endianness = unicode.LittleEndian
+		}
+		if hasBOM {
+			bomPolicy = unicode.ExpectBOM
 		}
-		if d.opts.diagnostics != nil {
-			d.opts.diagnostics.Converted = bdup(data)
+		utf16Encoding := unicode.UTF16(endianness, bomPolicy)
+		src = &stageReader{r: transform.NewReader(src, utf16Encoding.NewDecoder()), sentinel: wxx.ErrInvalidUTF16}
+		if tracer != nil {
+			utf16 = &traceReader{r: src}
+			src = utf16
+		}
+		if diagnostics != nil {
+			src = io.TeeReader(src, &converted)
 		}
 	}
 
-	// extract the XML header
-	xmlHeaderIndex := -1 // sentinel value meaning no header found
-	if d.opts.hasXmlHeader {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// br is sized to hold the opening <map ...> tag, which is read ahead below
+	br := bufio.NewReaderSize(src, mapElementReadAhead)
+
+	// extract the XML header, remembering how many lines it held so that the
+	// positions the codec reports can be made positions in the document
+	at := time.Now()
+	headerLines, headerLen := 0, 0
+	if d.opts.autoDetect {
+		// A header is optional, and need not be one Worldographer writes: the
+		// encoding it declares is ignored, since the BOM has already decided that.
+		heading, err := sniffXMLHeader(br)
+		if err != nil {
+			return nil, err
+		}
+		headerLines, headerLen = bytes.Count(heading, []byte("\n")), len(heading)
+		if diagnostics != nil {
+			diagnostics.XMLHeader = heading
+		}
+	} else if d.opts.hasXmlHeader {
 		// verify that we have an XML header before we extract it.
 		// this will fail if the input is not UTF-8 encoded.
-		if !bytes.HasPrefix(data, []byte("<?xml")) {
-			return nil, wxx.ErrMissingXMLHeader
+		prefix, err := peek(br, len("<?xml"))
+		if err != nil {
+			return nil, err
 		}
-		foundValidHeader := false
-		for i, header := range xmlHeaders {
-			if bytes.HasPrefix(data, []byte(header.heading)) {
-				foundValidHeader, xmlHeaderIndex = true, i
-				break
-			}
+		if !bytes.HasPrefix(prefix, []byte("<?xml")) {
+			return nil, wxx.ErrMissingXMLHeader
 		}
-		if !foundValidHeader {
+		xmlHeaderIndex := knownXMLHeader(br)
+		if xmlHeaderIndex == -1 {
 			return nil, wxx.ErrInvalidXMLHeader
 		}
-		if d.opts.diagnostics != nil {
-			d.opts.diagnostics.XMLHeader = bdup(data[:len(xmlHeaders[xmlHeaderIndex].heading)])
+		headerLines = strings.Count(xmlHeaders[xmlHeaderIndex].heading, "\n")
+		headerLen = len(xmlHeaders[xmlHeaderIndex].heading)
+		if diagnostics != nil {
+			diagnostics.XMLHeader = []byte(xmlHeaders[xmlHeaderIndex].heading)
 		}
-		// consume the XML header since our unmarshal code expects only the XML data
-		data = data[len(xmlHeaders[xmlHeaderIndex].heading):]
-		if d.opts.diagnostics != nil {
-			d.opts.diagnostics.XMLHeader = bdup(data)
+		// consume the XML header since our decoders expect only the XML data
+		if _, err := br.Discard(len(xmlHeaders[xmlHeaderIndex].heading)); err != nil {
+			return nil, err
 		}
 	}
 
-	// data is now clean UTF‑8 XML data with no header
+	if tracer != nil {
+		tracer.Trace(TraceEvent_t{Stage: StageHeader, BytesIn: int64(headerLen), Duration: time.Since(at)})
+		at = time.Now()
+	}
+
+	// the stream is now clean UTF‑8 XML data with no header
+
+	// Read ahead far enough to hold the opening <map ...> tag. Peek returns what
+	// there is when the input is shorter than the buffer, so only a read error
+	// from a lower stage is fatal here.
+	data, err := peek(br, br.Size())
+	if err != nil {
+		return nil, err
+	}
 
 	// quick sanity check on the input
 	if !bytes.HasPrefix(data, []byte("<map ")) {
@@ -220,43 +522,210 @@ func (d *Decoder) Decode(r io.Reader) (*wxx.Map_t, error) {
 		xmlMetaData.buffer = append(append(make([]byte, 0, end+2), data[:end]...), '/', '>')
 	}
 	// Now xmlMetaData.buffer holds a self-contained <map .../>.
-	if d.opts.diagnostics != nil {
-		d.opts.diagnostics.MapElement = bdup(xmlMetaData.buffer)
+	if diagnostics != nil {
+		diagnostics.MapElement = bdup(xmlMetaData.buffer)
 	}
 	// unmarshal that metadata
 	if err := xml.Unmarshal(xmlMetaData.buffer, &xmlMetaData); err != nil {
-		return nil, errors.Join(wxx.ErrInvalidXML, err)
+		return nil, errors.Join(wxx.ErrInvalidXML, wxx.ErrInvalidMapMetadata, err)
 	}
 
-	// read the version from our copy of the map attributes
-	err = xml.Unmarshal(xmlMetaData.buffer, &xmlMetaData)
-	if err != nil {
-		return nil, errors.Join(wxx.ErrInvalidMapMetadata, err)
+	// a traced decode counts the elements of each section with the limiter
+	var xr io.Reader = br
+	var limiter *elementLimiter
+	if d.opts.maxElements > 0 || d.opts.maxDepth > 0 || tracer != nil {
+		limiter = &elementLimiter{r: xr, maxElements: d.opts.maxElements, maxDepth: d.opts.maxDepth, record: tracer != nil}
+		xr = limiter
+	}
+	if diagnostics != nil {
+		xr = io.TeeReader(xr, &xmlData)
+	}
+	// lexical is the document as the file spells it, for WithPreserveFormatting
+	var lexical bytes.Buffer
+	if d.opts.preserve {
+		xr = io.TeeReader(xr, &lexical)
 	}
 
 	// use the metadata to call the correct decoder for the XML
+	var decode func(context.Context, io.Reader, *xmlstream.Lenient, int, xmlstream.Limits, *xmlstream.Trace) (*wxx.Map_t, error)
+	fallback := ""
 	switch xmlMetaData.Release {
 	case "2025":
-		// any W2025 build (release=2025) routes to the v1_06 decoder;
-		// version/schema no longer gate the dispatch.
-		if d.opts.diagnostics != nil {
-			d.opts.diagnostics.Schema = "h2025v1"
+		// a W2025 build is read by the codec that declares the schema the file
+		// states, or the nearest older one; see decoderFor
+		c, older, err := decoderFor(xmlMetaData.Schema)
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("map: release %q: version %q", xmlMetaData.Release, xmlMetaData.Version))
+		}
+		if older != "" && d.opts.strictSchema {
+			return nil, errors.Join(wxx.ErrNewerSchema, fmt.Errorf("map: schema %q: the newest codec for it reads schema %q", xmlMetaData.Schema, older))
+		}
+		if diagnostics != nil {
+			diagnostics.Schema = "h2025v1"
+			diagnostics.FallbackSchema = older
 		}
-		return v1_06.Decode(data)
+		decode, fallback = c.DecodeReader, older
 	case "":
 		// H2017 ("classic") files carry no release or schema attribute; they
 		// are identified solely by a "1.x" version (e.g. 1.73/1.74/1.77).
 		// Be conservative: only classic-shaped versions route here so we do
 		// not accidentally swallow unknown or future formats.
 		if strings.HasPrefix(xmlMetaData.Version, "1.") {
-			if d.opts.diagnostics != nil {
-				d.opts.diagnostics.Schema = "h2017v1"
+			if diagnostics != nil {
+				diagnostics.Schema = "h2017v1"
 			}
-			return v0_77.Decode(data)
+			decode = v0_77.Codec_t{}.DecodeReader
 		}
 	}
+	if decode == nil {
+		return nil, errors.Join(wxx.ErrUnsupportedMapMetadata, fmt.Errorf("map: release %q: version %q: schema %q", xmlMetaData.Release, xmlMetaData.Version, xmlMetaData.Schema))
+	}
+
+	var trace *xmlstream.Trace // nil traces nothing
+	if tracer != nil {
+		tracer.Trace(TraceEvent_t{Stage: StageDispatch, Detail: xmlMetaData.Version, Duration: time.Since(at)})
+		trace = &xmlstream.Trace{Section: func(name string, start, end int64, took time.Duration) {
+			first, n := limiter.sectionElements(start, end)
+			tracer.Trace(TraceEvent_t{Stage: StageSection, Section: name, BytesIn: end - first, Elements: n, Duration: took})
+		}}
+	}
+
+	var lax *xmlstream.Lenient // nil is a strict decode
+	if d.opts.lenient {
+		lax = &xmlstream.Lenient{}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	at = time.Now()
+	m, err := decode(ctx, xr, lax, d.opts.workers, xmlstream.Limits{Tiles: d.opts.maxTiles}, trace)
+	if lax != nil && diagnostics != nil {
+		diagnostics.Warnings = asDecodeWarnings(lax.Warnings)
+	}
+	if err != nil {
+		return m, asDecodeError(err, headerLines)
+	}
+
+	// The codec stops reading at </map>. Read the rest of the input so that the
+	// gzip trailer is checked, as it was when the input was read whole; whatever
+	// follows the root element is otherwise ignored.
+	if _, err := io.Copy(io.Discard, xr); err != nil {
+		return nil, err
+	}
+	if tracer != nil {
+		tracer.Trace(TraceEvent_t{Stage: StageDecode, Detail: xmlMetaData.Version, BytesIn: limiter.offset, Elements: limiter.elements, Duration: time.Since(at)})
+	}
 
-	return nil, errors.Join(wxx.ErrUnsupportedMapMetadata, fmt.Errorf("map: release %q: version %q: schema %q", xmlMetaData.Release, xmlMetaData.Version, xmlMetaData.Schema))
+	if fallback != "" {
+		rereadExtras(m, xmlMetaData.Schema)
+	}
+	unknown := unknownContent(m)
+	if diagnostics != nil {
+		diagnostics.UnknownContent = unknown
+	}
+	if d.opts.strictSchema && len(unknown) != 0 {
+		paths := make([]string, 0, len(unknown))
+		for _, u := range unknown {
+			paths = append(paths, u.Path)
+		}
+		return nil, errors.Join(wxx.ErrUnknownContent, fmt.Errorf("map: schema %q: %s", xmlMetaData.Schema, strings.Join(paths, ", ")))
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if d.opts.blackAsNoColor {
+		foldBlack(m)
+	}
+	if d.opts.preserve {
+		var warnings []DecodeWarning
+		if lax != nil {
+			warnings = asDecodeWarnings(lax.Warnings)
+		}
+		at = time.Now()
+		if err := preserveFormatting(ctx, m, lexical.Bytes(), warnings, d.opts.workers); err != nil {
+			return nil, err
+		}
+		if tracer != nil {
+			tracer.Trace(TraceEvent_t{Stage: StagePreserve, BytesIn: int64(lexical.Len()), Duration: time.Since(at)})
+		}
+	}
+	return m, nil
+}
+
+// preserveFormatting records on m how original, the XML it was decoded from,
+// spells what the encoder would spell differently. A map the encoder cannot
+// write again has no canonical form to compare with, and is left without. The
+// canonical form is written on workers goroutines, as the map was read.
+func preserveFormatting(ctx context.Context, m *wxx.Map_t, original []byte, warnings []DecodeWarning, workers int) error {
+	app := m.MetaData.Version.App.Raw
+	canonical, err := marshalXML(ctx, m, app, workers)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	} else if err != nil {
+		return nil
+	}
+	forms, err := recordFormatting(original, canonical)
+	if err != nil {
+		return errors.Join(wxx.ErrInvalidXML, err)
+	}
+	dropRepaired(forms, warnings)
+	m.Formatting = &wxx.Formatting_t{App: app, Forms: forms}
+	return nil
+}
+
+// traceStreams reports the stages of a decode that stream, those that were in
+// the reader stack, with the time each spent in its own reads: every read of a
+// stage reads the stage below it, and that time is taken out.
+func traceStreams(t Tracer, read, gunzipped, utf16 *traceReader) {
+	var in int64
+	var below time.Duration
+	for _, s := range []struct {
+		stage Stage_e
+		r     *traceReader
+	}{{StageRead, read}, {StageGunzip, gunzipped}, {StageUTF16, utf16}} {
+		if s.r == nil {
+			continue
+		}
+		if s.stage == StageRead {
+			in = s.r.n
+		}
+		t.Trace(TraceEvent_t{Stage: s.stage, BytesIn: in, BytesOut: s.r.n, Duration: max(s.r.took-below, 0)})
+		in, below = s.r.n, s.r.took
+	}
+}
+
+// mapElementReadAhead bounds the read-ahead used to find the opening <map ...>
+// tag, and so the longest opening tag Decode accepts. Worldographer writes
+// around a kilobyte of attributes there.
+const mapElementReadAhead = 64 * 1024
+
+// peek returns up to the next n bytes of br without consuming them. Running out
+// of input is not an error here -- the caller's prefix check reports a short
+// input as what it is -- but a read error from a lower stage is.
+func peek(br *bufio.Reader, n int) ([]byte, error) {
+	b, err := br.Peek(n)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return b, nil
+}
+
+// stageReader tags the read errors of one stage of the decode pipeline with
+// that stage's sentinel. io.EOF is passed through untouched, as readers must,
+// and so is a decode limit, which is no failure of the stage it passes through.
+type stageReader struct {
+	r        io.Reader
+	sentinel error
+}
+
+func (s *stageReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if err != nil && err != io.EOF && !isLimit(err) {
+		err = errors.Join(s.sentinel, err)
+	}
+	return n, err
 }
 
 // bdup returns a copy of the source
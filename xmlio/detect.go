@@ -0,0 +1,42 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import "bytes"
+
+// The character encodings the decoder recognizes, as DecoderDiagnostics.Encoding
+// reports them.
+const (
+	encodingUTF16BE = "UTF-16BE"
+	encodingUTF16LE = "UTF-16LE"
+	encodingUTF8    = "UTF-8"
+)
+
+var (
+	utf16BEBOM = []byte{0xfe, 0xff}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+)
+
+// sniffEncoding names the character encoding of XML that opens with head, and
+// reports whether head opens with a byte order mark.
+//
+// A BOM decides it when there is one. Without one, the first character of an
+// XML document is '<', and its UTF-16 spelling gives the byte order away:
+// 0x00 0x3C is big-endian, 0x3C 0x00 little-endian. Anything else is taken to be
+// UTF-8, which is also what an empty or very short input is reported as.
+func sniffEncoding(head []byte) (encoding string, hasBOM bool) {
+	switch {
+	case bytes.HasPrefix(head, utf16BEBOM):
+		return encodingUTF16BE, true
+	case bytes.HasPrefix(head, utf16LEBOM):
+		return encodingUTF16LE, true
+	case bytes.HasPrefix(head, utf8BOM):
+		return encodingUTF8, true
+	case bytes.HasPrefix(head, []byte{0x00, '<'}):
+		return encodingUTF16BE, false
+	case bytes.HasPrefix(head, []byte{'<', 0x00}):
+		return encodingUTF16LE, false
+	}
+	return encodingUTF8, false
+}
@@ -3,7 +3,6 @@
 package xmlio
 
 import (
-	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -87,11 +86,23 @@ func (d DroppedFeature_t) String() string {
 // encode unless the caller passes WithAllowLossy (too blunt: it makes the common,
 // fully-enumerated downgrade as loud as the one we genuinely cannot describe).
 //
-// Consequence, and it is intended: when a feature moves from stub to modeled
-// (#34 would do exactly this for <extraTerrain>), its hard error BECOMES a
-// diagnostic. Modeling it is what earns the encoder the right to be quiet about
-// it, because only then can it say what was lost.
+// Consequence, and it is intended: when a feature moves from stub to modeled,
+// its hard error BECOMES a diagnostic. Modeling it is what earns the encoder the
+// right to be quiet about it, because only then can it say what was lost. #34
+// did exactly this for <extraTerrain>, which was the last stub, so no content
+// reaches the error half today; ErrUnmodeledStubLoss stays as the contract for
+// the next element that arrives unmodeled.
+//
+// Extras (wxx.Extras_t) look like stubs -- content Map_t does not understand,
+// held verbatim -- but they are on the reported side of the line. A stub was a
+// whole element whose loss could only be described as "something"; an extra is
+// a named attribute with its value, or a named element, on a known element, so
+// the encoder can say exactly what it leaves out and where (see extrasLoss).
+// They are also the one loss that does not depend on the target being classic:
+// extras are written back only to the schema they were read against, so they
+// are reported for every other target, W2025 included.
 func downgradeLoss(m *wxx.Map_t, targetSchema string) ([]DroppedFeature_t, error) {
+	extras := extrasLoss(m, targetSchema)
 	if targetSchema != "" {
 		// Every non-classic supported schema is W2025 1.06, which expresses
 		// everything Map_t models: Map_t is the superset of the two supported
@@ -104,9 +115,13 @@ func downgradeLoss(m *wxx.Map_t, targetSchema string) ([]DroppedFeature_t, error
 		// schema that cannot express something Map_t models needs its own arm
 		// here; adding the codec alone would silently claim the target is
 		// lossless.
-		return nil, nil
+		return extras, nil
+	}
+	dropped, err := classicDowngradeLoss(m)
+	if err != nil {
+		return nil, err
 	}
-	return classicDowngradeLoss(m)
+	return append(dropped, extras...), nil
 }
 
 // classicDowngradeLoss reports what m carries that the implicit legacy (classic)
@@ -126,15 +141,14 @@ func downgradeLoss(m *wxx.Map_t, targetSchema string) ([]DroppedFeature_t, error
 //     map/@schema disappear. That is the classic codec writing the identity of the
 //     application version the caller asked for; a classic file states no release
 //     and no schema. Nothing is lost -- the file is being told what it now is.
-//   - CLASSIC CODEC GAPS. map/mapkey/@viewlevel is altered and
-//     map/informations/information and map/configuration/text-config/labelstyle
-//     are dropped -- but the classic FORMAT has room for all three (RelaxNG
-//     schema/utf-8-xml.rnc defines them, classic samples carry them). Our classic
-//     ENCODER does not write them yet, which internal/v0_77/COVERAGE.md documents and
-//     the classic round-trip harness proves by losing the same three on a
-//     classic -> classic trip. They cost the caller data, but they are this
-//     codec's gaps and would not be fixed by targeting differently. Reporting
-//     them as downgrade loss would blame the format for our encoder.
+//   - CLASSIC CODEC GAPS. Where the classic FORMAT has room for something our
+//     classic ENCODER does not write yet, internal/v0_77/COVERAGE.md documents
+//     it and the classic round-trip harness proves it by losing the same thing
+//     on a classic -> classic trip. Such a gap costs the caller data, but it is
+//     this codec's and would not be fixed by targeting differently. Reporting it
+//     as downgrade loss would blame the format for our encoder. The map key,
+//     the lore tree and the label styles used to be such gaps; the samples show
+//     none today.
 //
 // The residual -- what the W2025 -> classic diff shows that a classic -> classic
 // and a W2025 -> W2025 diff do not -- is the inventory below.
@@ -199,6 +213,14 @@ func classicDowngradeLoss(m *wxx.Map_t) ([]DroppedFeature_t, error) {
 	// Gated on non-empty: classic decode leaves both strings "".
 	dropped = append(dropped, shapeStyleLineLoss(m)...)
 
+	// map/configuration/text-config/labelstyle/@dropShadowColor, @dropShadowRadius
+	// and @dropShadowSpread -- the classic <labelstyle> element has 9 attributes
+	// and none of these (RelaxNG; v0_77.LabelStyle_t has no drop shadow).
+	//
+	// Gated on DropShadowColor, as the 1.06 encoder gates writing them: the trio
+	// is all-or-none, and the color is never "" when present.
+	dropped = append(dropped, labelStyleDropShadowLoss(m)...)
+
 	// map/blurTerrainBG -- a W2025 top-level element the classic format does not
 	// define at all (absent from the RelaxNG schema; schema/README.md
 	// independently flags it as a verified W2025 delta). Modeled as a pointer, so
@@ -214,62 +236,53 @@ func classicDowngradeLoss(m *wxx.Map_t) ([]DroppedFeature_t, error) {
 		})
 	}
 
-	// map/extraTerrain -- the ADR 0004 terrain-layers loss, and the one entry that
-	// ERRORS instead of reporting.
+	// map/extraTerrain -- the ADR 0004 terrain-layers loss.
 	//
 	// W2025 binds terrain to a named layer per hex
 	// (<extraTerrain><mapLayer name="..."><terrainAndLocation location="x,y"/>);
 	// classic binds mapLayer to features, labels and shapes but never to tiles,
 	// so all classic terrain sits on one hard-coded layer and a per-hex layer
-	// assignment collapses. That much is a genuine downgrade loss.
+	// assignment collapses.
 	//
-	// What makes it an error rather than a diagnostic is that Map_t models this
-	// element ONLY as opaque InnerXML (#34 tracks modeling it): the bytes
-	// round-trip 2025 -> 2025 intact, yet nothing in the model understands them.
-	// The encoder therefore cannot enumerate what dropping them costs -- it cannot
-	// say how many hexes, on which layers, with what terrain -- and under the loss
-	// contract it must refuse rather than discard content it cannot describe.
-	// When #34 models terrainAndLocation, this becomes a diagnostic like the rest.
-	if m.ExtraTerrain != nil && !isEmptyInnerXML(m.ExtraTerrain.InnerXML) {
-		inner := strings.TrimSpace(m.ExtraTerrain.InnerXML)
-		return nil, errors.Join(wxx.ErrUnmodeledStubLoss, fmt.Errorf(
-			"map/extraTerrain (Map_t.ExtraTerrain.InnerXML): the classic format defines no <extraTerrain> element, and this map carries %d bytes of it that the model holds only as an opaque stub, so the encoder cannot describe what dropping them would cost: %s",
-			len(m.ExtraTerrain.InnerXML), stubExcerpt(inner)))
+	// This entry used to be the contract's one hard error, because Map_t held
+	// the element only as opaque InnerXML and could not say what dropping it
+	// cost. It is modeled now (#34), so the loss is enumerated like the rest.
+	// Gated on having layers: the blank 2.06 fixture's empty container has
+	// nothing in it to lose.
+	if m.ExtraTerrain != nil && len(m.ExtraTerrain.MapLayers) != 0 {
+		dropped = append(dropped, extraTerrainLoss(m.ExtraTerrain))
 	}
 
 	return dropped, nil
 }
 
-// isEmptyInnerXML reports whether a verbatim InnerXML stub holds nothing.
-//
-// InnerXML is the raw bytes between the element's tags, so an empty container is
-// not "" but whatever the writer's pretty-printer put there: the blank 2.06
-// fixture's <extraTerrain> carries "\n", one newline. A "" test would call that
-// container populated and error on a file that loses nothing.
-//
-// TrimSpace is exactly the right test, and not an approximation. Whitespace
-// BETWEEN elements is insignificant formatting, and no XML content can hide in
-// it: an element needs a '<', an attribute lives inside a tag, and a text node
-// made only of whitespace has no content to lose. So an all-whitespace InnerXML
-// means the container has no children and no text -- dropping it costs nothing.
-// Conversely a single non-whitespace byte means at least one child or text node
-// the model never understood, which is precisely what the encoder must not
-// silently discard.
-func isEmptyInnerXML(inner string) bool {
-	return strings.TrimSpace(inner) == ""
-}
-
-// stubExcerpt renders opaque stub content for an error message, truncated so a
-// large stub cannot turn one error into a wall of XML. The content is echoed
-// because the caller cannot ask the model what was in it -- that is what makes it
-// a stub -- so the bytes are the only description available.
-func stubExcerpt(inner string) string {
-	const max = 160
-	inner = strings.Join(strings.Fields(inner), " ")
-	if len(inner) > max {
-		return strconv.Quote(inner[:max]) + " (truncated)"
+// extraTerrainLoss describes the terrain placements a downgrade drops, per
+// layer, with the terrain each layer holds.
+func extraTerrainLoss(e *wxx.ExtraTerrain_t) DroppedFeature_t {
+	var hexes int
+	var layers []string
+	for _, l := range e.MapLayers {
+		if l == nil {
+			continue
+		}
+		var names []string
+		seen := map[string]bool{}
+		for _, t := range l.Terrain {
+			if t == nil || seen[t.Terrain] {
+				continue
+			}
+			seen[t.Terrain] = true
+			names = append(names, t.Terrain)
+		}
+		hexes += len(l.Terrain)
+		layers = append(layers, fmt.Sprintf("%q=%d hex(es) of %s", l.Name, len(l.Terrain), strings.Join(names, ", ")))
+	}
+	return DroppedFeature_t{
+		Path:   "map/extraTerrain",
+		Field:  "Map_t.ExtraTerrain",
+		Detail: fmt.Sprintf("terrain on %d hex(es) across %d layer(s) is dropped: %s", hexes, len(layers), strings.Join(layers, "; ")),
+		Reason: "the classic format defines no <extraTerrain> element; classic binds map layers to features, labels and shapes but never to terrain, so all of its terrain sits on the tiles' one layer",
 	}
-	return strconv.Quote(inner)
 }
 
 // layersWithOpacity returns "name"=opacity for every map layer carrying a
@@ -285,6 +298,44 @@ func layersWithOpacity(m *wxx.Map_t) []string {
 	return names
 }
 
+// labelStyleDropShadowLoss returns the @dropShadow* entries for m's label
+// styles, one per attribute, naming the styles that carry them.
+func labelStyleDropShadowLoss(m *wxx.Map_t) []DroppedFeature_t {
+	if m.Configuration == nil || m.Configuration.TextConfig == nil {
+		return nil
+	}
+	var colors, radii, spreads []string
+	for _, s := range m.Configuration.TextConfig.LabelStyles {
+		if s == nil || s.DropShadowColor == "" {
+			continue
+		}
+		colors = append(colors, fmt.Sprintf("%q=%s", s.Name, s.DropShadowColor))
+		radii = append(radii, fmt.Sprintf("%q=%s", s.Name, floatDetail(s.DropShadowRadius)))
+		spreads = append(spreads, fmt.Sprintf("%q=%s", s.Name, floatDetail(s.DropShadowSpread)))
+	}
+	if len(colors) == 0 {
+		return nil
+	}
+	const reason = "the classic <labelstyle> element states no drop shadow (schema/utf-8-xml.rnc defines 9 labelstyle attributes, none of them a shadow)"
+	var out []DroppedFeature_t
+	for _, a := range []struct {
+		name, field string
+		values      []string
+	}{
+		{"dropShadowColor", "DropShadowColor", colors},
+		{"dropShadowRadius", "DropShadowRadius", radii},
+		{"dropShadowSpread", "DropShadowSpread", spreads},
+	} {
+		out = append(out, DroppedFeature_t{
+			Path:   "map/configuration/text-config/labelstyle/@" + a.name,
+			Field:  "Map_t.Configuration.TextConfig.LabelStyles[]." + a.field,
+			Detail: fmt.Sprintf("%s is dropped from %d label style(s): %s", a.name, len(a.values), strings.Join(a.values, ", ")),
+			Reason: reason,
+		})
+	}
+	return out
+}
+
 // shapeStyleLineLoss returns the @lineCap/@lineJoin entries for m's shape styles,
 // one per attribute, naming the styles that carry it.
 func shapeStyleLineLoss(m *wxx.Map_t) []DroppedFeature_t {
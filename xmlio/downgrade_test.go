@@ -4,7 +4,6 @@ package xmlio_test
 
 import (
 	"bytes"
-	"errors"
 	"os"
 	"sort"
 	"strings"
@@ -29,32 +28,17 @@ import (
 // executable rather than a claim in a comment.
 //
 // The controls are what keep the inventory honest. Control A is the reason
-// map/features/feature/label/@dropShadow* is NOT reported as a downgrade loss:
-// those attributes are dropped on a 2.06 -> 2.06 round trip too (Map_t.Label_t
-// models no drop shadow -- the trio lives on LabelStyle_t), so they are an h2025
-// codec gap that targeting classic merely also exhibits. Control B is the reason
-// mapkey/@viewlevel, <informations> and <labelstyle> are not reported: classic
-// loses those to itself.
+// map/features/feature/label/@dropShadow* is NOT in the inventory: Map_t.Label_t
+// models no drop shadow -- the trio lives on LabelStyle_t -- so it is kept as an
+// extra, written back on a 2.06 -> 2.06 round trip and reported by extrasLoss,
+// not by classicDowngradeLoss, when the target is classic. Control B is the reason
+// a classic codec gap is not reported: classic loses it to itself.
 
 // classicTarget is the classic release every downgrade test targets. Any of
 // 1.73/1.74/1.77 would do -- they share the one implicit legacy schema, and the
 // schema is what determines expressiveness -- so this names the newest.
 const classicTarget = "1.77"
 
-// countingWriter records whether Encode wrote anything. The stub error must fire
-// BEFORE any output reaches the caller: an error alongside a partial file still
-// hands the user a file, so `err != nil` alone would not prove the refusal.
-type countingWriter struct {
-	n     int
-	calls int
-}
-
-func (w *countingWriter) Write(p []byte) (int, error) {
-	w.calls++
-	w.n += len(p)
-	return len(p), nil
-}
-
 // decodeW2025 decodes a tracked .wxx fixture through the public pipeline.
 func decodeW2025(t *testing.T, path string) *wxx.Map_t {
 	t.Helper()
@@ -70,89 +54,75 @@ func decodeW2025(t *testing.T, path string) *wxx.Map_t {
 	return m
 }
 
-// TestClassicDowngradeStubError is the loss contract's error half: a W2025 map
-// whose <extraTerrain> carries real content cannot be written as classic,
-// because Map_t holds that content only as an opaque stub and the encoder cannot
-// say what dropping it would cost.
+// TestClassicDowngradeExtraTerrain covers the entry that used to be the loss
+// contract's hard error. <extraTerrain> is modeled now (#34), so a W2025 map
+// whose <extraTerrain> places terrain on a layer downgrades to classic and
+// reports what it drops, in the map's own values.
 //
-// The two tracked 2.06 fixtures differ in exactly the way the contract turns on,
-// which is why both are here: `layers` carries 183 bytes of <extraTerrain>
-// children, `blank` carries "\n". If only the erroring fixture were tested, an
-// encoder that refused EVERY W2025 downgrade would pass.
-func TestClassicDowngradeStubError(t *testing.T) {
+// The two tracked 2.06 fixtures differ in exactly the way the entry turns on,
+// which is why both are here: `layers` places one hex on "Terrain Layer",
+// `blank` carries an empty container. If only the reporting fixture were tested,
+// an encoder that reported the loss for EVERY W2025 downgrade would pass.
+func TestClassicDowngradeExtraTerrain(t *testing.T) {
 	for _, tc := range []struct {
-		name      string
-		fixture   string
-		wantErr   bool
-		wantInner string // the InnerXML the fixture must carry for this to be a real test
+		name       string
+		fixture    string
+		wantLayers int
+		wantDetail []string // substrings of the reported Detail; nil for no entry
 	}{
 		{
-			name:      "layers: populated <extraTerrain> is an unmodeled stub -> hard error",
-			fixture:   sample2025_206Layers,
-			wantErr:   true,
-			wantInner: "terrainAndLocation",
+			name:       "layers: terrain on a layer is reported dropped",
+			fixture:    sample2025_206Layers,
+			wantLayers: 1,
+			wantDetail: []string{"1 hex(es) across 1 layer(s)", `"Terrain Layer"`, "Classic/Flat Dead Soil"},
 		},
 		{
-			name:    "blank: empty <extraTerrain> container loses nothing -> no stub error",
-			fixture: sample2025_206,
-			wantErr: false,
+			name:       "blank: empty <extraTerrain> container loses nothing",
+			fixture:    sample2025_206,
+			wantLayers: 0,
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			m := decodeW2025(t, tc.fixture)
 
 			// Guard against a vacuous pass. If the fixture ever stops carrying an
-			// <extraTerrain> in the shape this case is about, the assertion below
-			// proves nothing -- an absent element and an empty one both fail to
-			// error, for different reasons.
+			// <extraTerrain> in the shape this case is about, the assertions below
+			// prove nothing.
 			if m.ExtraTerrain == nil {
-				t.Fatalf("%s: ExtraTerrain = nil; this fixture no longer exercises the stub gate", tc.fixture)
+				t.Fatalf("%s: ExtraTerrain = nil; this fixture no longer exercises the entry", tc.fixture)
 			}
-			if tc.wantErr {
-				if !strings.Contains(m.ExtraTerrain.InnerXML, tc.wantInner) {
-					t.Fatalf("%s: ExtraTerrain.InnerXML = %q, want it to contain %q; without real stub content the error below would not be under test",
-						tc.fixture, m.ExtraTerrain.InnerXML, tc.wantInner)
-				}
-			} else {
-				if strings.TrimSpace(m.ExtraTerrain.InnerXML) != "" {
-					t.Fatalf("%s: ExtraTerrain.InnerXML = %q, want whitespace only; this case is about an EMPTY container",
-						tc.fixture, m.ExtraTerrain.InnerXML)
-				}
-				if m.ExtraTerrain.InnerXML == "" {
-					t.Fatalf("%s: ExtraTerrain.InnerXML is \"\"; the emptiness test is only meaningful while the fixture carries whitespace (it carried %q)",
-						tc.fixture, "\n")
-				}
+			if got := len(m.ExtraTerrain.MapLayers); got != tc.wantLayers {
+				t.Fatalf("%s: len(ExtraTerrain.MapLayers) = %d, want %d", tc.fixture, got, tc.wantLayers)
 			}
 
-			var w countingWriter
-			err := xmlio.NewEncoder(classicTarget).Encode(&w, m)
+			var d xmlio.EncoderDiagnostics
+			var buf bytes.Buffer
+			if err := xmlio.NewEncoder(classicTarget, xmlio.WithEncoderDiagnostics(&d)).Encode(&buf, m); err != nil {
+				t.Fatalf("%s -> classic %s: %v", tc.fixture, classicTarget, err)
+			}
+			if bytes.Contains(buf.Bytes(), []byte("extraTerrain")) {
+				t.Errorf("%s -> classic %s: output carries <extraTerrain>, which classic does not define", tc.fixture, classicTarget)
+			}
 
-			if !tc.wantErr {
-				if err != nil {
-					t.Fatalf("%s -> classic %s: unexpected error: %v", tc.fixture, classicTarget, err)
+			var entry *xmlio.DroppedFeature_t
+			for i := range d.Dropped {
+				if d.Dropped[i].Path == "map/extraTerrain" {
+					entry = &d.Dropped[i]
 				}
-				if w.n == 0 {
-					t.Errorf("%s -> classic %s: wrote 0 bytes, want a file", tc.fixture, classicTarget)
+			}
+			if tc.wantDetail == nil {
+				if entry != nil {
+					t.Errorf("%s -> classic %s: reported %s, but the container is empty", tc.fixture, classicTarget, entry)
 				}
 				return
 			}
-
-			if err == nil {
-				t.Fatalf("%s -> classic %s: want an error (populated <extraTerrain> is an unmodeled stub), got nil", tc.fixture, classicTarget)
+			if entry == nil {
+				t.Fatalf("%s -> classic %s: map/extraTerrain not reported dropped", tc.fixture, classicTarget)
 			}
-			if !errors.Is(err, wxx.ErrUnmodeledStubLoss) {
-				t.Errorf("%s -> classic %s: error = %v, want it to wrap ErrUnmodeledStubLoss", tc.fixture, classicTarget, err)
-			}
-			// The refusal must precede the write. A file handed over alongside an
-			// error is still a file the caller can save.
-			if w.calls != 0 || w.n != 0 {
-				t.Errorf("%s -> classic %s: wrote %d bytes in %d call(s), want 0: the encode must refuse before any output reaches the writer",
-					tc.fixture, classicTarget, w.n, w.calls)
-			}
-			// The error must describe the loss, not merely announce it: the caller
-			// cannot ask the model what was in a stub.
-			if got := err.Error(); !strings.Contains(got, "extraTerrain") || !strings.Contains(got, "terrainAndLocation") {
-				t.Errorf("%s -> classic %s: error = %q, want it to name the element and echo the stub content", tc.fixture, classicTarget, got)
+			for _, want := range tc.wantDetail {
+				if !strings.Contains(entry.Detail, want) {
+					t.Errorf("Detail = %q, want it to contain %q", entry.Detail, want)
+				}
 			}
 		})
 	}
@@ -161,8 +131,8 @@ func TestClassicDowngradeStubError(t *testing.T) {
 // TestClassicDowngradeDiagnostics is the loss contract's reporting half: a
 // downgrade that drops only MODELED features succeeds and inventories them.
 //
-// It runs on the blank fixture because the layers fixture cannot be downgraded at
-// all (its stub errors), which is itself the contract working.
+// It runs on the blank fixture; the layers fixture's extra entry has a test of
+// its own, TestClassicDowngradeExtraTerrain.
 func TestClassicDowngradeDiagnostics(t *testing.T) {
 	m := decodeW2025(t, sample2025_206)
 
@@ -180,6 +150,9 @@ func TestClassicDowngradeDiagnostics(t *testing.T) {
 		"map/blurTerrainBG",
 		"map/configuration/shape-config/shapestyle/@lineCap",
 		"map/configuration/shape-config/shapestyle/@lineJoin",
+		"map/configuration/text-config/labelstyle/@dropShadowColor",
+		"map/configuration/text-config/labelstyle/@dropShadowRadius",
+		"map/configuration/text-config/labelstyle/@dropShadowSpread",
 		"map/maplayer/@opacity",
 	}
 	assertDroppedPaths(t, sample2025_206+" -> classic", want, d.Dropped)
@@ -286,10 +259,10 @@ func TestNoLossOnSameReleaseTargets(t *testing.T) {
 		{"classic 1.77 merge-01", "../testdata/2017-1.77-1.0-merge-01.wxx"},
 		{"classic 1.77 merge-02", "../testdata/2017-1.77-1.0-merge-02.wxx"},
 		{"w2025 2.06 blank", sample2025_206},
-		// The layers fixture carries a populated <extraTerrain> that hard-errors
-		// on a classic target. Targeted at its OWN release it must sail through:
-		// the stub error is a property of the target's expressiveness, not of the
-		// content being unusual.
+		// The layers fixture carries a populated <extraTerrain> that a classic
+		// target reports dropped. Targeted at its OWN release it must report
+		// nothing: the loss is a property of the target's expressiveness, not of
+		// the content being unusual.
 		{"w2025 2.06 layers", sample2025_206Layers},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -382,16 +355,11 @@ func TestClassicDowngradeLossInventory(t *testing.T) {
 	// was given, the codec-gap entries by classicRoundTripExpect (classic loses
 	// them to ITSELF).
 	notDowngrade := map[string]string{
-		"attr-altered\tmap\tversion":                                            "target identity: the file states the release the caller asked for",
-		"attr-dropped\tmap\trelease":                                            "target identity: a classic file states no @release",
-		"attr-dropped\tmap\tschema":                                             "target identity: a classic file states no @schema",
-		"attr-altered\tmap/mapkey\tviewlevel":                                   "classic codec gap: encodeMapKey writes a constant block (classic->classic loses it too)",
-		"element-dropped\tmap/configuration/text-config/labelstyle":             "classic codec gap: encodeLabelStyle is a no-op (classic->classic loses it too)",
-		"element-dropped\tmap/informations/information":                         "classic codec gap: encodeInformations emits an empty wrapper (classic->classic loses it too)",
-		"element-dropped\tmap/informations/information/information":             "classic codec gap: as above",
-		"element-dropped\tmap/informations/information/information/information": "classic codec gap: as above",
-		// The container is dropped, but this fixture's is empty ("\n"), so
-		// nothing is lost. The populated case never reaches a diff: it errors.
+		"attr-altered\tmap\tversion": "target identity: the file states the release the caller asked for",
+		"attr-dropped\tmap\trelease": "target identity: a classic file states no @release",
+		"attr-dropped\tmap\tschema":  "target identity: a classic file states no @schema",
+		// The container is dropped, but this fixture's is empty, so nothing is
+		// lost. The populated case is TestClassicDowngradeExtraTerrain's.
 		"element-dropped\tmap/extraTerrain": "empty container: no children, no text, nothing to lose",
 	}
 
@@ -405,10 +373,13 @@ func TestClassicDowngradeLossInventory(t *testing.T) {
 
 	// Map each surviving harness entry to the inventory Path it evidences.
 	harnessToPath := map[string]string{
-		"attr-dropped\tmap/maplayer\topacity":                               "map/maplayer/@opacity",
-		"attr-dropped\tmap/configuration/shape-config/shapestyle\tlineCap":  "map/configuration/shape-config/shapestyle/@lineCap",
-		"attr-dropped\tmap/configuration/shape-config/shapestyle\tlineJoin": "map/configuration/shape-config/shapestyle/@lineJoin",
-		"element-dropped\tmap/blurTerrainBG":                                "map/blurTerrainBG",
+		"attr-dropped\tmap/maplayer\topacity":                                      "map/maplayer/@opacity",
+		"attr-dropped\tmap/configuration/shape-config/shapestyle\tlineCap":         "map/configuration/shape-config/shapestyle/@lineCap",
+		"attr-dropped\tmap/configuration/shape-config/shapestyle\tlineJoin":        "map/configuration/shape-config/shapestyle/@lineJoin",
+		"attr-dropped\tmap/configuration/text-config/labelstyle\tdropShadowColor":  "map/configuration/text-config/labelstyle/@dropShadowColor",
+		"attr-dropped\tmap/configuration/text-config/labelstyle\tdropShadowRadius": "map/configuration/text-config/labelstyle/@dropShadowRadius",
+		"attr-dropped\tmap/configuration/text-config/labelstyle\tdropShadowSpread": "map/configuration/text-config/labelstyle/@dropShadowSpread",
+		"element-dropped\tmap/blurTerrainBG":                                       "map/blurTerrainBG",
 	}
 
 	evidenced := map[string]bool{}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// encodeFixtures are the maps the encode benchmarks measure: a 2017 map, written
+// back by the classic codec, and the populated W2025 map, written by the 1.06 one.
+var encodeFixtures = []struct {
+	name string
+	path string
+	app  string
+}{
+	{"2017", classicInputDir + "2017-1.77-1.0-merge-02.wxx", classicTarget},
+	{"2025", populatedFixture, "2.06"},
+}
+
+// loadMap decodes the map at path through the public decoder.
+func loadMap(tb testing.TB, path string) *wxx.Map_t {
+	tb.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("read %s: %v", path, err)
+	}
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(bytes.NewReader(raw))
+	if err != nil {
+		tb.Fatalf("decode %s: %v", path, err)
+	}
+	return m
+}
+
+// encodeAllocs returns the average number of allocations an encode of m to app
+// makes, as UTF-8 XML so the allocations are the codec's own.
+func encodeAllocs(t *testing.T, m *wxx.Map_t, app string) float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	e := xmlio.NewEncoder(app, xmlio.WithGzipOutput(false), xmlio.WithUTF16BEOutput(false))
+	return testing.AllocsPerRun(5, func() {
+		buf.Reset()
+		if err := e.Encode(&buf, m); err != nil {
+			t.Fatalf("encode to %s: %v", app, err)
+		}
+	})
+}
+
+// TestEncodeTileAllocs asserts that encoding tiles allocates nothing per tile:
+// a map with a hundred times the tiles of another takes no more than a handful
+// of extra allocations, for growing buffers, to encode. Writing the tile fields
+// with fmt.Sprintf took about ten allocations per tile.
+func TestEncodeTileAllocs(t *testing.T) {
+	small, large := syntheticMap(t, 10, 10), syntheticMap(t, 100, 100)
+	for _, app := range []string{"2.06", classicTarget} {
+		base, got := encodeAllocs(t, small, app), encodeAllocs(t, large, app)
+		if extra := got - base; extra > 50 {
+			t.Errorf("encode to %s: %.0f tiles took %.0f more allocations than %.0f tiles, want them to take none per tile",
+				app, float64(large.Tiles.TilesWide*large.Tiles.TilesHigh), extra, float64(small.Tiles.TilesWide*small.Tiles.TilesHigh))
+		}
+	}
+}
+
+// TestEncodeFixtureAllocs guards the allocation count of encoding each of the
+// benchmark fixtures. The limits are about three times what an encode takes
+// today (28 and 67), and well under what it took with fmt.Sprintf per field
+// (1163 and 2743), so they trip on a regression, not on noise.
+func TestEncodeFixtureAllocs(t *testing.T) {
+	limits := map[string]float64{"2017": 100, "2025": 200}
+	for _, f := range encodeFixtures {
+		if got := encodeAllocs(t, loadMap(t, f.path), f.app); got > limits[f.name] {
+			t.Errorf("encode %s fixture: %.0f allocations, want at most %.0f", f.name, got, limits[f.name])
+		}
+	}
+}
+
+// BenchmarkEncodeFixtures encodes each fixture, reporting allocations:
+//
+//	go test ./xmlio -run '^$' -bench EncodeFixtures -benchmem
+func BenchmarkEncodeFixtures(b *testing.B) {
+	for _, f := range encodeFixtures {
+		m := loadMap(b, f.path)
+		b.Run(f.name, func(b *testing.B) {
+			var buf bytes.Buffer
+			e := xmlio.NewEncoder(f.app, xmlio.WithGzipOutput(false), xmlio.WithUTF16BEOutput(false))
+			b.ReportAllocs()
+			for b.Loop() {
+				buf.Reset()
+				if err := e.Encode(&buf, m); err != nil {
+					b.Fatalf("encode to %s: %v", f.app, err)
+				}
+			}
+			b.SetBytes(int64(buf.Len()))
+		})
+	}
+}
@@ -5,11 +5,14 @@ package xmlio
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
 )
@@ -31,7 +34,10 @@ type encoderOpts struct {
 	compressedOutput bool
 	utf16BeOutput    bool
 	xmlHeader        bool
+	workers          int
 	diagnostics      *EncoderDiagnostics
+	backups          int // numbered copies WriteFile keeps, see WithBackups
+	tracer           Tracer
 }
 
 type EncoderDiagnostics struct {
@@ -100,6 +106,7 @@ func NewEncoder(app string, opts ...EncoderOption) *Encoder {
 			compressedOutput: true,
 			utf16BeOutput:    true,
 			xmlHeader:        true,
+			workers:          1,
 			diagnostics:      nil,
 		},
 	}
@@ -134,7 +141,29 @@ func WithXMLHeader(enabled bool) EncoderOption {
 	}
 }
 
+// WithEncoderConcurrency writes <tilerow> elements on up to n goroutines; n < 1
+// means one per CPU, runtime.GOMAXPROCS(0). The default is 1.
+//
+// Each row is written to a buffer of its own and the buffers are joined in
+// order, so the file is byte for byte the file a sequential encode writes. It is
+// the Encoder's half of WithDecoderConcurrency, and named apart from it for the
+// reason given there.
+func WithEncoderConcurrency(n int) EncoderOption {
+	return func(o *encoderOpts) {
+		o.workers = xmlstream.Workers(n)
+	}
+}
+
+// Encode writes m to w as the Encoder's application version. w is written once,
+// with the whole file, and only when the encode succeeds.
 func (e *Encoder) Encode(w io.Writer, m *wxx.Map_t) error {
+	return e.EncodeContext(context.Background(), w, m)
+}
+
+// EncodeContext is Encode, stopping when ctx is done. ctx is checked between the
+// stages of the pipeline and before each <tilerow>, and an encode it stops
+// returns ctx.Err() and writes nothing to w.
+func (e *Encoder) EncodeContext(ctx context.Context, w io.Writer, m *wxx.Map_t) error {
 	var err error
 
 	// Resolve the codec before anything is written. An application version no
@@ -164,12 +193,25 @@ func (e *Encoder) Encode(w io.Writer, m *wxx.Map_t) error {
 	}
 
 	// marshal the Map_t to UTF‑8 XML. The target is named by its verbatim
-	// application version, the only way to name one: MarshalXML resolves it back
+	// application version, the only way to name one: marshalXML resolves it back
 	// to this same codec.
-	data, err := MarshalXML(m, e.app)
+	tracer := e.opts.tracer
+	at := time.Now()
+	data, err := marshalXML(ctx, m, e.app, e.opts.workers)
 	if err != nil {
 		return err
 	}
+	// a map decoded with WithPreserveFormatting is written as its file spelled it
+	if f := m.Formatting; f != nil && f.App == e.app {
+		if data, err = applyFormatting(data, f.Forms); err != nil {
+			return errors.Join(wxx.ErrInvalidXML, err)
+		} else if err = checkOutput(data); err != nil {
+			return err
+		}
+	}
+	if tracer != nil {
+		tracer.Trace(TraceEvent_t{Stage: StageEncode, Detail: e.app, BytesOut: int64(len(data)), Elements: countElements(data), Duration: time.Since(at)})
+	}
 	if e.opts.diagnostics != nil {
 		e.opts.diagnostics.Utf8Encoded = bdup(data)
 	}
@@ -192,21 +234,34 @@ func (e *Encoder) Encode(w io.Writer, m *wxx.Map_t) error {
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if e.opts.utf16BeOutput {
 		// encode as UTF-16/BE for Worldographer
 		utf16Encoding := unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+		at, in := time.Now(), len(data)
 		data, err = io.ReadAll(transform.NewReader(bytes.NewReader(data), utf16Encoding.NewEncoder()))
 		if err != nil {
 			return errors.Join(wxx.ErrInvalidUTF8, err)
 		}
+		if tracer != nil {
+			tracer.Trace(TraceEvent_t{Stage: StageUTF16, BytesIn: int64(in), BytesOut: int64(len(data)), Duration: time.Since(at)})
+		}
 		if e.opts.diagnostics != nil {
 			e.opts.diagnostics.Utf16Encoded = bdup(data)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if e.opts.compressedOutput {
 		// compress the encoded data, returning any errors
 		var buf bytes.Buffer
+		at, in := time.Now(), len(data)
 		gz := gzip.NewWriter(&buf)
 		if _, err := gz.Write(data); err != nil {
 			return err
@@ -214,15 +269,25 @@ func (e *Encoder) Encode(w io.Writer, m *wxx.Map_t) error {
 			return err
 		}
 		data = bdup(buf.Bytes())
+		if tracer != nil {
+			tracer.Trace(TraceEvent_t{Stage: StageGzip, BytesIn: int64(in), BytesOut: int64(len(data)), Duration: time.Since(at)})
+		}
 		if e.opts.diagnostics != nil {
 			e.opts.diagnostics.Utf16Encoded = bdup(data)
 		}
 	}
 
-	_, err = w.Write(data)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	at = time.Now()
+	n, err := w.Write(data)
 	if err != nil {
 		return err
 	}
+	if tracer != nil {
+		tracer.Trace(TraceEvent_t{Stage: StageWrite, BytesIn: int64(len(data)), BytesOut: int64(n), Duration: time.Since(at)})
+	}
 
 	return nil
 }
@@ -289,6 +354,12 @@ func xmlHeaderFor(xmlVersion string) ([]byte, error) {
 //
 // Returns an error for an unsupported target or if the conversion fails.
 func MarshalXML(m *wxx.Map_t, app string) ([]byte, error) {
+	return marshalXML(context.Background(), m, app, 1)
+}
+
+// marshalXML is MarshalXML, writing <tilerow> elements on up to workers
+// goroutines and stopping when ctx is done.
+func marshalXML(ctx context.Context, m *wxx.Map_t, app string, workers int) ([]byte, error) {
 	c, err := codecFor(app)
 	if err != nil {
 		return nil, err
@@ -296,5 +367,23 @@ func MarshalXML(m *wxx.Map_t, app string) ([]byte, error) {
 	if _, err := downgradeLoss(m, c.AcceptedApps().Schema); err != nil {
 		return nil, err
 	}
-	return c.Encode(m, app)
+	data, err := c.Encode(ctx, m, app, workers)
+	if err != nil {
+		return nil, err
+	} else if err = checkOutput(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// checkOutput returns an error if data, the XML an encoder wrote, is not
+// well-formed. The codecs escape every string they write, so what is left to
+// catch is a string holding a character XML cannot carry at all, such as a
+// control character or an unpaired surrogate. Either way nothing is written: a
+// file Worldographer cannot open is worse than no file.
+func checkOutput(data []byte) error {
+	if err := xmlstream.WellFormed(data); err != nil {
+		return errors.Join(wxx.ErrMalformedOutput, err)
+	}
+	return nil
 }
@@ -0,0 +1,265 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+)
+
+// extraSite is one element of a map that can carry extras: where it sits on
+// disk, the Map_t field that holds its extras, and the extras themselves.
+type extraSite struct {
+	path  string // "map/features/feature"
+	field string // "Map_t.Features[].Extras"
+	x     *wxx.Extras_t
+}
+
+// extraSites lists every element of m that carries extras, in document order.
+// Elements without extras are left out, so a map read from a file the codecs
+// fully understand gives an empty list.
+func extraSites(m *wxx.Map_t) []extraSite {
+	var sites []extraSite
+	add := func(path, field string, x *wxx.Extras_t) {
+		if x != nil {
+			sites = append(sites, extraSite{path: path, field: field, x: x})
+		}
+	}
+	label := func(path, field string, l *wxx.Label_t) {
+		if l == nil {
+			return
+		}
+		add(path, field+".Extras", l.Extras)
+		if l.Location != nil {
+			add(path+"/location", field+".Location.Extras", l.Location.Extras)
+		}
+	}
+
+	add("map", "Map_t.Extras", m.Extras)
+	if m.GridAndNumbering != nil {
+		add("map/gridandnumbering", "Map_t.GridAndNumbering.Extras", m.GridAndNumbering.Extras)
+	}
+	if m.BlurTerrainBG != nil {
+		add("map/blurTerrainBG", "Map_t.BlurTerrainBG.Extras", m.BlurTerrainBG.Extras)
+	}
+	for _, l := range m.MapLayers {
+		if l != nil {
+			add("map/maplayer", "Map_t.MapLayers[].Extras", l.Extras)
+		}
+	}
+	if m.Tiles != nil {
+		add("map/tiles", "Map_t.Tiles.Extras", m.Tiles.Extras)
+	}
+	if m.MapKey != nil {
+		add("map/mapkey", "Map_t.MapKey.Extras", m.MapKey.Extras)
+	}
+	for _, f := range m.Features {
+		if f == nil {
+			continue
+		}
+		add("map/features/feature", "Map_t.Features[].Extras", f.Extras)
+		if f.Location != nil {
+			add("map/features/feature/location", "Map_t.Features[].Location.Extras", f.Location.Extras)
+		}
+		label("map/features/feature/label", "Map_t.Features[].Label", f.Label)
+	}
+	if m.ExtraTerrain != nil {
+		for _, l := range m.ExtraTerrain.MapLayers {
+			if l == nil {
+				continue
+			}
+			add("map/extraTerrain/mapLayer", "Map_t.ExtraTerrain.MapLayers[].Extras", l.Extras)
+			for _, t := range l.Terrain {
+				if t != nil {
+					add("map/extraTerrain/mapLayer/terrainAndLocation", "Map_t.ExtraTerrain.MapLayers[].Terrain[].Extras", t.Extras)
+				}
+			}
+		}
+	}
+	for _, l := range m.Labels {
+		label("map/labels/label", "Map_t.Labels[]", l)
+	}
+	for _, s := range m.Shapes {
+		if s == nil {
+			continue
+		}
+		add("map/shapes/shape", "Map_t.Shapes[].Extras", s.Extras)
+		for _, p := range s.Points {
+			if p != nil {
+				add("map/shapes/shape/p", "Map_t.Shapes[].Points[].Extras", p.Extras)
+			}
+		}
+	}
+	for _, n := range m.Notes {
+		if n != nil {
+			add("map/notes/note", "Map_t.Notes[].Extras", n.Extras)
+		}
+	}
+	if m.Informations != nil {
+		// details nest as deep as the file nests them; depth counts the levels
+		// below the entry, and the names are only built for extras to report
+		var details func(depth int, ds []*wxx.InformationDetail_t)
+		details = func(depth int, ds []*wxx.InformationDetail_t) {
+			for _, d := range ds {
+				if d == nil {
+					continue
+				}
+				if d.Extras != nil {
+					add("map/informations/information"+strings.Repeat("/information", depth),
+						"Map_t.Informations.Informations[]"+strings.Repeat(".Details[]", depth)+".Extras", d.Extras)
+				}
+				details(depth+1, d.Details)
+			}
+		}
+		for _, i := range m.Informations.Informations {
+			if i == nil {
+				continue
+			}
+			add("map/informations/information", "Map_t.Informations.Informations[].Extras", i.Extras)
+			details(1, i.Details)
+		}
+	}
+	if c := m.Configuration; c != nil {
+		if c.TextConfig != nil {
+			for _, s := range c.TextConfig.LabelStyles {
+				if s != nil {
+					add("map/configuration/text-config/labelstyle", "Map_t.Configuration.TextConfig.LabelStyles[].Extras", s.Extras)
+				}
+			}
+		}
+		if c.ShapeConfig != nil {
+			for _, s := range c.ShapeConfig.ShapeStyles {
+				if s != nil {
+					add("map/configuration/shape-config/shapestyle", "Map_t.Configuration.ShapeConfig.ShapeStyles[].Extras", s.Extras)
+				}
+			}
+		}
+	}
+	return sites
+}
+
+// extraGroup is every occurrence of one extra, an attribute or element name, at
+// one path.
+type extraGroup struct {
+	path    string // "map/features/feature/@tint" or "map/features/feature/aura"
+	field   string
+	element string   // the element the extra was found on
+	extra   string   // "@tint" or "<aura>"
+	schema  string   // the schema it was read against
+	count   int      // the elements it was found on
+	values  []string // distinct attribute values, quoted, in order of appearance
+}
+
+// detail describes g for a report, finishing "<extra> is <verb> <count>
+// <element> element(s)" with the values the attribute had.
+func (g *extraGroup) detail(verb string) string {
+	detail := fmt.Sprintf("%s is %s %d <%s> element(s)", g.extra, verb, g.count, g.element)
+	if len(g.values) != 0 {
+		detail += ", with value(s) " + strings.Join(g.values, ", ")
+	}
+	return detail
+}
+
+// groupExtras collects the extras of sites into one group per attribute or
+// element name at each path, in document order, so a new attribute on every
+// feature is one group that counts them rather than one per feature.
+func groupExtras(sites []extraSite) []*extraGroup {
+	var groups []*extraGroup
+	byPath := map[string]*extraGroup{}
+	record := func(site extraSite, extra, value string) {
+		path := site.path + "/" + strings.Trim(extra, "<>")
+		g, ok := byPath[path]
+		if !ok {
+			g = &extraGroup{path: path, field: site.field, element: site.path[strings.LastIndex(site.path, "/")+1:], extra: extra, schema: site.x.Schema}
+			byPath[path] = g
+			groups = append(groups, g)
+		}
+		g.count++
+		if value != "" && !slices.Contains(g.values, value) {
+			g.values = append(g.values, value)
+		}
+	}
+	for _, s := range sites {
+		for _, a := range s.x.Attrs {
+			record(s, "@"+a.Name, fmt.Sprintf("%q", a.Value))
+		}
+		for _, e := range s.x.Elements {
+			record(s, "<"+extraElementName(e)+">", "")
+		}
+	}
+	return groups
+}
+
+// extrasLoss reports the extras in m that an encoder for targetSchema leaves
+// out: every one read against a different schema, one entry per group.
+//
+// Extras are not a codec gap the way a field the encoder forgets is. The target
+// format may well have room for an attribute of the same name, but nothing says
+// it means the same thing there, and the encoder that would write it has never
+// heard of it; the only honest place for it is the schema it was read from. So
+// they are reported for upgrades as well as downgrades, which is the one place
+// a W2025 target reports anything.
+func extrasLoss(m *wxx.Map_t, targetSchema string) []DroppedFeature_t {
+	var sites []extraSite
+	for _, s := range extraSites(m) {
+		if s.x.Schema != targetSchema {
+			sites = append(sites, s)
+		}
+	}
+	var dropped []DroppedFeature_t
+	for _, g := range groupExtras(sites) {
+		dropped = append(dropped, DroppedFeature_t{
+			Path:   g.path,
+			Field:  g.field,
+			Detail: g.detail("dropped from"),
+			Reason: fmt.Sprintf("%s is not in %s as this module knows it and was kept as read; extras are written back only to the schema they were read against, and the target is %s", g.extra, schemaName(g.schema), schemaName(targetSchema)),
+		})
+	}
+	return dropped
+}
+
+// unknownContent reports the extras in m, which are the attributes and elements
+// of the file the codec that read it did not consume.
+func unknownContent(m *wxx.Map_t) []UnknownContent_t {
+	var unknown []UnknownContent_t
+	for _, g := range groupExtras(extraSites(m)) {
+		unknown = append(unknown, UnknownContent_t{
+			Path:   g.path,
+			Field:  g.field,
+			Count:  g.count,
+			Detail: g.detail("on"),
+		})
+	}
+	return unknown
+}
+
+// rereadExtras marks every extra in m as read against schema. A file read by an
+// older codec than its schema's has its extras stamped with the codec's schema,
+// which is not where they came from; an encoder for that schema would write them
+// into a file whose format does not define them.
+func rereadExtras(m *wxx.Map_t, schema string) {
+	for _, s := range extraSites(m) {
+		s.x.Schema = schema
+	}
+}
+
+// extraElementName returns the name of the element whose XML text is e.
+func extraElementName(e string) string {
+	e = strings.TrimPrefix(e, "<")
+	if i := strings.IndexAny(e, " \t\r\n/>"); i != -1 {
+		return e[:i]
+	}
+	return e
+}
+
+// schemaName names a schema for a Reason: "schema 1.06", or "the classic
+// schema" for "", which classic files state by stating none.
+func schemaName(schema string) string {
+	if schema == "" {
+		return "the classic schema"
+	}
+	return "schema " + schema
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// injectExtras adds to doc an attribute and a child element on <map>, <tiles>,
+// the first <feature>, the first <label> and, when doc has one, the first
+// <shape>, none of which any codec knows. One value needs escaping, so the test
+// also covers what the encoder does with a value it did not write itself.
+func injectExtras(t *testing.T, doc string) string {
+	t.Helper()
+	replace := func(old, new string) {
+		if !strings.Contains(doc, old) {
+			t.Fatalf("document has no %q to inject extras at", old)
+		}
+		doc = strings.Replace(doc, old, new, 1)
+	}
+	replace("<map ", `<map glow="soft" `)
+	replace("</map>", "<weather kind=\"rain\"><cloud/></weather>\n</map>")
+	replace("<tiles ", `<tiles fog="0.25" `)
+	replace("</tiles>", "<mist/></tiles>")
+	if strings.Contains(doc, "<feature ") {
+		replace("<feature ", `<feature tint="red &amp; gold" `)
+		replace("</feature>", "<aura radius=\"3\">warm</aura></feature>")
+	}
+	if strings.Contains(doc, "<label ") {
+		replace("<label ", `<label halo="2" `)
+	}
+	if strings.Contains(doc, "<shape ") {
+		replace("<shape ", `<shape wobble="0.5" `)
+	}
+	return doc
+}
+
+// encodeExtras encodes m to target and returns the document and the losses it
+// reported.
+func encodeExtras(t *testing.T, m *wxx.Map_t, target string) (string, []xmlio.DroppedFeature_t) {
+	t.Helper()
+	var ed xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder(target, xmlio.WithEncoderDiagnostics(&ed)).Encode(&bytes.Buffer{}, m); err != nil {
+		t.Fatalf("encode to %s: %v", target, err)
+	}
+	return string(ed.Utf8Encoded), ed.Dropped
+}
+
+// TestExtrasRoundTrip asserts that what the W2025 codec does not know survives a
+// trip through it: the extras are on the map after a decode, are in the encoded
+// document, decode to the same extras again, and are reported as nothing lost.
+func TestExtrasRoundTrip(t *testing.T) {
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	doc := injectExtras(t, string(raw))
+
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := &wxx.Extras_t{
+		Schema:   "1.06",
+		Attrs:    []wxx.ExtraAttr_t{{Name: "glow", Value: "soft"}},
+		Elements: []string{`<weather kind="rain"><cloud/></weather>`},
+	}
+	if !reflect.DeepEqual(m.Extras, want) {
+		t.Errorf("Map_t.Extras = %+v, want %+v", m.Extras, want)
+	}
+	if x := m.Features[0].Extras; x == nil || len(x.Attrs) != 1 || x.Attrs[0].Value != "red & gold" {
+		t.Errorf("Features[0].Extras = %+v, want the tint with its entity expanded", x)
+	}
+	if m.Features[1].Extras != nil {
+		t.Errorf("Features[1].Extras = %+v, want nil; nothing was injected there", m.Features[1].Extras)
+	}
+
+	out, dropped := encodeExtras(t, m, m.MetaData.Version.App.Raw)
+	for _, e := range dropped {
+		t.Errorf("reported a loss encoding to the schema the extras were read against: %s", e)
+	}
+	for _, s := range []string{
+		` glow="soft"`, `<weather kind="rain"><cloud/></weather>`,
+		` fog="0.25"`, `<mist/></tiles>`,
+		` tint="red &amp; gold"`, `<aura radius="3">warm</aura></feature>`,
+		` halo="2"`, ` wobble="0.5"`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not have %s", s)
+		}
+	}
+
+	again, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(strings.NewReader(out))
+	if err != nil {
+		t.Fatalf("re-decode: %v", err)
+	}
+	normalizeVolatile(m)
+	normalizeVolatile(again)
+	compareGroups(t, m, again)
+}
+
+// TestExtrasDowngrade asserts that extras read against schema 1.06 are not
+// written to a classic file, and that each is reported with the element it was
+// on and its value.
+func TestExtrasDowngrade(t *testing.T) {
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(strings.NewReader(injectExtras(t, string(raw))))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	out, dropped := encodeExtras(t, m, classicTarget)
+	for _, s := range []string{"glow=", "<weather", "fog=", "<mist", "tint=", "<aura", "halo=", "wobble="} {
+		if strings.Contains(out, s) {
+			t.Errorf("classic output has %s, which was read against schema 1.06", s)
+		}
+	}
+
+	got := map[string]xmlio.DroppedFeature_t{}
+	for _, e := range dropped {
+		got[e.Path] = e
+	}
+	for path, detail := range map[string]string{
+		"map/@glow":                        `@glow is dropped from 1 <map> element(s), with value(s) "soft"`,
+		"map/weather":                      "<weather> is dropped from 1 <map> element(s)",
+		"map/tiles/@fog":                   `"0.25"`,
+		"map/tiles/mist":                   "<mist>",
+		"map/features/feature/@tint":       `"red & gold"`,
+		"map/features/feature/aura":        "<aura>",
+		"map/features/feature/label/@halo": `"2"`,
+		"map/shapes/shape/@wobble":         `"0.5"`,
+	} {
+		e, ok := got[path]
+		if !ok {
+			t.Errorf("%s not reported dropped", path)
+			continue
+		}
+		if !strings.Contains(e.Detail, detail) {
+			t.Errorf("%s Detail = %q, want it to contain %q", path, e.Detail, detail)
+		}
+		if e.Field == "" || !strings.Contains(e.Reason, "schema 1.06") {
+			t.Errorf("%s: Field=%q Reason=%q, want a field and the schema the extra was read against", path, e.Field, e.Reason)
+		}
+	}
+}
+
+// TestExtrasClassicRoundTrip asserts that the classic codec keeps extras too,
+// on the elements its encoder writes.
+func TestExtrasClassicRoundTrip(t *testing.T) {
+	doc := injectExtras(t, string(unwrapWXX(t, classicFixture)))
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if m.Extras == nil || m.Extras.Schema != "" {
+		t.Fatalf("Map_t.Extras = %+v, want extras read against the classic schema", m.Extras)
+	}
+
+	out, dropped := encodeExtras(t, m, m.MetaData.Version.App.Raw)
+	for _, e := range dropped {
+		t.Errorf("reported a loss encoding to the classic schema: %s", e)
+	}
+	for _, s := range []string{` glow="soft"`, `<weather kind="rain"><cloud/></weather>`, ` fog="0.25"`, `<mist/></tiles>`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not have %s", s)
+		}
+	}
+}
+
+// TestExtrasLabelDropShadow holds the layers fixture to what extras changed for
+// #35: its labels' drop-shadow attributes, which Label_t does not model, are no
+// longer lost on a 2.06 -> 2.06 trip, and a classic target says it drops them.
+func TestExtrasLabelDropShadow(t *testing.T) {
+	m := decodeW2025(t, sample2025_206Layers)
+
+	out, dropped := encodeExtras(t, m, m.MetaData.Version.App.Raw)
+	if len(dropped) != 0 {
+		t.Errorf("2.06 -> 2.06 reported %d loss(es), want none", len(dropped))
+	}
+	if n := strings.Count(out, `dropShadowColor="null"`) - strings.Count(out, "<labelstyle"); n != 3 {
+		t.Errorf("output has %d label(s) with dropShadowColor, want the fixture's 3", n)
+	}
+
+	_, dropped = encodeExtras(t, m, classicTarget)
+	for _, attr := range []string{"dropShadowColor", "dropShadowRadius", "dropShadowSpread"} {
+		found := false
+		for _, e := range dropped {
+			found = found || strings.HasSuffix(e.Path, "label/@"+attr)
+		}
+		if !found {
+			t.Errorf("label @%s not reported dropped by a classic target", attr)
+		}
+	}
+}
@@ -4,41 +4,190 @@ package xmlio
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strconv"
 
 	"github.com/maloquacious/wxx"
 )
 
 // ReadFile reads and decodes a Worldographer .wxx file from path.
 // Decoder behavior may be tuned with DecoderOption values (see NewDecoder).
+//
+// ReadFile detects the container format (WithAutoDetect), because a path says
+// nothing reliable about what is in the file: it reads a .wxx as readily as the
+// XML `wxx export` writes. It also limits what the file may make it allocate
+// (WithDefaultLimits), because a path says nothing about where the file came
+// from either. The options given are applied after those defaults, so a caller
+// who wants a fixed format, or a larger map, can still ask for one.
 func ReadFile(path string, opts ...DecoderOption) (*wxx.Map_t, error) {
+	return ReadFileContext(context.Background(), path, opts...)
+}
+
+// ReadFileContext is ReadFile, stopping when ctx is done. See
+// Decoder.DecodeContext.
+func ReadFileContext(ctx context.Context, path string, opts ...DecoderOption) (*wxx.Map_t, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, fmt.Errorf("read %s: %w", path, err)
 	}
 	defer func() { _ = f.Close() }()
-	return NewDecoder(opts...).Decode(f)
+	return fileDecoder(opts).DecodeContext(ctx, f)
+}
+
+// ReadFS reads and decodes the map name from fsys, as ReadFile reads one from
+// the operating system's file system, with the same defaults. fsys is whatever
+// holds the map: an embed.FS, a *zip.Reader, an fstest.MapFS, or os.DirFS.
+func ReadFS(fsys fs.FS, name string, opts ...DecoderOption) (*wxx.Map_t, error) {
+	return ReadFSContext(context.Background(), fsys, name, opts...)
+}
+
+// ReadFSContext is ReadFS, stopping when ctx is done. See
+// Decoder.DecodeContext.
+func ReadFSContext(ctx context.Context, fsys fs.FS, name string, opts ...DecoderOption) (*wxx.Map_t, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", name, err)
+	}
+	defer func() { _ = f.Close() }()
+	return fileDecoder(opts).DecodeContext(ctx, f)
+}
+
+// fileDecoder returns the Decoder ReadFile and ReadFS use: one that detects the
+// container and applies the default limits, and then opts.
+func fileDecoder(opts []DecoderOption) *Decoder {
+	return NewDecoder(append([]DecoderOption{WithAutoDetect(), WithDefaultLimits()}, opts...)...)
 }
 
 // WriteFile encodes m as the supported application version app ("1.73", "1.77",
-// "2.06") and writes it to path (0644). Encoder behavior may be tuned with
+// "2.06") and writes it to path. Encoder behavior may be tuned with
 // EncoderOption values (see NewEncoder).
 //
 // app is required, for the reason it is required on NewEncoder: writing the
 // version the map happens to state would make the SOURCE file's identity the
 // target, and a caller who wants that says so with m.MetaData.Version.App.Raw.
 //
-// The map is encoded into memory first and only written to disk once the
-// encode succeeds, so a failed encode never truncates or creates a corrupt
-// file at path.
+// The map is encoded into memory first, written to a temporary file in path's
+// directory, synced, and only then renamed over path. A failed encode, a full
+// disk or a crash part way through leaves the file at path as it was: it is
+// either the old map or the new one, never a truncated mix. A file that is
+// replaced keeps its permissions; a new one is created 0644. WithBackups keeps
+// copies of the maps it replaces.
 func WriteFile(path string, m *wxx.Map_t, app string, opts ...EncoderOption) error {
+	return WriteFileContext(context.Background(), path, m, app, opts...)
+}
+
+// WriteFileContext is WriteFile, stopping when ctx is done. An encode that ctx
+// stops leaves path as it was. See Encoder.EncodeContext.
+func WriteFileContext(ctx context.Context, path string, m *wxx.Map_t, app string, opts ...EncoderOption) error {
+	e := NewEncoder(app, opts...)
 	var buf bytes.Buffer
-	if err := NewEncoder(app, opts...).Encode(&buf, m); err != nil {
+	if err := e.EncodeContext(ctx, &buf, m); err != nil {
 		return fmt.Errorf("write %s: %w", path, err)
 	}
-	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+	if err := writeAtomic(path, buf.Bytes(), e.opts.backups); err != nil {
 		return fmt.Errorf("write %s: %w", path, err)
 	}
 	return nil
 }
+
+// WithBackups has WriteFile keep the last n maps it replaced at path, as
+// path.1.bak (the newest) through path.n.bak (the oldest). Each write moves the
+// copies down one place and drops the one past n. The default, and any n < 1,
+// keeps none. A backup is taken only once the new map is safely on disk, and
+// it does not change what an Encoder writes.
+func WithBackups(n int) EncoderOption {
+	return func(o *encoderOpts) {
+		o.backups = max(n, 0)
+	}
+}
+
+// writeAtomic replaces the file at path with data, keeping backups numbered
+// copies of the file it replaces.
+func writeAtomic(path string, data []byte, backups int) (err error) {
+	perm := fs.FileMode(0644)
+	fi, err := os.Stat(path)
+	if err == nil {
+		if !fi.Mode().IsRegular() {
+			return fmt.Errorf("%w: not a regular file", fs.ErrInvalid)
+		}
+		perm = fi.Mode().Perm()
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	exists := err == nil
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	} else if err = tmp.Chmod(perm); err != nil {
+		return err
+	} else if err = tmp.Sync(); err != nil {
+		return err
+	} else if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if exists && backups > 0 {
+		if err = rotateBackups(path, backups); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	// the rename is durable only once the directory is; not every system can
+	// sync one, and the map is in place either way, so this is best effort
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+	return nil
+}
+
+// backupName returns the name of the nth backup of path.
+func backupName(path string, n int) string {
+	return path + "." + strconv.Itoa(n) + ".bak"
+}
+
+// rotateBackups moves the n-1 newest backups of path down one place, dropping
+// the oldest, and copies path to the first. path itself is not moved, so there
+// is always a map at path.
+func rotateBackups(path string, n int) error {
+	for i := n - 1; i >= 1; i-- {
+		if err := os.Rename(backupName(path, i), backupName(path, i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	first := backupName(path, 1)
+	if err := os.Remove(first); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	// a hard link costs nothing; a file system without them gets a copy
+	if err := os.Link(path, first); err == nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(first, data, fi.Mode().Perm())
+}
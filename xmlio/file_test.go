@@ -3,8 +3,14 @@
 package xmlio_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/maloquacious/wxx/xmlio"
 )
@@ -79,11 +85,66 @@ func TestWriteFileReadFileRoundTrip(t *testing.T) {
 	if got, want := m2.Tiles.TilesHigh, m1.Tiles.TilesHigh; got != want {
 		t.Errorf("Tiles.TilesHigh = %d, want %d", got, want)
 	}
-	if got, want := len(m2.Tiles.Tiles), len(m1.Tiles.Tiles); got != want {
-		t.Errorf("len(Tiles.Tiles) = %d, want %d", got, want)
+	got, _ := m2.Tiles.Size()
+	want, _ := m1.Tiles.Size()
+	if got != want {
+		t.Errorf("Tiles.Size() wide = %d, want %d", got, want)
 	}
 }
 
+// TestWriteFileBackups writes a map three times with WithBackups(2) and asserts
+// that the file holds the newest write and the backups the two before it, then
+// that a write that fails leaves all three as they were and no temporary file
+// behind.
+func TestWriteFileBackups(t *testing.T) {
+	m, err := xmlio.ReadFile(classicFixture)
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", classicFixture, err)
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "world.wxx")
+	var writes [][]byte
+	for i := 1; i <= 3; i++ {
+		m.HexWidth = float64(i) // so each write is a different file
+		if err := xmlio.WriteFile(out, m, m.MetaData.Version.App.Raw, xmlio.WithBackups(2)); err != nil {
+			t.Fatalf("WriteFile(%s) #%d: %v", out, i, err)
+		}
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writes = append(writes, data)
+	}
+
+	check := func(when string) {
+		t.Helper()
+		for name, want := range map[string][]byte{
+			"world.wxx":       writes[2],
+			"world.wxx.1.bak": writes[1],
+			"world.wxx.2.bak": writes[0],
+		} {
+			if got, err := os.ReadFile(filepath.Join(dir, name)); err != nil {
+				t.Errorf("%s: %v", when, err)
+			} else if !bytes.Equal(got, want) {
+				t.Errorf("%s: %s does not hold the write it should", when, name)
+			}
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 3 {
+			var names []string
+			for _, e := range entries {
+				names = append(names, e.Name())
+			}
+			t.Errorf("%s: %s holds %q, want the map and two backups", when, dir, names)
+		}
+	}
+	check("after three writes")
+
+	if err := xmlio.WriteFile(out, m, "0.01", xmlio.WithBackups(2)); err == nil {
+		t.Fatalf("WriteFile(%s) as 0.01: expected error, got nil", out)
+	}
+	check("after a failed write")
+}
+
 // TestReadFileMissing asserts ReadFile returns an error (not a panic) for a
 // path that does not exist.
 func TestReadFileMissing(t *testing.T) {
@@ -92,3 +153,53 @@ func TestReadFileMissing(t *testing.T) {
 		t.Fatalf("ReadFile(%s): expected error, got nil", missing)
 	}
 }
+
+// TestReadFS asserts that ReadFS reads a map from any fs.FS as ReadFile reads it
+// from disk: from a MapFS, from a zip archive, and from a directory.
+func TestReadFS(t *testing.T) {
+	data, err := os.ReadFile(classicFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := xmlio.ReadFile(classicFixture)
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", classicFixture, err)
+	}
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	if w, err := zw.Create("maps/world.wxx"); err != nil {
+		t.Fatal(err)
+	} else if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, fsys := range map[string]fs.FS{
+		"MapFS": fstest.MapFS{"maps/world.wxx": {Data: data}},
+		"zip":   zr,
+		"dir":   os.DirFS(filepath.Dir(classicFixture)),
+	} {
+		path := "maps/world.wxx"
+		if name == "dir" {
+			path = filepath.Base(classicFixture)
+		}
+		m, err := xmlio.ReadFS(fsys, path)
+		if err != nil {
+			t.Errorf("%s: ReadFS: %v", name, err)
+			continue
+		}
+		if m.MetaData.Version.App != want.MetaData.Version.App || m.Tiles.TilesWide != want.Tiles.TilesWide {
+			t.Errorf("%s: ReadFS read %s %d wide, want %s %d wide", name, m.MetaData.Version.App.Raw, m.Tiles.TilesWide, want.MetaData.Version.App.Raw, want.Tiles.TilesWide)
+		}
+		if _, err := xmlio.ReadFS(fsys, "missing.wxx"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: ReadFS of a missing map: error = %v, want %v", name, err, fs.ErrNotExist)
+		}
+	}
+}
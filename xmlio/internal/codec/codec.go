@@ -31,8 +31,12 @@
 package codec
 
 import (
+	"context"
+	"io"
+
 	"github.com/maloquacious/wxx"
 	"github.com/maloquacious/wxx/xmlio/internal/appver"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // Codec emits exactly one schema, and declares what it accepts and what it
@@ -46,16 +50,15 @@ import (
 // set or does not compile. The check that guarded against the half-built pair is
 // not rescued anywhere, because it is now a compile error.
 //
-// It carries no Decode. A codec is conceptually the parse/emit pair for one
-// schema (ADR 0004 Decision 4), and each codec package does implement both, but
-// the parse half is not reached through this interface: decoder.go dispatches on
-// the map/@release the file states and calls the codec package's Decode function
-// directly, because it has already read the bytes that identify the file and does
-// not need the registry to tell it which parser to use. A Decode method here was
-// never called by anything, and the ruling is that what is unused goes. The
-// asymmetry is real and it is the point: ENCODE dispatch is what the registry
-// exists for, because an encode has no file to read its target from and must be
-// told one (issue #45).
+// It carries DecodeReader as well. For a while it did not: decoder.go sent
+// every release="2025" file to v1_06 by name, a Decode method had no caller, and
+// the ruling is that what is unused goes. What brought it back is a file stating
+// a schema newer than any codec's. Choosing the codec that reads it means
+// comparing the file's map/@schema with the schema each codec declares, and then
+// holding the one chosen -- which is this interface. Encode dispatch is still
+// keyed on the application version and decode dispatch on the schema, because
+// an encode has no file to read its target from and must be told one (issue
+// #45), while a decode has the file's own word for it.
 //
 // AcceptedApps is on the interface, not alongside it, for the same reason Encode
 // is: the declaration is the codec's own knowledge (see appver), and the
@@ -72,7 +75,21 @@ type Codec interface {
 	// must be one input, which is issue #45. It has to be passed rather than
 	// derived because a codec's schema cannot tell it which of the application
 	// versions sharing that schema the caller meant.
-	Encode(m *wxx.Map_t, app string) ([]byte, error)
+	//
+	// workers is the number of goroutines the <tilerow> elements may be written
+	// on, at least 1. The output is the same for any number. A cancelled ctx
+	// stops the encode before the next row, with ctx's error.
+	Encode(ctx context.Context, m *wxx.Map_t, app string, workers int) ([]byte, error)
+
+	// DecodeReader decodes one schema's XML, read as a stream from r, which
+	// starts at the <map> element. lax is nil for a strict decode; see
+	// xmlstream.Lenient. workers is the number of goroutines the <tilerow>
+	// elements may be parsed on, at least 1; the map, the warnings and the error
+	// are the same for any number. limits bounds the tiles the decode allocates,
+	// which the file states and must not be trusted for. A cancelled ctx stops
+	// the decode before the next row, with ctx's error. trace, when it is not
+	// nil, is told about each child of <map> as it is read.
+	DecodeReader(ctx context.Context, r io.Reader, lax *xmlstream.Lenient, workers int, limits xmlstream.Limits, trace *xmlstream.Trace) (*wxx.Map_t, error)
 
 	// AcceptedApps returns the codec's declaration: the application versions it
 	// accepts, the map/@release each writes, the schema it writes, and the XML
@@ -3,8 +3,12 @@
 package v0_77
 
 import (
+	"context"
+	"io"
+
 	"github.com/maloquacious/wxx"
 	"github.com/maloquacious/wxx/xmlio/internal/appver"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // Codec_t is this package's codec as a VALUE, which is what the dispatcher holds.
@@ -15,10 +19,10 @@ import (
 // that is the real implementation, which stays exported because the test units
 // requirement 5 admits call it directly (see xmlio/chimera_test.go).
 //
-// It forwards Encode but not Decode, because the dispatcher only ever asks a
-// codec to encode: xmlio's decoder reads the file's own map/@release and calls
-// this package's Decode function directly, so a forwarding method had no caller.
-// See codec.Codec.
+// It forwards DecodeReader, the streaming decoder, and not Decode, which
+// unmarshals a buffered document: xmlio's decoder only streams, and picks the
+// codec by comparing the file's map/@schema with each codec's declaration. See
+// codec.Codec.
 //
 // It carries the declaration alongside encode because the registry is built by
 // ASKING each codec what it accepts (issue #45 Decision 8): the mapping
@@ -26,8 +30,19 @@ import (
 // dispatcher reads it rather than restating it.
 type Codec_t struct{}
 
-// Encode emits a Map_t as classic XML, as the application version app.
-func (Codec_t) Encode(m *wxx.Map_t, app string) ([]byte, error) { return Encode(m, app) }
+// Encode emits a Map_t as classic XML, as the application version app,
+// writing <tilerow> elements on up to workers goroutines. See the package
+// function.
+func (Codec_t) Encode(ctx context.Context, m *wxx.Map_t, app string, workers int) ([]byte, error) {
+	return encode(ctx, m, app, workers)
+}
+
+// DecodeReader decodes classic XML from r, parsing <tilerow> elements on up to
+// workers goroutines and building no more tiles than limits allow. See the
+// package function.
+func (Codec_t) DecodeReader(ctx context.Context, r io.Reader, lax *xmlstream.Lenient, workers int, limits xmlstream.Limits, trace *xmlstream.Trace) (*wxx.Map_t, error) {
+	return decodeReader(ctx, r, lax, workers, limits, trace)
+}
 
 // AcceptedApps returns this codec's declaration. See acceptedApps.
 func (Codec_t) AcceptedApps() appver.Set_t { return AcceptedApps() }
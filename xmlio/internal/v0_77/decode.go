@@ -13,6 +13,7 @@ import (
 
 	"github.com/maloquacious/wxx"
 	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // classicVersionIdentity models the on-disk version identity of a classic file
@@ -34,6 +35,11 @@ func classicVersionIdentity(version string) wxx.Version_t {
 }
 
 // Decode the XML data using the H2017.V1 schema and return a Map_t or an error.
+//
+// Decode holds the whole document in memory. xmlio's decoder.go calls
+// DecodeReader (stream.go), the bounded-memory path, instead. Both share the
+// conversion from the schema structs and differ only in how <tiles> is reached,
+// so they produce the same Map_t.
 func Decode(input []byte) (*wxx.Map_t, error) {
 	m := &XMLSchema{}
 
@@ -43,7 +49,19 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 		log.Printf("v0_77: %v\n", err)
 		return nil, err
 	}
+	w, err := decodeMapAttributes(m, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeElements(m, w, nil, func() error {
+		return decodeTiles(m.Tiles, m.MapKey, w, nil)
+	})
+}
 
+// decodeMapAttributes returns a new domain map populated from the <map> root
+// attributes. It needs nothing below the root element, so the streaming decoder
+// calls it before the first child is read.
+func decodeMapAttributes(m *XMLSchema, lax *xmlstream.Lenient) (*wxx.Map_t, error) {
 	// process source into a WXX structure and return it or any errors
 	w := &wxx.Map_t{}
 	w.MetaData.AppVersion = wxx.Version()
@@ -76,7 +94,11 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	case "ROWS":
 		w.GridOrientation = hexg.OddR
 	default:
-		return nil, fmt.Errorf("%q: unknown orientation", m.HexOrientation)
+		err := &xmlstream.Error{Field: "hexOrientation", Err: fmt.Errorf("%w: %q: unknown orientation", wxx.ErrInvalidHexOrientation, m.HexOrientation)}
+		if err := lax.Repair(err, m.HexOrientation, "set to COLUMNS"); err != nil {
+			return nil, err
+		}
+		w.HexOrientation, w.GridOrientation = "COLUMNS", hexg.OddQ
 	}
 	w.HexWidth = m.HexWidth
 	w.KingdomFactor = m.KingdomFactor
@@ -89,7 +111,11 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	case "ICOSAHEDRAL":
 		w.MapProjection = wxx.ICOSAHEDRAL
 	default:
-		return nil, fmt.Errorf("%q: unknown projection", m.MapProjection)
+		err := &xmlstream.Error{Field: "mapProjection", Err: fmt.Errorf("%w: %q: unknown projection", wxx.ErrInvalidMapProjection, m.MapProjection)}
+		if err := lax.Repair(err, m.MapProjection, "set to FLAT"); err != nil {
+			return nil, err
+		}
+		w.MapProjection = wxx.FLAT
 	}
 	w.ProvinceFactor = m.ProvinceFactor
 	w.ShowFeatureLabels = m.ShowFeatureLabels
@@ -104,6 +130,20 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	w.WorldToContinentHOffset = m.WorldToContinentHOffset
 	w.WorldToContinentVOffset = m.WorldToContinentVOffset
 
+	return w, nil
+}
+
+// decodeElements converts the children of <map> into the domain map, in the
+// order they appear on disk. The tiles step is supplied by the caller because
+// it is the one the two decode paths do differently. An error is located at the
+// element that failed; the caller adds the XML position when it has one.
+func decodeElements(m *XMLSchema, w *wxx.Map_t, lax *xmlstream.Lenient, decodeTiles func() error) (*wxx.Map_t, error) {
+	var err error
+
+	// the root's unrecognised children are only all known once the stream has
+	// passed them, so its extras are taken here rather than with its attributes
+	w.Extras = extras(m.ExtraAttrs, m.ExtraElements)
+
 	w.GridAndNumbering = &wxx.GridAndNumbering_t{}
 	w.GridAndNumbering.Color0 = m.GridAndNumbering.Color0
 	w.GridAndNumbering.Color1 = m.GridAndNumbering.Color1
@@ -136,11 +176,12 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	w.GridAndNumbering.NumberPosition = m.GridAndNumbering.NumberPosition
 	w.GridAndNumbering.NumberPrePad = m.GridAndNumbering.NumberPrePad
 	w.GridAndNumbering.NumberSeparator = m.GridAndNumbering.NumberSeparator
+	w.GridAndNumbering.Extras = extras(m.GridAndNumbering.ExtraAttrs, m.GridAndNumbering.ExtraElements)
 
 	// convert terrain map. in the source, the terrain key and values are stored as tab delimited columns.
 	w.TerrainMap = &wxx.TerrainMap_t{Data: map[string]int{}}
 	if fields := strings.Split(m.TerrainMap.InnerText, "\t"); len(fields)%2 != 0 {
-		return w, errors.Join(wxx.ErrInvalidTerrainMapFieldCount, fmt.Errorf("field count '%d' is not even", len(fields)))
+		return w, xmlstream.Locate(errors.Join(wxx.ErrInvalidTerrainMapFieldCount, fmt.Errorf("field count '%d' is not even", len(fields))), "map/terrainmap", 0, 0)
 	} else {
 		for len(fields) != 0 {
 			t := &wxx.Terrain_t{
@@ -148,7 +189,7 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 			}
 			t.Index, err = strconv.Atoi(fields[1])
 			if err != nil {
-				return w, fmt.Errorf("field: %s: invalid index: %w", fields[0], err)
+				return w, xmlstream.Locate(fmt.Errorf("field: %s: invalid index: %w", fields[0], err), "map/terrainmap", 0, 0)
 			}
 			w.TerrainMap.List = append(w.TerrainMap.List, t)
 			w.TerrainMap.Data[t.Label] = t.Index
@@ -157,157 +198,14 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	}
 
 	for _, layer := range m.MapLayers {
-		w.MapLayers = append(w.MapLayers, &wxx.MapLayer_t{Name: layer.Name, IsVisible: layer.IsVisible})
+		w.MapLayers = append(w.MapLayers, &wxx.MapLayer_t{Name: layer.Name, IsVisible: layer.IsVisible, Extras: extras(layer.ExtraAttrs, layer.ExtraElements)})
 	}
 
-	w.Tiles = &wxx.Tiles_t{
-		ViewLevel: m.Tiles.ViewLevel,
-		TilesWide: m.Tiles.TilesWide,
-		TilesHigh: m.Tiles.TilesHigh,
-	}
-
-	// Set RowsHigh and ColumnsWide based on GridOrientation
-	switch w.GridOrientation {
-	case hexg.OddQ:
-		// Column orientation: TilesWide = columns, TilesHigh = rows
-		w.RowsHigh = w.Tiles.TilesHigh
-		w.ColumnsWide = w.Tiles.TilesWide
-	case hexg.OddR:
-		// Row orientation: TilesWide = rows, TilesHigh = columns
-		w.RowsHigh = w.Tiles.TilesWide
-		w.ColumnsWide = w.Tiles.TilesHigh
-	}
-	for _, tilerow := range m.Tiles.TileRows {
-		x, y := len(w.Tiles.Tiles), 0
-		w.Tiles.Tiles = append(w.Tiles.Tiles, make([]*wxx.Tile_t, w.Tiles.TilesHigh))
-		for _, line := range strings.Split(tilerow.InnerText, "\n") {
-			if len(line) == 0 { // ignore blank lines
-				continue
-			}
-			t := &wxx.Tile_t{Row: x, Column: y}
-			if w.GridOrientation == hexg.OddQ {
-				t.Coords = hexg.NewOddQCoord(y, x).ToCube()
-			} else if w.GridOrientation == hexg.OddR {
-				t.Coords = hexg.NewOddRCoord(y, x).ToCube()
-			}
-			w.Tiles.Tiles[x][y] = t
-			y++
-			// values are TerrainMapIndex Elevation IsIcy IsGMOnly Animals (Z|(Brick Crops Gems Lumber Metals Rock)) RGBA?
-			values := strings.Split(line, "\t")
-			//fmt.Printf("tilerow: %d %d: len(inner) %d lines %d line %d values %d\n", r, i+1, len(element.InnerText), len(lines), len(line), len(values))
-			switch len(values) {
-			case 6, 7, 11, 12: // allowed
-			default:
-				return w, fmt.Errorf("values: expected 6/7/11/12, got %d", len(values))
-			}
-			if t.Terrain, err = strconv.Atoi(values[0]); err != nil {
-				return w, fmt.Errorf("value: terrainType: %w", err)
-			}
-			if t.Elevation, err = strconv.ParseFloat(values[1], 64); err != nil {
-				return w, fmt.Errorf("value: elevation: %w", err)
-			}
-			t.IsIcy = values[2] == "1"
-			t.IsGMOnly = values[3] == "1"
-			if t.Resources.Animal, err = strconv.Atoi(values[4]); err != nil {
-				return w, fmt.Errorf("value: animals: %w", err)
-			} else if t.Resources.Animal < 0 {
-				return w, fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
-			} else if t.Resources.Animal > 100 {
-				return w, fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
-			}
-			compressedResources := len(values) == 6 || len(values) == 7
-			if compressedResources {
-				// a with compressed resources should flag them with a Z
-				if values[5] != "Z" {
-					return w, fmt.Errorf("value: sentinel: %w", fmt.Errorf("invalid value"))
-				}
-			} else {
-				if t.Resources.Brick, err = strconv.Atoi(values[5]); err != nil {
-					return w, fmt.Errorf("value: brick: %q: %w", values, err)
-				} else if t.Resources.Brick < 0 {
-					return w, fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Brick > 100 {
-					return w, fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Crops, err = strconv.Atoi(values[6]); err != nil {
-					return w, fmt.Errorf("value: crops: %w", err)
-				} else if t.Resources.Crops < 0 {
-					return w, fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Crops > 100 {
-					return w, fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Gems, err = strconv.Atoi(values[7]); err != nil {
-					return w, fmt.Errorf("value: gems: %w", err)
-				} else if t.Resources.Gems < 0 {
-					return w, fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Gems > 100 {
-					return w, fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Lumber, err = strconv.Atoi(values[8]); err != nil {
-					return w, fmt.Errorf("value: lumber: %w", err)
-				} else if t.Resources.Lumber < 0 {
-					return w, fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Lumber > 100 {
-					return w, fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Metals, err = strconv.Atoi(values[9]); err != nil {
-					return w, fmt.Errorf("value: metals: %w", err)
-				} else if t.Resources.Metals < 0 {
-					return w, fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Metals > 100 {
-					return w, fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Rock, err = strconv.Atoi(values[10]); err != nil {
-					return w, fmt.Errorf("value: rock: %w", err)
-				} else if t.Resources.Rock < 0 {
-					return w, fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Rock > 100 {
-					return w, fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
-				}
-			}
-			if len(values) == 7 || len(values) == 12 {
-				// split rgba
-				if t.CustomBackgroundColor, err = decodeRgba(values[len(values)-1]); err != nil {
-					return w, fmt.Errorf("value: rgba: %w", err)
-				}
-			}
-		}
-
-		w.MapKey = &wxx.MapKey_t{
-			PositionX: m.MapKey.PositionX,
-			PositionY: m.MapKey.PositionY,
-			Viewlevel: m.MapKey.Viewlevel,
-			Height:    m.MapKey.Height,
-		}
-		if w.MapKey.BackgroundColor, err = decodeRgba(m.MapKey.BackgroundColor); err != nil {
-			return w, fmt.Errorf("mapkey.backgroundcolor: %w", err)
-		}
-		w.MapKey.BackgroundOpacity = m.MapKey.BackgroundOpacity
-		w.MapKey.TitleText = m.MapKey.TitleText
-		w.MapKey.TitleFontFace = m.MapKey.TitleFontFace
-		if w.MapKey.TitleFontColor, err = decodeRgba(m.MapKey.TitleFontColor); err != nil {
-			return w, fmt.Errorf("mapkey.titleFontColor: %w", err)
-		}
-		w.MapKey.TitleFontBold = m.MapKey.TitleFontBold
-		w.MapKey.TitleFontItalic = m.MapKey.TitleFontItalic
-		w.MapKey.TitleScale = m.MapKey.TitleScale
-		w.MapKey.ScaleText = m.MapKey.ScaleText
-		w.MapKey.ScaleFontFace = m.MapKey.ScaleFontFace
-		if w.MapKey.ScaleFontColor, err = decodeRgba(m.MapKey.ScaleFontColor); err != nil {
-			return w, fmt.Errorf("mapkey.scaleFontColor: %w", err)
-		}
-		w.MapKey.ScaleFontBold = m.MapKey.ScaleFontBold
-		w.MapKey.ScaleFontItalic = m.MapKey.ScaleFontItalic
-		w.MapKey.ScaleScale = m.MapKey.ScaleScale
-		w.MapKey.EntryFontFace = m.MapKey.EntryFontFace
-		if w.MapKey.EntryFontColor, err = decodeRgba(m.MapKey.EntryFontColor); err != nil {
-			return w, fmt.Errorf("mapkey.entryFontColor: %w", err)
-		}
-		w.MapKey.EntryFontBold = m.MapKey.EntryFontBold
-		w.MapKey.EntryFontItalic = m.MapKey.EntryFontItalic
-		w.MapKey.EntryScale = m.MapKey.EntryScale
+	if err := decodeTiles(); err != nil {
+		return w, err
 	}
 
+	lax.In("map/features")
 	for _, mFeature := range m.Features.Features {
 		f := &wxx.Feature_t{}
 		f.Type = mFeature.Type
@@ -319,11 +217,11 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 		f.Scale = mFeature.Scale
 		f.ScaleHt = mFeature.ScaleHt
 		f.Tags = mFeature.Tags
-		if f.Color, err = decodeRgba(mFeature.Color); err != nil {
-			return w, fmt.Errorf("feature.Color: %w", err)
+		if f.Color, err = lax.RGBA(decodeRgba, mFeature.Color, "feature.Color"); err != nil {
+			return w, xmlstream.Locate(err, "map/features", 0, 0)
 		}
-		if f.RingColor, err = decodeRgba(mFeature.RingColor); err != nil {
-			return w, fmt.Errorf("feature.RingColor: %w", err)
+		if f.RingColor, err = lax.RGBA(decodeRgba, mFeature.RingColor, "feature.RingColor"); err != nil {
+			return w, xmlstream.Locate(err, "map/features", 0, 0)
 		}
 		f.IsGMOnly = mFeature.IsGMOnly
 		f.IsPlaceFreely = mFeature.IsPlaceFreely
@@ -335,10 +233,12 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 		f.IsProvince = mFeature.IsProvince
 		f.IsFillHexBottom = mFeature.IsFillHexBottom
 		f.IsHideTerrainIcon = mFeature.IsHideTerrainIcon
+		f.Extras = extras(mFeature.ExtraAttrs, mFeature.ExtraElements)
 		f.Location = &wxx.FeatureLocation_t{
 			ViewLevel: mFeature.Location.ViewLevel,
 			X:         mFeature.Location.X,
 			Y:         mFeature.Location.Y,
+			Extras:    extras(mFeature.Location.ExtraAttrs, mFeature.Location.ExtraElements),
 		}
 
 		f.Label = &wxx.Label_t{
@@ -356,25 +256,28 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 			IsGMOnly:    mFeature.Label.IsGMOnly,
 			Tags:        mFeature.Label.Tags,
 			InnerText:   mFeature.Label.InnerText,
+			Extras:      extras(mFeature.Label.ExtraAttrs, mFeature.Label.ExtraElements),
 		}
-		if f.Label.Color, err = decodeRgba(mFeature.Label.Color); err != nil {
-			return w, fmt.Errorf("feature.label.color: %w", err)
+		if f.Label.Color, err = lax.RGBA(decodeRgba, mFeature.Label.Color, "feature.label.color"); err != nil {
+			return w, xmlstream.Locate(err, "map/features", 0, 0)
 		}
-		if f.Label.OutlineColor, err = decodeRgba(mFeature.Label.OutlineColor); err != nil {
-			return w, fmt.Errorf("feature.label.outlineColor: %w", err)
+		if f.Label.OutlineColor, err = lax.RGBA(decodeRgba, mFeature.Label.OutlineColor, "feature.label.outlineColor"); err != nil {
+			return w, xmlstream.Locate(err, "map/features", 0, 0)
 		}
-		if f.Label.BackgroundColor, err = decodeRgba(mFeature.Label.BackgroundColor); err != nil {
-			return w, fmt.Errorf("feature.label.backgroundColor: %w", err)
+		if f.Label.BackgroundColor, err = lax.RGBA(decodeRgba, mFeature.Label.BackgroundColor, "feature.label.backgroundColor"); err != nil {
+			return w, xmlstream.Locate(err, "map/features", 0, 0)
 		}
 		f.Label.Location = &wxx.LabelLocation_t{
 			ViewLevel: mFeature.Label.Location.ViewLevel,
 			X:         mFeature.Label.Location.X,
 			Y:         mFeature.Label.Location.Y,
 			Scale:     mFeature.Label.Location.Scale,
+			Extras:    extras(mFeature.Label.Location.ExtraAttrs, mFeature.Label.Location.ExtraElements),
 		}
 		w.Features = append(w.Features, f)
 	}
 
+	lax.In("map/labels")
 	for _, mLabel := range m.Labels.Labels {
 		wLabel := &wxx.Label_t{
 			MapLayer:    mLabel.MapLayer,
@@ -390,23 +293,23 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 			IsProvince:  mLabel.IsProvince,
 			IsGMOnly:    mLabel.IsGMOnly,
 			Tags:        mLabel.Tags,
+			Extras:      extras(mLabel.ExtraAttrs, mLabel.ExtraElements),
 		}
-		if wLabel.Color, err = decodeRgba(mLabel.Color); err != nil {
-			return w, fmt.Errorf("label.color: %w", err)
+		if wLabel.Color, err = lax.RGBA(decodeRgba, mLabel.Color, "label.color"); err != nil {
+			return w, xmlstream.Locate(err, "map/labels", 0, 0)
 		}
-		if wLabel.OutlineColor, err = decodeRgba(mLabel.OutlineColor); err != nil {
-			return w, fmt.Errorf("label.outlineColor: %w", err)
+		if wLabel.OutlineColor, err = lax.RGBA(decodeRgba, mLabel.OutlineColor, "label.outlineColor"); err != nil {
+			return w, xmlstream.Locate(err, "map/labels", 0, 0)
 		}
-		if mLabel.BackgroundColor == "" {
-			wLabel.BackgroundColor = nil
-		} else if wLabel.BackgroundColor, err = decodeZeroableRgba(mLabel.BackgroundColor); err != nil {
-			return w, fmt.Errorf("label.backgroundColor: %w", err)
+		if wLabel.BackgroundColor, err = lax.RGBA(decodeRgba, mLabel.BackgroundColor, "label.backgroundColor"); err != nil {
+			return w, xmlstream.Locate(err, "map/labels", 0, 0)
 		}
 		wLabel.Location = &wxx.LabelLocation_t{
 			ViewLevel: mLabel.Location.ViewLevel,
 			X:         mLabel.Location.X,
 			Y:         mLabel.Location.Y,
 			Scale:     mLabel.Location.Scale,
+			Extras:    extras(mLabel.Location.ExtraAttrs, mLabel.Location.ExtraElements),
 		}
 		wLabel.InnerText = mLabel.InnerText
 		w.Labels = append(w.Labels, wLabel)
@@ -488,32 +391,16 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 			Culture:      info.Culture,
 			HolySymbol:   info.HolySymbol,
 			Domains:      info.Domains,
+			Details:      decodeInformationDetails(info.Details),
 			InnerText:    info.InnerText,
+			IsCDATA:      xmlstream.StartsWithCDATA(info.InnerXML),
 		}
-
-		for _, detail := range info.Details {
-			wDetail := &wxx.InformationDetail_t{
-				Uuid:         detail.Uuid,
-				Type:         detail.Type,
-				Title:        detail.Title,
-				Rulers:       detail.Rulers,
-				Government:   detail.Government,
-				Cultures:     detail.Cultures,
-				Language:     detail.Language,
-				ReligionType: detail.ReligionType,
-				Culture:      detail.Culture,
-				HolySymbol:   detail.HolySymbol,
-				Domains:      detail.Domains,
-				InnerText:    detail.InnerText,
-			}
-			wInfo.Details = append(wInfo.Details, wDetail)
-		}
-
 		w.Informations.Informations = append(w.Informations.Informations, wInfo)
 	}
 	w.Informations.InnerText = m.Informations.InnerText
 
 	// convert m.Configuration to w.Configuration
+	lax.In("map/configuration")
 	w.Configuration = &wxx.Configuration_t{}
 	for _, mTerrainConfig := range m.Configuration.TerrainConfig {
 		wTerrainConfig := &wxx.TerrainConfig_t{
@@ -545,16 +432,14 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 				IsItalic:    mLabelStyle.IsItalic,
 				OutlineSize: mLabelStyle.OutlineSize,
 			}
-			if wLabelStyle.Color, err = decodeRgba(mLabelStyle.Color); err != nil {
-				return w, fmt.Errorf("labelStyle.color: %w", err)
+			if wLabelStyle.Color, err = lax.RGBA(decodeRgba, mLabelStyle.Color, "labelStyle.color"); err != nil {
+				return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 			}
-			if wLabelStyle.BackgroundColor, err = decodeRgba(mLabelStyle.BackgroundColor); err != nil {
-				return w, fmt.Errorf("labelStyle.backgroundColor: %w", err)
+			if wLabelStyle.BackgroundColor, err = lax.RGBA(decodeRgba, mLabelStyle.BackgroundColor, "labelStyle.backgroundColor"); err != nil {
+				return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 			}
-			if mLabelStyle.OutlineColor == "null" {
-				wLabelStyle.OutlineColor = nil
-			} else if wLabelStyle.OutlineColor, err = decodeZeroableRgba(mLabelStyle.OutlineColor); err != nil {
-				return w, fmt.Errorf("labelStyle.outlineColor: %w", err)
+			if wLabelStyle.OutlineColor, err = lax.RGBA(decodeRgba, mLabelStyle.OutlineColor, "labelStyle.outlineColor"); err != nil {
+				return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 			}
 			w.Configuration.TextConfig.LabelStyles = append(w.Configuration.TextConfig.LabelStyles, wLabelStyle)
 		}
@@ -586,18 +471,19 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 				BbIterations:  mShapeStyle.BbIterations,
 				FillTexture:   mShapeStyle.FillTexture,
 				StrokeTexture: mShapeStyle.StrokeTexture,
+				Extras:        extras(mShapeStyle.ExtraAttrs, mShapeStyle.ExtraElements),
 			}
-			if wShapeStyle.StrokePaint, err = decodeRgba(mShapeStyle.StrokePaint); err != nil {
-				return w, fmt.Errorf("shapeStyle.strokePaint: %w", err)
+			if wShapeStyle.StrokePaint, err = lax.RGBA(decodeRgba, mShapeStyle.StrokePaint, "shapeStyle.strokePaint"); err != nil {
+				return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 			}
-			if wShapeStyle.FillPaint, err = decodeRgba(mShapeStyle.FillPaint); err != nil {
-				return w, fmt.Errorf("shapeStyle.fillPaint: %w", err)
+			if wShapeStyle.FillPaint, err = lax.RGBA(decodeRgba, mShapeStyle.FillPaint, "shapeStyle.fillPaint"); err != nil {
+				return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 			}
-			if wShapeStyle.DsColor, err = decodeRgba(mShapeStyle.Dscolor); err != nil {
-				return w, fmt.Errorf("shapeStyle.dsColor: %w", err)
+			if wShapeStyle.DsColor, err = lax.RGBA(decodeRgba, mShapeStyle.Dscolor, "shapeStyle.dsColor"); err != nil {
+				return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 			}
-			if wShapeStyle.InsColor, err = decodeRgba(mShapeStyle.InsColor); err != nil {
-				return w, fmt.Errorf("shapeStyle.insColor: %w", err)
+			if wShapeStyle.InsColor, err = lax.RGBA(decodeRgba, mShapeStyle.InsColor, "shapeStyle.insColor"); err != nil {
+				return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 			}
 			w.Configuration.ShapeConfig.ShapeStyles = append(w.Configuration.ShapeConfig.ShapeStyles, wShapeStyle)
 		}
@@ -605,3 +491,256 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 
 	return w, nil
 }
+
+// decodeInformationDetails copies the <information> children of an entry, and
+// theirs.
+func decodeInformationDetails(src []Information_t) []*wxx.InformationDetail_t {
+	var details []*wxx.InformationDetail_t
+	for _, detail := range src {
+		details = append(details, &wxx.InformationDetail_t{
+			Uuid:         detail.Uuid,
+			Type:         detail.Type,
+			Title:        detail.Title,
+			Rulers:       detail.Rulers,
+			Government:   detail.Government,
+			Cultures:     detail.Cultures,
+			Language:     detail.Language,
+			ReligionType: detail.ReligionType,
+			Culture:      detail.Culture,
+			HolySymbol:   detail.HolySymbol,
+			Domains:      detail.Domains,
+			Details:      decodeInformationDetails(detail.Details),
+			InnerText:    detail.InnerText,
+			IsCDATA:      xmlstream.StartsWithCDATA(detail.InnerXML),
+		})
+	}
+	return details
+}
+
+// decodeTiles parses the <tiles>/<tilerow> data into the domain map. The map key
+// is materialized after the rows, when there are any. This decoder used to
+// materialize it after every row, to the same result; once is what keeps a
+// lenient decode from reporting a bad map key color once per row.
+func decodeTiles(src Tiles_t, mapKeySrc MapKey_t, w *wxx.Map_t, lax *xmlstream.Lenient) error {
+	if err := (xmlstream.Limits{}).CheckTiles(src.TilesWide, src.TilesHigh); err != nil {
+		return xmlstream.Locate(err, "map/tiles", 0, 0)
+	}
+	decodeTilesHeader(src, w)
+	for _, tilerow := range src.TileRows {
+		if err := decodeTileRow(tilerow.InnerText, w, lax); err != nil {
+			return xmlstream.Locate(err, fmt.Sprintf("map/tiles/tilerow[%d]", tileColumns(w.Tiles)), 0, 0)
+		}
+	}
+	if tileColumns(w.Tiles) != 0 {
+		if err := decodeMapKey(mapKeySrc, w, lax.In("map/mapkey")); err != nil {
+			return xmlstream.Locate(err, "map/mapkey", 0, 0)
+		}
+	}
+	return nil
+}
+
+// decodeTilesHeader copies the <tiles> attributes into a new, empty w.Tiles and
+// sets RowsHigh and ColumnsWide from them. The orientation must already be set.
+func decodeTilesHeader(src Tiles_t, w *wxx.Map_t) {
+	w.Tiles = &wxx.Tiles_t{
+		ViewLevel:   src.ViewLevel,
+		TilesWide:   src.TilesWide,
+		TilesHigh:   src.TilesHigh,
+		Orientation: w.GridOrientation,
+		Extras:      extras(src.ExtraAttrs, src.ExtraElements),
+	}
+
+	// Set RowsHigh and ColumnsWide based on GridOrientation
+	switch w.GridOrientation {
+	case hexg.OddQ:
+		// Column orientation: TilesWide = columns, TilesHigh = rows
+		w.RowsHigh = w.Tiles.TilesHigh
+		w.ColumnsWide = w.Tiles.TilesWide
+	case hexg.OddR:
+		// Row orientation: TilesWide = rows, TilesHigh = columns
+		w.RowsHigh = w.Tiles.TilesWide
+		w.ColumnsWide = w.Tiles.TilesHigh
+	}
+}
+
+// decodeTileRow parses the text of one <tilerow> and appends it to w.Tiles as
+// the next row. Each row is parsed on its own so the streaming decoder can drop
+// the text once it has been read.
+//
+// An error is an *xmlstream.Error naming the row, the line, and the field that
+// failed; the caller knows where the row sits in the document and adds that with
+// xmlstream.Locate. A lenient decode (non-nil lax) replaces a bad value and
+// records a warning instead, except for a line with the wrong number of fields,
+// which cannot be lined up with the fields it was meant to have.
+func decodeTileRow(text string, w *wxx.Map_t, lax *xmlstream.Lenient) error {
+	row, err := parseTileRow(text, tileColumns(w.Tiles), w.Tiles.TilesHigh, lax)
+	w.Tiles.AddColumn(row)
+	return err
+}
+
+// tileColumns returns the number of <tilerow> elements decoded into tiles.
+func tileColumns(tiles *wxx.Tiles_t) int {
+	wide, _ := tiles.Size()
+	return wide
+}
+
+// parseTileRow parses the text of the <tilerow> at index x into the tiles of
+// its lines, which wxx.Tiles_t.AddColumn pads to tilesHigh. It reads nothing
+// but its arguments and writes nothing but lax, so the streaming decoder can
+// parse rows on separate goroutines, each with a Lenient of its own. A row with
+// more than tilesHigh lines is wxx.ErrInvalidTileGrid. A row that fails is
+// returned as far as it got, which is the partial row decodeTileRow appends.
+// The tiles' positions are left for wxx.Tiles_t to work out when the row is
+// added to the grid.
+func parseTileRow(text string, x, tilesHigh int, lax *xmlstream.Lenient) ([]wxx.Tile_t, error) {
+	var err error
+	y := 0
+	lines := strings.Split(text, "\n")
+	// the row grows with the lines the text holds, never past tilesHigh, so a
+	// file cannot make it allocate more tiles than it writes
+	row := make([]wxx.Tile_t, 0, min(tilesHigh, len(lines)))
+	for i, line := range lines {
+		if len(line) == 0 { // ignore blank lines
+			continue
+		}
+		if y >= tilesHigh {
+			return row, &xmlstream.Error{Path: fmt.Sprintf("map/tiles/tilerow[%d]", x+1), Line: y + 1, TextLine: i + 1, Err: fmt.Errorf("%w: more than %d tiles in the row", wxx.ErrInvalidTileGrid, tilesHigh)}
+		}
+		row = append(row, wxx.Tile_t{})
+		t := &row[y]
+		y++
+		// fail reports a bad value on this line; repair lets a lenient decode
+		// carry on with the replacement described by action instead
+		fail := func(field string, err error) error {
+			return &xmlstream.Error{Path: fmt.Sprintf("map/tiles/tilerow[%d]", x+1), Line: y, TextLine: i + 1, Field: field, Err: err}
+		}
+		repair := func(field, value, action string, err error) error {
+			return lax.Repair(fail(field, err), value, action)
+		}
+		// values are TerrainMapIndex Elevation IsIcy IsGMOnly Animals (Z|(Brick Crops Gems Lumber Metals Rock)) RGBA?
+		values := strings.Split(line, "\t")
+		switch len(values) {
+		case 6, 7, 11, 12: // allowed
+		default:
+			return row, fail("", fmt.Errorf("%w: expected 6/7/11/12, got %d", wxx.ErrInvalidTileFieldCount, len(values)))
+		}
+		if t.Terrain, err = strconv.Atoi(values[0]); err != nil {
+			t.Terrain = 0
+			if err := repair("terrainType", values[0], "set to 0", fmt.Errorf("%w: %w", wxx.ErrInvalidTileValue, err)); err != nil {
+				return row, err
+			}
+		}
+		if t.Elevation, err = strconv.ParseFloat(values[1], 64); err != nil {
+			t.Elevation = 0
+			if err := repair("elevation", values[1], "set to 0", fmt.Errorf("%w: %w", wxx.ErrInvalidTileValue, err)); err != nil {
+				return row, err
+			}
+		}
+		t.IsIcy = values[2] == "1"
+		t.IsGMOnly = values[3] == "1"
+		// resource parses the value at index n, which a lenient decode clamps
+		resource := func(field string, n int) (int, error) {
+			v, err := decodeTileResource(values[n])
+			if err != nil {
+				return v, repair(field, values[n], fmt.Sprintf("set to %d", v), err)
+			}
+			return v, nil
+		}
+		if t.Resources.Animal, err = resource("animals", 4); err != nil {
+			return row, err
+		}
+		compressedResources := len(values) == 6 || len(values) == 7
+		if compressedResources {
+			// a with compressed resources should flag them with a Z
+			if values[5] != "Z" {
+				if err := repair("sentinel", values[5], "read as Z", fmt.Errorf("%w: %q is not Z", wxx.ErrInvalidTileValue, values[5])); err != nil {
+					return row, err
+				}
+			}
+		} else {
+			if t.Resources.Brick, err = resource("brick", 5); err != nil {
+				return row, err
+			}
+			if t.Resources.Crops, err = resource("crops", 6); err != nil {
+				return row, err
+			}
+			if t.Resources.Gems, err = resource("gems", 7); err != nil {
+				return row, err
+			}
+			if t.Resources.Lumber, err = resource("lumber", 8); err != nil {
+				return row, err
+			}
+			if t.Resources.Metals, err = resource("metals", 9); err != nil {
+				return row, err
+			}
+			if t.Resources.Rock, err = resource("rock", 10); err != nil {
+				return row, err
+			}
+		}
+		if len(values) == 7 || len(values) == 12 {
+			// split rgba
+			if t.CustomBackgroundColor, err = decodeRgba(values[len(values)-1]); err != nil {
+				t.CustomBackgroundColor = nil
+				if err := repair("rgba", values[len(values)-1], "dropped", err); err != nil {
+					return row, err
+				}
+			}
+		}
+	}
+	return row, nil
+}
+
+// decodeTileResource parses one resource value from a tile line. Resources are
+// integers in the range 0...100. On error the value returned is the one a
+// lenient decode uses: the nearest end of the range for a number outside it,
+// and 0 for anything that is not a number.
+func decodeTileResource(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("%w: %w", wxx.ErrInvalidTileValue, err)
+	} else if n < 0 {
+		return 0, fmt.Errorf("%w: %s is not in 0...100", wxx.ErrInvalidTileValue, s)
+	} else if n > 100 {
+		return 100, fmt.Errorf("%w: %s is not in 0...100", wxx.ErrInvalidTileValue, s)
+	}
+	return n, nil
+}
+
+// decodeMapKey copies the <mapkey> attributes into the domain map.
+func decodeMapKey(src MapKey_t, w *wxx.Map_t, lax *xmlstream.Lenient) error {
+	var err error
+	w.MapKey = &wxx.MapKey_t{
+		PositionX: src.PositionX,
+		PositionY: src.PositionY,
+		Viewlevel: src.Viewlevel,
+		Height:    src.Height,
+	}
+	if w.MapKey.BackgroundColor, err = lax.RGBA(decodeRgba, src.BackgroundColor, "mapkey.backgroundcolor"); err != nil {
+		return err
+	}
+	w.MapKey.BackgroundOpacity = src.BackgroundOpacity
+	w.MapKey.TitleText = src.TitleText
+	w.MapKey.TitleFontFace = src.TitleFontFace
+	if w.MapKey.TitleFontColor, err = lax.RGBA(decodeRgba, src.TitleFontColor, "mapkey.titleFontColor"); err != nil {
+		return err
+	}
+	w.MapKey.TitleFontBold = src.TitleFontBold
+	w.MapKey.TitleFontItalic = src.TitleFontItalic
+	w.MapKey.TitleScale = src.TitleScale
+	w.MapKey.ScaleText = src.ScaleText
+	w.MapKey.ScaleFontFace = src.ScaleFontFace
+	if w.MapKey.ScaleFontColor, err = lax.RGBA(decodeRgba, src.ScaleFontColor, "mapkey.scaleFontColor"); err != nil {
+		return err
+	}
+	w.MapKey.ScaleFontBold = src.ScaleFontBold
+	w.MapKey.ScaleFontItalic = src.ScaleFontItalic
+	w.MapKey.ScaleScale = src.ScaleScale
+	w.MapKey.EntryFontFace = src.EntryFontFace
+	if w.MapKey.EntryFontColor, err = lax.RGBA(decodeRgba, src.EntryFontColor, "mapkey.entryFontColor"); err != nil {
+		return err
+	}
+	w.MapKey.EntryFontBold = src.EntryFontBold
+	w.MapKey.EntryFontItalic = src.EntryFontItalic
+	w.MapKey.EntryScale = src.EntryScale
+	return nil
+}
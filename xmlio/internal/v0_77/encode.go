@@ -4,13 +4,15 @@ package v0_77
 
 import (
 	"bytes"
+	"context"
 	"fmt"
-	"html"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/maloquacious/wxx"
 	"github.com/maloquacious/wxx/xmlio/internal/appver"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // Encode the Map_t into a slice of UTF-8 bytes that matches this version's XML schema.
@@ -37,6 +39,12 @@ import (
 // Note: the style of this code is intentionally verbose to make it easier to find changes between
 // versions of the Worldographer files.
 func Encode(w *wxx.Map_t, app string) ([]byte, error) {
+	return encode(context.Background(), w, app, 1)
+}
+
+// encode is Encode, writing <tilerow> elements on up to workers goroutines. See
+// encodeTiles.
+func encode(ctx context.Context, w *wxx.Map_t, app string, workers int) ([]byte, error) {
 	target, ok := acceptedApps.App(app)
 	if !ok {
 		// VerifyApp is what names the accepted set in the error text; App reports
@@ -44,7 +52,7 @@ func Encode(w *wxx.Map_t, app string) ([]byte, error) {
 		return nil, acceptedApps.VerifyApp(app)
 	}
 	wb := &bytes.Buffer{}
-	if err := encodeMap(w, target, wb); err != nil {
+	if err := encodeMap(ctx, w, target, workers, wb); err != nil {
 		return nil, err
 	}
 	return wb.Bytes(), nil
@@ -67,39 +75,41 @@ func Encode(w *wxx.Map_t, app string) ([]byte, error) {
 // it by string equality, so the two are the same string. It is never re-rendered
 // from a parsed version's components, because "2.06" must never reach disk as
 // "2.6" (ADR 0004 Decision 1).
-func encodeMap(w *wxx.Map_t, target appver.App_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<map"))
-	wb.WriteString(fmt.Sprintf(" type=%q", w.Type))
-	wb.WriteString(fmt.Sprintf(" version=%q", target.Version))
-	wb.WriteString(fmt.Sprintf(" lastViewLevel=%q", w.LastViewLevel))
-	wb.WriteString(fmt.Sprintf(" continentFactor=%q", ints(w.ContinentFactor)))
-	wb.WriteString(fmt.Sprintf(" kingdomFactor=%q", ints(w.KingdomFactor)))
-	wb.WriteString(fmt.Sprintf(" provinceFactor=%q", ints(w.ProvinceFactor)))
-	wb.WriteString(fmt.Sprintf(" worldToContinentHOffset=%q", floats(w.WorldToContinentHOffset)))
-	wb.WriteString(fmt.Sprintf(" continentToKingdomHOffset=%q", floats(w.ContinentToKingdomHOffset)))
-	wb.WriteString(fmt.Sprintf(" kingdomToProvinceHOffset=%q", floats(w.KingdomToProvinceHOffset)))
-	wb.WriteString(fmt.Sprintf(" worldToContinentVOffset=%q", floats(w.WorldToContinentVOffset)))
-	wb.WriteString(fmt.Sprintf(" continentToKingdomVOffset=%q", floats(w.ContinentToKingdomVOffset)))
-	wb.WriteString(fmt.Sprintf(" kingdomToProvinceVOffset=%q \n", floats(w.KingdomToProvinceVOffset)))
-	wb.WriteString(fmt.Sprintf("hexWidth=%q", floats(w.HexWidth)))
-	wb.WriteString(fmt.Sprintf(" hexHeight=%q", floats(w.HexHeight)))
-	wb.WriteString(fmt.Sprintf(" hexOrientation=%q", w.HexOrientation))
+func encodeMap(ctx context.Context, w *wxx.Map_t, target appver.App_t, workers int, wb *bytes.Buffer) error {
+	wb.WriteString("<map")
+	writeAttr(wb, " type=", w.Type)
+	writeAttr(wb, " version=", target.Version)
+	writeAttr(wb, " lastViewLevel=", w.LastViewLevel)
+	writeIntAttr(wb, " continentFactor=", w.ContinentFactor)
+	writeIntAttr(wb, " kingdomFactor=", w.KingdomFactor)
+	writeIntAttr(wb, " provinceFactor=", w.ProvinceFactor)
+	writeFloatAttr(wb, " worldToContinentHOffset=", w.WorldToContinentHOffset)
+	writeFloatAttr(wb, " continentToKingdomHOffset=", w.ContinentToKingdomHOffset)
+	writeFloatAttr(wb, " kingdomToProvinceHOffset=", w.KingdomToProvinceHOffset)
+	writeFloatAttr(wb, " worldToContinentVOffset=", w.WorldToContinentVOffset)
+	writeFloatAttr(wb, " continentToKingdomVOffset=", w.ContinentToKingdomVOffset)
+	writeFloatAttr(wb, " kingdomToProvinceVOffset=", w.KingdomToProvinceVOffset)
+	wb.WriteString(" \n")
+	writeFloatAttr(wb, "hexWidth=", w.HexWidth)
+	writeFloatAttr(wb, " hexHeight=", w.HexHeight)
+	writeAttr(wb, " hexOrientation=", w.HexOrientation)
 	if w.MapProjection == wxx.FLAT {
-		wb.WriteString(fmt.Sprintf(" mapProjection=%q", "FLAT"))
+		writeAttr(wb, " mapProjection=", "FLAT")
 	} else if w.MapProjection == wxx.ICOSAHEDRAL {
-		wb.WriteString(fmt.Sprintf(" mapProjection=%q", "ICOSAHEDRAL"))
+		writeAttr(wb, " mapProjection=", "ICOSAHEDRAL")
 	} else {
 		return fmt.Errorf("assert(map.projection != %q)", w.MapProjection)
 	}
-	wb.WriteString(fmt.Sprintf(" showNotes=%q", bools(w.ShowNotes)))
-	wb.WriteString(fmt.Sprintf(" showGMOnly=%q", bools(w.ShowGMOnly)))
-	wb.WriteString(fmt.Sprintf(" showGMOnlyGlow=%q", bools(w.ShowGMOnlyGlow)))
-	wb.WriteString(fmt.Sprintf(" showFeatureLabels=%q", bools(w.ShowFeatureLabels)))
-	wb.WriteString(fmt.Sprintf(" showGrid=%q", bools(w.ShowGrid)))
-	wb.WriteString(fmt.Sprintf(" showGridNumbers=%q", bools(w.ShowGridNumbers)))
-	wb.WriteString(fmt.Sprintf(" showShadows=%q", bools(w.ShowShadows)))
-	wb.WriteString(fmt.Sprintf("  triangleSize=%q", ints(w.TriangleSize)))
-	wb.WriteString(fmt.Sprintf(">\n"))
+	writeBoolAttr(wb, " showNotes=", w.ShowNotes)
+	writeBoolAttr(wb, " showGMOnly=", w.ShowGMOnly)
+	writeBoolAttr(wb, " showGMOnlyGlow=", w.ShowGMOnlyGlow)
+	writeBoolAttr(wb, " showFeatureLabels=", w.ShowFeatureLabels)
+	writeBoolAttr(wb, " showGrid=", w.ShowGrid)
+	writeBoolAttr(wb, " showGridNumbers=", w.ShowGridNumbers)
+	writeBoolAttr(wb, " showShadows=", w.ShowShadows)
+	writeIntAttr(wb, "  triangleSize=", w.TriangleSize)
+	wb.WriteString(extraAttrs(w.Extras))
+	wb.WriteString(">\n")
 
 	if err := encodeGridAndNumbering(w.GridAndNumbering, wb); err != nil {
 		return err
@@ -113,7 +123,7 @@ func encodeMap(w *wxx.Map_t, target appver.App_t, wb *bytes.Buffer) error {
 		return err
 	}
 
-	if err := encodeTiles(w.Tiles, w.HexOrientation, wb); err != nil {
+	if err := encodeTiles(ctx, w.Tiles, w.HexOrientation, workers, wb); err != nil {
 		return err
 	}
 
@@ -145,58 +155,63 @@ func encodeMap(w *wxx.Map_t, target appver.App_t, wb *bytes.Buffer) error {
 		return err
 	}
 
+	if children := extraElements(w.Extras); children != "" {
+		wb.WriteString(children + "\n")
+	}
 	wb.WriteString("</map>\n")
 
 	return nil
 }
 
 func encodeGridAndNumbering(gridAndNumbering *wxx.GridAndNumbering_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf(`<gridandnumbering`))
-	wb.WriteString(fmt.Sprintf(" color0=%q", gridAndNumbering.Color0))
-	wb.WriteString(fmt.Sprintf(" color1=%q", gridAndNumbering.Color1))
-	wb.WriteString(fmt.Sprintf(" color2=%q", gridAndNumbering.Color2))
-	wb.WriteString(fmt.Sprintf(" color3=%q", gridAndNumbering.Color3))
-	wb.WriteString(fmt.Sprintf(" color4=%q", gridAndNumbering.Color4))
-	wb.WriteString(fmt.Sprintf(" width0=%q", floats(gridAndNumbering.Width0)))
-	wb.WriteString(fmt.Sprintf(" width1=%q", floats(gridAndNumbering.Width1)))
-	wb.WriteString(fmt.Sprintf(" width2=%q", floats(gridAndNumbering.Width2)))
-	wb.WriteString(fmt.Sprintf(" width3=%q", floats(gridAndNumbering.Width3)))
-	wb.WriteString(fmt.Sprintf(" width4=%q", floats(gridAndNumbering.Width4)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetContinentKingdomX=%q", floats(gridAndNumbering.GridOffsetContinentKingdomX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetContinentKingdomY=%q", floats(gridAndNumbering.GridOffsetContinentKingdomY)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldContinentX=%q", floats(gridAndNumbering.GridOffsetWorldContinentX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldContinentY=%q", floats(gridAndNumbering.GridOffsetWorldContinentY)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldKingdomX=%q", floats(gridAndNumbering.GridOffsetWorldKingdomX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldKingdomY=%q", floats(gridAndNumbering.GridOffsetWorldKingdomY)))
-	wb.WriteString(fmt.Sprintf(" gridSquare=%q", ints(gridAndNumbering.GridSquare)))
-	wb.WriteString(fmt.Sprintf(" gridSquareHeight=%q", floats(gridAndNumbering.GridSquareHeight)))
-	wb.WriteString(fmt.Sprintf(" gridSquareWidth=%q", floats(gridAndNumbering.GridSquareWidth)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetX=%q", floats(gridAndNumbering.GridOffsetX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetY=%q", floats(gridAndNumbering.GridOffsetY)))
-	wb.WriteString(fmt.Sprintf(" numberFont=%q", gridAndNumbering.NumberFont))
-	wb.WriteString(fmt.Sprintf(" numberColor=%q", gridAndNumbering.NumberColor))
-	wb.WriteString(fmt.Sprintf(" numberSize=%q", ints(gridAndNumbering.NumberSize)))
-	wb.WriteString(fmt.Sprintf(" numberStyle=%q", gridAndNumbering.NumberStyle))
-	wb.WriteString(fmt.Sprintf(" numberFirstCol=%q", ints(gridAndNumbering.NumberFirstCol)))
-	wb.WriteString(fmt.Sprintf(" numberFirstRow=%q", ints(gridAndNumbering.NumberFirstRow)))
-	wb.WriteString(fmt.Sprintf(" numberOrder=%q", gridAndNumbering.NumberOrder))
-	wb.WriteString(fmt.Sprintf(" numberPosition=%q", gridAndNumbering.NumberPosition))
-	wb.WriteString(fmt.Sprintf(" numberPrePad=%q", gridAndNumbering.NumberPrePad))
-	wb.WriteString(fmt.Sprintf(" numberSeparator=%q", gridAndNumbering.NumberSeparator))
-	wb.WriteString(fmt.Sprintf(" />\n"))
+	wb.WriteString(`<gridandnumbering`)
+	writeAttr(wb, " color0=", gridAndNumbering.Color0)
+	writeAttr(wb, " color1=", gridAndNumbering.Color1)
+	writeAttr(wb, " color2=", gridAndNumbering.Color2)
+	writeAttr(wb, " color3=", gridAndNumbering.Color3)
+	writeAttr(wb, " color4=", gridAndNumbering.Color4)
+	writeFloatAttr(wb, " width0=", gridAndNumbering.Width0)
+	writeFloatAttr(wb, " width1=", gridAndNumbering.Width1)
+	writeFloatAttr(wb, " width2=", gridAndNumbering.Width2)
+	writeFloatAttr(wb, " width3=", gridAndNumbering.Width3)
+	writeFloatAttr(wb, " width4=", gridAndNumbering.Width4)
+	writeFloatAttr(wb, " gridOffsetContinentKingdomX=", gridAndNumbering.GridOffsetContinentKingdomX)
+	writeFloatAttr(wb, " gridOffsetContinentKingdomY=", gridAndNumbering.GridOffsetContinentKingdomY)
+	writeFloatAttr(wb, " gridOffsetWorldContinentX=", gridAndNumbering.GridOffsetWorldContinentX)
+	writeFloatAttr(wb, " gridOffsetWorldContinentY=", gridAndNumbering.GridOffsetWorldContinentY)
+	writeFloatAttr(wb, " gridOffsetWorldKingdomX=", gridAndNumbering.GridOffsetWorldKingdomX)
+	writeFloatAttr(wb, " gridOffsetWorldKingdomY=", gridAndNumbering.GridOffsetWorldKingdomY)
+	writeIntAttr(wb, " gridSquare=", gridAndNumbering.GridSquare)
+	writeFloatAttr(wb, " gridSquareHeight=", gridAndNumbering.GridSquareHeight)
+	writeFloatAttr(wb, " gridSquareWidth=", gridAndNumbering.GridSquareWidth)
+	writeFloatAttr(wb, " gridOffsetX=", gridAndNumbering.GridOffsetX)
+	writeFloatAttr(wb, " gridOffsetY=", gridAndNumbering.GridOffsetY)
+	writeAttr(wb, " numberFont=", gridAndNumbering.NumberFont)
+	writeAttr(wb, " numberColor=", gridAndNumbering.NumberColor)
+	writeIntAttr(wb, " numberSize=", gridAndNumbering.NumberSize)
+	writeAttr(wb, " numberStyle=", gridAndNumbering.NumberStyle)
+	writeIntAttr(wb, " numberFirstCol=", gridAndNumbering.NumberFirstCol)
+	writeIntAttr(wb, " numberFirstRow=", gridAndNumbering.NumberFirstRow)
+	writeAttr(wb, " numberOrder=", gridAndNumbering.NumberOrder)
+	writeAttr(wb, " numberPosition=", gridAndNumbering.NumberPosition)
+	writeAttr(wb, " numberPrePad=", gridAndNumbering.NumberPrePad)
+	writeAttr(wb, " numberSeparator=", gridAndNumbering.NumberSeparator)
+	wb.WriteString(extraAttrs(gridAndNumbering.Extras))
+	wb.WriteString(endEmpty("gridandnumbering", " />", gridAndNumbering.Extras) + "\n")
 	return nil
 }
 
 func encodeTerrainMap(terrainMap *wxx.TerrainMap_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<terrainmap>"))
+	wb.WriteString("<terrainmap>")
 	for k, v := range terrainMapToSlice(terrainMap.Data) {
-		if k == 0 {
-			wb.WriteString(fmt.Sprintf("%s\t%d", v, k))
-		} else {
-			wb.WriteString(fmt.Sprintf("\t%s\t%d", v, k))
+		if k != 0 {
+			wb.WriteByte('\t')
 		}
+		writeText(wb, v)
+		wb.WriteByte('\t')
+		writeInt(wb, k)
 	}
-	wb.WriteString(fmt.Sprintf("</terrainmap>\n"))
+	wb.WriteString("</terrainmap>\n")
 	return nil
 }
 
@@ -212,42 +227,116 @@ func encodeMapLayers(mapLayers []*wxx.MapLayer_t, wb *bytes.Buffer) error {
 
 func encodeMapLayer(mapLayer *wxx.MapLayer_t, wb *bytes.Buffer) error {
 	wb.WriteString("<maplayer")
-	wb.WriteString(fmt.Sprintf(" name=%q", mapLayer.Name))
-	wb.WriteString(fmt.Sprintf(" isVisible=%q", bools(mapLayer.IsVisible)))
-	wb.WriteString("/>\n")
+	writeAttr(wb, " name=", mapLayer.Name)
+	writeBoolAttr(wb, " isVisible=", mapLayer.IsVisible)
+	wb.WriteString(extraAttrs(mapLayer.Extras))
+	wb.WriteString(endEmpty("maplayer", "/>", mapLayer.Extras) + "\n")
 	return nil
 }
 
-func encodeTiles(tiles *wxx.Tiles_t, hexOrientation string, wb *bytes.Buffer) error {
+func encodeTiles(ctx context.Context, tiles *wxx.Tiles_t, hexOrientation string, workers int, wb *bytes.Buffer) error {
 	// to: width is the number of columns, height is the number of rows. does that depend on the orientation?
-	wb.WriteString(fmt.Sprintf("<tiles"))
-	wb.WriteString(fmt.Sprintf(" viewLevel=%q", tiles.ViewLevel))
-	wb.WriteString(fmt.Sprintf(" tilesWide=%q", ints(tiles.TilesWide)))
-	wb.WriteString(fmt.Sprintf(" tilesHigh=%q", ints(tiles.TilesHigh)))
-	wb.WriteString(fmt.Sprintf(">\n"))
+	wb.WriteString("<tiles")
+	writeAttr(wb, " viewLevel=", tiles.ViewLevel)
+	writeIntAttr(wb, " tilesWide=", tiles.TilesWide)
+	writeIntAttr(wb, " tilesHigh=", tiles.TilesHigh)
+	wb.WriteString(extraAttrs(tiles.Extras))
+	wb.WriteString(">\n")
 
 	// generate the tile-row elements:
 	// * each tile-row will have a tile.tilesHigh lines of tab delimited data
 	// * each line of data has the following values: Terrain type, elevation, is it icy, is it GM only, and its resources
 	// * terrainType is an index into the terrainmap element
 	// * resources are Animals, Brick, Crops, Gems, Lumber, Metals, and Rock, in that order, but are "compressed"
-	if hexOrientation == "COLUMNS" {
-		for x := 0; x < tiles.TilesWide; x++ {
-			wb.WriteString("<tilerow>\n")
-			for y := 0; y < tiles.TilesHigh; y++ {
-				tile := tiles.Tiles[x][y]
-				if err := encodeTile(tile, wb); err != nil {
-					return err
-				}
-			}
-			wb.WriteString(fmt.Sprintf("</tilerow>\n"))
+	//
+	// ROWS maps are written by the same loop. The decoder keeps the grid in the
+	// order the file lays it out, Tiles[tilerow][line], whatever the orientation,
+	// so the transposition a ROWS map needs (each <tilerow> is a row of hexes, not
+	// a column) already happened when the tiles were read: decodeTilesHeader swaps
+	// RowsHigh and ColumnsWide and decodeTileRow builds OddR coordinates from
+	// (line, tilerow). Writing Tiles[x][y] back out in file order undoes exactly
+	// that. Transposing here as well would swap the map on every round trip.
+	if hexOrientation == "COLUMNS" || hexOrientation == "ROWS" {
+		if err := encodeTileRows(ctx, tiles, workers, wb); err != nil {
+			return err
 		}
-	} else if hexOrientation == "ROWS" {
-		return fmt.Errorf("assert(orientation != %q)", hexOrientation)
 	} else {
 		return fmt.Errorf("assert(orientation != %q)", hexOrientation)
 	}
-	wb.WriteString(fmt.Sprintf("</tiles>\n"))
+	wb.WriteString(extraElements(tiles.Extras))
+	wb.WriteString("</tiles>\n")
+	return nil
+}
+
+// encodeTileRows writes the <tilerow> elements, one per column of tiles.
+//
+// With more than one worker each row is written to a buffer of its own on up to
+// workers goroutines, and the buffers are copied to wb in order, so the bytes are
+// the same as a sequential encode's. Rows are independent of each other -- a
+// <tilerow> is a function of its own tiles and nothing else -- which is what lets
+// a large map's tiles, most of its file, be written in parallel.
+func encodeTileRows(ctx context.Context, tiles *wxx.Tiles_t, workers int, wb *bytes.Buffer) error {
+	if wide, high := tiles.Size(); wide < tiles.TilesWide || high < tiles.TilesHigh {
+		return fmt.Errorf("%w: %d x %d tiles for a %d x %d map", wxx.ErrInvalidTileGrid, wide, high, tiles.TilesWide, tiles.TilesHigh)
+	}
+	wb.Grow(tiles.TilesWide * tileRowSize(tiles.TilesHigh))
+	if workers == 1 {
+		var row []wxx.Tile_t
+		for x := 0; x < tiles.TilesWide; x++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			row = tiles.ColumnTiles(row[:0], x)
+			if err := encodeTileRow(row, tiles.TilesHigh, wb); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	type encodedRow struct {
+		buf *bytes.Buffer
+		err error
+	}
+	rows := xmlstream.NewOrdered(workers, func(r encodedRow) error {
+		if r.err != nil {
+			return r.err
+		}
+		_, err := wb.Write(r.buf.Bytes())
+		return err
+	})
+	for x := 0; x < tiles.TilesWide; x++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := rows.Submit(func() encodedRow {
+			buf := bytes.NewBuffer(make([]byte, 0, tileRowSize(tiles.TilesHigh)))
+			return encodedRow{buf: buf, err: encodeTileRow(tiles.ColumnTiles(nil, x), tiles.TilesHigh, buf)}
+		}); err != nil {
+			return err
+		}
+	}
+	return rows.Wait()
+}
+
+// tileLineSize is the length of a typical tile line, "1\t0\t0\t0\t0\tZ\n" with
+// room for larger values, used to size buffers before the tiles are written.
+const tileLineSize = 16
+
+// tileRowSize estimates the length of a <tilerow> holding tilesHigh tiles.
+func tileRowSize(tilesHigh int) int {
+	return len("<tilerow>\n</tilerow>\n") + tilesHigh*tileLineSize
+}
+
+// encodeTileRow writes one <tilerow> element holding the first tilesHigh tiles
+// of row.
+func encodeTileRow(row []wxx.Tile_t, tilesHigh int, wb *bytes.Buffer) error {
+	wb.WriteString("<tilerow>\n")
+	for y := 0; y < tilesHigh; y++ {
+		if err := encodeTile(&row[y], wb); err != nil {
+			return err
+		}
+	}
+	wb.WriteString("</tilerow>\n")
 	return nil
 }
 
@@ -259,43 +348,73 @@ func encodeTiles(tiles *wxx.Tiles_t, hexOrientation string, wb *bytes.Buffer) er
 // * field after resource.animal is "Z" if remaining resources are all 0
 // * otherwise we have brick, crops, gems, lumber, metals, rock
 // * customBackgroundColor is an RGBA that is optional
+//
+// The line is built in wb's spare capacity with the strconv append functions
+// and written with a single Write, so encoding a tile allocates nothing once wb
+// has grown to hold the row. This is most of the work of encoding a large map.
 func encodeTile(tile *wxx.Tile_t, wb *bytes.Buffer) error {
-	// todo: implement this
-	wb.WriteString(fmt.Sprintf("%d", tile.Terrain))
-	wb.WriteString(fmt.Sprintf("\t%d", floatd(tile.Elevation)))
-	wb.WriteString(fmt.Sprintf("\t%d", boold(tile.IsIcy)))
-	wb.WriteString(fmt.Sprintf("\t%d", boold(tile.IsGMOnly)))
-	if err := encodeTileResources(tile.Resources, wb); err != nil {
-		return err
-	}
+	b := wb.AvailableBuffer()
+	b = strconv.AppendInt(b, int64(tile.Terrain), 10)
+	b = strconv.AppendInt(append(b, '\t'), int64(floatd(tile.Elevation)), 10)
+	b = strconv.AppendInt(append(b, '\t'), int64(boold(tile.IsIcy)), 10)
+	b = strconv.AppendInt(append(b, '\t'), int64(boold(tile.IsGMOnly)), 10)
+	b = appendTileResources(b, tile.Resources)
 	if tile.CustomBackgroundColor != nil {
-		wb.WriteString(fmt.Sprintf("\t%s", rgbas(tile.CustomBackgroundColor)))
+		b = appendRgbas(append(b, '\t'), tile.CustomBackgroundColor)
 	}
-	wb.WriteString(fmt.Sprintf("\n"))
+	wb.Write(append(b, '\n'))
 	return nil
 }
 
-// all resources are supposed to be in the range of 0...100, but we don't enforce
-func encodeTileResources(resources wxx.Resources_t, wb *bytes.Buffer) error {
+// appendTileResources appends the resource fields of a tile line to b.
+// All resources are supposed to be in the range of 0...100, but we don't enforce
+func appendTileResources(b []byte, resources wxx.Resources_t) []byte {
+	b = strconv.AppendInt(append(b, '\t'), int64(resources.Animal), 10)
 	// compress if there are no resources other than Animal
 	if resources.Brick == 0 && resources.Crops == 0 && resources.Gems == 0 && resources.Lumber == 0 && resources.Metals == 0 && resources.Rock == 0 {
-		wb.WriteString(fmt.Sprintf("\t%d", resources.Animal))
-		wb.WriteString(fmt.Sprintf("\tZ"))
-		return nil
+		return append(b, "\tZ"...)
 	}
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Animal))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Brick))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Crops))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Gems))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Lumber))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Metals))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Rock))
-	return nil
+	for _, n := range [...]int{resources.Brick, resources.Crops, resources.Gems, resources.Lumber, resources.Metals, resources.Rock} {
+		b = strconv.AppendInt(append(b, '\t'), int64(n), 10)
+	}
+	return b
 }
 
+// defaultMapKey is the <mapkey> Worldographer writes for a new map. The encoder
+// writes it for a map with no map key, which is what decoding a map with no
+// tiles gives.
+const defaultMapKey = `<mapkey positionx="0.0" positiony="0.0" viewlevel="WORLD" height="-1" backgroundcolor="0.9803921580314636,0.9215686321258545,0.843137264251709,1.0" backgroundopacity="50" titleText="Map Key" titleFontFace="Arial"  titleFontColor="0.0,0.0,0.0,1.0" titleFontBold="true" titleFontItalic="false" titleScale="80" scaleText="1 Hex = ? units" scaleFontFace="Arial"  scaleFontColor="0.0,0.0,0.0,1.0" scaleFontBold="true" scaleFontItalic="false" scaleScale="65" entryFontFace="Arial"  entryFontColor="0.0,0.0,0.0,1.0" entryFontBold="true" entryFontItalic="false" entryScale="55"  >`
+
 func encodeMapKey(mapKey *wxx.MapKey_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf(`<mapkey positionx="0.0" positiony="0.0" viewlevel="WORLD" height="-1" backgroundcolor="0.9803921580314636,0.9215686321258545,0.843137264251709,1.0" backgroundopacity="50" titleText="Map Key" titleFontFace="Arial"  titleFontColor="0.0,0.0,0.0,1.0" titleFontBold="true" titleFontItalic="false" titleScale="80" scaleText="1 Hex = ? units" scaleFontFace="Arial"  scaleFontColor="0.0,0.0,0.0,1.0" scaleFontBold="true" scaleFontItalic="false" scaleScale="65" entryFontFace="Arial"  entryFontColor="0.0,0.0,0.0,1.0" entryFontBold="true" entryFontItalic="false" entryScale="55"  >`))
-	wb.WriteByte('\n')
+	if mapKey == nil {
+		wb.WriteString(defaultMapKey + "\n</mapkey>\n")
+		return nil
+	}
+	wb.WriteString("<mapkey")
+	writeFloatAttr(wb, " positionx=", mapKey.PositionX)
+	writeFloatAttr(wb, " positiony=", mapKey.PositionY)
+	writeAttr(wb, " viewlevel=", mapKey.Viewlevel)
+	writeFloatAttr(wb, " height=", mapKey.Height)
+	writeRgbaAttr(wb, " backgroundcolor=", mapKey.BackgroundColor)
+	writeFloatAttr(wb, " backgroundopacity=", mapKey.BackgroundOpacity)
+	writeAttr(wb, " titleText=", mapKey.TitleText)
+	writeAttr(wb, " titleFontFace=", mapKey.TitleFontFace)
+	writeRgbaAttr(wb, " titleFontColor=", mapKey.TitleFontColor)
+	writeBoolAttr(wb, " titleFontBold=", mapKey.TitleFontBold)
+	writeBoolAttr(wb, " titleFontItalic=", mapKey.TitleFontItalic)
+	writeFloatAttr(wb, " titleScale=", mapKey.TitleScale)
+	writeAttr(wb, " scaleText=", mapKey.ScaleText)
+	writeAttr(wb, " scaleFontFace=", mapKey.ScaleFontFace)
+	writeRgbaAttr(wb, " scaleFontColor=", mapKey.ScaleFontColor)
+	writeBoolAttr(wb, " scaleFontBold=", mapKey.ScaleFontBold)
+	writeBoolAttr(wb, " scaleFontItalic=", mapKey.ScaleFontItalic)
+	writeFloatAttr(wb, " scaleScale=", mapKey.ScaleScale)
+	writeAttr(wb, " entryFontFace=", mapKey.EntryFontFace)
+	writeRgbaAttr(wb, " entryFontColor=", mapKey.EntryFontColor)
+	writeBoolAttr(wb, " entryFontBold=", mapKey.EntryFontBold)
+	writeBoolAttr(wb, " entryFontItalic=", mapKey.EntryFontItalic)
+	writeFloatAttr(wb, " entryScale=", mapKey.EntryScale)
+	wb.WriteString(">\n")
 	wb.WriteString("</mapkey>\n")
 	return nil
 }
@@ -313,28 +432,29 @@ func encodeFeatures(features []*wxx.Feature_t, wb *bytes.Buffer) error {
 }
 
 func encodeFeature(feature *wxx.Feature_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<feature"))
-	wb.WriteString(fmt.Sprintf(" type=%q", feature.Type))
-	wb.WriteString(fmt.Sprintf(" rotate=%q", floats(feature.Rotate)))
-	wb.WriteString(fmt.Sprintf(" uuid=%q", feature.Uuid))
-	wb.WriteString(fmt.Sprintf(" mapLayer=%q", feature.MapLayer))
-	wb.WriteString(fmt.Sprintf(" isFlipHorizontal=%q", bools(feature.IsFlipHorizontal)))
-	wb.WriteString(fmt.Sprintf(" isFlipVertical=%q", bools(feature.IsFlipVertical)))
-	wb.WriteString(fmt.Sprintf(" scale=%q", floats(feature.Scale)))
-	wb.WriteString(fmt.Sprintf(" scaleHt=%q", floats(feature.ScaleHt)))
-	wb.WriteString(fmt.Sprintf(" tags=%q", feature.Tags))
-	wb.WriteString(fmt.Sprintf(" color=%q", rgbans(feature.Color))) // nullable
-	wb.WriteString(fmt.Sprintf(" ringcolor=%q", rgbans(feature.RingColor)))
-	wb.WriteString(fmt.Sprintf(" isGMOnly=%q", bools(feature.IsGMOnly)))
-	wb.WriteString(fmt.Sprintf(" isPlaceFreely=%q", bools(feature.IsPlaceFreely)))
-	wb.WriteString(fmt.Sprintf(" labelPosition=%q", feature.LabelPosition))
-	wb.WriteString(fmt.Sprintf(" labelDistance=%q", ints(feature.LabelDistance)))
-	wb.WriteString(fmt.Sprintf(" isWorld=%q", bools(feature.IsWorld)))
-	wb.WriteString(fmt.Sprintf(" isContinent=%q", bools(feature.IsContinent)))
-	wb.WriteString(fmt.Sprintf(" isKingdom=%q", bools(feature.IsKingdom)))
-	wb.WriteString(fmt.Sprintf(" isProvince=%q", bools(feature.IsProvince)))
-	wb.WriteString(fmt.Sprintf(" isFillHexBottom=%q", bools(feature.IsFillHexBottom)))
-	wb.WriteString(fmt.Sprintf(" isHideTerrainIcon=%q", bools(feature.IsHideTerrainIcon)))
+	wb.WriteString("<feature")
+	writeAttr(wb, " type=", feature.Type)
+	writeFloatAttr(wb, " rotate=", feature.Rotate)
+	writeAttr(wb, " uuid=", feature.Uuid)
+	writeAttr(wb, " mapLayer=", feature.MapLayer)
+	writeBoolAttr(wb, " isFlipHorizontal=", feature.IsFlipHorizontal)
+	writeBoolAttr(wb, " isFlipVertical=", feature.IsFlipVertical)
+	writeFloatAttr(wb, " scale=", feature.Scale)
+	writeFloatAttr(wb, " scaleHt=", feature.ScaleHt)
+	writeAttr(wb, " tags=", feature.Tags)
+	writeRgbanAttr(wb, " color=", feature.Color) // nullable
+	writeRgbanAttr(wb, " ringcolor=", feature.RingColor)
+	writeBoolAttr(wb, " isGMOnly=", feature.IsGMOnly)
+	writeBoolAttr(wb, " isPlaceFreely=", feature.IsPlaceFreely)
+	writeAttr(wb, " labelPosition=", feature.LabelPosition)
+	writeIntAttr(wb, " labelDistance=", feature.LabelDistance)
+	writeBoolAttr(wb, " isWorld=", feature.IsWorld)
+	writeBoolAttr(wb, " isContinent=", feature.IsContinent)
+	writeBoolAttr(wb, " isKingdom=", feature.IsKingdom)
+	writeBoolAttr(wb, " isProvince=", feature.IsProvince)
+	writeBoolAttr(wb, " isFillHexBottom=", feature.IsFillHexBottom)
+	writeBoolAttr(wb, " isHideTerrainIcon=", feature.IsHideTerrainIcon)
+	wb.WriteString(extraAttrs(feature.Extras))
 	wb.WriteString(">")
 	if feature.Location != nil {
 		if err := encodeFeatureLocation(feature.Location, wb); err != nil {
@@ -346,16 +466,18 @@ func encodeFeature(feature *wxx.Feature_t, wb *bytes.Buffer) error {
 			return err
 		}
 	}
+	wb.WriteString(extraElements(feature.Extras))
 	wb.WriteString("</feature>\n")
 	return nil
 }
 
 func encodeFeatureLocation(location *wxx.FeatureLocation_t, wb *bytes.Buffer) error {
 	wb.WriteString("<location")
-	wb.WriteString(fmt.Sprintf(" viewLevel=%q", location.ViewLevel))
-	wb.WriteString(fmt.Sprintf(" x=%q", floats(location.X)))
-	wb.WriteString(fmt.Sprintf(" y=%q", floats(location.Y)))
-	wb.WriteString(" />")
+	writeAttr(wb, " viewLevel=", location.ViewLevel)
+	writeFloatAttr(wb, " x=", location.X)
+	writeFloatAttr(wb, " y=", location.Y)
+	wb.WriteString(extraAttrs(location.Extras))
+	wb.WriteString(endEmpty("location", " />", location.Extras))
 	return nil
 }
 
@@ -376,45 +498,47 @@ func encodeLabels(labels []*wxx.Label_t, wb *bytes.Buffer) error {
 
 func encodeLabel(label *wxx.Label_t, wb *bytes.Buffer) error {
 	wb.WriteString("<label")
-	wb.WriteString(fmt.Sprintf("  mapLayer=%q", label.MapLayer))
-	wb.WriteString(fmt.Sprintf(" style=%q", label.Style))       // can be null!
-	wb.WriteString(fmt.Sprintf(" fontFace=%q", label.FontFace)) // can be null!
-	wb.WriteString(fmt.Sprintf(" color=%q", rgbas(label.Color)))
-	// todo: backgroundColor is sometimes not displayed when its value is "0.0,0.0,0.0,1.0".
-	// I may need to ask on the Inkwell Discord about this; I can't figure out the pattern.
-	// Until then, seems to be no harm in excluding it (other than noise in the diff).
-	if attr := rgbas(label.BackgroundColor); attr != "0.0,0.0,0.0,1.0" { // do not include if null
-		wb.WriteString(fmt.Sprintf(" backgroundColor=%q", attr))
-	}
-	wb.WriteString(fmt.Sprintf(" outlineColor=%q", rgbas(label.OutlineColor)))
-	wb.WriteString(fmt.Sprintf(" outlineSize=%q", floats(label.OutlineSize)))
-	wb.WriteString(fmt.Sprintf(" rotate=%q", floats(label.Rotate)))
-	wb.WriteString(fmt.Sprintf(" isBold=%q", bools(label.IsBold)))
-	wb.WriteString(fmt.Sprintf(" isItalic=%q", bools(label.IsItalic)))
-	wb.WriteString(fmt.Sprintf(" isWorld=%q", bools(label.IsWorld)))
-	wb.WriteString(fmt.Sprintf(" isContinent=%q", bools(label.IsContinent)))
-	wb.WriteString(fmt.Sprintf(" isKingdom=%q", bools(label.IsKingdom)))
-	wb.WriteString(fmt.Sprintf(" isProvince=%q", bools(label.IsProvince)))
-	wb.WriteString(fmt.Sprintf(" isGMOnly=%q", bools(label.IsGMOnly)))
-	wb.WriteString(fmt.Sprintf(" tags=%q", label.Tags))
+	writeAttr(wb, "  mapLayer=", label.MapLayer)
+	writeAttr(wb, " style=", label.Style)       // can be null!
+	writeAttr(wb, " fontFace=", label.FontFace) // can be null!
+	writeRgbaAttr(wb, " color=", label.Color)
+	// backgroundColor is left out when the label has none, which is how the files
+	// that were read said so. An explicit opaque black is a background and is kept.
+	if label.BackgroundColor != nil {
+		writeRgbaAttr(wb, " backgroundColor=", label.BackgroundColor)
+	}
+	writeRgbaAttr(wb, " outlineColor=", label.OutlineColor)
+	writeFloatAttr(wb, " outlineSize=", label.OutlineSize)
+	writeFloatAttr(wb, " rotate=", label.Rotate)
+	writeBoolAttr(wb, " isBold=", label.IsBold)
+	writeBoolAttr(wb, " isItalic=", label.IsItalic)
+	writeBoolAttr(wb, " isWorld=", label.IsWorld)
+	writeBoolAttr(wb, " isContinent=", label.IsContinent)
+	writeBoolAttr(wb, " isKingdom=", label.IsKingdom)
+	writeBoolAttr(wb, " isProvince=", label.IsProvince)
+	writeBoolAttr(wb, " isGMOnly=", label.IsGMOnly)
+	writeAttr(wb, " tags=", label.Tags)
+	wb.WriteString(extraAttrs(label.Extras))
 	wb.WriteString(">")
 	if err := encodeLabelLocation(label.Location, wb); err != nil {
 		return err
 	}
 	if label.InnerText != "" {
-		wb.WriteString(encodeInnerText(label.InnerText))
+		writeText(wb, label.InnerText)
 	}
+	wb.WriteString(extraElements(label.Extras))
 	wb.WriteString("</label>\n")
 	return nil
 }
 
 func encodeLabelLocation(location *wxx.LabelLocation_t, wb *bytes.Buffer) error {
 	wb.WriteString("<location")
-	wb.WriteString(fmt.Sprintf(" viewLevel=%q", location.ViewLevel))
-	wb.WriteString(fmt.Sprintf(" x=%q", floats(location.X)))
-	wb.WriteString(fmt.Sprintf(" y=%q", floats(location.Y)))
-	wb.WriteString(fmt.Sprintf(" scale=%q", floats(location.Scale)))
-	wb.WriteString(" />")
+	writeAttr(wb, " viewLevel=", location.ViewLevel)
+	writeFloatAttr(wb, " x=", location.X)
+	writeFloatAttr(wb, " y=", location.Y)
+	writeFloatAttr(wb, " scale=", location.Scale)
+	wb.WriteString(extraAttrs(location.Extras))
+	wb.WriteString(endEmpty("location", " />", location.Extras))
 	return nil
 }
 
@@ -479,13 +603,77 @@ func encodeNote(note *wxx.Note_t, wb *bytes.Buffer) error {
 }
 
 func encodeInformations(informations *wxx.Informations_t, wb *bytes.Buffer) error {
-	wb.WriteString("<informations>\n")
+	wb.WriteString("<informations>")
+	if informations == nil {
+		wb.WriteString("\n</informations>\n")
+		return nil
+	}
+	// as in the 1.06 encoder: the wrapper's chardata, then the entries
+	// back-to-back, so that on re-decode the chardata is informations.InnerText
+	writeText(wb, informations.InnerText)
+	for _, information := range informations.Informations {
+		encodeInformationDetail(&wxx.InformationDetail_t{
+			Uuid:         information.Uuid,
+			Type:         information.Type,
+			Title:        information.Title,
+			Rulers:       information.Rulers,
+			Government:   information.Government,
+			Cultures:     information.Cultures,
+			Language:     information.Language,
+			ReligionType: information.ReligionType,
+			Culture:      information.Culture,
+			HolySymbol:   information.HolySymbol,
+			Domains:      information.Domains,
+			Details:      information.Details,
+			InnerText:    information.InnerText,
+			IsCDATA:      information.IsCDATA,
+		}, wb)
+	}
 	wb.WriteString("</informations>\n")
 	return nil
 }
 
+// encodeInformationDetail writes an <information> and the entries nested in it.
+// The classic schema has no extras, so a top-level entry is written through it
+// too.
+func encodeInformationDetail(detail *wxx.InformationDetail_t, wb *bytes.Buffer) {
+	wb.WriteString("<information")
+	writeAttr(wb, " uuid=", detail.Uuid)
+	writeAttr(wb, " type=", detail.Type)
+	writeAttr(wb, " title=", detail.Title)
+	// the rest come in groups, each written by the type of entry it describes:
+	// a nation's rulers, a culture's language, a religion's gods. A group is
+	// written whole, empty values included, when any of it is set.
+	for _, group := range [][]struct{ prefix, value string }{
+		{{" rulers=", detail.Rulers}, {" government=", detail.Government}, {" cultures=", detail.Cultures}},
+		{{" language=", detail.Language}},
+		{{" religionType=", detail.ReligionType}, {" culture=", detail.Culture}, {" holySymbol=", detail.HolySymbol}, {" domains=", detail.Domains}},
+	} {
+		set := false
+		for _, a := range group {
+			set = set || a.value != ""
+		}
+		if !set {
+			continue
+		}
+		for _, a := range group {
+			writeAttr(wb, a.prefix, a.value)
+		}
+	}
+	wb.WriteString(">")
+	if detail.IsCDATA {
+		wb.Write(xmlstream.AppendCDATAText(wb.AvailableBuffer(), detail.InnerText))
+	} else {
+		writeText(wb, detail.InnerText)
+	}
+	for _, nested := range detail.Details {
+		encodeInformationDetail(nested, wb)
+	}
+	wb.WriteString("</information>")
+}
+
 func encodeConfiguration(configuration *wxx.Configuration_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<configuration>\n"))
+	wb.WriteString("<configuration>\n")
 	if err := encodeTerrainConfig(configuration.TerrainConfig, wb); err != nil {
 		return err
 	}
@@ -501,7 +689,7 @@ func encodeConfiguration(configuration *wxx.Configuration_t, wb *bytes.Buffer) e
 	if err := encodeShapeConfig(configuration.ShapeConfig, wb); err != nil {
 		return err
 	}
-	wb.WriteString(fmt.Sprintf("  </configuration>\n"))
+	wb.WriteString("  </configuration>\n")
 	return nil
 }
 
@@ -535,17 +723,17 @@ func encodeTextConfig(textConfig *wxx.TextConfig_t, wb *bytes.Buffer) error {
 }
 
 func encodeLabelStyle(labelStyle *wxx.LabelStyle_t, wb *bytes.Buffer) error {
-	//wb.WriteString("<labelstyle")
-	////name="Building"
-	////fontFace="Arial"
-	////scale="25.0"
-	////isBold="false"
-	////isItalic="false"
-	////color="0.0,0.0,0.0,1.0"
-	////backgroundColor="null"
-	////outlineSize="0.0"
-	////outlineColor="null"
-	//wb.WriteString(" />\n\n")
+	wb.WriteString("<labelstyle")
+	writeAttr(wb, " name=", labelStyle.Name)
+	writeAttr(wb, " fontFace=", labelStyle.FontFace)
+	writeFloatAttr(wb, " scale=", labelStyle.Scale)
+	writeBoolAttr(wb, " isBold=", labelStyle.IsBold)
+	writeBoolAttr(wb, " isItalic=", labelStyle.IsItalic)
+	writeRgbaAttr(wb, " color=", labelStyle.Color)
+	writeRgbanAttr(wb, " backgroundColor=", labelStyle.BackgroundColor) // nullable
+	writeFloatAttr(wb, " outlineSize=", labelStyle.OutlineSize)
+	writeRgbanAttr(wb, " outlineColor=", labelStyle.OutlineColor) // nullable
+	wb.WriteString(" />\n")
 	return nil
 }
 
@@ -562,34 +750,35 @@ func encodeShapeConfig(shapeConfig *wxx.ShapeConfig_t, wb *bytes.Buffer) error {
 
 func encodeShapeStyle(shapeStyle *wxx.ShapeStyle_t, wb *bytes.Buffer) error {
 	wb.WriteString("<shapestyle")
-	wb.WriteString(fmt.Sprintf(" name=%q", shapeStyle.Name))
-	wb.WriteString(fmt.Sprintf(" strokeType=%q", shapeStyle.StrokeType))
-	wb.WriteString(fmt.Sprintf(" isFractal=%q", bools(shapeStyle.IsFractal)))
-	wb.WriteString(fmt.Sprintf(" strokeWidth=%q", floats(shapeStyle.StrokeWidth)))
-	wb.WriteString(fmt.Sprintf(" opacity=%q", floats(shapeStyle.Opacity)))
-	wb.WriteString(fmt.Sprintf(" snapVertices=%q", bools(shapeStyle.SnapVertices)))
-	wb.WriteString(fmt.Sprintf(" tags=%q", shapeStyle.Tags))
-	wb.WriteString(fmt.Sprintf(" dropShadow=%q", bools(shapeStyle.DropShadow)))
-	wb.WriteString(fmt.Sprintf(" innerShadow=%q", bools(shapeStyle.InnerShadow)))
-	wb.WriteString(fmt.Sprintf(" boxBlur=%q", bools(shapeStyle.BoxBlur)))
-	wb.WriteString(fmt.Sprintf(" dsSpread=%q", floats(shapeStyle.DsSpread)))
-	wb.WriteString(fmt.Sprintf(" dsRadius=%q", floats(shapeStyle.DsRadius)))
-	wb.WriteString(fmt.Sprintf(" dsOffsetX=%q", floats(shapeStyle.DsOffsetX)))
-	wb.WriteString(fmt.Sprintf(" dsOffsetY=%q", floats(shapeStyle.DsOffsetY)))
-	wb.WriteString(fmt.Sprintf(" insChoke=%q", floats(shapeStyle.InsChoke)))
-	wb.WriteString(fmt.Sprintf(" insRadius=%q", floats(shapeStyle.InsRadius)))
-	wb.WriteString(fmt.Sprintf(" insOffsetX=%q", floats(shapeStyle.InsOffsetX)))
-	wb.WriteString(fmt.Sprintf(" insOffsetY=%q", floats(shapeStyle.InsOffsetY)))
-	wb.WriteString(fmt.Sprintf(" bbWidth=%q", floats(shapeStyle.BbWidth)))
-	wb.WriteString(fmt.Sprintf(" bbHeight=%q", floats(shapeStyle.BbHeight)))
-	wb.WriteString(fmt.Sprintf(" bbIterations=%q", ints(shapeStyle.BbIterations)))
-	wb.WriteString(fmt.Sprintf(" fillTexture=%q", shapeStyle.FillTexture))         // nullable
-	wb.WriteString(fmt.Sprintf(" strokeTexture=%q", shapeStyle.StrokeTexture))     // nullable
-	wb.WriteString(fmt.Sprintf("  strokePaint=%q", rgbas(shapeStyle.StrokePaint))) // not nullable
-	wb.WriteString(fmt.Sprintf("  fillPaint=%q", rgbans(shapeStyle.FillPaint)))    // nullable
-	wb.WriteString(fmt.Sprintf("  dscolor=%q", rgbans(shapeStyle.DsColor)))        // nullable
-	wb.WriteString(fmt.Sprintf("  insColor=%q", rgbans(shapeStyle.InsColor)))      // nullable
-	wb.WriteString(" />\n")
+	writeAttr(wb, " name=", shapeStyle.Name)
+	writeAttr(wb, " strokeType=", shapeStyle.StrokeType)
+	writeBoolAttr(wb, " isFractal=", shapeStyle.IsFractal)
+	writeFloatAttr(wb, " strokeWidth=", shapeStyle.StrokeWidth)
+	writeFloatAttr(wb, " opacity=", shapeStyle.Opacity)
+	writeBoolAttr(wb, " snapVertices=", shapeStyle.SnapVertices)
+	writeAttr(wb, " tags=", shapeStyle.Tags)
+	writeBoolAttr(wb, " dropShadow=", shapeStyle.DropShadow)
+	writeBoolAttr(wb, " innerShadow=", shapeStyle.InnerShadow)
+	writeBoolAttr(wb, " boxBlur=", shapeStyle.BoxBlur)
+	writeFloatAttr(wb, " dsSpread=", shapeStyle.DsSpread)
+	writeFloatAttr(wb, " dsRadius=", shapeStyle.DsRadius)
+	writeFloatAttr(wb, " dsOffsetX=", shapeStyle.DsOffsetX)
+	writeFloatAttr(wb, " dsOffsetY=", shapeStyle.DsOffsetY)
+	writeFloatAttr(wb, " insChoke=", shapeStyle.InsChoke)
+	writeFloatAttr(wb, " insRadius=", shapeStyle.InsRadius)
+	writeFloatAttr(wb, " insOffsetX=", shapeStyle.InsOffsetX)
+	writeFloatAttr(wb, " insOffsetY=", shapeStyle.InsOffsetY)
+	writeFloatAttr(wb, " bbWidth=", shapeStyle.BbWidth)
+	writeFloatAttr(wb, " bbHeight=", shapeStyle.BbHeight)
+	writeIntAttr(wb, " bbIterations=", shapeStyle.BbIterations)
+	writeAttr(wb, " fillTexture=", shapeStyle.FillTexture)      // nullable
+	writeAttr(wb, " strokeTexture=", shapeStyle.StrokeTexture)  // nullable
+	writeRgbaAttr(wb, "  strokePaint=", shapeStyle.StrokePaint) // not nullable
+	writeRgbanAttr(wb, "  fillPaint=", shapeStyle.FillPaint)    // nullable
+	writeRgbanAttr(wb, "  dscolor=", shapeStyle.DsColor)        // nullable
+	writeRgbanAttr(wb, "  insColor=", shapeStyle.InsColor)      // nullable
+	wb.WriteString(extraAttrs(shapeStyle.Extras))
+	wb.WriteString(endEmpty("shapestyle", " />", shapeStyle.Extras) + "\n")
 	return nil
 }
 
@@ -603,7 +792,7 @@ func boold(b bool) int {
 
 // bools formats a bool as a string
 func bools(b bool) string {
-	return fmt.Sprintf("%v", b)
+	return strconv.FormatBool(b)
 }
 
 // floatd formats a float as an integer.
@@ -641,18 +830,24 @@ func floatf(f float64) string {
 //	floats(1234567.00) returns "1234567.0"
 //	floats(0.120300) returns "0.1203"
 func floats(f float64) string {
-	s := fmt.Sprintf("%g", f)
-	if strings.IndexByte(s, 'e') != -1 {
-		s = fmt.Sprintf("%f", f)
+	return string(appendFloats(nil, f))
+}
+
+// appendFloats appends floats(f) to b.
+func appendFloats(b []byte, f float64) []byte {
+	start := len(b)
+	b = strconv.AppendFloat(b, f, 'g', -1, 64)
+	if bytes.IndexByte(b[start:], 'e') != -1 {
+		b = strconv.AppendFloat(b[:start], f, 'f', 6, 64)
 	}
-	if strings.IndexByte(s, '.') == -1 {
-		return s + ".0"
+	if bytes.IndexByte(b[start:], '.') == -1 {
+		return append(b, ".0"...)
 	}
-	s = strings.TrimRight(s, "0")
-	if s[len(s)-1] == '.' {
-		return s + "0"
+	b = bytes.TrimRight(b, "0")
+	if b[len(b)-1] == '.' {
+		return append(b, '0')
 	}
-	return s
+	return b
 }
 
 // floatg formats a float in the style that Worldographer expects.
@@ -662,17 +857,15 @@ func floatg(f float64) string {
 
 // ints formats an int as a string
 func ints(i int) string {
-	return fmt.Sprintf("%d", i)
+	return strconv.Itoa(i)
 }
 
-// rgbans converts an RGBA_t to a nullable string.
-// It uses the rgbas function to format the RGBA_t
+// rgbans converts an RGBA_t to a nullable string: nil, "no color", is "null".
 func rgbans(rgba *wxx.RGBA_t) string {
-	s := rgbas(rgba)
-	if s == "0.0,0.0,0.0,1.0" {
-		s = "null"
+	if rgba == nil {
+		return "null"
 	}
-	return s
+	return rgbas(rgba)
 }
 
 // rgbas converts an RGBA_t struct into an XML attribute string.
@@ -681,6 +874,9 @@ func rgbans(rgba *wxx.RGBA_t) string {
 // If the provided rgba pointer is nil, it defaults to "0.0,0.0,0.0,1.0".
 //
 // We use the floats function to format the float values into an XML-friendly format.
+// A color that was decoded is written as the file spelled it instead, for as long
+// as Raw still parses to the same four values, so an unedited "0,0,0,1" is not
+// rewritten as "0.0,0.0,0.0,1.0".
 //
 // Parameters:
 // - rgba: a pointer to an RGBA_t struct. Can be nil.
@@ -688,14 +884,42 @@ func rgbans(rgba *wxx.RGBA_t) string {
 // Returns:
 // - A XML attribute string representing the rgba. If rgba is nil, returns "0.0,0.0,0.0,1.0"
 func rgbas(rgba *wxx.RGBA_t) string {
+	return string(appendRgbas(nil, rgba))
+}
+
+// appendRgbas appends rgbas(rgba) to b.
+func appendRgbas(b []byte, rgba *wxx.RGBA_t) []byte {
 	if rgba == nil {
-		return "0.0,0.0,0.0,1.0"
+		return append(b, "0.0,0.0,0.0,1.0"...)
+	}
+	if rawRgba(rgba) {
+		return append(b, rgba.Raw...)
+	}
+	b = appendFloats(b, rgba.R)
+	b = appendFloats(append(b, ','), rgba.G)
+	b = appendFloats(append(b, ','), rgba.B)
+	return appendFloats(append(b, ','), rgba.A)
+}
+
+// rawRgba reports whether rgba is written as the file spelled it: it has a Raw
+// spelling and that spelling still parses to the same four values. It reads Raw
+// as decodeRgba does, but without allocating, since it is asked once per tile.
+func rawRgba(rgba *wxx.RGBA_t) bool {
+	if rgba.Raw == "" {
+		return false
+	}
+	want, rest := [4]float64{rgba.R, rgba.G, rgba.B, rgba.A}, rgba.Raw
+	for i := range want {
+		value, tail, found := strings.Cut(rest, ",")
+		if found == (i == len(want)-1) {
+			return false // not four values
+		}
+		if f, err := strconv.ParseFloat(value, 64); err != nil || f != want[i] {
+			return false
+		}
+		rest = tail
 	}
-	return fmt.Sprintf("%s,%s,%s,%s",
-		floats(rgba.R),
-		floats(rgba.G),
-		floats(rgba.B),
-		floats(rgba.A))
+	return true
 }
 
 // terrainMapToSlice converts a map of terrain names and slot into a list
@@ -723,7 +947,69 @@ func terrainMapToSlice(data map[string]int) []string {
 	return s
 }
 
-func encodeInnerText(input string) string {
-	escaped := html.EscapeString(input) // Escapes < > & "
-	return strings.ReplaceAll(escaped, "\n", "&#10;")
+// writeText writes s as character data, escaped by xmlstream.AppendText.
+func writeText(wb *bytes.Buffer, s string) {
+	wb.Write(xmlstream.AppendText(wb.AvailableBuffer(), s))
+}
+
+// The write helpers put an attribute straight into wb, without the strings that
+// fmt.Sprintf and the formatting helpers above allocate. Each writes prefix, which
+// is the leading space and "name=", and then the quoted value. A string value is
+// escaped by xmlstream.AppendAttr; the others need no escaping.
+
+// writeAttr writes a string attribute.
+func writeAttr(wb *bytes.Buffer, prefix, value string) {
+	wb.WriteString(prefix)
+	wb.Write(xmlstream.AppendAttr(wb.AvailableBuffer(), value))
+}
+
+// writeBoolAttr writes a bool attribute as "true" or "false".
+func writeBoolAttr(wb *bytes.Buffer, prefix string, value bool) {
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = strconv.AppendBool(b, value)
+	wb.Write(append(b, '"'))
+}
+
+// writeIntAttr writes an int attribute.
+func writeIntAttr(wb *bytes.Buffer, prefix string, value int) {
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = strconv.AppendInt(b, int64(value), 10)
+	wb.Write(append(b, '"'))
+}
+
+// writeFloatAttr writes a float attribute formatted by floats.
+func writeFloatAttr(wb *bytes.Buffer, prefix string, value float64) {
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = appendFloats(b, value)
+	wb.Write(append(b, '"'))
+}
+
+// writeRgbaAttr writes a color attribute formatted by rgbas.
+func writeRgbaAttr(wb *bytes.Buffer, prefix string, value *wxx.RGBA_t) {
+	if value != nil && rawRgba(value) {
+		writeAttr(wb, prefix, value.Raw)
+		return
+	}
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = appendRgbas(b, value)
+	wb.Write(append(b, '"'))
+}
+
+// writeRgbanAttr writes a nullable color attribute formatted by rgbans.
+func writeRgbanAttr(wb *bytes.Buffer, prefix string, value *wxx.RGBA_t) {
+	if value == nil {
+		wb.WriteString(prefix)
+		wb.WriteString(`"null"`)
+		return
+	}
+	writeRgbaAttr(wb, prefix, value)
+}
+
+// writeInt writes an int without quotes, as a tile line field is written.
+func writeInt(wb *bytes.Buffer, value int) {
+	wb.Write(strconv.AppendInt(wb.AvailableBuffer(), int64(value), 10))
 }
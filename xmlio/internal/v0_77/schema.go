@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // XMLSchema defines the structure for reading a Worldographer file with the H2017v1 XML Schema
@@ -53,6 +54,10 @@ type XMLSchema struct {
 	Notes            Notes_t          `xml:"notes"`
 	Informations     Informations_t   `xml:"informations"`
 	Configuration    Configuration_t  `xml:"configuration"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Configuration_t struct {
@@ -94,9 +99,17 @@ type Feature struct {
 		ViewLevel string  `xml:"viewLevel,attr"`
 		X         float64 `xml:"x,attr"`
 		Y         float64 `xml:"y,attr"`
+
+		// anything else, kept for the encoder (see wxx.Extras_t)
+		ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+		ExtraElements []xmlstream.Element `xml:",any"`
 	} `xml:"location"`
 	Label     Label_t `xml:"label"`
 	InnerText string  `xml:",chardata"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type FeatureConfig struct {
@@ -142,6 +155,10 @@ type GridAndNumbering struct {
 	NumberPosition              string  `xml:"numberPosition,attr"`
 	NumberPrePad                string  `xml:"numberPrePad,attr"`
 	NumberSeparator             string  `xml:"numberSeparator,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Information_t struct {
@@ -163,6 +180,10 @@ type Information_t struct {
 	// elements
 	Details   []Information_t `xml:"information"`
 	InnerText string          `xml:",chardata"`
+
+	// the element's content as the file has it, to tell whether InnerText was
+	// a CDATA section
+	InnerXML string `xml:",innerxml"`
 }
 
 type Informations_t struct {
@@ -193,6 +214,10 @@ type Label_t struct {
 	// elements
 	Location  Location_t `xml:"location"`
 	InnerText string     `xml:",chardata"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Labels_t struct {
@@ -219,6 +244,10 @@ type Location_t struct {
 	X         float64 `xml:"x,attr"`
 	Y         float64 `xml:"y,attr"`
 	Scale     float64 `xml:"scale,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type MapKey_t struct {
@@ -252,6 +281,10 @@ type MapLayer_t struct {
 	// attributes
 	Name      string `xml:"name,attr"`
 	IsVisible bool   `xml:"isVisible,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Note_t struct {
@@ -351,6 +384,10 @@ type ShapeStyle_t struct {
 	StrokeType    string  `xml:"strokeType,attr"`
 	StrokeWidth   float64 `xml:"strokeWidth,attr"`
 	Tags          string  `xml:"tags,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Shapes_t struct {
@@ -387,6 +424,10 @@ type Tiles_t struct {
 
 	// elements
 	TileRows []TileRow_t `xml:"tilerow"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type TileRow_t struct {
@@ -394,45 +435,26 @@ type TileRow_t struct {
 	InnerText string `xml:",chardata"`
 }
 
+// decodeRgba parses a Worldographer float-RGBA attribute ("r,g,b,a"). "" and
+// "null" decode to nil, "no color"; any other value, opaque black included, is an
+// explicit color that keeps the file's spelling in Raw. Opaque black is no longer
+// folded to nil here -- see xmlio.WithBlackAsNoColor.
 func decodeRgba(s string) (rgba *wxx.RGBA_t, err error) {
-	if s == "" || s == "null" || s == "0.0,0.0,0.0,1.0" {
-		return nil, nil
-	}
-	rgba = &wxx.RGBA_t{}
-	values := strings.Split(s, ",")
-	if len(values) != 4 {
-		return rgba, fmt.Errorf("invalid value")
-	} else if rgba.R, err = strconv.ParseFloat(values[0], 64); err != nil {
-		return rgba, err
-	} else if rgba.G, err = strconv.ParseFloat(values[1], 64); err != nil {
-		return rgba, err
-	} else if rgba.B, err = strconv.ParseFloat(values[2], 64); err != nil {
-		return rgba, err
-	} else if rgba.A, err = strconv.ParseFloat(values[3], 64); err != nil {
-		return rgba, err
-	}
-	if rgba.R == 0 && rgba.G == 0 && rgba.B == 0 && rgba.A == 1 {
-		return nil, nil
-	}
-	return rgba, nil
-}
-
-func decodeZeroableRgba(s string) (rgba *wxx.RGBA_t, err error) {
 	if s == "" || s == "null" {
 		return nil, nil
 	}
-	rgba = &wxx.RGBA_t{}
+	rgba = &wxx.RGBA_t{Raw: s}
 	values := strings.Split(s, ",")
 	if len(values) != 4 {
-		return rgba, fmt.Errorf("invalid value")
+		return rgba, fmt.Errorf("%w: %q", wxx.ErrInvalidRGBA, s)
 	} else if rgba.R, err = strconv.ParseFloat(values[0], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	} else if rgba.G, err = strconv.ParseFloat(values[1], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	} else if rgba.B, err = strconv.ParseFloat(values[2], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	} else if rgba.A, err = strconv.ParseFloat(values[3], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	}
 	return rgba, nil
 }
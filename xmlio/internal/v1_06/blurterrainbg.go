@@ -4,7 +4,6 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
 )
@@ -23,6 +22,7 @@ func decodeBlurTerrainBG(src *BlurTerrainBG_t, w *wxx.Map_t) {
 		Randomness:  src.Randomness,
 		BlurStart:   src.BlurStart,
 		BlurEnd:     src.BlurEnd,
+		Extras:      extras(src.ExtraAttrs, src.ExtraElements),
 	}
 }
 
@@ -33,12 +33,13 @@ func encodeBlurTerrainBG(blurTerrainBG *wxx.BlurTerrainBG_t, wb *bytes.Buffer) e
 		return nil
 	}
 	wb.WriteString("<blurTerrainBG")
-	wb.WriteString(fmt.Sprintf(" blur=%q", bools(blurTerrainBG.Blur)))
-	wb.WriteString(fmt.Sprintf(" topBleed=%q", floats(blurTerrainBG.TopBleed)))
-	wb.WriteString(fmt.Sprintf(" bottomBleed=%q", floats(blurTerrainBG.BottomBleed)))
-	wb.WriteString(fmt.Sprintf(" randomness=%q", floats(blurTerrainBG.Randomness)))
-	wb.WriteString(fmt.Sprintf(" blurStart=%q", floats(blurTerrainBG.BlurStart)))
-	wb.WriteString(fmt.Sprintf(" blurEnd=%q", floats(blurTerrainBG.BlurEnd)))
-	wb.WriteString("/>\n")
+	writeBoolAttr(wb, " blur=", blurTerrainBG.Blur)
+	writeFloatAttr(wb, " topBleed=", blurTerrainBG.TopBleed)
+	writeFloatAttr(wb, " bottomBleed=", blurTerrainBG.BottomBleed)
+	writeFloatAttr(wb, " randomness=", blurTerrainBG.Randomness)
+	writeFloatAttr(wb, " blurStart=", blurTerrainBG.BlurStart)
+	writeFloatAttr(wb, " blurEnd=", blurTerrainBG.BlurEnd)
+	wb.WriteString(extraAttrs(blurTerrainBG.Extras))
+	wb.WriteString(endEmpty("blurTerrainBG", "/>", blurTerrainBG.Extras) + "\n")
 	return nil
 }
@@ -4,16 +4,16 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // decodeConfiguration copies the <configuration> tree into the domain map. The
 // terrain-config / feature-config / texture-config sub-sections are copied as
 // raw chardata (they are no-op(intentional) on encode); text-config/labelstyle
 // and shape-config/shapestyle are fully modeled.
-func decodeConfiguration(src Configuration_t, w *wxx.Map_t) error {
+func decodeConfiguration(src Configuration_t, w *wxx.Map_t, lax *xmlstream.Lenient) error {
 	var err error
 	w.Configuration = &wxx.Configuration_t{}
 	for _, mTerrainConfig := range src.TerrainConfig {
@@ -48,17 +48,16 @@ func decodeConfiguration(src Configuration_t, w *wxx.Map_t) error {
 				DropShadowColor:  mLabelStyle.DropShadowColor,
 				DropShadowRadius: mLabelStyle.DropShadowRadius,
 				DropShadowSpread: mLabelStyle.DropShadowSpread,
+				Extras:           extras(mLabelStyle.ExtraAttrs, mLabelStyle.ExtraElements),
 			}
-			if wLabelStyle.Color, err = decodeRgba(mLabelStyle.Color); err != nil {
-				return fmt.Errorf("labelStyle.color: %w", err)
+			if wLabelStyle.Color, err = lax.RGBA(decodeRgba, mLabelStyle.Color, "labelStyle.color"); err != nil {
+				return err
 			}
-			if wLabelStyle.BackgroundColor, err = decodeRgba(mLabelStyle.BackgroundColor); err != nil {
-				return fmt.Errorf("labelStyle.backgroundColor: %w", err)
+			if wLabelStyle.BackgroundColor, err = lax.RGBA(decodeRgba, mLabelStyle.BackgroundColor, "labelStyle.backgroundColor"); err != nil {
+				return err
 			}
-			if mLabelStyle.OutlineColor == "null" {
-				wLabelStyle.OutlineColor = nil
-			} else if wLabelStyle.OutlineColor, err = decodeZeroableRgba(mLabelStyle.OutlineColor); err != nil {
-				return fmt.Errorf("labelStyle.outlineColor: %w", err)
+			if wLabelStyle.OutlineColor, err = lax.RGBA(decodeRgba, mLabelStyle.OutlineColor, "labelStyle.outlineColor"); err != nil {
+				return err
 			}
 			w.Configuration.TextConfig.LabelStyles = append(w.Configuration.TextConfig.LabelStyles, wLabelStyle)
 		}
@@ -92,18 +91,19 @@ func decodeConfiguration(src Configuration_t, w *wxx.Map_t) error {
 				StrokeTexture: mShapeStyle.StrokeTexture,
 				LineCap:       mShapeStyle.LineCap,
 				LineJoin:      mShapeStyle.LineJoin,
+				Extras:        extras(mShapeStyle.ExtraAttrs, mShapeStyle.ExtraElements),
 			}
-			if wShapeStyle.StrokePaint, err = decodeRgba(mShapeStyle.StrokePaint); err != nil {
-				return fmt.Errorf("shapeStyle.strokePaint: %w", err)
+			if wShapeStyle.StrokePaint, err = lax.RGBA(decodeRgba, mShapeStyle.StrokePaint, "shapeStyle.strokePaint"); err != nil {
+				return err
 			}
-			if wShapeStyle.FillPaint, err = decodeRgba(mShapeStyle.FillPaint); err != nil {
-				return fmt.Errorf("shapeStyle.fillPaint: %w", err)
+			if wShapeStyle.FillPaint, err = lax.RGBA(decodeRgba, mShapeStyle.FillPaint, "shapeStyle.fillPaint"); err != nil {
+				return err
 			}
-			if wShapeStyle.DsColor, err = decodeRgba(mShapeStyle.Dscolor); err != nil {
-				return fmt.Errorf("shapeStyle.dsColor: %w", err)
+			if wShapeStyle.DsColor, err = lax.RGBA(decodeRgba, mShapeStyle.Dscolor, "shapeStyle.dsColor"); err != nil {
+				return err
 			}
-			if wShapeStyle.InsColor, err = decodeRgba(mShapeStyle.InsColor); err != nil {
-				return fmt.Errorf("shapeStyle.insColor: %w", err)
+			if wShapeStyle.InsColor, err = lax.RGBA(decodeRgba, mShapeStyle.InsColor, "shapeStyle.insColor"); err != nil {
+				return err
 			}
 			w.Configuration.ShapeConfig.ShapeStyles = append(w.Configuration.ShapeConfig.ShapeStyles, wShapeStyle)
 		}
@@ -112,7 +112,7 @@ func decodeConfiguration(src Configuration_t, w *wxx.Map_t) error {
 }
 
 func encodeConfiguration(configuration *wxx.Configuration_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<configuration>\n"))
+	wb.WriteString("<configuration>\n")
 	if err := encodeTerrainConfig(configuration.TerrainConfig, wb); err != nil {
 		return err
 	}
@@ -128,7 +128,7 @@ func encodeConfiguration(configuration *wxx.Configuration_t, wb *bytes.Buffer) e
 	if err := encodeShapeConfig(configuration.ShapeConfig, wb); err != nil {
 		return err
 	}
-	wb.WriteString(fmt.Sprintf("  </configuration>\n"))
+	wb.WriteString("  </configuration>\n")
 	return nil
 }
 
@@ -187,15 +187,15 @@ func encodeTextConfig(textConfig *wxx.TextConfig_t, wb *bytes.Buffer) error {
 
 func encodeLabelStyle(labelStyle *wxx.LabelStyle_t, wb *bytes.Buffer) error {
 	wb.WriteString("<labelstyle")
-	wb.WriteString(fmt.Sprintf(" name=%q", labelStyle.Name))
-	wb.WriteString(fmt.Sprintf(" fontFace=%q", labelStyle.FontFace))
-	wb.WriteString(fmt.Sprintf(" scale=%q", floats(labelStyle.Scale)))
-	wb.WriteString(fmt.Sprintf(" isBold=%q", bools(labelStyle.IsBold)))
-	wb.WriteString(fmt.Sprintf(" isItalic=%q", bools(labelStyle.IsItalic)))
-	wb.WriteString(fmt.Sprintf(" color=%q", rgbas(labelStyle.Color)))                     // decodeRgba
-	wb.WriteString(fmt.Sprintf(" backgroundColor=%q", rgbas(labelStyle.BackgroundColor))) // decodeRgba
-	wb.WriteString(fmt.Sprintf(" outlineSize=%q", floats(labelStyle.OutlineSize)))
-	wb.WriteString(fmt.Sprintf(" outlineColor=%q", rgbans(labelStyle.OutlineColor))) // "null" or decodeZeroableRgba
+	writeAttr(wb, " name=", labelStyle.Name)
+	writeAttr(wb, " fontFace=", labelStyle.FontFace)
+	writeFloatAttr(wb, " scale=", labelStyle.Scale)
+	writeBoolAttr(wb, " isBold=", labelStyle.IsBold)
+	writeBoolAttr(wb, " isItalic=", labelStyle.IsItalic)
+	writeRgbaAttr(wb, " color=", labelStyle.Color)                      // decodeRgba
+	writeRgbanAttr(wb, " backgroundColor=", labelStyle.BackgroundColor) // nullable
+	writeFloatAttr(wb, " outlineSize=", labelStyle.OutlineSize)
+	writeRgbanAttr(wb, " outlineColor=", labelStyle.OutlineColor) // nullable
 	// The W2025 drop-shadow trio is present all-or-none in real data;
 	// dropShadowColor is "null" or an RGBA string when present, never empty, so an
 	// empty DropShadowColor reliably means "absent from the source". Gate the whole
@@ -203,11 +203,12 @@ func encodeLabelStyle(labelStyle *wxx.LabelStyle_t, wb *bytes.Buffer) error {
 	// (ADR 0002: never emit what was not on input). Do not gate on the numeric
 	// fields: 0 is a legal radius/spread value.
 	if labelStyle.DropShadowColor != "" {
-		wb.WriteString(fmt.Sprintf(" dropShadowColor=%q", labelStyle.DropShadowColor)) // nullable string ("null")
-		wb.WriteString(fmt.Sprintf(" dropShadowRadius=%q", floats(labelStyle.DropShadowRadius)))
-		wb.WriteString(fmt.Sprintf(" dropShadowSpread=%q", floats(labelStyle.DropShadowSpread)))
+		writeAttr(wb, " dropShadowColor=", labelStyle.DropShadowColor) // nullable string ("null")
+		writeFloatAttr(wb, " dropShadowRadius=", labelStyle.DropShadowRadius)
+		writeFloatAttr(wb, " dropShadowSpread=", labelStyle.DropShadowSpread)
 	}
-	wb.WriteString(" />\n")
+	wb.WriteString(extraAttrs(labelStyle.Extras))
+	wb.WriteString(endEmpty("labelstyle", " />", labelStyle.Extras) + "\n")
 	return nil
 }
 
@@ -224,35 +225,36 @@ func encodeShapeConfig(shapeConfig *wxx.ShapeConfig_t, wb *bytes.Buffer) error {
 
 func encodeShapeStyle(shapeStyle *wxx.ShapeStyle_t, wb *bytes.Buffer) error {
 	wb.WriteString("<shapestyle")
-	wb.WriteString(fmt.Sprintf(" name=%q", shapeStyle.Name))
-	wb.WriteString(fmt.Sprintf(" strokeType=%q", shapeStyle.StrokeType))
-	wb.WriteString(fmt.Sprintf(" isFractal=%q", bools(shapeStyle.IsFractal)))
-	wb.WriteString(fmt.Sprintf(" strokeWidth=%q", floats(shapeStyle.StrokeWidth)))
-	wb.WriteString(fmt.Sprintf(" opacity=%q", floats(shapeStyle.Opacity)))
-	wb.WriteString(fmt.Sprintf(" snapVertices=%q", bools(shapeStyle.SnapVertices)))
-	wb.WriteString(fmt.Sprintf(" tags=%q", shapeStyle.Tags))
-	wb.WriteString(fmt.Sprintf(" dropShadow=%q", bools(shapeStyle.DropShadow)))
-	wb.WriteString(fmt.Sprintf(" innerShadow=%q", bools(shapeStyle.InnerShadow)))
-	wb.WriteString(fmt.Sprintf(" boxBlur=%q", bools(shapeStyle.BoxBlur)))
-	wb.WriteString(fmt.Sprintf(" dsSpread=%q", floats(shapeStyle.DsSpread)))
-	wb.WriteString(fmt.Sprintf(" dsRadius=%q", floats(shapeStyle.DsRadius)))
-	wb.WriteString(fmt.Sprintf(" dsOffsetX=%q", floats(shapeStyle.DsOffsetX)))
-	wb.WriteString(fmt.Sprintf(" dsOffsetY=%q", floats(shapeStyle.DsOffsetY)))
-	wb.WriteString(fmt.Sprintf(" insChoke=%q", floats(shapeStyle.InsChoke)))
-	wb.WriteString(fmt.Sprintf(" insRadius=%q", floats(shapeStyle.InsRadius)))
-	wb.WriteString(fmt.Sprintf(" insOffsetX=%q", floats(shapeStyle.InsOffsetX)))
-	wb.WriteString(fmt.Sprintf(" insOffsetY=%q", floats(shapeStyle.InsOffsetY)))
-	wb.WriteString(fmt.Sprintf(" bbWidth=%q", floats(shapeStyle.BbWidth)))
-	wb.WriteString(fmt.Sprintf(" bbHeight=%q", floats(shapeStyle.BbHeight)))
-	wb.WriteString(fmt.Sprintf(" bbIterations=%q", ints(shapeStyle.BbIterations)))
-	wb.WriteString(fmt.Sprintf(" fillTexture=%q", shapeStyle.FillTexture))         // nullable
-	wb.WriteString(fmt.Sprintf(" strokeTexture=%q", shapeStyle.StrokeTexture))     // nullable
-	wb.WriteString(fmt.Sprintf("  strokePaint=%q", rgbas(shapeStyle.StrokePaint))) // not nullable
-	wb.WriteString(fmt.Sprintf("  fillPaint=%q", rgbans(shapeStyle.FillPaint)))    // nullable
-	wb.WriteString(fmt.Sprintf("  dscolor=%q", rgbans(shapeStyle.DsColor)))        // nullable
-	wb.WriteString(fmt.Sprintf("  insColor=%q", rgbans(shapeStyle.InsColor)))      // nullable
-	wb.WriteString(fmt.Sprintf(" lineCap=%q", shapeStyle.LineCap))
-	wb.WriteString(fmt.Sprintf(" lineJoin=%q", shapeStyle.LineJoin))
-	wb.WriteString(" />\n")
+	writeAttr(wb, " name=", shapeStyle.Name)
+	writeAttr(wb, " strokeType=", shapeStyle.StrokeType)
+	writeBoolAttr(wb, " isFractal=", shapeStyle.IsFractal)
+	writeFloatAttr(wb, " strokeWidth=", shapeStyle.StrokeWidth)
+	writeFloatAttr(wb, " opacity=", shapeStyle.Opacity)
+	writeBoolAttr(wb, " snapVertices=", shapeStyle.SnapVertices)
+	writeAttr(wb, " tags=", shapeStyle.Tags)
+	writeBoolAttr(wb, " dropShadow=", shapeStyle.DropShadow)
+	writeBoolAttr(wb, " innerShadow=", shapeStyle.InnerShadow)
+	writeBoolAttr(wb, " boxBlur=", shapeStyle.BoxBlur)
+	writeFloatAttr(wb, " dsSpread=", shapeStyle.DsSpread)
+	writeFloatAttr(wb, " dsRadius=", shapeStyle.DsRadius)
+	writeFloatAttr(wb, " dsOffsetX=", shapeStyle.DsOffsetX)
+	writeFloatAttr(wb, " dsOffsetY=", shapeStyle.DsOffsetY)
+	writeFloatAttr(wb, " insChoke=", shapeStyle.InsChoke)
+	writeFloatAttr(wb, " insRadius=", shapeStyle.InsRadius)
+	writeFloatAttr(wb, " insOffsetX=", shapeStyle.InsOffsetX)
+	writeFloatAttr(wb, " insOffsetY=", shapeStyle.InsOffsetY)
+	writeFloatAttr(wb, " bbWidth=", shapeStyle.BbWidth)
+	writeFloatAttr(wb, " bbHeight=", shapeStyle.BbHeight)
+	writeIntAttr(wb, " bbIterations=", shapeStyle.BbIterations)
+	writeAttr(wb, " fillTexture=", shapeStyle.FillTexture)      // nullable
+	writeAttr(wb, " strokeTexture=", shapeStyle.StrokeTexture)  // nullable
+	writeRgbaAttr(wb, "  strokePaint=", shapeStyle.StrokePaint) // not nullable
+	writeRgbanAttr(wb, "  fillPaint=", shapeStyle.FillPaint)    // nullable
+	writeRgbanAttr(wb, "  dscolor=", shapeStyle.DsColor)        // nullable
+	writeRgbanAttr(wb, "  insColor=", shapeStyle.InsColor)      // nullable
+	writeAttr(wb, " lineCap=", shapeStyle.LineCap)
+	writeAttr(wb, " lineJoin=", shapeStyle.LineJoin)
+	wb.WriteString(extraAttrs(shapeStyle.Extras))
+	wb.WriteString(endEmpty("shapestyle", " />", shapeStyle.Extras) + "\n")
 	return nil
 }
@@ -0,0 +1,38 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package v1_06
+
+import (
+	"encoding/xml"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
+)
+
+// extras returns what a schema struct's ",any" fields caught on an element --
+// the attributes and child elements schema 1.06 as this codec knows it does not
+// name -- or nil when they caught nothing.
+func extras(attrs []xml.Attr, elements []xmlstream.Element) *wxx.Extras_t {
+	return xmlstream.Extras(acceptedApps.Schema, attrs, elements)
+}
+
+// extraAttrs returns x's attributes as they go at the end of a start tag. Extras
+// read against another schema are not written; xmlio reports them as dropped.
+func extraAttrs(x *wxx.Extras_t) string {
+	return xmlstream.ExtraAttrs(x, acceptedApps.Schema)
+}
+
+// extraElements returns x's child elements as they go before an end tag.
+func extraElements(x *wxx.Extras_t) string {
+	return xmlstream.ExtraElements(x, acceptedApps.Schema)
+}
+
+// endEmpty ends a start tag the encoder writes as an empty-element tag, which
+// empty spells ("/>" or " />"). An element with child elements in x cannot be
+// empty, and is given its children and an end tag instead.
+func endEmpty(name, empty string, x *wxx.Extras_t) string {
+	if children := extraElements(x); children != "" {
+		return ">" + children + "</" + name + ">"
+	}
+	return empty
+}
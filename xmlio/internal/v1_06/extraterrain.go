@@ -4,31 +4,88 @@ package v1_06
 
 import (
 	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/maloquacious/wxx"
 )
 
 // decodeExtraTerrain copies the optional top-level <extraTerrain> element into
-// the domain map. src is nil when the element is absent; an empty container is
-// preserved by carrying its (possibly whitespace) inner XML verbatim so that a
-// present-but-empty element round-trips and any unseen children survive.
-func decodeExtraTerrain(src *ExtraTerrain_t, w *wxx.Map_t) {
+// the domain map. src is nil when the element is absent, and an empty container
+// decodes to a non-nil ExtraTerrain_t with no layers, so that a present-but-empty
+// element round-trips.
+func decodeExtraTerrain(src *ExtraTerrain_t, w *wxx.Map_t) error {
 	if src == nil {
-		return
+		return nil
+	}
+	w.ExtraTerrain = &wxx.ExtraTerrain_t{}
+	for _, mLayer := range src.MapLayers {
+		wLayer := &wxx.ExtraTerrainLayer_t{Name: mLayer.Name, Extras: extras(mLayer.ExtraAttrs, mLayer.ExtraElements)}
+		for _, mTerrain := range mLayer.TerrainAndLocation {
+			x, y, err := decodeTerrainLocation(mTerrain.Location)
+			if err != nil {
+				return fmt.Errorf("mapLayer %q: terrainAndLocation.location: %w", mLayer.Name, err)
+			}
+			wLayer.Terrain = append(wLayer.Terrain, &wxx.TerrainAndLocation_t{
+				Terrain:   mTerrain.Name,
+				Elevation: mTerrain.Elevation,
+				IsIcy:     mTerrain.Icy,
+				IsGMOnly:  mTerrain.GMOnly,
+				Resources: mTerrain.Resources,
+				X:         x,
+				Y:         y,
+				Extras:    extras(mTerrain.ExtraAttrs, mTerrain.ExtraElements),
+			})
+		}
+		w.ExtraTerrain.MapLayers = append(w.ExtraTerrain.MapLayers, wLayer)
+	}
+	return nil
+}
+
+// decodeTerrainLocation parses a terrainAndLocation location, "x,y".
+func decodeTerrainLocation(s string) (x, y float64, err error) {
+	xs, ys, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %q: want x,y", wxx.ErrInvalidTerrainLocation, s)
 	}
-	w.ExtraTerrain = &wxx.ExtraTerrain_t{
-		InnerXML: src.InnerXML,
+	if x, err = strconv.ParseFloat(xs, 64); err != nil {
+		return 0, 0, fmt.Errorf("%w: %q: %w", wxx.ErrInvalidTerrainLocation, s, err)
 	}
+	if y, err = strconv.ParseFloat(ys, 64); err != nil {
+		return 0, 0, fmt.Errorf("%w: %q: %w", wxx.ErrInvalidTerrainLocation, s, err)
+	}
+	return x, y, nil
 }
 
-// encodeExtraTerrain emits <extraTerrain> only when present (non-nil). The
-// preserved inner XML is written verbatim between the tags.
+// encodeExtraTerrain emits <extraTerrain> only when present (non-nil), laid out
+// as Worldographer writes it: one tab of indent per level, and a space before
+// the "/>" that closes each <terrainAndLocation>.
 func encodeExtraTerrain(extraTerrain *wxx.ExtraTerrain_t, wb *bytes.Buffer) error {
 	if extraTerrain == nil {
 		return nil
 	}
-	wb.WriteString("<extraTerrain>")
-	wb.WriteString(extraTerrain.InnerXML)
+	wb.WriteString("<extraTerrain>\n")
+	for _, layer := range extraTerrain.MapLayers {
+		writeAttr(wb, "\t<mapLayer name=", layer.Name)
+		wb.WriteString(extraAttrs(layer.Extras))
+		wb.WriteString(">\n")
+		for _, t := range layer.Terrain {
+			wb.WriteString("\t\t<terrainAndLocation")
+			writeAttr(wb, " name=", t.Terrain)
+			wb.WriteString(" elevation=")
+			wb.Write(append(strconv.AppendFloat(append(wb.AvailableBuffer(), '"'), t.Elevation, 'f', -1, 64), '"'))
+			writeBoolAttr(wb, " icy=", t.IsIcy)
+			writeBoolAttr(wb, " gmOnly=", t.IsGMOnly)
+			writeAttr(wb, " resources=", t.Resources)
+			wb.WriteString(" location=")
+			wb.Write(append(appendFloats(append(appendFloats(append(wb.AvailableBuffer(), '"'), t.X), ','), t.Y), '"'))
+			wb.WriteString(extraAttrs(t.Extras))
+			wb.WriteString(endEmpty("terrainAndLocation", " />", t.Extras) + "\n")
+		}
+		wb.WriteString(extraElements(layer.Extras))
+		wb.WriteString("\t</mapLayer>\n")
+	}
 	wb.WriteString("</extraTerrain>\n")
 	return nil
 }
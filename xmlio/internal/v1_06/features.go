@@ -4,15 +4,15 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // decodeFeatures copies each <feature> (with its <location> and optional inline
 // <label>) into the domain map. A labelless feature leaves Feature.Label nil so
 // the encoder omits the <label> child.
-func decodeFeatures(src Features, w *wxx.Map_t) error {
+func decodeFeatures(src Features, w *wxx.Map_t, lax *xmlstream.Lenient) error {
 	var err error
 	for _, mFeature := range src.Features {
 		f := &wxx.Feature_t{}
@@ -25,11 +25,11 @@ func decodeFeatures(src Features, w *wxx.Map_t) error {
 		f.Scale = mFeature.Scale
 		f.ScaleHt = mFeature.ScaleHt
 		f.Tags = mFeature.Tags
-		if f.Color, err = decodeRgba(mFeature.Color); err != nil {
-			return fmt.Errorf("feature.Color: %w", err)
+		if f.Color, err = lax.RGBA(decodeRgba, mFeature.Color, "feature.Color"); err != nil {
+			return err
 		}
-		if f.RingColor, err = decodeRgba(mFeature.RingColor); err != nil {
-			return fmt.Errorf("feature.RingColor: %w", err)
+		if f.RingColor, err = lax.RGBA(decodeRgba, mFeature.RingColor, "feature.RingColor"); err != nil {
+			return err
 		}
 		f.IsGMOnly = mFeature.IsGMOnly
 		f.IsPlaceFreely = mFeature.IsPlaceFreely
@@ -45,7 +45,9 @@ func decodeFeatures(src Features, w *wxx.Map_t) error {
 			ViewLevel: mFeature.Location.ViewLevel,
 			X:         mFeature.Location.X,
 			Y:         mFeature.Location.Y,
+			Extras:    extras(mFeature.Location.ExtraAttrs, mFeature.Location.ExtraElements),
 		}
+		f.Extras = extras(mFeature.ExtraAttrs, mFeature.ExtraElements)
 
 		// only build f.Label when the source feature actually had a <label> child;
 		// a labelless feature must leave f.Label nil so the encoder omits <label>.
@@ -65,21 +67,23 @@ func decodeFeatures(src Features, w *wxx.Map_t) error {
 				IsGMOnly:    mFeature.Label.IsGMOnly,
 				Tags:        mFeature.Label.Tags,
 				InnerText:   mFeature.Label.InnerText,
+				Extras:      extras(mFeature.Label.ExtraAttrs, mFeature.Label.ExtraElements),
 			}
-			if f.Label.Color, err = decodeRgba(mFeature.Label.Color); err != nil {
-				return fmt.Errorf("feature.label.color: %w", err)
+			if f.Label.Color, err = lax.RGBA(decodeRgba, mFeature.Label.Color, "feature.label.color"); err != nil {
+				return err
 			}
-			if f.Label.OutlineColor, err = decodeRgba(mFeature.Label.OutlineColor); err != nil {
-				return fmt.Errorf("feature.label.outlineColor: %w", err)
+			if f.Label.OutlineColor, err = lax.RGBA(decodeRgba, mFeature.Label.OutlineColor, "feature.label.outlineColor"); err != nil {
+				return err
 			}
-			if f.Label.BackgroundColor, err = decodeRgba(mFeature.Label.BackgroundColor); err != nil {
-				return fmt.Errorf("feature.label.backgroundColor: %w", err)
+			if f.Label.BackgroundColor, err = lax.RGBA(decodeRgba, mFeature.Label.BackgroundColor, "feature.label.backgroundColor"); err != nil {
+				return err
 			}
 			f.Label.Location = &wxx.LabelLocation_t{
 				ViewLevel: mFeature.Label.Location.ViewLevel,
 				X:         mFeature.Label.Location.X,
 				Y:         mFeature.Label.Location.Y,
 				Scale:     mFeature.Label.Location.Scale,
+				Extras:    extras(mFeature.Label.Location.ExtraAttrs, mFeature.Label.Location.ExtraElements),
 			}
 		}
 		w.Features = append(w.Features, f)
@@ -100,28 +104,29 @@ func encodeFeatures(features []*wxx.Feature_t, wb *bytes.Buffer) error {
 }
 
 func encodeFeature(feature *wxx.Feature_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<feature"))
-	wb.WriteString(fmt.Sprintf(" type=%q", feature.Type))
-	wb.WriteString(fmt.Sprintf(" rotate=%q", floats(feature.Rotate)))
-	wb.WriteString(fmt.Sprintf(" uuid=%q", feature.Uuid))
-	wb.WriteString(fmt.Sprintf(" mapLayer=%q", feature.MapLayer))
-	wb.WriteString(fmt.Sprintf(" isFlipHorizontal=%q", bools(feature.IsFlipHorizontal)))
-	wb.WriteString(fmt.Sprintf(" isFlipVertical=%q", bools(feature.IsFlipVertical)))
-	wb.WriteString(fmt.Sprintf(" scale=%q", floats(feature.Scale)))
-	wb.WriteString(fmt.Sprintf(" scaleHt=%q", floats(feature.ScaleHt)))
-	wb.WriteString(fmt.Sprintf(" tags=%q", feature.Tags))
-	wb.WriteString(fmt.Sprintf(" color=%q", rgbans(feature.Color))) // nullable
-	wb.WriteString(fmt.Sprintf(" ringcolor=%q", rgbans(feature.RingColor)))
-	wb.WriteString(fmt.Sprintf(" isGMOnly=%q", bools(feature.IsGMOnly)))
-	wb.WriteString(fmt.Sprintf(" isPlaceFreely=%q", bools(feature.IsPlaceFreely)))
-	wb.WriteString(fmt.Sprintf(" labelPosition=%q", feature.LabelPosition))
-	wb.WriteString(fmt.Sprintf(" labelDistance=%q", ints(feature.LabelDistance)))
-	wb.WriteString(fmt.Sprintf(" isWorld=%q", bools(feature.IsWorld)))
-	wb.WriteString(fmt.Sprintf(" isContinent=%q", bools(feature.IsContinent)))
-	wb.WriteString(fmt.Sprintf(" isKingdom=%q", bools(feature.IsKingdom)))
-	wb.WriteString(fmt.Sprintf(" isProvince=%q", bools(feature.IsProvince)))
-	wb.WriteString(fmt.Sprintf(" isFillHexBottom=%q", bools(feature.IsFillHexBottom)))
-	wb.WriteString(fmt.Sprintf(" isHideTerrainIcon=%q", bools(feature.IsHideTerrainIcon)))
+	wb.WriteString("<feature")
+	writeAttr(wb, " type=", feature.Type)
+	writeFloatAttr(wb, " rotate=", feature.Rotate)
+	writeAttr(wb, " uuid=", feature.Uuid)
+	writeAttr(wb, " mapLayer=", feature.MapLayer)
+	writeBoolAttr(wb, " isFlipHorizontal=", feature.IsFlipHorizontal)
+	writeBoolAttr(wb, " isFlipVertical=", feature.IsFlipVertical)
+	writeFloatAttr(wb, " scale=", feature.Scale)
+	writeFloatAttr(wb, " scaleHt=", feature.ScaleHt)
+	writeAttr(wb, " tags=", feature.Tags)
+	writeRgbanAttr(wb, " color=", feature.Color) // nullable
+	writeRgbanAttr(wb, " ringcolor=", feature.RingColor)
+	writeBoolAttr(wb, " isGMOnly=", feature.IsGMOnly)
+	writeBoolAttr(wb, " isPlaceFreely=", feature.IsPlaceFreely)
+	writeAttr(wb, " labelPosition=", feature.LabelPosition)
+	writeIntAttr(wb, " labelDistance=", feature.LabelDistance)
+	writeBoolAttr(wb, " isWorld=", feature.IsWorld)
+	writeBoolAttr(wb, " isContinent=", feature.IsContinent)
+	writeBoolAttr(wb, " isKingdom=", feature.IsKingdom)
+	writeBoolAttr(wb, " isProvince=", feature.IsProvince)
+	writeBoolAttr(wb, " isFillHexBottom=", feature.IsFillHexBottom)
+	writeBoolAttr(wb, " isHideTerrainIcon=", feature.IsHideTerrainIcon)
+	wb.WriteString(extraAttrs(feature.Extras))
 	wb.WriteString(">")
 	if feature.Location != nil {
 		if err := encodeFeatureLocation(feature.Location, wb); err != nil {
@@ -133,16 +138,18 @@ func encodeFeature(feature *wxx.Feature_t, wb *bytes.Buffer) error {
 			return err
 		}
 	}
+	wb.WriteString(extraElements(feature.Extras))
 	wb.WriteString("</feature>\n")
 	return nil
 }
 
 func encodeFeatureLocation(location *wxx.FeatureLocation_t, wb *bytes.Buffer) error {
 	wb.WriteString("<location")
-	wb.WriteString(fmt.Sprintf(" viewLevel=%q", location.ViewLevel))
-	wb.WriteString(fmt.Sprintf(" x=%q", floats(location.X)))
-	wb.WriteString(fmt.Sprintf(" y=%q", floats(location.Y)))
-	wb.WriteString(" />")
+	writeAttr(wb, " viewLevel=", location.ViewLevel)
+	writeFloatAttr(wb, " x=", location.X)
+	writeFloatAttr(wb, " y=", location.Y)
+	wb.WriteString(extraAttrs(location.Extras))
+	wb.WriteString(endEmpty("location", " />", location.Extras))
 	return nil
 }
 
@@ -4,7 +4,6 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
 )
@@ -44,41 +43,43 @@ func decodeGridAndNumbering(src GridAndNumbering, w *wxx.Map_t) {
 	w.GridAndNumbering.NumberPosition = src.NumberPosition
 	w.GridAndNumbering.NumberPrePad = src.NumberPrePad
 	w.GridAndNumbering.NumberSeparator = src.NumberSeparator
+	w.GridAndNumbering.Extras = extras(src.ExtraAttrs, src.ExtraElements)
 }
 
 func encodeGridAndNumbering(gridAndNumbering *wxx.GridAndNumbering_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf(`<gridandnumbering`))
-	wb.WriteString(fmt.Sprintf(" color0=%q", gridAndNumbering.Color0))
-	wb.WriteString(fmt.Sprintf(" color1=%q", gridAndNumbering.Color1))
-	wb.WriteString(fmt.Sprintf(" color2=%q", gridAndNumbering.Color2))
-	wb.WriteString(fmt.Sprintf(" color3=%q", gridAndNumbering.Color3))
-	wb.WriteString(fmt.Sprintf(" color4=%q", gridAndNumbering.Color4))
-	wb.WriteString(fmt.Sprintf(" width0=%q", floats(gridAndNumbering.Width0)))
-	wb.WriteString(fmt.Sprintf(" width1=%q", floats(gridAndNumbering.Width1)))
-	wb.WriteString(fmt.Sprintf(" width2=%q", floats(gridAndNumbering.Width2)))
-	wb.WriteString(fmt.Sprintf(" width3=%q", floats(gridAndNumbering.Width3)))
-	wb.WriteString(fmt.Sprintf(" width4=%q", floats(gridAndNumbering.Width4)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetContinentKingdomX=%q", floats(gridAndNumbering.GridOffsetContinentKingdomX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetContinentKingdomY=%q", floats(gridAndNumbering.GridOffsetContinentKingdomY)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldContinentX=%q", floats(gridAndNumbering.GridOffsetWorldContinentX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldContinentY=%q", floats(gridAndNumbering.GridOffsetWorldContinentY)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldKingdomX=%q", floats(gridAndNumbering.GridOffsetWorldKingdomX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetWorldKingdomY=%q", floats(gridAndNumbering.GridOffsetWorldKingdomY)))
-	wb.WriteString(fmt.Sprintf(" gridSquare=%q", ints(gridAndNumbering.GridSquare)))
-	wb.WriteString(fmt.Sprintf(" gridSquareHeight=%q", floats(gridAndNumbering.GridSquareHeight)))
-	wb.WriteString(fmt.Sprintf(" gridSquareWidth=%q", floats(gridAndNumbering.GridSquareWidth)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetX=%q", floats(gridAndNumbering.GridOffsetX)))
-	wb.WriteString(fmt.Sprintf(" gridOffsetY=%q", floats(gridAndNumbering.GridOffsetY)))
-	wb.WriteString(fmt.Sprintf(" numberFont=%q", gridAndNumbering.NumberFont))
-	wb.WriteString(fmt.Sprintf(" numberColor=%q", gridAndNumbering.NumberColor))
-	wb.WriteString(fmt.Sprintf(" numberSize=%q", ints(gridAndNumbering.NumberSize)))
-	wb.WriteString(fmt.Sprintf(" numberStyle=%q", gridAndNumbering.NumberStyle))
-	wb.WriteString(fmt.Sprintf(" numberFirstCol=%q", ints(gridAndNumbering.NumberFirstCol)))
-	wb.WriteString(fmt.Sprintf(" numberFirstRow=%q", ints(gridAndNumbering.NumberFirstRow)))
-	wb.WriteString(fmt.Sprintf(" numberOrder=%q", gridAndNumbering.NumberOrder))
-	wb.WriteString(fmt.Sprintf(" numberPosition=%q", gridAndNumbering.NumberPosition))
-	wb.WriteString(fmt.Sprintf(" numberPrePad=%q", gridAndNumbering.NumberPrePad))
-	wb.WriteString(fmt.Sprintf(" numberSeparator=%q", gridAndNumbering.NumberSeparator))
-	wb.WriteString(fmt.Sprintf(" />\n"))
+	wb.WriteString(`<gridandnumbering`)
+	writeAttr(wb, " color0=", gridAndNumbering.Color0)
+	writeAttr(wb, " color1=", gridAndNumbering.Color1)
+	writeAttr(wb, " color2=", gridAndNumbering.Color2)
+	writeAttr(wb, " color3=", gridAndNumbering.Color3)
+	writeAttr(wb, " color4=", gridAndNumbering.Color4)
+	writeFloatAttr(wb, " width0=", gridAndNumbering.Width0)
+	writeFloatAttr(wb, " width1=", gridAndNumbering.Width1)
+	writeFloatAttr(wb, " width2=", gridAndNumbering.Width2)
+	writeFloatAttr(wb, " width3=", gridAndNumbering.Width3)
+	writeFloatAttr(wb, " width4=", gridAndNumbering.Width4)
+	writeFloatAttr(wb, " gridOffsetContinentKingdomX=", gridAndNumbering.GridOffsetContinentKingdomX)
+	writeFloatAttr(wb, " gridOffsetContinentKingdomY=", gridAndNumbering.GridOffsetContinentKingdomY)
+	writeFloatAttr(wb, " gridOffsetWorldContinentX=", gridAndNumbering.GridOffsetWorldContinentX)
+	writeFloatAttr(wb, " gridOffsetWorldContinentY=", gridAndNumbering.GridOffsetWorldContinentY)
+	writeFloatAttr(wb, " gridOffsetWorldKingdomX=", gridAndNumbering.GridOffsetWorldKingdomX)
+	writeFloatAttr(wb, " gridOffsetWorldKingdomY=", gridAndNumbering.GridOffsetWorldKingdomY)
+	writeIntAttr(wb, " gridSquare=", gridAndNumbering.GridSquare)
+	writeFloatAttr(wb, " gridSquareHeight=", gridAndNumbering.GridSquareHeight)
+	writeFloatAttr(wb, " gridSquareWidth=", gridAndNumbering.GridSquareWidth)
+	writeFloatAttr(wb, " gridOffsetX=", gridAndNumbering.GridOffsetX)
+	writeFloatAttr(wb, " gridOffsetY=", gridAndNumbering.GridOffsetY)
+	writeAttr(wb, " numberFont=", gridAndNumbering.NumberFont)
+	writeAttr(wb, " numberColor=", gridAndNumbering.NumberColor)
+	writeIntAttr(wb, " numberSize=", gridAndNumbering.NumberSize)
+	writeAttr(wb, " numberStyle=", gridAndNumbering.NumberStyle)
+	writeIntAttr(wb, " numberFirstCol=", gridAndNumbering.NumberFirstCol)
+	writeIntAttr(wb, " numberFirstRow=", gridAndNumbering.NumberFirstRow)
+	writeAttr(wb, " numberOrder=", gridAndNumbering.NumberOrder)
+	writeAttr(wb, " numberPosition=", gridAndNumbering.NumberPosition)
+	writeAttr(wb, " numberPrePad=", gridAndNumbering.NumberPrePad)
+	writeAttr(wb, " numberSeparator=", gridAndNumbering.NumberSeparator)
+	wb.WriteString(extraAttrs(gridAndNumbering.Extras))
+	wb.WriteString(endEmpty("gridandnumbering", " />", gridAndNumbering.Extras) + "\n")
 	return nil
 }
@@ -3,12 +3,14 @@
 package v1_06
 
 import (
+	"bytes"
 	"fmt"
-	"html"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // boold formats a bool as an integer
@@ -21,7 +23,7 @@ func boold(b bool) int {
 
 // bools formats a bool as a string
 func bools(b bool) string {
-	return fmt.Sprintf("%v", b)
+	return strconv.FormatBool(b)
 }
 
 // floatd formats a float as an integer.
@@ -59,18 +61,24 @@ func floatf(f float64) string {
 //	floats(1234567.00) returns "1234567.0"
 //	floats(0.120300) returns "0.1203"
 func floats(f float64) string {
-	s := fmt.Sprintf("%g", f)
-	if strings.IndexByte(s, 'e') != -1 {
-		s = fmt.Sprintf("%f", f)
+	return string(appendFloats(nil, f))
+}
+
+// appendFloats appends floats(f) to b.
+func appendFloats(b []byte, f float64) []byte {
+	start := len(b)
+	b = strconv.AppendFloat(b, f, 'g', -1, 64)
+	if bytes.IndexByte(b[start:], 'e') != -1 {
+		b = strconv.AppendFloat(b[:start], f, 'f', 6, 64)
 	}
-	if strings.IndexByte(s, '.') == -1 {
-		return s + ".0"
+	if bytes.IndexByte(b[start:], '.') == -1 {
+		return append(b, ".0"...)
 	}
-	s = strings.TrimRight(s, "0")
-	if s[len(s)-1] == '.' {
-		return s + "0"
+	b = bytes.TrimRight(b, "0")
+	if b[len(b)-1] == '.' {
+		return append(b, '0')
 	}
-	return s
+	return b
 }
 
 // floatg formats a float in the style that Worldographer expects.
@@ -80,17 +88,15 @@ func floatg(f float64) string {
 
 // ints formats an int as a string
 func ints(i int) string {
-	return fmt.Sprintf("%d", i)
+	return strconv.Itoa(i)
 }
 
-// rgbans converts an RGBA_t to a nullable string.
-// It uses the rgbas function to format the RGBA_t
+// rgbans converts an RGBA_t to a nullable string: nil, "no color", is "null".
 func rgbans(rgba *wxx.RGBA_t) string {
-	s := rgbas(rgba)
-	if s == "0.0,0.0,0.0,1.0" {
-		s = "null"
+	if rgba == nil {
+		return "null"
 	}
-	return s
+	return rgbas(rgba)
 }
 
 // rgbas converts an RGBA_t struct into an XML attribute string.
@@ -99,6 +105,9 @@ func rgbans(rgba *wxx.RGBA_t) string {
 // If the provided rgba pointer is nil, it defaults to "0.0,0.0,0.0,1.0".
 //
 // We use the floats function to format the float values into an XML-friendly format.
+// A color that was decoded is written as the file spelled it instead, for as long
+// as Raw still parses to the same four values, so an unedited "0,0,0,1" is not
+// rewritten as "0.0,0.0,0.0,1.0".
 //
 // Parameters:
 // - rgba: a pointer to an RGBA_t struct. Can be nil.
@@ -106,14 +115,42 @@ func rgbans(rgba *wxx.RGBA_t) string {
 // Returns:
 // - A XML attribute string representing the rgba. If rgba is nil, returns "0.0,0.0,0.0,1.0"
 func rgbas(rgba *wxx.RGBA_t) string {
+	return string(appendRgbas(nil, rgba))
+}
+
+// appendRgbas appends rgbas(rgba) to b.
+func appendRgbas(b []byte, rgba *wxx.RGBA_t) []byte {
 	if rgba == nil {
-		return "0.0,0.0,0.0,1.0"
+		return append(b, "0.0,0.0,0.0,1.0"...)
+	}
+	if rawRgba(rgba) {
+		return append(b, rgba.Raw...)
 	}
-	return fmt.Sprintf("%s,%s,%s,%s",
-		floats(rgba.R),
-		floats(rgba.G),
-		floats(rgba.B),
-		floats(rgba.A))
+	b = appendFloats(b, rgba.R)
+	b = appendFloats(append(b, ','), rgba.G)
+	b = appendFloats(append(b, ','), rgba.B)
+	return appendFloats(append(b, ','), rgba.A)
+}
+
+// rawRgba reports whether rgba is written as the file spelled it: it has a Raw
+// spelling and that spelling still parses to the same four values. It reads Raw
+// as decodeRgba does, but without allocating, since it is asked once per tile.
+func rawRgba(rgba *wxx.RGBA_t) bool {
+	if rgba.Raw == "" {
+		return false
+	}
+	want, rest := [4]float64{rgba.R, rgba.G, rgba.B, rgba.A}, rgba.Raw
+	for i := range want {
+		value, tail, found := strings.Cut(rest, ",")
+		if found == (i == len(want)-1) {
+			return false // not four values
+		}
+		if f, err := strconv.ParseFloat(value, 64); err != nil || f != want[i] {
+			return false
+		}
+		rest = tail
+	}
+	return true
 }
 
 // terrainMapToSlice converts a map of terrain names and slot into a list
@@ -141,7 +178,69 @@ func terrainMapToSlice(data map[string]int) []string {
 	return s
 }
 
-func encodeInnerText(input string) string {
-	escaped := html.EscapeString(input) // Escapes < > & "
-	return strings.ReplaceAll(escaped, "\n", "&#10;")
+// writeText writes s as character data, escaped by xmlstream.AppendText.
+func writeText(wb *bytes.Buffer, s string) {
+	wb.Write(xmlstream.AppendText(wb.AvailableBuffer(), s))
+}
+
+// The write helpers put an attribute straight into wb, without the strings that
+// fmt.Sprintf and the formatting helpers above allocate. Each writes prefix, which
+// is the leading space and "name=", and then the quoted value. A string value is
+// escaped by xmlstream.AppendAttr; the others need no escaping.
+
+// writeAttr writes a string attribute.
+func writeAttr(wb *bytes.Buffer, prefix, value string) {
+	wb.WriteString(prefix)
+	wb.Write(xmlstream.AppendAttr(wb.AvailableBuffer(), value))
+}
+
+// writeBoolAttr writes a bool attribute as "true" or "false".
+func writeBoolAttr(wb *bytes.Buffer, prefix string, value bool) {
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = strconv.AppendBool(b, value)
+	wb.Write(append(b, '"'))
+}
+
+// writeIntAttr writes an int attribute.
+func writeIntAttr(wb *bytes.Buffer, prefix string, value int) {
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = strconv.AppendInt(b, int64(value), 10)
+	wb.Write(append(b, '"'))
+}
+
+// writeFloatAttr writes a float attribute formatted by floats.
+func writeFloatAttr(wb *bytes.Buffer, prefix string, value float64) {
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = appendFloats(b, value)
+	wb.Write(append(b, '"'))
+}
+
+// writeRgbaAttr writes a color attribute formatted by rgbas.
+func writeRgbaAttr(wb *bytes.Buffer, prefix string, value *wxx.RGBA_t) {
+	if value != nil && rawRgba(value) {
+		writeAttr(wb, prefix, value.Raw)
+		return
+	}
+	wb.WriteString(prefix)
+	b := append(wb.AvailableBuffer(), '"')
+	b = appendRgbas(b, value)
+	wb.Write(append(b, '"'))
+}
+
+// writeRgbanAttr writes a nullable color attribute formatted by rgbans.
+func writeRgbanAttr(wb *bytes.Buffer, prefix string, value *wxx.RGBA_t) {
+	if value == nil {
+		wb.WriteString(prefix)
+		wb.WriteString(`"null"`)
+		return
+	}
+	writeRgbaAttr(wb, prefix, value)
+}
+
+// writeInt writes an int without quotes, as a tile line field is written.
+func writeInt(wb *bytes.Buffer, value int) {
+	wb.Write(strconv.AppendInt(wb.AvailableBuffer(), int64(value), 10))
 }
@@ -4,13 +4,13 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // decodeInformations copies the <informations> tree (including nested
-// <information> detail children) into the domain map.
+// <information> detail children, however deep) into the domain map.
 func decodeInformations(src Informations_t, w *wxx.Map_t) {
 	w.Informations = &wxx.Informations_t{}
 	for _, info := range src.Informations {
@@ -26,39 +26,49 @@ func decodeInformations(src Informations_t, w *wxx.Map_t) {
 			Culture:      info.Culture,
 			HolySymbol:   info.HolySymbol,
 			Domains:      info.Domains,
+			Details:      decodeInformationDetails(info.Details),
 			InnerText:    info.InnerText,
+			IsCDATA:      xmlstream.StartsWithCDATA(info.InnerXML),
+			Extras:       extras(info.ExtraAttrs, info.ExtraElements),
 		}
-
-		for _, detail := range info.Details {
-			wDetail := &wxx.InformationDetail_t{
-				Uuid:         detail.Uuid,
-				Type:         detail.Type,
-				Title:        detail.Title,
-				Rulers:       detail.Rulers,
-				Government:   detail.Government,
-				Cultures:     detail.Cultures,
-				Language:     detail.Language,
-				ReligionType: detail.ReligionType,
-				Culture:      detail.Culture,
-				HolySymbol:   detail.HolySymbol,
-				Domains:      detail.Domains,
-				InnerText:    detail.InnerText,
-			}
-			wInfo.Details = append(wInfo.Details, wDetail)
-		}
-
 		w.Informations.Informations = append(w.Informations.Informations, wInfo)
 	}
 	w.Informations.InnerText = src.InnerText
 }
 
+// decodeInformationDetails copies the <information> children of an entry, and
+// theirs.
+func decodeInformationDetails(src []Information_t) []*wxx.InformationDetail_t {
+	var details []*wxx.InformationDetail_t
+	for _, detail := range src {
+		details = append(details, &wxx.InformationDetail_t{
+			Uuid:         detail.Uuid,
+			Type:         detail.Type,
+			Title:        detail.Title,
+			Rulers:       detail.Rulers,
+			Government:   detail.Government,
+			Cultures:     detail.Cultures,
+			Language:     detail.Language,
+			ReligionType: detail.ReligionType,
+			Culture:      detail.Culture,
+			HolySymbol:   detail.HolySymbol,
+			Domains:      detail.Domains,
+			Details:      decodeInformationDetails(detail.Details),
+			InnerText:    detail.InnerText,
+			IsCDATA:      xmlstream.StartsWithCDATA(detail.InnerXML),
+			Extras:       extras(detail.ExtraAttrs, detail.ExtraElements),
+		})
+	}
+	return details
+}
+
 func encodeInformations(informations *wxx.Informations_t, wb *bytes.Buffer) error {
 	wb.WriteString("<informations>")
 	// The wrapper's chardata (whitespace between <information> children) is
 	// emitted here as escaped text. The <information> children below are emitted
 	// back-to-back with no surrounding whitespace, so on re-decode the wrapper's
 	// chardata is exactly informations.InnerText.
-	wb.WriteString(encodeInnerText(informations.InnerText))
+	writeText(wb, informations.InnerText)
 	for _, information := range informations.Informations {
 		if err := encodeInformation(information, wb); err != nil {
 			return err
@@ -70,46 +80,65 @@ func encodeInformations(informations *wxx.Informations_t, wb *bytes.Buffer) erro
 
 func encodeInformation(information *wxx.Information_t, wb *bytes.Buffer) error {
 	wb.WriteString("<information")
-	wb.WriteString(fmt.Sprintf(" uuid=%q", information.Uuid))
-	wb.WriteString(fmt.Sprintf(" type=%q", information.Type))
-	wb.WriteString(fmt.Sprintf(" title=%q", information.Title))
-	wb.WriteString(fmt.Sprintf(" rulers=%q", information.Rulers))
-	wb.WriteString(fmt.Sprintf(" government=%q", information.Government))
-	wb.WriteString(fmt.Sprintf(" cultures=%q", information.Cultures))
-	wb.WriteString(fmt.Sprintf(" language=%q", information.Language))
-	wb.WriteString(fmt.Sprintf(" religionType=%q", information.ReligionType))
-	wb.WriteString(fmt.Sprintf(" culture=%q", information.Culture))
-	wb.WriteString(fmt.Sprintf(" holySymbol=%q", information.HolySymbol))
-	wb.WriteString(fmt.Sprintf(" domains=%q", information.Domains))
+	writeAttr(wb, " uuid=", information.Uuid)
+	writeAttr(wb, " type=", information.Type)
+	writeAttr(wb, " title=", information.Title)
+	writeAttr(wb, " rulers=", information.Rulers)
+	writeAttr(wb, " government=", information.Government)
+	writeAttr(wb, " cultures=", information.Cultures)
+	writeAttr(wb, " language=", information.Language)
+	writeAttr(wb, " religionType=", information.ReligionType)
+	writeAttr(wb, " culture=", information.Culture)
+	writeAttr(wb, " holySymbol=", information.HolySymbol)
+	writeAttr(wb, " domains=", information.Domains)
+	wb.WriteString(extraAttrs(information.Extras))
 	wb.WriteString(">")
 	// Emit this element's chardata first, then its <information> detail children
 	// back-to-back with no surrounding whitespace, so on re-decode this element's
 	// chardata is exactly information.InnerText.
-	wb.WriteString(encodeInnerText(information.InnerText))
+	writeInformationText(wb, information.InnerText, information.IsCDATA)
 	for _, detail := range information.Details {
 		if err := encodeInformationDetail(detail, wb); err != nil {
 			return err
 		}
 	}
+	wb.WriteString(extraElements(information.Extras))
 	wb.WriteString("</information>")
 	return nil
 }
 
 func encodeInformationDetail(detail *wxx.InformationDetail_t, wb *bytes.Buffer) error {
 	wb.WriteString("<information")
-	wb.WriteString(fmt.Sprintf(" uuid=%q", detail.Uuid))
-	wb.WriteString(fmt.Sprintf(" type=%q", detail.Type))
-	wb.WriteString(fmt.Sprintf(" title=%q", detail.Title))
-	wb.WriteString(fmt.Sprintf(" rulers=%q", detail.Rulers))
-	wb.WriteString(fmt.Sprintf(" government=%q", detail.Government))
-	wb.WriteString(fmt.Sprintf(" cultures=%q", detail.Cultures))
-	wb.WriteString(fmt.Sprintf(" language=%q", detail.Language))
-	wb.WriteString(fmt.Sprintf(" religionType=%q", detail.ReligionType))
-	wb.WriteString(fmt.Sprintf(" culture=%q", detail.Culture))
-	wb.WriteString(fmt.Sprintf(" holySymbol=%q", detail.HolySymbol))
-	wb.WriteString(fmt.Sprintf(" domains=%q", detail.Domains))
+	writeAttr(wb, " uuid=", detail.Uuid)
+	writeAttr(wb, " type=", detail.Type)
+	writeAttr(wb, " title=", detail.Title)
+	writeAttr(wb, " rulers=", detail.Rulers)
+	writeAttr(wb, " government=", detail.Government)
+	writeAttr(wb, " cultures=", detail.Cultures)
+	writeAttr(wb, " language=", detail.Language)
+	writeAttr(wb, " religionType=", detail.ReligionType)
+	writeAttr(wb, " culture=", detail.Culture)
+	writeAttr(wb, " holySymbol=", detail.HolySymbol)
+	writeAttr(wb, " domains=", detail.Domains)
+	wb.WriteString(extraAttrs(detail.Extras))
 	wb.WriteString(">")
-	wb.WriteString(encodeInnerText(detail.InnerText))
+	writeInformationText(wb, detail.InnerText, detail.IsCDATA)
+	for _, nested := range detail.Details {
+		if err := encodeInformationDetail(nested, wb); err != nil {
+			return err
+		}
+	}
+	wb.WriteString(extraElements(detail.Extras))
 	wb.WriteString("</information>")
 	return nil
 }
+
+// writeInformationText writes the chardata of an <information>, as a CDATA
+// section when the file had one.
+func writeInformationText(wb *bytes.Buffer, s string, isCDATA bool) {
+	if !isCDATA {
+		writeText(wb, s)
+		return
+	}
+	wb.Write(xmlstream.AppendCDATAText(wb.AvailableBuffer(), s))
+}
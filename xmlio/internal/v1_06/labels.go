@@ -4,13 +4,13 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // decodeLabels copies each standalone <label> into the domain map.
-func decodeLabels(src Labels_t, w *wxx.Map_t) error {
+func decodeLabels(src Labels_t, w *wxx.Map_t, lax *xmlstream.Lenient) error {
 	var err error
 	for _, mLabel := range src.Labels {
 		wLabel := &wxx.Label_t{
@@ -27,23 +27,23 @@ func decodeLabels(src Labels_t, w *wxx.Map_t) error {
 			IsProvince:  mLabel.IsProvince,
 			IsGMOnly:    mLabel.IsGMOnly,
 			Tags:        mLabel.Tags,
+			Extras:      extras(mLabel.ExtraAttrs, mLabel.ExtraElements),
 		}
-		if wLabel.Color, err = decodeRgba(mLabel.Color); err != nil {
-			return fmt.Errorf("label.color: %w", err)
+		if wLabel.Color, err = lax.RGBA(decodeRgba, mLabel.Color, "label.color"); err != nil {
+			return err
 		}
-		if wLabel.OutlineColor, err = decodeRgba(mLabel.OutlineColor); err != nil {
-			return fmt.Errorf("label.outlineColor: %w", err)
+		if wLabel.OutlineColor, err = lax.RGBA(decodeRgba, mLabel.OutlineColor, "label.outlineColor"); err != nil {
+			return err
 		}
-		if mLabel.BackgroundColor == "" {
-			wLabel.BackgroundColor = nil
-		} else if wLabel.BackgroundColor, err = decodeZeroableRgba(mLabel.BackgroundColor); err != nil {
-			return fmt.Errorf("label.backgroundColor: %w", err)
+		if wLabel.BackgroundColor, err = lax.RGBA(decodeRgba, mLabel.BackgroundColor, "label.backgroundColor"); err != nil {
+			return err
 		}
 		wLabel.Location = &wxx.LabelLocation_t{
 			ViewLevel: mLabel.Location.ViewLevel,
 			X:         mLabel.Location.X,
 			Y:         mLabel.Location.Y,
 			Scale:     mLabel.Location.Scale,
+			Extras:    extras(mLabel.Location.ExtraAttrs, mLabel.Location.ExtraElements),
 		}
 		wLabel.InnerText = mLabel.InnerText
 		w.Labels = append(w.Labels, wLabel)
@@ -64,44 +64,46 @@ func encodeLabels(labels []*wxx.Label_t, wb *bytes.Buffer) error {
 
 func encodeLabel(label *wxx.Label_t, wb *bytes.Buffer) error {
 	wb.WriteString("<label")
-	wb.WriteString(fmt.Sprintf("  mapLayer=%q", label.MapLayer))
-	wb.WriteString(fmt.Sprintf(" style=%q", label.Style))       // can be null!
-	wb.WriteString(fmt.Sprintf(" fontFace=%q", label.FontFace)) // can be null!
-	wb.WriteString(fmt.Sprintf(" color=%q", rgbas(label.Color)))
-	// todo: backgroundColor is sometimes not displayed when its value is "0.0,0.0,0.0,1.0".
-	// I may need to ask on the Inkwell Discord about this; I can't figure out the pattern.
-	// Until then, seems to be no harm in excluding it (other than noise in the diff).
-	if attr := rgbas(label.BackgroundColor); attr != "0.0,0.0,0.0,1.0" { // do not include if null
-		wb.WriteString(fmt.Sprintf(" backgroundColor=%q", attr))
+	writeAttr(wb, "  mapLayer=", label.MapLayer)
+	writeAttr(wb, " style=", label.Style)       // can be null!
+	writeAttr(wb, " fontFace=", label.FontFace) // can be null!
+	writeRgbaAttr(wb, " color=", label.Color)
+	// backgroundColor is left out when the label has none, which is how the files
+	// that were read said so. An explicit opaque black is a background and is kept.
+	if label.BackgroundColor != nil {
+		writeRgbaAttr(wb, " backgroundColor=", label.BackgroundColor)
 	}
-	wb.WriteString(fmt.Sprintf(" outlineColor=%q", rgbas(label.OutlineColor)))
-	wb.WriteString(fmt.Sprintf(" outlineSize=%q", floats(label.OutlineSize)))
-	wb.WriteString(fmt.Sprintf(" rotate=%q", floats(label.Rotate)))
-	wb.WriteString(fmt.Sprintf(" isBold=%q", bools(label.IsBold)))
-	wb.WriteString(fmt.Sprintf(" isItalic=%q", bools(label.IsItalic)))
-	wb.WriteString(fmt.Sprintf(" isWorld=%q", bools(label.IsWorld)))
-	wb.WriteString(fmt.Sprintf(" isContinent=%q", bools(label.IsContinent)))
-	wb.WriteString(fmt.Sprintf(" isKingdom=%q", bools(label.IsKingdom)))
-	wb.WriteString(fmt.Sprintf(" isProvince=%q", bools(label.IsProvince)))
-	wb.WriteString(fmt.Sprintf(" isGMOnly=%q", bools(label.IsGMOnly)))
-	wb.WriteString(fmt.Sprintf(" tags=%q", label.Tags))
+	writeRgbaAttr(wb, " outlineColor=", label.OutlineColor)
+	writeFloatAttr(wb, " outlineSize=", label.OutlineSize)
+	writeFloatAttr(wb, " rotate=", label.Rotate)
+	writeBoolAttr(wb, " isBold=", label.IsBold)
+	writeBoolAttr(wb, " isItalic=", label.IsItalic)
+	writeBoolAttr(wb, " isWorld=", label.IsWorld)
+	writeBoolAttr(wb, " isContinent=", label.IsContinent)
+	writeBoolAttr(wb, " isKingdom=", label.IsKingdom)
+	writeBoolAttr(wb, " isProvince=", label.IsProvince)
+	writeBoolAttr(wb, " isGMOnly=", label.IsGMOnly)
+	writeAttr(wb, " tags=", label.Tags)
+	wb.WriteString(extraAttrs(label.Extras))
 	wb.WriteString(">")
 	if err := encodeLabelLocation(label.Location, wb); err != nil {
 		return err
 	}
 	if label.InnerText != "" {
-		wb.WriteString(encodeInnerText(label.InnerText))
+		writeText(wb, label.InnerText)
 	}
+	wb.WriteString(extraElements(label.Extras))
 	wb.WriteString("</label>\n")
 	return nil
 }
 
 func encodeLabelLocation(location *wxx.LabelLocation_t, wb *bytes.Buffer) error {
 	wb.WriteString("<location")
-	wb.WriteString(fmt.Sprintf(" viewLevel=%q", location.ViewLevel))
-	wb.WriteString(fmt.Sprintf(" x=%q", floats(location.X)))
-	wb.WriteString(fmt.Sprintf(" y=%q", floats(location.Y)))
-	wb.WriteString(fmt.Sprintf(" scale=%q", floats(location.Scale)))
-	wb.WriteString(" />")
+	writeAttr(wb, " viewLevel=", location.ViewLevel)
+	writeFloatAttr(wb, " x=", location.X)
+	writeFloatAttr(wb, " y=", location.Y)
+	writeFloatAttr(wb, " scale=", location.Scale)
+	wb.WriteString(extraAttrs(location.Extras))
+	wb.WriteString(endEmpty("location", " />", location.Extras))
 	return nil
 }
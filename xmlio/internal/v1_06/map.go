@@ -4,6 +4,7 @@ package v1_06
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"log"
@@ -12,6 +13,7 @@ import (
 	"github.com/maloquacious/wxx"
 	"github.com/maloquacious/wxx/hexg"
 	"github.com/maloquacious/wxx/xmlio/internal/appver"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // dottedOrRaw parses an on-disk dotted version, falling back to a Dotted that
@@ -38,9 +40,13 @@ func dottedOrRaw(s string) wxx.Dotted {
 // (schema.go) then copies the <map> root attributes here and dispatches each
 // child element to its co-located decodeXxx helper (see the per-element files).
 //
-// This is the whole of decode dispatch: xmlio's decoder.go calls it directly,
-// having read the map/@release that identifies the file. There is no Decode on
-// codec.Codec to route through -- see that interface for why.
+// Decode holds the whole document in memory. xmlio's decoder.go calls
+// DecodeReader (stream.go) instead, the bounded-memory path, having read the
+// map/@release that identifies the file; there is no Decode on codec.Codec to
+// route through -- see that interface for why. The two paths share the
+// conversion below, decodeMapAttributes for the <map> root and decodeElements
+// for its children, and differ only in how <tiles> is reached, so they produce
+// the same Map_t.
 func Decode(input []byte) (*wxx.Map_t, error) {
 	m := &XMLSchema{}
 
@@ -50,15 +56,29 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 		log.Printf("v1_06: %v\n", err)
 		return nil, err
 	}
+	w, err := decodeMapAttributes(m, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeElements(m, w, nil, func() error {
+		return decodeTiles(m.Tiles, m.MapKey, w, nil)
+	})
+}
+
+// decodeMapAttributes validates the <map> identity attributes and returns a new
+// domain map populated from the root attributes. It needs nothing below the root
+// element, which is what lets the streaming decoder call it before the first
+// child is read: the tile coordinates depend on the orientation it sets.
+func decodeMapAttributes(m *XMLSchema, lax *xmlstream.Lenient) (*wxx.Map_t, error) {
 	if m.Release == "" {
-		return nil, fmt.Errorf("missing map.Release")
+		return nil, &xmlstream.Error{Field: "release", Err: fmt.Errorf("%w: missing map.Release", wxx.ErrInvalidMapMetadata)}
 	} else if m.Version == "" {
-		return nil, fmt.Errorf("missing map.Version")
+		return nil, &xmlstream.Error{Field: "version", Err: fmt.Errorf("%w: missing map.Version", wxx.ErrInvalidMapMetadata)}
 	} else if m.Schema == "" {
-		return nil, fmt.Errorf("missing map.Schema")
+		return nil, &xmlstream.Error{Field: "schema", Err: fmt.Errorf("%w: missing map.Schema", wxx.ErrInvalidMapMetadata)}
 	}
 	if m.Release != "2025" {
-		return nil, fmt.Errorf("%s/%s/%s: unsupported release", m.Release, m.Version, m.Schema)
+		return nil, &xmlstream.Error{Field: "release", Err: fmt.Errorf("%w: %s/%s/%s: unsupported release", wxx.ErrUnsupportedMapMetadata, m.Release, m.Version, m.Schema)}
 	}
 	// The schema must parse: it is what selects the codec on the way back out
 	// (ADR 0004 Decision 4), so a file whose @schema is not a dotted version is
@@ -66,7 +86,7 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	// is the same input the removed schema-to-semver conversion rejected.
 	schema, err := wxx.ParseDotted(m.Schema)
 	if err != nil {
-		return nil, fmt.Errorf("%s/%s/%s: malformed schema: %w", m.Release, m.Version, m.Schema, err)
+		return nil, &xmlstream.Error{Field: "schema", Err: fmt.Errorf("%w: %s/%s/%s: malformed schema: %w", wxx.ErrInvalidMapMetadata, m.Release, m.Version, m.Schema, err)}
 	}
 
 	// process source into a WXX structure and return it or any errors
@@ -103,7 +123,11 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	case "ROWS":
 		w.GridOrientation = hexg.OddR
 	default:
-		return nil, fmt.Errorf("%q: unknown orientation", m.HexOrientation)
+		err := &xmlstream.Error{Field: "hexOrientation", Err: fmt.Errorf("%w: %q: unknown orientation", wxx.ErrInvalidHexOrientation, m.HexOrientation)}
+		if err := lax.Repair(err, m.HexOrientation, "set to COLUMNS"); err != nil {
+			return nil, err
+		}
+		w.HexOrientation, w.GridOrientation = "COLUMNS", hexg.OddQ
 	}
 	w.HexWidth = m.HexWidth
 	w.KingdomFactor = m.KingdomFactor
@@ -116,7 +140,11 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	case "ICOSAHEDRAL":
 		w.MapProjection = wxx.ICOSAHEDRAL
 	default:
-		return nil, fmt.Errorf("%q: unknown projection", m.MapProjection)
+		err := &xmlstream.Error{Field: "mapProjection", Err: fmt.Errorf("%w: %q: unknown projection", wxx.ErrInvalidMapProjection, m.MapProjection)}
+		if err := lax.Repair(err, m.MapProjection, "set to FLAT"); err != nil {
+			return nil, err
+		}
+		w.MapProjection = wxx.FLAT
 	}
 	w.ProvinceFactor = m.ProvinceFactor
 	w.ShowFeatureLabels = m.ShowFeatureLabels
@@ -133,40 +161,59 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 	w.HScrollbarPos = m.HScrollbarPos
 	w.VScrollbarPos = m.VScrollbarPos
 
+	return w, nil
+}
+
+// decodeElements dispatches each child of <map> to its co-located decodeXxx
+// helper, in the order the elements appear on disk. decodeTiles is the step the
+// two decode paths do differently, so the caller supplies it: Decode parses the
+// unmarshalled tilerows there, while DecodeReader has already parsed them as
+// they streamed past and only finishes the step.
+//
+// An error is located at the element that failed. The conversion runs after the
+// document has been read, so the XML position, when there is one, is added by
+// the caller.
+func decodeElements(m *XMLSchema, w *wxx.Map_t, lax *xmlstream.Lenient, decodeTiles func() error) (*wxx.Map_t, error) {
+	// the root's unrecognised children are only all known once the stream has
+	// passed them, so its extras are taken here rather than with its attributes
+	w.Extras = extras(m.ExtraAttrs, m.ExtraElements)
+
 	decodeGridAndNumbering(m.GridAndNumbering, w)
 
 	decodeBlurTerrainBG(m.BlurTerrainBG, w)
 
 	if err := decodeTerrainMap(m.TerrainMap, w); err != nil {
-		return w, err
+		return w, xmlstream.Locate(err, "map/terrainmap", 0, 0)
 	}
 
 	decodeMapLayers(m.MapLayers, w)
 
-	if err := decodeTiles(m.Tiles, m.MapKey, w); err != nil {
+	if err := decodeTiles(); err != nil {
 		return w, err
 	}
 
-	if err := decodeFeatures(m.Features, w); err != nil {
-		return w, err
+	if err := decodeFeatures(m.Features, w, lax.In("map/features")); err != nil {
+		return w, xmlstream.Locate(err, "map/features", 0, 0)
 	}
 
-	decodeExtraTerrain(m.ExtraTerrain, w)
+	if err := decodeExtraTerrain(m.ExtraTerrain, w); err != nil {
+		return w, xmlstream.Locate(err, "map/extraTerrain", 0, 0)
+	}
 
-	if err := decodeLabels(m.Labels, w); err != nil {
-		return w, err
+	if err := decodeLabels(m.Labels, w, lax.In("map/labels")); err != nil {
+		return w, xmlstream.Locate(err, "map/labels", 0, 0)
 	}
 
 	decodeShapes(m.Shapes, w)
 
-	if err := decodeNotes(m.Notes, w); err != nil {
-		return w, err
+	if err := decodeNotes(m.Notes, w, lax.In("map/notes")); err != nil {
+		return w, xmlstream.Locate(err, "map/notes", 0, 0)
 	}
 
 	decodeInformations(m.Informations, w)
 
-	if err := decodeConfiguration(m.Configuration, w); err != nil {
-		return w, err
+	if err := decodeConfiguration(m.Configuration, w, lax.In("map/configuration")); err != nil {
+		return w, xmlstream.Locate(err, "map/configuration", 0, 0)
 	}
 
 	return w, nil
@@ -199,6 +246,12 @@ func Decode(input []byte) (*wxx.Map_t, error) {
 // Note: the style of this code is intentionally verbose to make it easier to find changes between
 // versions of the Worldographer files.
 func Encode(w *wxx.Map_t, app string) ([]byte, error) {
+	return encode(context.Background(), w, app, 1)
+}
+
+// encode is Encode, writing <tilerow> elements on up to workers goroutines. See
+// encodeTiles.
+func encode(ctx context.Context, w *wxx.Map_t, app string, workers int) ([]byte, error) {
 	target, ok := acceptedApps.App(app)
 	if !ok {
 		// VerifyApp is what names the accepted set in the error text; App reports
@@ -206,7 +259,7 @@ func Encode(w *wxx.Map_t, app string) ([]byte, error) {
 		return nil, acceptedApps.VerifyApp(app)
 	}
 	wb := &bytes.Buffer{}
-	if err := encodeMap(w, target, acceptedApps.Schema, wb); err != nil {
+	if err := encodeMap(ctx, w, target, acceptedApps.Schema, workers, wb); err != nil {
 		return nil, err
 	}
 	return wb.Bytes(), nil
@@ -231,42 +284,46 @@ func Encode(w *wxx.Map_t, app string) ([]byte, error) {
 // from a parsed version's components, because "2.06" must never reach disk as
 // "2.6" (ADR 0004 Decision 1). target.Version is the app argument itself: App
 // matched it by string equality, so the two are the same string.
-func encodeMap(w *wxx.Map_t, target appver.App_t, schema string, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<map"))
-	wb.WriteString(fmt.Sprintf(" type=%q", w.Type))
-	wb.WriteString(fmt.Sprintf(" release=%q", target.Release))
-	wb.WriteString(fmt.Sprintf(" version=%q", target.Version))
-	wb.WriteString(fmt.Sprintf(" schema=%q", schema))
-	wb.WriteString(fmt.Sprintf(" lastViewLevel=%q", w.LastViewLevel))
-	wb.WriteString(fmt.Sprintf(" continentFactor=%q", ints(w.ContinentFactor)))
-	wb.WriteString(fmt.Sprintf(" kingdomFactor=%q", ints(w.KingdomFactor)))
-	wb.WriteString(fmt.Sprintf(" provinceFactor=%q", ints(w.ProvinceFactor)))
-	wb.WriteString(fmt.Sprintf(" worldToContinentHOffset=%q", floats(w.WorldToContinentHOffset)))
-	wb.WriteString(fmt.Sprintf(" continentToKingdomHOffset=%q", floats(w.ContinentToKingdomHOffset)))
-	wb.WriteString(fmt.Sprintf(" kingdomToProvinceHOffset=%q", floats(w.KingdomToProvinceHOffset)))
-	wb.WriteString(fmt.Sprintf(" worldToContinentVOffset=%q", floats(w.WorldToContinentVOffset)))
-	wb.WriteString(fmt.Sprintf(" continentToKingdomVOffset=%q", floats(w.ContinentToKingdomVOffset)))
-	wb.WriteString(fmt.Sprintf(" kingdomToProvinceVOffset=%q \n", floats(w.KingdomToProvinceVOffset)))
-	wb.WriteString(fmt.Sprintf("hScrollbarPos=%q vScrollbarPos=%q \n", floats(w.HScrollbarPos), floats(w.VScrollbarPos)))
-	wb.WriteString(fmt.Sprintf("hexWidth=%q", floats(w.HexWidth)))
-	wb.WriteString(fmt.Sprintf(" hexHeight=%q", floats(w.HexHeight)))
-	wb.WriteString(fmt.Sprintf(" hexOrientation=%q", w.HexOrientation))
+func encodeMap(ctx context.Context, w *wxx.Map_t, target appver.App_t, schema string, workers int, wb *bytes.Buffer) error {
+	wb.WriteString("<map")
+	writeAttr(wb, " type=", w.Type)
+	writeAttr(wb, " release=", target.Release)
+	writeAttr(wb, " version=", target.Version)
+	writeAttr(wb, " schema=", schema)
+	writeAttr(wb, " lastViewLevel=", w.LastViewLevel)
+	writeIntAttr(wb, " continentFactor=", w.ContinentFactor)
+	writeIntAttr(wb, " kingdomFactor=", w.KingdomFactor)
+	writeIntAttr(wb, " provinceFactor=", w.ProvinceFactor)
+	writeFloatAttr(wb, " worldToContinentHOffset=", w.WorldToContinentHOffset)
+	writeFloatAttr(wb, " continentToKingdomHOffset=", w.ContinentToKingdomHOffset)
+	writeFloatAttr(wb, " kingdomToProvinceHOffset=", w.KingdomToProvinceHOffset)
+	writeFloatAttr(wb, " worldToContinentVOffset=", w.WorldToContinentVOffset)
+	writeFloatAttr(wb, " continentToKingdomVOffset=", w.ContinentToKingdomVOffset)
+	writeFloatAttr(wb, " kingdomToProvinceVOffset=", w.KingdomToProvinceVOffset)
+	wb.WriteString(" \n")
+	writeFloatAttr(wb, "hScrollbarPos=", w.HScrollbarPos)
+	writeFloatAttr(wb, " vScrollbarPos=", w.VScrollbarPos)
+	wb.WriteString(" \n")
+	writeFloatAttr(wb, "hexWidth=", w.HexWidth)
+	writeFloatAttr(wb, " hexHeight=", w.HexHeight)
+	writeAttr(wb, " hexOrientation=", w.HexOrientation)
 	if w.MapProjection == wxx.FLAT {
-		wb.WriteString(fmt.Sprintf(" mapProjection=%q", "FLAT"))
+		writeAttr(wb, " mapProjection=", "FLAT")
 	} else if w.MapProjection == wxx.ICOSAHEDRAL {
-		wb.WriteString(fmt.Sprintf(" mapProjection=%q", "ICOSAHEDRAL"))
+		writeAttr(wb, " mapProjection=", "ICOSAHEDRAL")
 	} else {
 		return fmt.Errorf("assert(map.projection != %q)", w.MapProjection)
 	}
-	wb.WriteString(fmt.Sprintf(" showNotes=%q", bools(w.ShowNotes)))
-	wb.WriteString(fmt.Sprintf(" showGMOnly=%q", bools(w.ShowGMOnly)))
-	wb.WriteString(fmt.Sprintf(" showGMOnlyGlow=%q", bools(w.ShowGMOnlyGlow)))
-	wb.WriteString(fmt.Sprintf(" showFeatureLabels=%q", bools(w.ShowFeatureLabels)))
-	wb.WriteString(fmt.Sprintf(" showGrid=%q", bools(w.ShowGrid)))
-	wb.WriteString(fmt.Sprintf(" showGridNumbers=%q", bools(w.ShowGridNumbers)))
-	wb.WriteString(fmt.Sprintf(" showShadows=%q", bools(w.ShowShadows)))
-	wb.WriteString(fmt.Sprintf("  triangleSize=%q", ints(w.TriangleSize)))
-	wb.WriteString(fmt.Sprintf(">\n"))
+	writeBoolAttr(wb, " showNotes=", w.ShowNotes)
+	writeBoolAttr(wb, " showGMOnly=", w.ShowGMOnly)
+	writeBoolAttr(wb, " showGMOnlyGlow=", w.ShowGMOnlyGlow)
+	writeBoolAttr(wb, " showFeatureLabels=", w.ShowFeatureLabels)
+	writeBoolAttr(wb, " showGrid=", w.ShowGrid)
+	writeBoolAttr(wb, " showGridNumbers=", w.ShowGridNumbers)
+	writeBoolAttr(wb, " showShadows=", w.ShowShadows)
+	writeIntAttr(wb, "  triangleSize=", w.TriangleSize)
+	wb.WriteString(extraAttrs(w.Extras))
+	wb.WriteString(">\n")
 
 	if err := encodeGridAndNumbering(w.GridAndNumbering, wb); err != nil {
 		return err
@@ -284,7 +341,7 @@ func encodeMap(w *wxx.Map_t, target appver.App_t, schema string, wb *bytes.Buffe
 		return err
 	}
 
-	if err := encodeTiles(w.Tiles, w.HexOrientation, wb); err != nil {
+	if err := encodeTiles(ctx, w.Tiles, w.HexOrientation, workers, wb); err != nil {
 		return err
 	}
 
@@ -320,6 +377,9 @@ func encodeMap(w *wxx.Map_t, target appver.App_t, schema string, wb *bytes.Buffe
 		return err
 	}
 
+	if children := extraElements(w.Extras); children != "" {
+		wb.WriteString(children + "\n")
+	}
 	wb.WriteString("</map>\n")
 
 	return nil
@@ -4,16 +4,16 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // decodeMapKey copies the <mapkey> attributes into the domain map. Colors are
-// folded through decodeRgba. It is invoked from decodeTiles (inside the tilerow
-// loop) to preserve the original ordering, in which <mapkey> was decoded per
-// tilerow after the tiles were parsed.
-func decodeMapKey(src MapKey_t, w *wxx.Map_t) error {
+// parsed by decodeRgba. It is invoked from decodeTiles, after the tilerows,
+// to preserve the original ordering, in which <mapkey> was decoded only once the
+// tiles had been parsed.
+func decodeMapKey(src MapKey_t, w *wxx.Map_t, lax *xmlstream.Lenient) error {
 	var err error
 	w.MapKey = &wxx.MapKey_t{
 		PositionX: src.PositionX,
@@ -21,62 +21,65 @@ func decodeMapKey(src MapKey_t, w *wxx.Map_t) error {
 		Viewlevel: src.Viewlevel,
 		Height:    src.Height,
 	}
-	if w.MapKey.BackgroundColor, err = decodeRgba(src.BackgroundColor); err != nil {
-		return fmt.Errorf("mapkey.backgroundcolor: %w", err)
+	if w.MapKey.BackgroundColor, err = lax.RGBA(decodeRgba, src.BackgroundColor, "mapkey.backgroundcolor"); err != nil {
+		return err
 	}
 	w.MapKey.BackgroundOpacity = src.BackgroundOpacity
 	w.MapKey.TitleText = src.TitleText
 	w.MapKey.TitleFontFace = src.TitleFontFace
-	if w.MapKey.TitleFontColor, err = decodeRgba(src.TitleFontColor); err != nil {
-		return fmt.Errorf("mapkey.titleFontColor: %w", err)
+	if w.MapKey.TitleFontColor, err = lax.RGBA(decodeRgba, src.TitleFontColor, "mapkey.titleFontColor"); err != nil {
+		return err
 	}
 	w.MapKey.TitleFontBold = src.TitleFontBold
 	w.MapKey.TitleFontItalic = src.TitleFontItalic
 	w.MapKey.TitleScale = src.TitleScale
 	w.MapKey.ScaleText = src.ScaleText
 	w.MapKey.ScaleFontFace = src.ScaleFontFace
-	if w.MapKey.ScaleFontColor, err = decodeRgba(src.ScaleFontColor); err != nil {
-		return fmt.Errorf("mapkey.scaleFontColor: %w", err)
+	if w.MapKey.ScaleFontColor, err = lax.RGBA(decodeRgba, src.ScaleFontColor, "mapkey.scaleFontColor"); err != nil {
+		return err
 	}
 	w.MapKey.ScaleFontBold = src.ScaleFontBold
 	w.MapKey.ScaleFontItalic = src.ScaleFontItalic
 	w.MapKey.ScaleScale = src.ScaleScale
 	w.MapKey.EntryFontFace = src.EntryFontFace
-	if w.MapKey.EntryFontColor, err = decodeRgba(src.EntryFontColor); err != nil {
-		return fmt.Errorf("mapkey.entryFontColor: %w", err)
+	if w.MapKey.EntryFontColor, err = lax.RGBA(decodeRgba, src.EntryFontColor, "mapkey.entryFontColor"); err != nil {
+		return err
 	}
 	w.MapKey.EntryFontBold = src.EntryFontBold
 	w.MapKey.EntryFontItalic = src.EntryFontItalic
 	w.MapKey.EntryScale = src.EntryScale
+	w.MapKey.Extras = extras(src.ExtraAttrs, src.ExtraElements)
 	return nil
 }
 
 func encodeMapKey(mapKey *wxx.MapKey_t, wb *bytes.Buffer) error {
 	wb.WriteString("<mapkey")
-	wb.WriteString(fmt.Sprintf(" positionx=%q", floats(mapKey.PositionX)))
-	wb.WriteString(fmt.Sprintf(" positiony=%q", floats(mapKey.PositionY)))
-	wb.WriteString(fmt.Sprintf(" viewlevel=%q", mapKey.Viewlevel))
-	wb.WriteString(fmt.Sprintf(" height=%q", floats(mapKey.Height)))
-	wb.WriteString(fmt.Sprintf(" backgroundcolor=%q", rgbas(mapKey.BackgroundColor))) // decodeRgba
-	wb.WriteString(fmt.Sprintf(" backgroundopacity=%q", floats(mapKey.BackgroundOpacity)))
-	wb.WriteString(fmt.Sprintf(" titleText=%q", mapKey.TitleText))
-	wb.WriteString(fmt.Sprintf(" titleFontFace=%q", mapKey.TitleFontFace))
-	wb.WriteString(fmt.Sprintf(" titleFontColor=%q", rgbas(mapKey.TitleFontColor))) // decodeRgba
-	wb.WriteString(fmt.Sprintf(" titleFontBold=%q", bools(mapKey.TitleFontBold)))
-	wb.WriteString(fmt.Sprintf(" titleFontItalic=%q", bools(mapKey.TitleFontItalic)))
-	wb.WriteString(fmt.Sprintf(" titleScale=%q", floats(mapKey.TitleScale)))
-	wb.WriteString(fmt.Sprintf(" scaleText=%q", mapKey.ScaleText))
-	wb.WriteString(fmt.Sprintf(" scaleFontFace=%q", mapKey.ScaleFontFace))
-	wb.WriteString(fmt.Sprintf(" scaleFontColor=%q", rgbas(mapKey.ScaleFontColor))) // decodeRgba
-	wb.WriteString(fmt.Sprintf(" scaleFontBold=%q", bools(mapKey.ScaleFontBold)))
-	wb.WriteString(fmt.Sprintf(" scaleFontItalic=%q", bools(mapKey.ScaleFontItalic)))
-	wb.WriteString(fmt.Sprintf(" scaleScale=%q", floats(mapKey.ScaleScale)))
-	wb.WriteString(fmt.Sprintf(" entryFontFace=%q", mapKey.EntryFontFace))
-	wb.WriteString(fmt.Sprintf(" entryFontColor=%q", rgbas(mapKey.EntryFontColor))) // decodeRgba
-	wb.WriteString(fmt.Sprintf(" entryFontBold=%q", bools(mapKey.EntryFontBold)))
-	wb.WriteString(fmt.Sprintf(" entryFontItalic=%q", bools(mapKey.EntryFontItalic)))
-	wb.WriteString(fmt.Sprintf(" entryScale=%q", floats(mapKey.EntryScale)))
+	writeFloatAttr(wb, " positionx=", mapKey.PositionX)
+	writeFloatAttr(wb, " positiony=", mapKey.PositionY)
+	writeAttr(wb, " viewlevel=", mapKey.Viewlevel)
+	writeFloatAttr(wb, " height=", mapKey.Height)
+	writeRgbaAttr(wb, " backgroundcolor=", mapKey.BackgroundColor) // decodeRgba
+	writeFloatAttr(wb, " backgroundopacity=", mapKey.BackgroundOpacity)
+	writeAttr(wb, " titleText=", mapKey.TitleText)
+	writeAttr(wb, " titleFontFace=", mapKey.TitleFontFace)
+	writeRgbaAttr(wb, " titleFontColor=", mapKey.TitleFontColor) // decodeRgba
+	writeBoolAttr(wb, " titleFontBold=", mapKey.TitleFontBold)
+	writeBoolAttr(wb, " titleFontItalic=", mapKey.TitleFontItalic)
+	writeFloatAttr(wb, " titleScale=", mapKey.TitleScale)
+	writeAttr(wb, " scaleText=", mapKey.ScaleText)
+	writeAttr(wb, " scaleFontFace=", mapKey.ScaleFontFace)
+	writeRgbaAttr(wb, " scaleFontColor=", mapKey.ScaleFontColor) // decodeRgba
+	writeBoolAttr(wb, " scaleFontBold=", mapKey.ScaleFontBold)
+	writeBoolAttr(wb, " scaleFontItalic=", mapKey.ScaleFontItalic)
+	writeFloatAttr(wb, " scaleScale=", mapKey.ScaleScale)
+	writeAttr(wb, " entryFontFace=", mapKey.EntryFontFace)
+	writeRgbaAttr(wb, " entryFontColor=", mapKey.EntryFontColor) // decodeRgba
+	writeBoolAttr(wb, " entryFontBold=", mapKey.EntryFontBold)
+	writeBoolAttr(wb, " entryFontItalic=", mapKey.EntryFontItalic)
+	writeFloatAttr(wb, " entryScale=", mapKey.EntryScale)
+	wb.WriteString(extraAttrs(mapKey.Extras))
 	wb.WriteString(">\n")
+	wb.WriteString(extraElements(mapKey.Extras))
 	wb.WriteString("</mapkey>\n")
 	return nil
 }
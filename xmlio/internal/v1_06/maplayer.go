@@ -4,7 +4,6 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
 )
@@ -13,7 +12,7 @@ import (
 // domain map.
 func decodeMapLayers(src []MapLayer_t, w *wxx.Map_t) {
 	for _, layer := range src {
-		w.MapLayers = append(w.MapLayers, &wxx.MapLayer_t{Name: layer.Name, IsVisible: layer.IsVisible, Opacity: layer.Opacity})
+		w.MapLayers = append(w.MapLayers, &wxx.MapLayer_t{Name: layer.Name, IsVisible: layer.IsVisible, Opacity: layer.Opacity, Extras: extras(layer.ExtraAttrs, layer.ExtraElements)})
 	}
 }
 
@@ -29,9 +28,10 @@ func encodeMapLayers(mapLayers []*wxx.MapLayer_t, wb *bytes.Buffer) error {
 
 func encodeMapLayer(mapLayer *wxx.MapLayer_t, wb *bytes.Buffer) error {
 	wb.WriteString("<maplayer")
-	wb.WriteString(fmt.Sprintf(" name=%q", mapLayer.Name))
-	wb.WriteString(fmt.Sprintf(" isVisible=%q", bools(mapLayer.IsVisible)))
-	wb.WriteString(fmt.Sprintf(" opacity=%q", floats(mapLayer.Opacity)))
-	wb.WriteString("/>\n")
+	writeAttr(wb, " name=", mapLayer.Name)
+	writeBoolAttr(wb, " isVisible=", mapLayer.IsVisible)
+	writeFloatAttr(wb, " opacity=", mapLayer.Opacity)
+	wb.WriteString(extraAttrs(mapLayer.Extras))
+	wb.WriteString(endEmpty("maplayer", "/>", mapLayer.Extras) + "\n")
 	return nil
 }
@@ -4,14 +4,14 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // decodeNotes copies each <note> (with its <notetext> CDATA body) into the
 // domain map.
-func decodeNotes(src Notes_t, w *wxx.Map_t) error {
+func decodeNotes(src Notes_t, w *wxx.Map_t, lax *xmlstream.Lenient) error {
 	var err error
 	for _, note := range src.Notes {
 		wNote := &wxx.Note_t{
@@ -24,9 +24,10 @@ func decodeNotes(src Notes_t, w *wxx.Map_t) error {
 			Title:     note.Title,
 			IsGMOnly:  note.IsGMOnly,
 			NoteText:  note.NoteText,
+			Extras:    extras(note.ExtraAttrs, note.ExtraElements),
 		}
-		if wNote.Color, err = decodeRgba(note.Color); err != nil {
-			return fmt.Errorf("note.color: %w", err)
+		if wNote.Color, err = lax.RGBA(decodeRgba, note.Color, "note.color"); err != nil {
+			return err
 		}
 		w.Notes = append(w.Notes, wNote)
 	}
@@ -46,20 +47,23 @@ func encodeNotes(notes []*wxx.Note_t, wb *bytes.Buffer) error {
 
 func encodeNote(note *wxx.Note_t, wb *bytes.Buffer) error {
 	wb.WriteString("<note")
-	wb.WriteString(fmt.Sprintf(" key=%q", note.Key))
-	wb.WriteString(fmt.Sprintf(" viewLevel=%q", note.ViewLevel))
-	wb.WriteString(fmt.Sprintf(" x=%q", floats(note.X)))
-	wb.WriteString(fmt.Sprintf(" y=%q", floats(note.Y)))
-	wb.WriteString(fmt.Sprintf(" filename=%q", note.Filename))
-	wb.WriteString(fmt.Sprintf(" parent=%q", note.Parent))
-	wb.WriteString(fmt.Sprintf(" color=%q", rgbans(note.Color))) // decodeRgba
-	wb.WriteString(fmt.Sprintf(" title=%q", note.Title))
-	wb.WriteString(fmt.Sprintf(" isGMOnly=%q", bools(note.IsGMOnly)))
+	writeAttr(wb, " key=", note.Key)
+	writeAttr(wb, " viewLevel=", note.ViewLevel)
+	writeFloatAttr(wb, " x=", note.X)
+	writeFloatAttr(wb, " y=", note.Y)
+	writeAttr(wb, " filename=", note.Filename)
+	writeAttr(wb, " parent=", note.Parent)
+	writeRgbanAttr(wb, " color=", note.Color) // decodeRgba
+	writeAttr(wb, " title=", note.Title)
+	writeBoolAttr(wb, " isGMOnly=", note.IsGMOnly)
+	wb.WriteString(extraAttrs(note.Extras))
 	wb.WriteString(">")
-	// notetext is CDATA HTML; emit it verbatim so the round-trip preserves it.
-	wb.WriteString("<notetext><![CDATA[")
-	wb.WriteString(note.NoteText)
-	wb.WriteString("]]></notetext>")
+	// notetext is CDATA HTML; emit it verbatim so the round-trip preserves it,
+	// splitting the section where the text would otherwise end it.
+	wb.WriteString("<notetext>")
+	wb.Write(xmlstream.AppendCDATA(wb.AvailableBuffer(), note.NoteText))
+	wb.WriteString("</notetext>")
+	wb.WriteString(extraElements(note.Extras))
 	wb.WriteString("</note>\n")
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // XMLSchema defines the structure for reading a Worldographer file with the H2025v1 XML Schema
@@ -61,6 +62,10 @@ type XMLSchema struct {
 	Notes         Notes_t          `xml:"notes"`
 	Informations  Informations_t   `xml:"informations"`
 	Configuration Configuration_t  `xml:"configuration"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 // BlurTerrainBG_t is the on-disk <blurTerrainBG> element (W2025-native).
@@ -71,12 +76,41 @@ type BlurTerrainBG_t struct {
 	Randomness  float64 `xml:"randomness,attr"`
 	BlurStart   float64 `xml:"blurStart,attr"`
 	BlurEnd     float64 `xml:"blurEnd,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
-// ExtraTerrain_t is the on-disk <extraTerrain> element (W2025-native). It is an
-// empty container in observed samples; InnerXML preserves any inner content.
+// ExtraTerrain_t is the on-disk <extraTerrain> element (W2025-native): terrain
+// painted on map layers, grouped by layer.
 type ExtraTerrain_t struct {
-	InnerXML string `xml:",innerxml"`
+	MapLayers []ExtraTerrainLayer_t `xml:"mapLayer"`
+}
+
+// ExtraTerrainLayer_t is the <mapLayer> child of <extraTerrain>. It is not the
+// top-level <maplayer> (note the case), which declares a layer; this one lists
+// the terrain on a layer declared there.
+type ExtraTerrainLayer_t struct {
+	Name               string                 `xml:"name,attr"`
+	TerrainAndLocation []TerrainAndLocation_t `xml:"terrainAndLocation"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
+}
+
+type TerrainAndLocation_t struct {
+	Name      string  `xml:"name,attr"`
+	Elevation float64 `xml:"elevation,attr"`
+	Icy       bool    `xml:"icy,attr"`
+	GMOnly    bool    `xml:"gmOnly,attr"`
+	Resources string  `xml:"resources,attr"`
+	Location  string  `xml:"location,attr"` // "x,y"
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Configuration_t struct {
@@ -118,9 +152,17 @@ type Feature struct {
 		ViewLevel string  `xml:"viewLevel,attr"`
 		X         float64 `xml:"x,attr"`
 		Y         float64 `xml:"y,attr"`
+
+		// anything else, kept for the encoder (see wxx.Extras_t)
+		ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+		ExtraElements []xmlstream.Element `xml:",any"`
 	} `xml:"location"`
 	Label     *Label_t `xml:"label,omitempty"`
 	InnerText string   `xml:",chardata"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type FeatureConfig struct {
@@ -166,6 +208,10 @@ type GridAndNumbering struct {
 	NumberPosition              string  `xml:"numberPosition,attr"`
 	NumberPrePad                string  `xml:"numberPrePad,attr"`
 	NumberSeparator             string  `xml:"numberSeparator,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Information_t struct {
@@ -187,6 +233,14 @@ type Information_t struct {
 	// elements
 	Details   []Information_t `xml:"information"`
 	InnerText string          `xml:",chardata"`
+
+	// the element's content as the file has it, to tell whether InnerText was
+	// a CDATA section
+	InnerXML string `xml:",innerxml"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Informations_t struct {
@@ -217,6 +271,10 @@ type Label_t struct {
 	// elements
 	Location  Location_t `xml:"location"`
 	InnerText string     `xml:",chardata"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Labels_t struct {
@@ -240,6 +298,10 @@ type LabelStyle_t struct {
 	DropShadowColor  string  `xml:"dropShadowColor,attr"`
 	DropShadowRadius float64 `xml:"dropShadowRadius,attr"`
 	DropShadowSpread float64 `xml:"dropShadowSpread,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Location_t struct {
@@ -248,6 +310,10 @@ type Location_t struct {
 	X         float64 `xml:"x,attr"`
 	Y         float64 `xml:"y,attr"`
 	Scale     float64 `xml:"scale,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type MapKey_t struct {
@@ -275,6 +341,10 @@ type MapKey_t struct {
 	EntryFontBold     bool    `xml:"entryFontBold,attr"`
 	EntryFontItalic   bool    `xml:"entryFontItalic,attr"`
 	EntryScale        float64 `xml:"entryScale,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type MapLayer_t struct {
@@ -282,6 +352,10 @@ type MapLayer_t struct {
 	Name      string  `xml:"name,attr"`
 	IsVisible bool    `xml:"isVisible,attr"`
 	Opacity   float64 `xml:"opacity,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Note_t struct {
@@ -298,6 +372,10 @@ type Note_t struct {
 
 	// elements
 	NoteText string `xml:"notetext"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Notes_t struct {
@@ -309,6 +387,10 @@ type Point_t struct {
 	Type string  `xml:"type,attr"`
 	X    float64 `xml:"x,attr"`
 	Y    float64 `xml:"y,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Shape_t struct {
@@ -355,6 +437,10 @@ type Shape_t struct {
 
 	// elements
 	Points []Point_t `xml:"p"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type ShapeConfig_t struct {
@@ -396,6 +482,10 @@ type ShapeStyle_t struct {
 	// W2025 line rendering attributes (already modeled on <shape>).
 	LineCap  string `xml:"lineCap,attr"`
 	LineJoin string `xml:"lineJoin,attr"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type Shapes_t struct {
@@ -432,6 +522,10 @@ type Tiles_t struct {
 
 	// elements
 	TileRows []TileRow_t `xml:"tilerow"`
+
+	// anything else, kept for the encoder (see wxx.Extras_t)
+	ExtraAttrs    []xml.Attr          `xml:",any,attr"`
+	ExtraElements []xmlstream.Element `xml:",any"`
 }
 
 type TileRow_t struct {
@@ -439,63 +533,33 @@ type TileRow_t struct {
 	InnerText string `xml:",chardata"`
 }
 
-// decodeRgba parses a Worldographer float-RGBA attribute ("r,g,b,a"). It folds
-// "", "null", and the literal opaque black "0.0,0.0,0.0,1.0" all to nil.
+// decodeRgba parses a Worldographer float-RGBA attribute ("r,g,b,a"). "" (the
+// attribute is absent) and "null" are "no color" and decode to nil. Anything else
+// is an explicit color, opaque black included, and keeps the file's spelling in
+// Raw so that rgbas can write it back unchanged.
 //
-// Latent fidelity risk: this fold assumes opaque black always means "absent".
-// That round-trips losslessly only for fields the encoder re-emits with rgbas
-// (which writes nil back as "0.0,0.0,0.0,1.0"). For fields that instead OMIT
-// when nil (tile CustomBackgroundColor) or emit "null" via rgbans (feature
-// Color/RingColor, some shapeStyle colors), a genuinely opaque-black on-disk
-// value changes byte form across a round-trip.
-//
-// This IS now exercised by testdata/w2025-populated.xml: Features[0].Color
-// = "0.0,0.0,0.0,1.0" decodes here to nil, and encodeFeature re-emits it via
-// rgbans as "null"; re-decoding "null" also yields nil. So at the Map_t level it
-// round-trips losslessly (nil both passes), and only the on-disk byte form shifts
-// "0.0,0.0,0.0,1.0" -> "null" — lossless under Worldographer's semantics where
-// both spellings mean "no color". The remaining on-disk ambiguity (a map that
-// truly needs opaque-black distinct from "null") is still unresolved, so the fold
-// is left as-is.
+// This used to fold the literal opaque black "0.0,0.0,0.0,1.0" to nil as well,
+// on the assumption that Worldographer only writes it to mean "no color". The
+// assumption does not hold for every field: a label, feature ring, or shape style
+// that really is black came back uncolored after a round trip, and a black tile
+// background lost its field altogether. The fold is now the caller's choice,
+// xmlio.WithBlackAsNoColor, and is applied to the decoded Map_t, not here.
 func decodeRgba(s string) (rgba *wxx.RGBA_t, err error) {
-	if s == "" || s == "null" || s == "0.0,0.0,0.0,1.0" {
-		return nil, nil
-	}
-	rgba = &wxx.RGBA_t{}
-	values := strings.Split(s, ",")
-	if len(values) != 4 {
-		return rgba, fmt.Errorf("invalid value")
-	} else if rgba.R, err = strconv.ParseFloat(values[0], 64); err != nil {
-		return rgba, err
-	} else if rgba.G, err = strconv.ParseFloat(values[1], 64); err != nil {
-		return rgba, err
-	} else if rgba.B, err = strconv.ParseFloat(values[2], 64); err != nil {
-		return rgba, err
-	} else if rgba.A, err = strconv.ParseFloat(values[3], 64); err != nil {
-		return rgba, err
-	}
-	if rgba.R == 0 && rgba.G == 0 && rgba.B == 0 && rgba.A == 1 {
-		return nil, nil
-	}
-	return rgba, nil
-}
-
-func decodeZeroableRgba(s string) (rgba *wxx.RGBA_t, err error) {
 	if s == "" || s == "null" {
 		return nil, nil
 	}
-	rgba = &wxx.RGBA_t{}
+	rgba = &wxx.RGBA_t{Raw: s}
 	values := strings.Split(s, ",")
 	if len(values) != 4 {
-		return rgba, fmt.Errorf("invalid value")
+		return rgba, fmt.Errorf("%w: %q", wxx.ErrInvalidRGBA, s)
 	} else if rgba.R, err = strconv.ParseFloat(values[0], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	} else if rgba.G, err = strconv.ParseFloat(values[1], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	} else if rgba.B, err = strconv.ParseFloat(values[2], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	} else if rgba.A, err = strconv.ParseFloat(values[3], 64); err != nil {
-		return rgba, err
+		return rgba, fmt.Errorf("%w: %w", wxx.ErrInvalidRGBA, err)
 	}
 	return rgba, nil
 }
@@ -4,7 +4,6 @@ package v1_06
 
 import (
 	"bytes"
-	"fmt"
 
 	"github.com/maloquacious/wxx"
 )
@@ -52,13 +51,15 @@ func decodeShapes(src Shapes_t, w *wxx.Map_t) {
 			StrokeWidth:           shape.StrokeWidth,
 			Tags:                  shape.Tags,
 			Type:                  shape.Type,
+			Extras:                extras(shape.ExtraAttrs, shape.ExtraElements),
 		}
 
 		for _, point := range shape.Points {
 			wPoint := &wxx.Point_t{
-				Type: point.Type,
-				X:    point.X,
-				Y:    point.Y,
+				Type:   point.Type,
+				X:      point.X,
+				Y:      point.Y,
+				Extras: extras(point.ExtraAttrs, point.ExtraElements),
 			}
 			wShape.Points = append(wShape.Points, wPoint)
 		}
@@ -80,53 +81,56 @@ func encodeShapes(shapes []*wxx.Shape_t, wb *bytes.Buffer) error {
 
 func encodeShape(shape *wxx.Shape_t, wb *bytes.Buffer) error {
 	wb.WriteString("<shape")
-	wb.WriteString(fmt.Sprintf(" type=%q", shape.Type))
-	wb.WriteString(fmt.Sprintf(" creationType=%q", shape.CreationType))
-	wb.WriteString(fmt.Sprintf(" isWorld=%q", bools(shape.IsWorld)))
-	wb.WriteString(fmt.Sprintf(" isContinent=%q", bools(shape.IsContinent)))
-	wb.WriteString(fmt.Sprintf(" isKingdom=%q", bools(shape.IsKingdom)))
-	wb.WriteString(fmt.Sprintf(" isProvince=%q", bools(shape.IsProvince)))
-	wb.WriteString(fmt.Sprintf(" isGMOnly=%q", bools(shape.IsGMOnly)))
-	wb.WriteString(fmt.Sprintf(" isCurve=%q", bools(shape.IsCurve)))
-	wb.WriteString(fmt.Sprintf(" isSnapVertices=%q", bools(shape.IsSnapVertices)))
-	wb.WriteString(fmt.Sprintf(" isMatchTileBorders=%q", bools(shape.IsMatchTileBorders)))
-	wb.WriteString(fmt.Sprintf(" isBoxBlur=%q", bools(shape.IsBoxBlur)))
-	wb.WriteString(fmt.Sprintf(" isDropShadow=%q", bools(shape.IsDropShadow)))
-	wb.WriteString(fmt.Sprintf(" isInnerShadow=%q", bools(shape.IsInnerShadow)))
-	wb.WriteString(fmt.Sprintf(" dsOffsetX=%q", floats(shape.DsOffsetX)))
-	wb.WriteString(fmt.Sprintf(" dsOffsetY=%q", floats(shape.DsOffsetY)))
-	wb.WriteString(fmt.Sprintf(" dsRadius=%q", floats(shape.DsRadius)))
-	wb.WriteString(fmt.Sprintf(" dsSpread=%q", floats(shape.DsSpread)))
-	wb.WriteString(fmt.Sprintf(" dsColor=%q", shape.DsColor))
-	wb.WriteString(fmt.Sprintf(" insOffsetX=%q", floats(shape.InsOffsetX)))
-	wb.WriteString(fmt.Sprintf(" insOffsetY=%q", floats(shape.InsOffsetY)))
-	wb.WriteString(fmt.Sprintf(" insRadius=%q", floats(shape.InsRadius)))
-	wb.WriteString(fmt.Sprintf(" insChoke=%q", floats(shape.InsChoke)))
-	wb.WriteString(fmt.Sprintf(" insColor=%q", shape.InsColor))
-	wb.WriteString(fmt.Sprintf(" bbWidth=%q", floats(shape.BbWidth)))
-	wb.WriteString(fmt.Sprintf(" bbHeight=%q", floats(shape.BbHeight)))
-	wb.WriteString(fmt.Sprintf(" bbIterations=%q", ints(shape.BbIterations)))
-	wb.WriteString(fmt.Sprintf(" mapLayer=%q", shape.MapLayer))
-	wb.WriteString(fmt.Sprintf(" fillRule=%q", shape.FillRule))
-	wb.WriteString(fmt.Sprintf(" fillTexture=%q", shape.FillTexture))
-	wb.WriteString(fmt.Sprintf(" strokeTexture=%q", shape.StrokeTexture))
-	wb.WriteString(fmt.Sprintf(" strokeType=%q", shape.StrokeType))
-	wb.WriteString(fmt.Sprintf(" highestViewLevel=%q", shape.HighestViewLevel))
-	wb.WriteString(fmt.Sprintf(" currentShapeViewLevel=%q", shape.CurrentShapeViewLevel))
-	wb.WriteString(fmt.Sprintf(" lineCap=%q", shape.LineCap))
-	wb.WriteString(fmt.Sprintf(" lineJoin=%q", shape.LineJoin))
-	wb.WriteString(fmt.Sprintf(" opacity=%q", floats(shape.Opacity)))
-	wb.WriteString(fmt.Sprintf(" strokeColor=%q", shape.StrokeColor))
-	wb.WriteString(fmt.Sprintf(" strokeWidth=%q", floats(shape.StrokeWidth)))
-	wb.WriteString(fmt.Sprintf(" tags=%q", shape.Tags))
+	writeAttr(wb, " type=", shape.Type)
+	writeAttr(wb, " creationType=", shape.CreationType)
+	writeBoolAttr(wb, " isWorld=", shape.IsWorld)
+	writeBoolAttr(wb, " isContinent=", shape.IsContinent)
+	writeBoolAttr(wb, " isKingdom=", shape.IsKingdom)
+	writeBoolAttr(wb, " isProvince=", shape.IsProvince)
+	writeBoolAttr(wb, " isGMOnly=", shape.IsGMOnly)
+	writeBoolAttr(wb, " isCurve=", shape.IsCurve)
+	writeBoolAttr(wb, " isSnapVertices=", shape.IsSnapVertices)
+	writeBoolAttr(wb, " isMatchTileBorders=", shape.IsMatchTileBorders)
+	writeBoolAttr(wb, " isBoxBlur=", shape.IsBoxBlur)
+	writeBoolAttr(wb, " isDropShadow=", shape.IsDropShadow)
+	writeBoolAttr(wb, " isInnerShadow=", shape.IsInnerShadow)
+	writeFloatAttr(wb, " dsOffsetX=", shape.DsOffsetX)
+	writeFloatAttr(wb, " dsOffsetY=", shape.DsOffsetY)
+	writeFloatAttr(wb, " dsRadius=", shape.DsRadius)
+	writeFloatAttr(wb, " dsSpread=", shape.DsSpread)
+	writeAttr(wb, " dsColor=", shape.DsColor)
+	writeFloatAttr(wb, " insOffsetX=", shape.InsOffsetX)
+	writeFloatAttr(wb, " insOffsetY=", shape.InsOffsetY)
+	writeFloatAttr(wb, " insRadius=", shape.InsRadius)
+	writeFloatAttr(wb, " insChoke=", shape.InsChoke)
+	writeAttr(wb, " insColor=", shape.InsColor)
+	writeFloatAttr(wb, " bbWidth=", shape.BbWidth)
+	writeFloatAttr(wb, " bbHeight=", shape.BbHeight)
+	writeIntAttr(wb, " bbIterations=", shape.BbIterations)
+	writeAttr(wb, " mapLayer=", shape.MapLayer)
+	writeAttr(wb, " fillRule=", shape.FillRule)
+	writeAttr(wb, " fillTexture=", shape.FillTexture)
+	writeAttr(wb, " strokeTexture=", shape.StrokeTexture)
+	writeAttr(wb, " strokeType=", shape.StrokeType)
+	writeAttr(wb, " highestViewLevel=", shape.HighestViewLevel)
+	writeAttr(wb, " currentShapeViewLevel=", shape.CurrentShapeViewLevel)
+	writeAttr(wb, " lineCap=", shape.LineCap)
+	writeAttr(wb, " lineJoin=", shape.LineJoin)
+	writeFloatAttr(wb, " opacity=", shape.Opacity)
+	writeAttr(wb, " strokeColor=", shape.StrokeColor)
+	writeFloatAttr(wb, " strokeWidth=", shape.StrokeWidth)
+	writeAttr(wb, " tags=", shape.Tags)
+	wb.WriteString(extraAttrs(shape.Extras))
 	wb.WriteString(">\n")
 	for _, p := range shape.Points {
 		wb.WriteString("<p")
-		wb.WriteString(fmt.Sprintf(" type=%q", p.Type))
-		wb.WriteString(fmt.Sprintf(" x=%q", floats(p.X)))
-		wb.WriteString(fmt.Sprintf(" y=%q", floats(p.Y)))
-		wb.WriteString("/>\n")
+		writeAttr(wb, " type=", p.Type)
+		writeFloatAttr(wb, " x=", p.X)
+		writeFloatAttr(wb, " y=", p.Y)
+		wb.WriteString(extraAttrs(p.Extras))
+		wb.WriteString(endEmpty("p", "/>", p.Extras) + "\n")
 	}
+	wb.WriteString(extraElements(shape.Extras))
 	wb.WriteString("</shape>\n")
 	return nil
 }
@@ -0,0 +1,240 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package v1_06
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
+)
+
+// DecodeReader decodes the XML read from r using the H2025.V1 schema and returns
+// a Map_t or an error. It produces the same Map_t as Decode.
+//
+// It reads r as a token stream rather than unmarshalling a buffered document.
+// Every child of <map> except <tiles> is decoded with DecodeElement into the same
+// XMLSchema field Unmarshal would have filled, so those elements keep their struct
+// tags' meaning. <tiles> is walked by hand and each <tilerow> is parsed into the
+// domain map as soon as its text has been read, so a map's tile data is never held
+// twice: peak memory grows with the Map_t, not with the size of the file.
+//
+// Reading stops at the </map> end tag. Anything after it is left unread in r.
+//
+// An error that can be placed in the document is an *xmlstream.Error carrying
+// the element path and, where the stream knew it, the XML line and column.
+//
+// A non-nil lax makes the decode lenient: a bad value the codec knows how to
+// replace is replaced, and recorded in lax.Warnings, instead of failing the
+// decode. Structural faults (malformed XML, a tile line with the wrong number of
+// fields, missing identity attributes) fail either way.
+func DecodeReader(r io.Reader, lax *xmlstream.Lenient) (*wxx.Map_t, error) {
+	return decodeReader(context.Background(), r, lax, 1, xmlstream.Limits{}, nil)
+}
+
+// decodeReader is DecodeReader, parsing <tilerow> elements on up to workers
+// goroutines and building no more tiles than limits allow. See streamTiles.
+// Each child of <map> is reported to trace once it has been read.
+func decodeReader(ctx context.Context, r io.Reader, lax *xmlstream.Lenient, workers int, limits xmlstream.Limits, trace *xmlstream.Trace) (*wxx.Map_t, error) {
+	d := xml.NewDecoder(r)
+
+	root, err := xmlstream.Root(d)
+	if err == nil {
+		err = xmlstream.ExpectRoot(root, "map")
+	}
+	if err != nil {
+		line, column := d.InputPos()
+		return nil, xmlstream.Locate(err, "", line, column)
+	}
+	// starts records where each child of <map> began, so that an error the
+	// conversion reports after the stream has been read can still be placed.
+	starts := map[string]xmlstream.Pos{"map": xmlstream.InputPos(d)}
+	m := &XMLSchema{}
+	if err := xmlstream.DecodeAttrs(root, m); err != nil {
+		return nil, starts["map"].Locate(err, "map")
+	}
+	w, err := decodeMapAttributes(m, lax.In("map"))
+	if err != nil {
+		return nil, starts["map"].Locate(err, "map")
+	}
+
+	// sawTiles records whether <tiles> was present. Decode runs decodeTiles on the
+	// zero Tiles_t when it is not, and the end of the stream has to do the same.
+	sawTiles := false
+	for done := false; !done; {
+		span := trace.Begin(d.InputOffset())
+		tok, err := d.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return nil, xmlstream.InputPos(d).Locate(err, "map")
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path := "map/" + t.Name.Local
+			starts[path] = xmlstream.InputPos(d)
+			switch t.Name.Local {
+			case "gridandnumbering":
+				err = d.DecodeElement(&m.GridAndNumbering, &t)
+			case "blurTerrainBG":
+				err = d.DecodeElement(&m.BlurTerrainBG, &t)
+			case "terrainmap":
+				err = d.DecodeElement(&m.TerrainMap, &t)
+			case "maplayer":
+				err = d.DecodeElement(&m.MapLayers, &t)
+			case "tiles":
+				// a bad tile value is reported with the partial map, as Decode
+				// reports it
+				sawTiles = true
+				if err := streamTiles(ctx, d, t, &m.Tiles, w, lax, workers, limits); err != nil {
+					return w, xmlstream.InputPos(d).Locate(err, path)
+				}
+			case "mapkey":
+				err = d.DecodeElement(&m.MapKey, &t)
+			case "features":
+				err = d.DecodeElement(&m.Features, &t)
+			case "extraTerrain":
+				err = d.DecodeElement(&m.ExtraTerrain, &t)
+			case "labels":
+				err = d.DecodeElement(&m.Labels, &t)
+			case "shapes":
+				err = d.DecodeElement(&m.Shapes, &t)
+			case "notes":
+				err = d.DecodeElement(&m.Notes, &t)
+			case "informations":
+				err = d.DecodeElement(&m.Informations, &t)
+			case "configuration":
+				err = d.DecodeElement(&m.Configuration, &t)
+			default:
+				// an element the schema does not name is kept whole, as Unmarshal
+				// keeps it in the ",any" field
+				var e xmlstream.Element
+				if err = d.DecodeElement(&e, &t); err == nil {
+					m.ExtraElements = append(m.ExtraElements, e)
+				}
+			}
+			if err != nil {
+				return nil, xmlstream.InputPos(d).Locate(err, path)
+			}
+			span.End(t.Name.Local, d.InputOffset())
+		case xml.EndElement:
+			done = true // </map>
+		}
+	}
+
+	w, err = decodeElements(m, w, lax, func() error {
+		if !sawTiles {
+			return decodeTiles(m.Tiles, m.MapKey, w, lax)
+		}
+		// the tilerows were parsed as they streamed past; what is left is the map
+		// key decodeTiles materializes after them.
+		if tileColumns(w.Tiles) != 0 {
+			if err := decodeMapKey(m.MapKey, w, lax.In("map/mapkey")); err != nil {
+				return xmlstream.Locate(err, "map/mapkey", 0, 0)
+			}
+		}
+		return nil
+	})
+	return w, xmlstream.PlaceAt(err, starts)
+}
+
+// streamTiles decodes the <tiles> element whose start tag is start, parsing each
+// <tilerow> into w.Tiles as it is read. The attributes land in src, as Unmarshal
+// would have put them; src.TileRows is never filled.
+//
+// Rows are parsed on up to workers goroutines while the token stream is read
+// ahead of them. Reading the XML cannot be split up -- a token's meaning depends
+// on every byte before it -- but the text of a row, once read, is all parseTileRow
+// needs, and parsing it is most of the work in a large map. Parsed rows are
+// appended to w.Tiles and their warnings to lax in document order, so the map,
+// the warnings and the error returned are the same for any number of workers: a
+// bad row stops the decode where a sequential one would have stopped, even when
+// a later row was parsed first.
+//
+// The size the attributes state is checked against limits before a tile is
+// allocated, and so is each row past tilesWide, since every row adds a column.
+func streamTiles(ctx context.Context, d *xml.Decoder, start xml.StartElement, src *Tiles_t, w *wxx.Map_t, lax *xmlstream.Lenient, workers int, limits xmlstream.Limits) error {
+	if err := xmlstream.DecodeAttrs(start, src); err != nil {
+		return err
+	}
+	if err := limits.CheckTiles(src.TilesWide, src.TilesHigh); err != nil {
+		return err
+	}
+	decodeTilesHeader(*src, w)
+	tilesHigh := w.Tiles.TilesHigh
+	rows := xmlstream.NewOrdered(workers, func(r tileRow) error {
+		w.Tiles.AddColumn(r.tiles)
+		if lax != nil {
+			lax.Warnings = append(lax.Warnings, r.lax.Warnings...)
+		}
+		if r.err != nil {
+			return r.pos.Locate(r.err, fmt.Sprintf("map/tiles/tilerow[%d]", tileColumns(w.Tiles)))
+		}
+		return nil
+	})
+	// fault reports err, found by the reader, unless a row read before it is bad
+	fault := func(err error) error {
+		if rowErr := rows.Wait(); rowErr != nil {
+			return rowErr
+		}
+		return err
+	}
+	for x := 0; ; {
+		tok, err := d.Token()
+		if err != nil {
+			return fault(err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "tilerow" {
+				var e xmlstream.Element
+				if err := d.DecodeElement(&e, &t); err != nil {
+					return fault(err)
+				}
+				src.ExtraElements = append(src.ExtraElements, e)
+				continue
+			}
+			// a cancelled decode stops at the next row
+			if err := ctx.Err(); err != nil {
+				return fault(err)
+			}
+			// the row's text starts right after its start tag
+			pos := xmlstream.InputPos(d)
+			text, err := xmlstream.CharData(d)
+			if err != nil {
+				return fault(err)
+			}
+			row, rowText, rowLax := x, string(text), lax.Fork()
+			x++
+			if err := limits.CheckTiles(x, tilesHigh); err != nil {
+				return fault(err)
+			}
+			if err := rows.Submit(func() tileRow {
+				tiles, err := parseTileRow(rowText, row, tilesHigh, rowLax)
+				return tileRow{tiles: tiles, lax: rowLax, pos: pos, err: err}
+			}); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			// </tiles>; its rows and unrecognised children have all been read now
+			if err := rows.Wait(); err != nil {
+				return err
+			}
+			w.Tiles.Extras = extras(src.ExtraAttrs, src.ExtraElements)
+			return nil
+		}
+	}
+}
+
+// tileRow is one parsed <tilerow> on its way from the goroutine that parsed it
+// to w.Tiles.
+type tileRow struct {
+	tiles []wxx.Tile_t
+	lax   *xmlstream.Lenient // the row's own repairs; nil for a strict decode
+	pos   xmlstream.Pos      // where the row's text starts in the document
+	err   error
+}
@@ -38,14 +38,15 @@ func decodeTerrainMap(src TerrainMap_t, w *wxx.Map_t) error {
 }
 
 func encodeTerrainMap(terrainMap *wxx.TerrainMap_t, wb *bytes.Buffer) error {
-	wb.WriteString(fmt.Sprintf("<terrainmap>"))
+	wb.WriteString("<terrainmap>")
 	for k, v := range terrainMapToSlice(terrainMap.Data) {
-		if k == 0 {
-			wb.WriteString(fmt.Sprintf("%s\t%d", v, k))
-		} else {
-			wb.WriteString(fmt.Sprintf("\t%s\t%d", v, k))
+		if k != 0 {
+			wb.WriteByte('\t')
 		}
+		writeText(wb, v)
+		wb.WriteByte('\t')
+		writeInt(wb, k)
 	}
-	wb.WriteString(fmt.Sprintf("</terrainmap>\n"))
+	wb.WriteString("</terrainmap>\n")
 	return nil
 }
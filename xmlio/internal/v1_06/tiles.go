@@ -4,24 +4,49 @@ package v1_06
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/maloquacious/wxx"
 	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
 )
 
 // decodeTiles parses the <tiles>/<tilerow> data into the domain map. It also
-// decodes <mapkey> inside the tilerow loop (via decodeMapKey), preserving the
-// original decoder's ordering in which the map key was materialized per tilerow
-// after the tiles were parsed.
-func decodeTiles(src Tiles_t, mapKeySrc MapKey_t, w *wxx.Map_t) error {
-	var err error
+// decodes <mapkey> (via decodeMapKey), after the rows and only when there are
+// any. The original decoder materialized the map key again after every row;
+// the result was the same each time, and decoding it once keeps a lenient
+// decode from reporting a bad map key color once per row.
+func decodeTiles(src Tiles_t, mapKeySrc MapKey_t, w *wxx.Map_t, lax *xmlstream.Lenient) error {
+	if err := (xmlstream.Limits{}).CheckTiles(src.TilesWide, src.TilesHigh); err != nil {
+		return xmlstream.Locate(err, "map/tiles", 0, 0)
+	}
+	decodeTilesHeader(src, w)
+	for _, tilerow := range src.TileRows {
+		if err := decodeTileRow(tilerow.InnerText, w, lax); err != nil {
+			return xmlstream.Locate(err, fmt.Sprintf("map/tiles/tilerow[%d]", tileColumns(w.Tiles)), 0, 0)
+		}
+	}
+	if tileColumns(w.Tiles) != 0 {
+		if err := decodeMapKey(mapKeySrc, w, lax.In("map/mapkey")); err != nil {
+			return xmlstream.Locate(err, "map/mapkey", 0, 0)
+		}
+	}
+	return nil
+}
+
+// decodeTilesHeader copies the <tiles> attributes into a new, empty w.Tiles and
+// sets RowsHigh and ColumnsWide from them. The orientation must already be set.
+func decodeTilesHeader(src Tiles_t, w *wxx.Map_t) {
 	w.Tiles = &wxx.Tiles_t{
-		ViewLevel: src.ViewLevel,
-		TilesWide: src.TilesWide,
-		TilesHigh: src.TilesHigh,
+		ViewLevel:   src.ViewLevel,
+		TilesWide:   src.TilesWide,
+		TilesHigh:   src.TilesHigh,
+		Orientation: w.GridOrientation,
+		Extras:      extras(src.ExtraAttrs, src.ExtraElements),
 	}
 
 	// Set RowsHigh and ColumnsWide based on GridOrientation
@@ -35,116 +60,159 @@ func decodeTiles(src Tiles_t, mapKeySrc MapKey_t, w *wxx.Map_t) error {
 		w.RowsHigh = w.Tiles.TilesWide
 		w.ColumnsWide = w.Tiles.TilesHigh
 	}
-	for _, tilerow := range src.TileRows {
-		x, y := len(w.Tiles.Tiles), 0
-		w.Tiles.Tiles = append(w.Tiles.Tiles, make([]*wxx.Tile_t, w.Tiles.TilesHigh))
-		for _, line := range strings.Split(tilerow.InnerText, "\n") {
-			if len(line) == 0 { // ignore blank lines
-				continue
+}
+
+// decodeTileRow parses the text of one <tilerow> and appends it to w.Tiles as
+// the next row. Each row is parsed on its own so the streaming decoder can drop
+// the text once it has been read.
+//
+// An error is an *xmlstream.Error naming the row, the line, and the field that
+// failed; the caller knows where the row sits in the document and adds that with
+// xmlstream.Locate. A lenient decode (non-nil lax) replaces a bad value and
+// records a warning instead, except for a line with the wrong number of fields,
+// which cannot be lined up with the fields it was meant to have.
+func decodeTileRow(text string, w *wxx.Map_t, lax *xmlstream.Lenient) error {
+	row, err := parseTileRow(text, tileColumns(w.Tiles), w.Tiles.TilesHigh, lax)
+	w.Tiles.AddColumn(row)
+	return err
+}
+
+// tileColumns returns the number of <tilerow> elements decoded into tiles.
+func tileColumns(tiles *wxx.Tiles_t) int {
+	wide, _ := tiles.Size()
+	return wide
+}
+
+// parseTileRow parses the text of the <tilerow> at index x into the tiles of
+// its lines, which wxx.Tiles_t.AddColumn pads to tilesHigh. It reads nothing
+// but its arguments and writes nothing but lax, so the streaming decoder can
+// parse rows on separate goroutines, each with a Lenient of its own. A row with
+// more than tilesHigh lines is wxx.ErrInvalidTileGrid. A row that fails is
+// returned as far as it got, which is the partial row decodeTileRow appends.
+// The tiles' positions are left for wxx.Tiles_t to work out when the row is
+// added to the grid.
+func parseTileRow(text string, x, tilesHigh int, lax *xmlstream.Lenient) ([]wxx.Tile_t, error) {
+	var err error
+	y := 0
+	lines := strings.Split(text, "\n")
+	// the row grows with the lines the text holds, never past tilesHigh, so a
+	// file cannot make it allocate more tiles than it writes
+	row := make([]wxx.Tile_t, 0, min(tilesHigh, len(lines)))
+	for i, line := range lines {
+		if len(line) == 0 { // ignore blank lines
+			continue
+		}
+		if y >= tilesHigh {
+			return row, &xmlstream.Error{Path: fmt.Sprintf("map/tiles/tilerow[%d]", x+1), Line: y + 1, TextLine: i + 1, Err: fmt.Errorf("%w: more than %d tiles in the row", wxx.ErrInvalidTileGrid, tilesHigh)}
+		}
+		row = append(row, wxx.Tile_t{})
+		t := &row[y]
+		y++
+		// fail reports a bad value on this line; repair lets a lenient decode
+		// carry on with the replacement described by action instead
+		fail := func(field string, err error) error {
+			return &xmlstream.Error{Path: fmt.Sprintf("map/tiles/tilerow[%d]", x+1), Line: y, TextLine: i + 1, Field: field, Err: err}
+		}
+		repair := func(field, value, action string, err error) error {
+			return lax.Repair(fail(field, err), value, action)
+		}
+		// values are TerrainMapIndex Elevation IsIcy IsGMOnly Animals (Z|(Brick Crops Gems Lumber Metals Rock)) RGBA?
+		values := strings.Split(line, "\t")
+		switch len(values) {
+		case 6, 7, 11, 12: // allowed
+		default:
+			return row, fail("", fmt.Errorf("%w: expected 6/7/11/12, got %d", wxx.ErrInvalidTileFieldCount, len(values)))
+		}
+		if t.Terrain, err = strconv.Atoi(values[0]); err != nil {
+			t.Terrain = 0
+			if err := repair("terrainType", values[0], "set to 0", fmt.Errorf("%w: %w", wxx.ErrInvalidTileValue, err)); err != nil {
+				return row, err
 			}
-			t := &wxx.Tile_t{Row: x, Column: y}
-			if w.GridOrientation == hexg.OddQ {
-				t.Coords = hexg.NewOddQCoord(y, x).ToCube()
-			} else if w.GridOrientation == hexg.OddR {
-				t.Coords = hexg.NewOddRCoord(y, x).ToCube()
+		}
+		if t.Elevation, err = strconv.ParseFloat(values[1], 64); err != nil {
+			t.Elevation = 0
+			if err := repair("elevation", values[1], "set to 0", fmt.Errorf("%w: %w", wxx.ErrInvalidTileValue, err)); err != nil {
+				return row, err
 			}
-			w.Tiles.Tiles[x][y] = t
-			y++
-			// values are TerrainMapIndex Elevation IsIcy IsGMOnly Animals (Z|(Brick Crops Gems Lumber Metals Rock)) RGBA?
-			values := strings.Split(line, "\t")
-			//fmt.Printf("tilerow: %d %d: len(inner) %d lines %d line %d values %d\n", r, i+1, len(element.InnerText), len(lines), len(line), len(values))
-			switch len(values) {
-			case 6, 7, 11, 12: // allowed
-			default:
-				return fmt.Errorf("values: expected 6/7/11/12, got %d", len(values))
+		}
+		t.IsIcy = values[2] == "1"
+		t.IsGMOnly = values[3] == "1"
+		// resource parses the value at index n, which a lenient decode clamps
+		resource := func(field string, n int) (int, error) {
+			v, err := decodeTileResource(values[n])
+			if err != nil {
+				return v, repair(field, values[n], fmt.Sprintf("set to %d", v), err)
 			}
-			if t.Terrain, err = strconv.Atoi(values[0]); err != nil {
-				return fmt.Errorf("value: terrainType: %w", err)
+			return v, nil
+		}
+		if t.Resources.Animal, err = resource("animals", 4); err != nil {
+			return row, err
+		}
+		compressedResources := len(values) == 6 || len(values) == 7
+		if compressedResources {
+			// a with compressed resources should flag them with a Z
+			if values[5] != "Z" {
+				if err := repair("sentinel", values[5], "read as Z", fmt.Errorf("%w: %q is not Z", wxx.ErrInvalidTileValue, values[5])); err != nil {
+					return row, err
+				}
 			}
-			if t.Elevation, err = strconv.ParseFloat(values[1], 64); err != nil {
-				return fmt.Errorf("value: elevation: %w", err)
+		} else {
+			if t.Resources.Brick, err = resource("brick", 5); err != nil {
+				return row, err
 			}
-			t.IsIcy = values[2] == "1"
-			t.IsGMOnly = values[3] == "1"
-			if t.Resources.Animal, err = strconv.Atoi(values[4]); err != nil {
-				return fmt.Errorf("value: animals: %w", err)
-			} else if t.Resources.Animal < 0 {
-				return fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
-			} else if t.Resources.Animal > 100 {
-				return fmt.Errorf("value: animals: %w", fmt.Errorf("invalid value"))
+			if t.Resources.Crops, err = resource("crops", 6); err != nil {
+				return row, err
 			}
-			compressedResources := len(values) == 6 || len(values) == 7
-			if compressedResources {
-				// a with compressed resources should flag them with a Z
-				if values[5] != "Z" {
-					return fmt.Errorf("value: sentinel: %w", fmt.Errorf("invalid value"))
-				}
-			} else {
-				if t.Resources.Brick, err = strconv.Atoi(values[5]); err != nil {
-					return fmt.Errorf("value: brick: %q: %w", values, err)
-				} else if t.Resources.Brick < 0 {
-					return fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Brick > 100 {
-					return fmt.Errorf("value: brick: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Crops, err = strconv.Atoi(values[6]); err != nil {
-					return fmt.Errorf("value: crops: %w", err)
-				} else if t.Resources.Crops < 0 {
-					return fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Crops > 100 {
-					return fmt.Errorf("value: crops: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Gems, err = strconv.Atoi(values[7]); err != nil {
-					return fmt.Errorf("value: gems: %w", err)
-				} else if t.Resources.Gems < 0 {
-					return fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Gems > 100 {
-					return fmt.Errorf("value: gems: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Lumber, err = strconv.Atoi(values[8]); err != nil {
-					return fmt.Errorf("value: lumber: %w", err)
-				} else if t.Resources.Lumber < 0 {
-					return fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Lumber > 100 {
-					return fmt.Errorf("value: lumber: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Metals, err = strconv.Atoi(values[9]); err != nil {
-					return fmt.Errorf("value: metals: %w", err)
-				} else if t.Resources.Metals < 0 {
-					return fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Metals > 100 {
-					return fmt.Errorf("value: metals: %w", fmt.Errorf("invalid value"))
-				}
-				if t.Resources.Rock, err = strconv.Atoi(values[10]); err != nil {
-					return fmt.Errorf("value: rock: %w", err)
-				} else if t.Resources.Rock < 0 {
-					return fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
-				} else if t.Resources.Rock > 100 {
-					return fmt.Errorf("value: rock: %w", fmt.Errorf("invalid value"))
-				}
+			if t.Resources.Gems, err = resource("gems", 7); err != nil {
+				return row, err
 			}
-			if len(values) == 7 || len(values) == 12 {
-				// split rgba
-				if t.CustomBackgroundColor, err = decodeRgba(values[len(values)-1]); err != nil {
-					return fmt.Errorf("value: rgba: %w", err)
+			if t.Resources.Lumber, err = resource("lumber", 8); err != nil {
+				return row, err
+			}
+			if t.Resources.Metals, err = resource("metals", 9); err != nil {
+				return row, err
+			}
+			if t.Resources.Rock, err = resource("rock", 10); err != nil {
+				return row, err
+			}
+		}
+		if len(values) == 7 || len(values) == 12 {
+			// split rgba
+			if t.CustomBackgroundColor, err = decodeRgba(values[len(values)-1]); err != nil {
+				t.CustomBackgroundColor = nil
+				if err := repair("rgba", values[len(values)-1], "dropped", err); err != nil {
+					return row, err
 				}
 			}
 		}
+	}
+	return row, nil
+}
 
-		if err := decodeMapKey(mapKeySrc, w); err != nil {
-			return err
-		}
+// decodeTileResource parses one resource value from a tile line. Resources are
+// integers in the range 0...100. On error the value returned is the one a
+// lenient decode uses: the nearest end of the range for a number outside it,
+// and 0 for anything that is not a number.
+func decodeTileResource(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("%w: %w", wxx.ErrInvalidTileValue, err)
+	} else if n < 0 {
+		return 0, fmt.Errorf("%w: %s is not in 0...100", wxx.ErrInvalidTileValue, s)
+	} else if n > 100 {
+		return 100, fmt.Errorf("%w: %s is not in 0...100", wxx.ErrInvalidTileValue, s)
 	}
-	return nil
+	return n, nil
 }
 
-func encodeTiles(tiles *wxx.Tiles_t, hexOrientation string, wb *bytes.Buffer) error {
+func encodeTiles(ctx context.Context, tiles *wxx.Tiles_t, hexOrientation string, workers int, wb *bytes.Buffer) error {
 	// to: width is the number of columns, height is the number of rows. does that depend on the orientation?
-	wb.WriteString(fmt.Sprintf("<tiles"))
-	wb.WriteString(fmt.Sprintf(" viewLevel=%q", tiles.ViewLevel))
-	wb.WriteString(fmt.Sprintf(" tilesWide=%q", ints(tiles.TilesWide)))
-	wb.WriteString(fmt.Sprintf(" tilesHigh=%q", ints(tiles.TilesHigh)))
-	wb.WriteString(fmt.Sprintf(">\n"))
+	wb.WriteString("<tiles")
+	writeAttr(wb, " viewLevel=", tiles.ViewLevel)
+	writeIntAttr(wb, " tilesWide=", tiles.TilesWide)
+	writeIntAttr(wb, " tilesHigh=", tiles.TilesHigh)
+	wb.WriteString(extraAttrs(tiles.Extras))
+	wb.WriteString(">\n")
 
 	// generate the tile-row elements:
 	// * each tile-row will have a tile.tilesHigh lines of tab delimited data
@@ -161,20 +229,86 @@ func encodeTiles(tiles *wxx.Tiles_t, hexOrientation string, wb *bytes.Buffer) er
 	// here. (Cross-check: ROWS == pointy-top hexes per tcfna's vertex-geometry
 	// notes; that is a client rendering concern and does not alter this data grid.)
 	if hexOrientation == "COLUMNS" || hexOrientation == "ROWS" {
-		for x := 0; x < tiles.TilesWide; x++ {
-			wb.WriteString("<tilerow>\n")
-			for y := 0; y < tiles.TilesHigh; y++ {
-				tile := tiles.Tiles[x][y]
-				if err := encodeTile(tile, wb); err != nil {
-					return err
-				}
-			}
-			wb.WriteString(fmt.Sprintf("</tilerow>\n"))
+		if err := encodeTileRows(ctx, tiles, workers, wb); err != nil {
+			return err
 		}
 	} else {
 		return fmt.Errorf("assert(orientation != %q)", hexOrientation)
 	}
-	wb.WriteString(fmt.Sprintf("</tiles>\n"))
+	wb.WriteString(extraElements(tiles.Extras))
+	wb.WriteString("</tiles>\n")
+	return nil
+}
+
+// encodeTileRows writes the <tilerow> elements, one per column of tiles.
+//
+// With more than one worker each row is written to a buffer of its own on up to
+// workers goroutines, and the buffers are copied to wb in order, so the bytes are
+// the same as a sequential encode's. Rows are independent of each other -- a
+// <tilerow> is a function of its own tiles and nothing else -- which is what lets
+// a large map's tiles, most of its file, be written in parallel.
+func encodeTileRows(ctx context.Context, tiles *wxx.Tiles_t, workers int, wb *bytes.Buffer) error {
+	if wide, high := tiles.Size(); wide < tiles.TilesWide || high < tiles.TilesHigh {
+		return fmt.Errorf("%w: %d x %d tiles for a %d x %d map", wxx.ErrInvalidTileGrid, wide, high, tiles.TilesWide, tiles.TilesHigh)
+	}
+	wb.Grow(tiles.TilesWide * tileRowSize(tiles.TilesHigh))
+	if workers == 1 {
+		var row []wxx.Tile_t
+		for x := 0; x < tiles.TilesWide; x++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			row = tiles.ColumnTiles(row[:0], x)
+			if err := encodeTileRow(row, tiles.TilesHigh, wb); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	type encodedRow struct {
+		buf *bytes.Buffer
+		err error
+	}
+	rows := xmlstream.NewOrdered(workers, func(r encodedRow) error {
+		if r.err != nil {
+			return r.err
+		}
+		_, err := wb.Write(r.buf.Bytes())
+		return err
+	})
+	for x := 0; x < tiles.TilesWide; x++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := rows.Submit(func() encodedRow {
+			buf := bytes.NewBuffer(make([]byte, 0, tileRowSize(tiles.TilesHigh)))
+			return encodedRow{buf: buf, err: encodeTileRow(tiles.ColumnTiles(nil, x), tiles.TilesHigh, buf)}
+		}); err != nil {
+			return err
+		}
+	}
+	return rows.Wait()
+}
+
+// tileLineSize is the length of a typical tile line, "1\t0\t0\t0\t0\tZ\n" with
+// room for larger values, used to size buffers before the tiles are written.
+const tileLineSize = 16
+
+// tileRowSize estimates the length of a <tilerow> holding tilesHigh tiles.
+func tileRowSize(tilesHigh int) int {
+	return len("<tilerow>\n</tilerow>\n") + tilesHigh*tileLineSize
+}
+
+// encodeTileRow writes one <tilerow> element holding the first tilesHigh tiles
+// of row.
+func encodeTileRow(row []wxx.Tile_t, tilesHigh int, wb *bytes.Buffer) error {
+	wb.WriteString("<tilerow>\n")
+	for y := 0; y < tilesHigh; y++ {
+		if err := encodeTile(&row[y], wb); err != nil {
+			return err
+		}
+	}
+	wb.WriteString("</tilerow>\n")
 	return nil
 }
 
@@ -186,36 +320,34 @@ func encodeTiles(tiles *wxx.Tiles_t, hexOrientation string, wb *bytes.Buffer) er
 // * field after resource.animal is "Z" if remaining resources are all 0
 // * otherwise we have brick, crops, gems, lumber, metals, rock
 // * customBackgroundColor is an RGBA that is optional
+//
+// The line is built in wb's spare capacity with the strconv append functions
+// and written with a single Write, so encoding a tile allocates nothing once wb
+// has grown to hold the row. This is most of the work of encoding a large map.
 func encodeTile(tile *wxx.Tile_t, wb *bytes.Buffer) error {
-	// todo: implement this
-	wb.WriteString(fmt.Sprintf("%d", tile.Terrain))
-	wb.WriteString(fmt.Sprintf("\t%d", floatd(tile.Elevation)))
-	wb.WriteString(fmt.Sprintf("\t%d", boold(tile.IsIcy)))
-	wb.WriteString(fmt.Sprintf("\t%d", boold(tile.IsGMOnly)))
-	if err := encodeTileResources(tile.Resources, wb); err != nil {
-		return err
-	}
+	b := wb.AvailableBuffer()
+	b = strconv.AppendInt(b, int64(tile.Terrain), 10)
+	b = strconv.AppendInt(append(b, '\t'), int64(floatd(tile.Elevation)), 10)
+	b = strconv.AppendInt(append(b, '\t'), int64(boold(tile.IsIcy)), 10)
+	b = strconv.AppendInt(append(b, '\t'), int64(boold(tile.IsGMOnly)), 10)
+	b = appendTileResources(b, tile.Resources)
 	if tile.CustomBackgroundColor != nil {
-		wb.WriteString(fmt.Sprintf("\t%s", rgbas(tile.CustomBackgroundColor)))
+		b = appendRgbas(append(b, '\t'), tile.CustomBackgroundColor)
 	}
-	wb.WriteString(fmt.Sprintf("\n"))
+	wb.Write(append(b, '\n'))
 	return nil
 }
 
-// all resources are supposed to be in the range of 0...100, but we don't enforce
-func encodeTileResources(resources wxx.Resources_t, wb *bytes.Buffer) error {
+// appendTileResources appends the resource fields of a tile line to b.
+// All resources are supposed to be in the range of 0...100, but we don't enforce
+func appendTileResources(b []byte, resources wxx.Resources_t) []byte {
+	b = strconv.AppendInt(append(b, '\t'), int64(resources.Animal), 10)
 	// compress if there are no resources other than Animal
 	if resources.Brick == 0 && resources.Crops == 0 && resources.Gems == 0 && resources.Lumber == 0 && resources.Metals == 0 && resources.Rock == 0 {
-		wb.WriteString(fmt.Sprintf("\t%d", resources.Animal))
-		wb.WriteString(fmt.Sprintf("\tZ"))
-		return nil
+		return append(b, "\tZ"...)
 	}
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Animal))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Brick))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Crops))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Gems))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Lumber))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Metals))
-	wb.WriteString(fmt.Sprintf("\t%d", resources.Rock))
-	return nil
+	for _, n := range [...]int{resources.Brick, resources.Crops, resources.Gems, resources.Lumber, resources.Metals, resources.Rock} {
+		b = strconv.AppendInt(append(b, '\t'), int64(n), 10)
+	}
+	return b
 }
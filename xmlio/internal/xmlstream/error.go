@@ -0,0 +1,116 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Error is a decode error located in the document. The codecs return it, and
+// xmlio converts it to its exported DecodeError at the dispatch boundary; it is
+// a separate type only because the codecs cannot import xmlio.
+//
+// A codec fills in what it knows where it knows it: the tile parser knows the
+// line and field but not which <tilerow> it was handed, and the stream loop
+// knows the element path and the XML position but not what went wrong inside
+// the text. Locate merges the two.
+type Error struct {
+	Path      string // element path, "map/tiles/tilerow[17]"; indices are 1-based
+	Line      int    // 1-based line within the element's data, 0 for none
+	Field     string // value within the line or element that failed, "" for none
+	XMLLine   int    // 1-based line in the XML, 0 when unknown
+	XMLColumn int    // 1-based column in the XML, 0 when unknown
+
+	// TextLine is the 1-based line within the element's character data that
+	// Line sits on, counting blank lines, which Line does not. Locate uses it to
+	// turn the position of the element's start tag into the position of the line.
+	TextLine int
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	if e.Path != "" {
+		sb.WriteString(e.Path)
+		sb.WriteString(": ")
+	}
+	if e.Line != 0 {
+		sb.WriteString(fmt.Sprintf("line %d: ", e.Line))
+	}
+	if e.Field != "" {
+		sb.WriteString(e.Field)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Err.Error())
+	if e.XMLLine != 0 {
+		sb.WriteString(fmt.Sprintf(" (xml line %d, column %d)", e.XMLLine, e.XMLColumn))
+	}
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Locate places err at path. line and column are the position just after the
+// element's start tag, where its character data begins, or 0 when unknown.
+//
+// An err that is already an *Error keeps what it knows and gains what it was
+// missing; any other err is wrapped in a new *Error.
+func Locate(err error, path string, line, column int) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		return &Error{Path: path, XMLLine: line, XMLColumn: column, Err: err}
+	}
+	if e.Path == "" {
+		e.Path = path
+	}
+	if e.XMLLine == 0 && line != 0 {
+		switch {
+		case e.TextLine > 1:
+			e.XMLLine, e.XMLColumn = line+e.TextLine-1, 1
+		default:
+			e.XMLLine, e.XMLColumn = line, column
+		}
+	}
+	return err
+}
+
+// Pos is a position in the XML, as xml.Decoder.InputPos reports it.
+type Pos struct {
+	Line, Column int
+}
+
+// InputPos returns the decoder's current position: just after the token it
+// read last.
+func InputPos(d *xml.Decoder) Pos {
+	line, column := d.InputPos()
+	return Pos{Line: line, Column: column}
+}
+
+// Locate places err at path, with p as the position its element's character
+// data begins. See the Locate function.
+func (p Pos) Locate(err error, path string) error {
+	return Locate(err, path, p.Line, p.Column)
+}
+
+// PlaceAt adds an XML position to an *Error that has a path but no position,
+// using the position recorded for that path in starts. It is for errors from
+// the conversion that runs once the stream has been read, which know the
+// element that failed but not where it was.
+func PlaceAt(err error, starts map[string]Pos) error {
+	var e *Error
+	if errors.As(err, &e) && e.XMLLine == 0 {
+		if p, ok := starts[e.Path]; ok {
+			p.Locate(err, e.Path)
+		}
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// The escape helpers append a string from the map to the XML the encoders are
+// writing, escaped so that a parser reads back exactly that string.
+//
+// Escaping cannot make every string safe: a character XML does not allow at all,
+// such as U+0001 or half of a UTF-16 surrogate pair, has no spelling in an XML
+// document. Those are copied as they are, and WellFormed rejects the document.
+
+// AppendAttr appends value to b as a double-quoted attribute value and returns
+// the extended slice. '&', '<' and '"' are escaped, and so are the characters a
+// parser would not give back as they were: a tab, a line break or a carriage
+// return is turned into a space by attribute-value normalization, and the
+// characters in needsCharRef are changed or restricted by XML 1.1.
+//
+// The encoders used to quote attributes with strconv.AppendQuote, which is not
+// XML. A value of printable characters with none of those and no backslash is
+// written the same either way.
+func AppendAttr(b []byte, value string) []byte {
+	b = append(b, '"')
+	b = appendEscaped(b, value, true)
+	return append(b, '"')
+}
+
+// AppendText appends s to b escaped as character data and returns the extended
+// slice. It escapes what html.EscapeString escapes, which is how the encoders
+// have always written inner text, and a line break, for the same reason; and it
+// escapes a carriage return and the characters in needsCharRef, which a parser
+// would otherwise change.
+func AppendText(b []byte, s string) []byte {
+	return appendEscaped(b, s, false)
+}
+
+// AppendCDATA appends s to b as a CDATA section and returns the extended slice.
+// A CDATA section cannot hold "]]>", which ends it, nor a character reference,
+// so where s has "]]>" the section is closed between the brackets and a new one
+// opened, and a character AppendText would have written as a reference is
+// written as one between two sections. A parser reads the whole as s.
+func AppendCDATA(b []byte, s string) []byte {
+	b = append(b, "<![CDATA["...)
+	last := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ']' && strings.HasPrefix(s[i:], "]]>"):
+			b = append(b, s[last:i+2]...)
+			b = append(b, "]]><![CDATA["...)
+			i += 2
+			last = i
+		case c == '\r' || c == 0x7f:
+			b = append(b, s[last:i]...)
+			b = appendCharRef(append(b, "]]>"...), rune(c))
+			b = append(b, "<![CDATA["...)
+			i++
+			last = i
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if needsCharRef(r) {
+				b = append(b, s[last:i]...)
+				b = appendCharRef(append(b, "]]>"...), r)
+				b = append(b, "<![CDATA["...)
+				last = i + size
+			}
+			i += size
+		default:
+			i++
+		}
+	}
+	b = append(b, s[last:]...)
+	return append(b, "]]>"...)
+}
+
+// AppendCDATAText appends s to b as Worldographer writes the text of an
+// <information>: a CDATA section, as AppendCDATA writes it, holding s up to the
+// whitespace it ends with, and then that whitespace as plain text, which is
+// where the file had the line breaks between the entry and the entries nested
+// in it.
+func AppendCDATAText(b []byte, s string) []byte {
+	body := strings.TrimRight(s, " \t\n")
+	return append(AppendCDATA(b, body), s[len(body):]...)
+}
+
+// appendEscaped appends s to b, escaped for an attribute value or for text.
+func appendEscaped(b []byte, s string, attr bool) []byte {
+	last := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if needsCharRef(r) {
+				b = appendCharRef(append(b, s[last:i]...), r)
+				last = i + size
+			}
+			i += size
+			continue
+		}
+		var esc string
+		switch {
+		case c == '&':
+			esc = "&amp;"
+		case c == '<':
+			esc = "&lt;"
+		case c == '"' && attr:
+			esc = "&quot;"
+		case c == '"':
+			esc = "&#34;"
+		case c == '>' && !attr:
+			esc = "&gt;"
+		case c == '\'' && !attr:
+			esc = "&#39;"
+		case c == '\n':
+			esc = "&#10;"
+		case c == '\r':
+			esc = "&#13;"
+		case c == '\t' && attr:
+			esc = "&#9;"
+		case c == 0x7f:
+			esc = "&#127;"
+		default:
+			i++
+			continue
+		}
+		b = append(append(b, s[last:i]...), esc...)
+		i++
+		last = i
+	}
+	return append(b, s[last:]...)
+}
+
+// needsCharRef reports whether r, outside ASCII, has to be written as a
+// character reference. XML 1.1, which W2025 files declare, reads U+0085 and
+// U+2028 as line breaks, and allows the other C1 controls only as references.
+func needsCharRef(r rune) bool {
+	return (0x80 <= r && r <= 0x9f) || r == 0x2028
+}
+
+// appendCharRef appends r to b as a decimal character reference.
+func appendCharRef(b []byte, r rune) []byte {
+	b = append(b, "&#"...)
+	b = strconv.AppendInt(b, int64(r), 10)
+	return append(b, ';')
+}
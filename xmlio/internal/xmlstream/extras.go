@@ -0,0 +1,102 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+)
+
+// Element is an element a schema struct does not name, caught whole. The codecs'
+// schema structs carry a []Element field tagged ",any", which encoding/xml fills
+// with every child element no other field claims; that is how an unrecognised
+// child survives a decode instead of being skipped.
+type Element struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Inner   string     `xml:",innerxml"`
+}
+
+// String returns the element as XML text. The start tag is rebuilt from the
+// decoded name and attributes, so its spelling is encoding/xml's rather than the
+// file's; the content is the file's text, byte for byte.
+func (e Element) String() string {
+	var sb strings.Builder
+	sb.WriteString("<" + e.XMLName.Local)
+	writeAttrs(&sb, e.Attrs)
+	if e.Inner == "" {
+		sb.WriteString("/>")
+		return sb.String()
+	}
+	sb.WriteString(">" + e.Inner + "</" + e.XMLName.Local + ">")
+	return sb.String()
+}
+
+// writeAttrs writes each attribute as ` name="value"`, with the value escaped.
+func writeAttrs(sb *strings.Builder, attrs []xml.Attr) {
+	for _, a := range attrs {
+		sb.WriteString(" " + attrName(a.Name) + `="`)
+		sb.WriteString(escape(a.Value))
+		sb.WriteString(`"`)
+	}
+}
+
+// attrName is the attribute's name as the file wrote it. encoding/xml resolves a
+// prefix it knows to the namespace URL and leaves one it does not in Space; the
+// first cannot be written back as it was, so it is written without the prefix.
+func attrName(n xml.Name) string {
+	if n.Space == "" || strings.Contains(n.Space, "/") {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+// escape escapes s for a double-quoted attribute value.
+func escape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// Extras returns the unrecognised attributes and elements of an element read
+// against schema, or nil when it had none.
+func Extras(schema string, attrs []xml.Attr, elements []Element) *wxx.Extras_t {
+	if len(attrs) == 0 && len(elements) == 0 {
+		return nil
+	}
+	x := &wxx.Extras_t{Schema: schema}
+	for _, a := range attrs {
+		x.Attrs = append(x.Attrs, wxx.ExtraAttr_t{Name: attrName(a.Name), Value: a.Value})
+	}
+	for _, e := range elements {
+		x.Elements = append(x.Elements, e.String())
+	}
+	return x
+}
+
+// ExtraAttrs returns the attributes of x that an encoder for schema writes, as
+// the text to put after the element's known attributes: "" when x is nil or was
+// read against another schema.
+func ExtraAttrs(x *wxx.Extras_t, schema string) string {
+	if x == nil || x.Schema != schema || len(x.Attrs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for _, a := range x.Attrs {
+		sb.WriteString(" " + a.Name + `="` + escape(a.Value) + `"`)
+	}
+	return sb.String()
+}
+
+// ExtraElements returns the elements of x that an encoder for schema writes, as
+// the text to put before the element's end tag: "" when x is nil or was read
+// against another schema.
+func ExtraElements(x *wxx.Extras_t, schema string) string {
+	if x == nil || x.Schema != schema {
+		return ""
+	}
+	return strings.Join(x.Elements, "")
+}
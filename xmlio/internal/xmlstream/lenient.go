@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import (
+	"errors"
+
+	"github.com/maloquacious/wxx"
+)
+
+// Warning records a bad value that a lenient decode repaired instead of
+// rejecting the map. It carries the location an *Error would have, plus the
+// value as the file spelled it and what was done about it.
+type Warning struct {
+	Path   string // element path, as Error.Path
+	Line   int    // 1-based line within the element's data, 0 for none
+	Field  string // value that was repaired, as Error.Field
+	Value  string // the value as the file stated it
+	Action string // what the decoder used instead, "set to 100" or "dropped"
+	Err    error  // the error a strict decode would have returned
+}
+
+// Lenient collects the repairs of a lenient decode. The codecs take a
+// *Lenient and treat nil as strict, so every repair site is a single Repair
+// call that either returns the error or records it.
+type Lenient struct {
+	Warnings []Warning
+
+	// path is the element the codec is converting, for errors that do not
+	// name one themselves. See In.
+	path string
+}
+
+// In sets the element path recorded with warnings whose error does not name
+// one, and returns l. It exists for the element converters, which know their
+// field names but not where they were called from.
+func (l *Lenient) In(path string) *Lenient {
+	if l != nil {
+		l.path = path
+	}
+	return l
+}
+
+// Fork returns an empty Lenient for work done on another goroutine, nil when l
+// is, so the work is strict or lenient as l is. A Lenient is not safe for
+// concurrent use: In and Repair both write to it. The caller appends the fork's
+// Warnings to l's once the work is done, in document order.
+func (l *Lenient) Fork() *Lenient {
+	if l == nil {
+		return nil
+	}
+	return &Lenient{path: l.path}
+}
+
+// Repair decides what happens to err, the error for a bad value. A strict
+// decode (nil l) returns err unchanged. A lenient one records a Warning saying
+// that value was replaced by action, and returns nil so the caller goes on with
+// the replacement it has already put in place.
+//
+// The location comes from err when it is an *Error, so a codec reports a bad
+// value the same way in either mode.
+func (l *Lenient) Repair(err error, value, action string) error {
+	if l == nil || err == nil {
+		return err
+	}
+	w := Warning{Path: l.path, Value: value, Action: action, Err: err}
+	var e *Error
+	if errors.As(err, &e) {
+		if e.Path != "" {
+			w.Path = e.Path
+		}
+		w.Line, w.Field, w.Err = e.Line, e.Field, e.Err
+	}
+	l.Warnings = append(l.Warnings, w)
+	return nil
+}
+
+// RGBA decodes a color attribute with decode. A color a lenient decode cannot
+// parse is dropped, which is what a file that left the attribute out would have
+// decoded to. field names the attribute in the error, as the codecs' converters
+// already name it ("label.color").
+func (l *Lenient) RGBA(decode func(string) (*wxx.RGBA_t, error), value, field string) (*wxx.RGBA_t, error) {
+	rgba, err := decode(value)
+	if err != nil {
+		return nil, l.Repair(&Error{Field: field, Err: err}, value, "dropped")
+	}
+	return rgba, nil
+}
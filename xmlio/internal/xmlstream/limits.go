@@ -0,0 +1,30 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import (
+	"fmt"
+
+	"github.com/maloquacious/wxx"
+)
+
+// Limits bounds what a streaming decode will build from a document it has no
+// reason to trust. A zero field is no limit.
+type Limits struct {
+	// Tiles is the most tiles <tiles> may hold: the tilesWide x tilesHigh it
+	// states, and the tiles in the rows it has.
+	Tiles int
+}
+
+// CheckTiles returns an error when a grid of wide columns of high tiles is not
+// one to allocate: a negative size is wxx.ErrInvalidTileGrid whatever the
+// limits, and more tiles than l allows is wxx.ErrTooManyTiles.
+func (l Limits) CheckTiles(wide, high int) error {
+	if wide < 0 || high < 0 {
+		return fmt.Errorf("%w: %d x %d tiles", wxx.ErrInvalidTileGrid, wide, high)
+	}
+	if l.Tiles > 0 && high > 0 && wide > l.Tiles/high {
+		return fmt.Errorf("%w: %d x %d tiles is more than %d", wxx.ErrTooManyTiles, wide, high, l.Tiles)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import "runtime"
+
+// Workers returns the number of goroutines a concurrency setting of n asks for:
+// n itself when it is positive, and one per CPU Go will use otherwise.
+func Workers(n int) int {
+	if n < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// Ordered runs work on up to n goroutines at a time and hands each result to
+// done in the order the work was submitted, on the goroutine that submitted it.
+//
+// The codecs use it for <tilerow> elements, which are independent of each other:
+// a row can be parsed, or written, without knowing anything about its
+// neighbours. What is not independent is what happens next -- rows are appended
+// to the map, warnings recorded, bytes written -- and that is done, which runs on
+// the caller's goroutine in document order, so none of it needs a lock and the
+// result is the same as a sequential run's, whatever the timing.
+//
+// At most n pieces of work are in flight. Submit waits for the oldest to finish
+// before starting another, which bounds the memory a stream decode holds to n
+// rows no matter how far the reader gets ahead of the parsers. With n of 1 work
+// runs inline and no goroutine is started.
+type Ordered[T any] struct {
+	n       int
+	done    func(T) error
+	pending []chan T // results not yet handed to done, oldest first
+}
+
+// NewOrdered returns an Ordered that runs up to n pieces of work at once and
+// hands their results to done.
+func NewOrdered[T any](n int, done func(T) error) *Ordered[T] {
+	return &Ordered[T]{n: max(n, 1), done: done}
+}
+
+// Submit starts work. If n pieces are already in flight it first waits for the
+// oldest and hands its result to done; the error is done's.
+//
+// After an error the Ordered must not be used again. Work still in flight runs to
+// completion and its results are dropped.
+func (o *Ordered[T]) Submit(work func() T) error {
+	if o.n == 1 {
+		return o.done(work())
+	}
+	if len(o.pending) == o.n {
+		if err := o.next(); err != nil {
+			return err
+		}
+	}
+	ch := make(chan T, 1)
+	o.pending = append(o.pending, ch)
+	go func() { ch <- work() }()
+	return nil
+}
+
+// Wait hands every result still pending to done, in order, and returns the
+// first error done returns.
+func (o *Ordered[T]) Wait() error {
+	for len(o.pending) != 0 {
+		if err := o.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next waits for the oldest result and hands it to done.
+func (o *Ordered[T]) next() error {
+	ch := o.pending[0]
+	o.pending = o.pending[1:]
+	return o.done(<-ch)
+}
@@ -0,0 +1,39 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import "time"
+
+// Trace is told about each child of <map> as a codec reads it, for a caller
+// tracing the decode (see xmlio.WithDecoderTracer). A nil *Trace traces
+// nothing, so a codec calls it whether or not anyone is listening, as it calls
+// a nil *Lenient.
+type Trace struct {
+	// Section is called once a child of <map> has been read. name is its element
+	// name, and start and end are the input offsets, as xml.Decoder.InputOffset
+	// counts them, before the token that opened it and after its end tag. took
+	// is how long reading and decoding it took, the reads below it included.
+	Section func(name string, start, end int64, took time.Duration)
+}
+
+// Span is a section being read.
+type Span struct {
+	t     *Trace
+	start int64
+	at    time.Time
+}
+
+// Begin starts the span of a section that will be read from offset.
+func (t *Trace) Begin(offset int64) Span {
+	if t == nil {
+		return Span{}
+	}
+	return Span{t: t, start: offset, at: time.Now()}
+}
+
+// End reports the span as the section name, read up to offset.
+func (s Span) End(name string, offset int64) {
+	if s.t != nil {
+		s.t.Section(name, s.start, offset, time.Since(s.at))
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlstream
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maloquacious/wxx"
+)
+
+// WellFormed checks that doc is one well-formed XML document, and returns an
+// *Error placing the first fault it finds when it is not. A character XML does
+// not allow, or bytes that are not UTF-8, is wxx.ErrInvalidXMLCharacter.
+//
+// It is the check the encoders' output is put through before it is returned, so
+// it is written to cost little next to the encode: it reads doc once, allocates
+// only to hold the names of the open elements, and builds nothing. It knows the
+// XML the encoders write -- elements, attributes, text, CDATA sections, comments
+// and processing instructions -- and rejects a DOCTYPE, which they never write,
+// rather than read one.
+func WellFormed(doc []byte) error {
+	c := wellFormed{doc: doc, attr: span{-1, -1}}
+	return c.document()
+}
+
+// span is the [start, end) of a name in the document.
+type span struct {
+	start, end int
+}
+
+type wellFormed struct {
+	doc   []byte
+	i     int    // the next byte to read
+	open  []span // the names of the elements open at i
+	attrs []span // the names of the attributes read in the current start tag
+	attr  span   // the attribute being read, {-1, -1} outside one
+	root  bool   // the root element has been opened
+}
+
+func (c *wellFormed) document() error {
+	for c.i < len(c.doc) {
+		switch {
+		case c.doc[c.i] == '<':
+			if err := c.markup(); err != nil {
+				return err
+			}
+		case len(c.open) != 0:
+			if err := c.text(); err != nil {
+				return err
+			}
+		case isSpace(c.doc[c.i]):
+			c.i++
+		default:
+			return c.fail("text outside the root element")
+		}
+	}
+	if len(c.open) != 0 {
+		return c.fail(fmt.Sprintf("<%s> is not closed", c.name(c.open[len(c.open)-1])))
+	} else if !c.root {
+		return c.fail("no root element")
+	}
+	return nil
+}
+
+// markup reads the tag, comment, CDATA section or processing instruction that
+// opens at c.i.
+func (c *wellFormed) markup() error {
+	rest := c.doc[c.i:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("<!--")):
+		end := bytes.Index(rest[4:], []byte("--"))
+		if end == -1 {
+			return c.fail("comment is not closed")
+		}
+		end += 4
+		if end+2 >= len(rest) || rest[end+2] != '>' {
+			c.i += end
+			return c.fail(`"--" in a comment`)
+		}
+		return c.chars(c.i+4, c.i+end, c.i+end+3)
+	case bytes.HasPrefix(rest, []byte("<![CDATA[")):
+		if len(c.open) == 0 {
+			return c.fail("CDATA section outside the root element")
+		}
+		end := bytes.Index(rest, []byte("]]>"))
+		if end == -1 {
+			return c.fail("CDATA section is not closed")
+		}
+		return c.chars(c.i+len("<![CDATA["), c.i+end, c.i+end+3)
+	case bytes.HasPrefix(rest, []byte("<?")):
+		c.i += 2
+		target, err := c.readName()
+		if err != nil {
+			return err
+		}
+		if strings.EqualFold(c.name(target), "xml") && target.start != 2 {
+			return c.fail("XML declaration is not at the start of the document")
+		}
+		end := bytes.Index(c.doc[c.i:], []byte("?>"))
+		if end == -1 {
+			return c.fail("processing instruction is not closed")
+		}
+		return c.chars(c.i, c.i+end, c.i+end+2)
+	case bytes.HasPrefix(rest, []byte("<!")):
+		return c.fail("unexpected <! declaration")
+	case bytes.HasPrefix(rest, []byte("</")):
+		return c.endTag()
+	}
+	return c.startTag()
+}
+
+// startTag reads the start tag or empty-element tag that opens at c.i.
+func (c *wellFormed) startTag() error {
+	if c.root && len(c.open) == 0 {
+		return c.fail("a second root element")
+	}
+	c.i++
+	name, err := c.readName()
+	if err != nil {
+		return err
+	}
+	c.root = true
+	c.open = append(c.open, name)
+	c.attrs = c.attrs[:0]
+	for {
+		spaced := c.skipSpace()
+		if c.i >= len(c.doc) {
+			return c.fail("start tag is not closed")
+		}
+		switch {
+		case c.doc[c.i] == '>':
+			c.i++
+			return nil
+		case c.doc[c.i] == '/':
+			if c.i+1 >= len(c.doc) || c.doc[c.i+1] != '>' {
+				return c.fail(`"/" in a start tag`)
+			}
+			c.i += 2
+			c.open = c.open[:len(c.open)-1]
+			return nil
+		case !spaced:
+			return c.fail("no space before an attribute")
+		}
+		if err := c.attribute(); err != nil {
+			return err
+		}
+	}
+}
+
+// attribute reads the name="value" that starts at c.i.
+func (c *wellFormed) attribute() error {
+	name, err := c.readName()
+	if err != nil {
+		return err
+	}
+	for _, a := range c.attrs {
+		if bytes.Equal(c.doc[a.start:a.end], c.doc[name.start:name.end]) {
+			return c.fail(fmt.Sprintf("attribute %s is repeated", c.name(name)))
+		}
+	}
+	c.attrs = append(c.attrs, name)
+	c.attr = name
+	c.skipSpace()
+	if c.i >= len(c.doc) || c.doc[c.i] != '=' {
+		return c.fail(`no "=" after an attribute name`)
+	}
+	c.i++
+	c.skipSpace()
+	if c.i >= len(c.doc) || (c.doc[c.i] != '"' && c.doc[c.i] != '\'') {
+		return c.fail("attribute value is not quoted")
+	}
+	quote := c.doc[c.i]
+	c.i++
+	for {
+		if c.i >= len(c.doc) {
+			return c.fail("attribute value is not closed")
+		}
+		switch b := c.doc[c.i]; {
+		case b == quote:
+			c.i++
+			c.attr = span{-1, -1}
+			return nil
+		case b == '<':
+			return c.fail(`"<" in an attribute value`)
+		case b == '&':
+			if err := c.reference(); err != nil {
+				return err
+			}
+		default:
+			if err := c.char(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// endTag reads the end tag that opens at c.i, which must close the element
+// opened last.
+func (c *wellFormed) endTag() error {
+	c.i += 2
+	name, err := c.readName()
+	if err != nil {
+		return err
+	}
+	c.skipSpace()
+	if c.i >= len(c.doc) || c.doc[c.i] != '>' {
+		return c.fail("end tag is not closed")
+	}
+	if len(c.open) == 0 {
+		return c.fail(fmt.Sprintf("</%s> closes no element", c.name(name)))
+	}
+	top := c.open[len(c.open)-1]
+	if !bytes.Equal(c.doc[top.start:top.end], c.doc[name.start:name.end]) {
+		return c.fail(fmt.Sprintf("</%s> closes <%s>", c.name(name), c.name(top)))
+	}
+	c.open = c.open[:len(c.open)-1]
+	c.i++
+	return nil
+}
+
+// text reads character data up to the next markup.
+func (c *wellFormed) text() error {
+	for c.i < len(c.doc) {
+		// the common case, and all of a tile row
+		for c.i < len(c.doc) && plainText[c.doc[c.i]] {
+			c.i++
+		}
+		if c.i == len(c.doc) {
+			break
+		}
+		switch b := c.doc[c.i]; {
+		case b == '<':
+			return nil
+		case b == '&':
+			if err := c.reference(); err != nil {
+				return err
+			}
+		case b == ']' && bytes.HasPrefix(c.doc[c.i:], []byte("]]>")):
+			return c.fail(`"]]>" in text`)
+		case b == ']':
+			c.i++
+		default:
+			if err := c.char(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// reference reads the entity or character reference that starts at c.i. Only
+// the five entities XML predefines exist, since there is no DTD.
+func (c *wellFormed) reference() error {
+	end := bytes.IndexByte(c.doc[c.i:], ';')
+	if end == -1 || end > 16 {
+		return c.fail(`"&" does not start a reference`)
+	}
+	ref := c.doc[c.i+1 : c.i+end]
+	if len(ref) > 1 && ref[0] == '#' {
+		base, digits := 10, ref[1:]
+		if digits[0] == 'x' {
+			base, digits = 16, digits[1:]
+		}
+		r := rune(0)
+		for _, d := range digits {
+			v := digitValue(d)
+			if v >= base {
+				return c.fail(fmt.Sprintf("character reference &%s; is malformed", ref))
+			}
+			r = r*rune(base) + rune(v)
+		}
+		if len(digits) == 0 || !isChar(r) {
+			return c.failErr(fmt.Errorf("%w: &%s;", wxx.ErrInvalidXMLCharacter, ref))
+		}
+	} else {
+		switch string(ref) {
+		case "amp", "lt", "gt", "quot", "apos":
+		default:
+			return c.fail(fmt.Sprintf("undefined entity &%s;", ref))
+		}
+	}
+	c.i += end + 1
+	return nil
+}
+
+// char reads one character, which must be one XML allows.
+func (c *wellFormed) char() error {
+	r, size := utf8.DecodeRune(c.doc[c.i:])
+	if r == utf8.RuneError && size == 1 {
+		return c.failErr(fmt.Errorf("%w: byte %#02x is not UTF-8", wxx.ErrInvalidXMLCharacter, c.doc[c.i]))
+	} else if !isChar(r) {
+		return c.failErr(fmt.Errorf("%w: %U", wxx.ErrInvalidXMLCharacter, r))
+	}
+	c.i += size
+	return nil
+}
+
+// chars checks the characters of doc[from:to], the content of a comment, CDATA
+// section or processing instruction, and moves on to next.
+func (c *wellFormed) chars(from, to, next int) error {
+	for c.i = from; c.i < to; {
+		if b := c.doc[c.i]; b >= 0x20 && b < utf8.RuneSelf || b == '\t' || b == '\n' {
+			c.i++
+		} else if err := c.char(); err != nil {
+			return err
+		}
+	}
+	c.i = next
+	return nil
+}
+
+// readName reads the name that starts at c.i.
+func (c *wellFormed) readName() (span, error) {
+	start := c.i
+	for c.i < len(c.doc) {
+		r, size := rune(c.doc[c.i]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(c.doc[c.i:])
+		}
+		if !isNameChar(r) || (c.i == start && !isNameStart(r)) {
+			break
+		}
+		c.i += size
+	}
+	if c.i == start {
+		return span{}, c.fail("missing or malformed name")
+	}
+	return span{start, c.i}, nil
+}
+
+// skipSpace moves past white space, reporting whether there was any.
+func (c *wellFormed) skipSpace() bool {
+	start := c.i
+	for c.i < len(c.doc) && isSpace(c.doc[c.i]) {
+		c.i++
+	}
+	return c.i != start
+}
+
+func (c *wellFormed) name(s span) string {
+	return string(c.doc[s.start:s.end])
+}
+
+// fail returns an *Error for the fault at c.i.
+func (c *wellFormed) fail(msg string) error {
+	return c.failErr(errors.New(msg))
+}
+
+// failErr returns an *Error wrapping err, placed at c.i and in the element, and
+// the attribute, that c.i is in.
+func (c *wellFormed) failErr(err error) error {
+	var path []string
+	for _, s := range c.open {
+		path = append(path, c.name(s))
+	}
+	if c.attr.start != -1 {
+		path = append(path, "@"+c.name(c.attr))
+	}
+	line := 1 + bytes.Count(c.doc[:c.i], []byte("\n"))
+	column := c.i - bytes.LastIndexByte(c.doc[:c.i], '\n')
+	return &Error{Path: strings.Join(path, "/"), XMLLine: line, XMLColumn: column, Err: err}
+}
+
+// plainText is the bytes text may hold that need no more than a glance: ASCII
+// other than markup and the controls XML does not allow.
+var plainText = func() (t [256]bool) {
+	for b := 0x20; b < utf8.RuneSelf; b++ {
+		t[b] = b != '<' && b != '&' && b != ']'
+	}
+	t['\t'], t['\n'], t['\r'] = true, true, true
+	return t
+}()
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// isChar reports whether XML 1.0 allows r in a document.
+func isChar(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\r' ||
+		(0x20 <= r && r <= 0xd7ff) || (0xe000 <= r && r <= 0xfffd) || (0x10000 <= r && r <= 0x10ffff)
+}
+
+// isNameStart reports whether r may start an XML name.
+func isNameStart(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', r == '_', r == ':':
+		return true
+	case r < 0xc0:
+		return false
+	}
+	return (0xc0 <= r && r <= 0xd6) || (0xd8 <= r && r <= 0xf6) || (0xf8 <= r && r <= 0x2ff) ||
+		(0x370 <= r && r <= 0x37d) || (0x37f <= r && r <= 0x1fff) || (0x200c <= r && r <= 0x200d) ||
+		(0x2070 <= r && r <= 0x218f) || (0x2c00 <= r && r <= 0x2fef) || (0x3001 <= r && r <= 0xd7ff) ||
+		(0xf900 <= r && r <= 0xfdcf) || (0xfdf0 <= r && r <= 0xfffd) || (0x10000 <= r && r <= 0xeffff)
+}
+
+// isNameChar reports whether r may be in an XML name.
+func isNameChar(r rune) bool {
+	return isNameStart(r) || r == '-' || r == '.' || ('0' <= r && r <= '9') ||
+		r == 0xb7 || (0x300 <= r && r <= 0x36f) || (0x203f <= r && r <= 0x2040)
+}
+
+// digitValue returns the value of the hexadecimal digit d, or 16 when d is not one.
+func digitValue(d byte) int {
+	switch {
+	case '0' <= d && d <= '9':
+		return int(d - '0')
+	case 'a' <= d && d <= 'f':
+		return int(d-'a') + 10
+	case 'A' <= d && d <= 'F':
+		return int(d-'A') + 10
+	}
+	return 16
+}
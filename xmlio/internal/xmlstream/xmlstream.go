@@ -0,0 +1,111 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+// Package xmlstream holds the token-level helpers the codecs' streaming decoders
+// share.
+//
+// The codecs used to decode with a single xml.Unmarshal of the whole document,
+// which needs the whole document in memory, plus every <tilerow> as a string in
+// the schema structs, plus the Map_t built from them. A streaming decoder walks
+// the token stream instead: the small elements are still decoded with
+// DecodeElement into the same schema structs, so their struct tags keep the
+// meaning they had under Unmarshal, and only <tiles> is walked by hand so that
+// each <tilerow> can be parsed into the model and dropped before the next one is
+// read.
+//
+// Nothing here knows a schema. The helpers reproduce encoding/xml's own rules
+// (attribute binding, the ",chardata" concatenation) for a start element the
+// caller has already consumed, which is what DecodeElement cannot do without
+// also consuming the element's children.
+package xmlstream
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Root returns the document's root start element, skipping the prolog (XML
+// declaration, comments, directives, and whitespace) the way xml.Unmarshal
+// does. A document with no root element is io.ErrUnexpectedEOF.
+func Root(d *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return xml.StartElement{}, io.ErrUnexpectedEOF
+		} else if err != nil {
+			return xml.StartElement{}, err
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			return se, nil
+		}
+	}
+}
+
+// ExpectRoot returns an error shaped like the one xml.Unmarshal returns when the
+// root element is not the one a schema's XMLName names.
+func ExpectRoot(se xml.StartElement, name string) error {
+	if se.Name.Local != name {
+		return xml.UnmarshalError(fmt.Sprintf("expected element type <%s> but have <%s>", name, se.Name.Local))
+	}
+	return nil
+}
+
+// DecodeAttrs binds the attributes of start to v using v's ",attr" struct tags,
+// without reading anything from the stream start came from.
+//
+// It replays start, immediately closed, through a decoder of its own, so the
+// attribute rules (names, type conversion, the errors for a malformed number)
+// are exactly encoding/xml's and not a re-implementation of them. Element fields
+// in v are left untouched because the replayed element has no children.
+func DecodeAttrs(start xml.StartElement, v any) error {
+	d := xml.NewTokenDecoder(&tokens{list: []xml.Token{start, start.End()}})
+	return d.Decode(v)
+}
+
+// CharData reads the rest of the element whose start tag was just consumed and
+// returns its character data, through the matching end tag.
+//
+// It is the ",chardata" rule: text and CDATA sections directly inside the
+// element are concatenated, and the text of any nested element is skipped.
+func CharData(d *xml.Decoder) ([]byte, error) {
+	var text []byte
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text = append(text, t...)
+		case xml.StartElement:
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			return text, nil
+		}
+	}
+}
+
+// StartsWithCDATA reports whether the character data of an element whose inner
+// XML is innerXML begins, after any whitespace, with a CDATA section. encoding/xml
+// gives a CDATA section back as plain character data, so a schema struct that
+// needs to know keeps its element's ",innerxml" as well.
+func StartsWithCDATA(innerXML string) bool {
+	return strings.HasPrefix(strings.TrimLeft(innerXML, " \t\r\n"), "<![CDATA[")
+}
+
+// tokens is an xml.TokenReader over a fixed list of tokens.
+type tokens struct {
+	list []xml.Token
+}
+
+func (t *tokens) Token() (xml.Token, error) {
+	if len(t.list) == 0 {
+		return nil, io.EOF
+	}
+	tok := t.list[0]
+	t.list = t.list[1:]
+	return tok, nil
+}
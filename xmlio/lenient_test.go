@@ -0,0 +1,128 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// decodeLenient decodes data with WithLenientDecode and returns the map and the
+// warnings, after checking that the default strict decode rejects the same
+// input with want.
+func decodeLenient(t *testing.T, data []byte, want error) (*wxx.Map_t, []xmlio.DecodeWarning) {
+	t.Helper()
+	if _, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(bytes.NewReader(data)); !errors.Is(err, want) {
+		t.Fatalf("strict decode error = %v, want it to wrap %v", err, want)
+	}
+	var d xmlio.DecoderDiagnostics
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithLenientDecode(), xmlio.WithDecoderDiagnostics(&d)).Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("lenient decode: %v", err)
+	}
+	return m, d.Warnings
+}
+
+// assertWarnings compares the warnings' locations, values, and actions, in order.
+func assertWarnings(t *testing.T, got []xmlio.DecodeWarning, want []xmlio.DecodeWarning) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d warnings, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Path != w.Path || g.Line != w.Line || g.Field != w.Field || g.Value != w.Value || g.Action != w.Action {
+			t.Errorf("warning %d = %v, want %v", i, g, w)
+		}
+		if g.Err == nil {
+			t.Errorf("warning %d has no cause", i)
+		}
+	}
+}
+
+// TestLenientDecodeRepairsW2025 corrupts a tile resource, a compressed-resource
+// sentinel, and the color of a feature's label in the populated W2025 fixture.
+func TestLenientDecodeRepairsW2025(t *testing.T) {
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	lines := strings.Split(string(raw), "\n")
+	lines[31] = "0\t1\t0\t0\t0\t101\t0\t0\t0\t0\t0" // tilerow 2, line 2: brick out of range
+	lines[32] = "0\t1\t0\t0\t0\tQ"                  // tilerow 2, line 3: not Z
+	// the label of the City feature
+	lines[191] = strings.Replace(lines[191], `color="0.0,0.0,0.0,1.0"`, `color="black"`, 1)
+
+	m, warnings := decodeLenient(t, []byte(strings.Join(lines, "\n")), wxx.ErrInvalidTileValue)
+	assertWarnings(t, warnings, []xmlio.DecodeWarning{
+		{Path: "map/tiles/tilerow[2]", Line: 2, Field: "brick", Value: "101", Action: "set to 100"},
+		{Path: "map/tiles/tilerow[2]", Line: 3, Field: "sentinel", Value: "Q", Action: "read as Z"},
+		{Path: "map/features", Field: "feature.label.color", Value: "black", Action: "dropped"},
+	})
+	if got := m.Tiles.Tile(1, 1).Resources.Brick; got != 100 {
+		t.Errorf("Tiles[1][1].Resources.Brick = %d, want 100", got)
+	}
+	if got := m.Features[0].Label.Color; got != nil {
+		t.Errorf("Features[0].Label.Color = %v, want nil", got)
+	}
+	if !errors.Is(warnings[2].Err, wxx.ErrInvalidRGBA) {
+		t.Errorf("label warning cause = %v, want it to wrap %v", warnings[2].Err, wxx.ErrInvalidRGBA)
+	}
+}
+
+// TestLenientDecodeRepairsClassic does the same for the classic codec, and
+// checks that a bad map key color is reported once, not once per tilerow.
+func TestLenientDecodeRepairsClassic(t *testing.T) {
+	text := string(unwrapWXX(t, classicFixture))
+	for _, edit := range [][2]string{
+		{`hexOrientation="COLUMNS"`, `hexOrientation="SIDEWAYS"`},
+		{"<tilerow>\n0\t1\t0\t0\t0\tZ", "<tilerow>\nx\t1\t0\t0\t0\tZ"},
+		{`backgroundcolor="0.9803921580314636,`, `backgroundcolor="beige,`},
+	} {
+		if !strings.Contains(text, edit[0]) {
+			t.Fatalf("%s: no %q to corrupt", classicFixture, edit[0])
+		}
+		text = strings.Replace(text, edit[0], edit[1], 1)
+	}
+
+	m, warnings := decodeLenient(t, []byte(text), wxx.ErrInvalidHexOrientation)
+	assertWarnings(t, warnings, []xmlio.DecodeWarning{
+		{Path: "map", Field: "hexOrientation", Value: "SIDEWAYS", Action: "set to COLUMNS"},
+		{Path: "map/tiles/tilerow[1]", Line: 1, Field: "terrainType", Value: "x", Action: "set to 0"},
+		{Path: "map/mapkey", Field: "mapkey.backgroundcolor", Value: "beige,0.9215686321258545,0.843137264251709,1.0", Action: "dropped"},
+	})
+	if m.HexOrientation != "COLUMNS" {
+		t.Errorf("HexOrientation = %q, want COLUMNS", m.HexOrientation)
+	}
+	if m.MapKey.BackgroundColor != nil {
+		t.Errorf("MapKey.BackgroundColor = %v, want nil", m.MapKey.BackgroundColor)
+	}
+}
+
+// TestLenientDecodeOfCleanMap asserts that lenience changes nothing about a map
+// with nothing to repair.
+func TestLenientDecodeOfCleanMap(t *testing.T) {
+	for _, fixture := range []string{classicFixture, sample2025_206} {
+		want, err := decodeFile(t, fixture)
+		if err != nil {
+			t.Fatalf("decode %s: %v", fixture, err)
+		}
+		var d xmlio.DecoderDiagnostics
+		got, err := xmlio.ReadFile(fixture, xmlio.WithLenientDecode(), xmlio.WithDecoderDiagnostics(&d))
+		if err != nil {
+			t.Fatalf("lenient ReadFile(%s): %v", fixture, err)
+		}
+		if len(d.Warnings) != 0 {
+			t.Errorf("%s: warnings = %v, want none", fixture, d.Warnings)
+		}
+		normalizeVolatile(want)
+		normalizeVolatile(got)
+		assertSameMap(t, want, got)
+	}
+}
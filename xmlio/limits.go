@@ -0,0 +1,332 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/maloquacious/wxx"
+)
+
+// The limits WithDefaultLimits sets. They are well above any map Worldographer
+// makes -- a 1000x1000 map is a million tiles, and its file a few megabytes --
+// and well below what it takes to exhaust a server's memory.
+//
+// DefaultMaxTiles is budgeted in bytes. A tile is a 24-byte cell in the grid,
+// allocated for the size the file states, and a 128-byte wxx.Tile_t while the
+// line that holds it is parsed, so 4 million tiles are at most 580 MiB.
+const (
+	DefaultMaxCompressedSize   = 256 << 20  // 256 MiB read from the input
+	DefaultMaxDecompressedSize = 1 << 30    // 1 GiB of XML, before UTF-16 is converted
+	DefaultMaxTiles            = 4_000_000  // tilesWide x tilesHigh; see below
+	DefaultMaxElements         = 10_000_000 // elements in the document
+	DefaultMaxDepth            = 64         // elements open at once; a map nests about six
+)
+
+// WithMaxCompressedSize fails a decode that reads more than n bytes from its
+// input with wxx.ErrCompressedTooLarge. It counts the input as it is read, so it
+// bounds a file that is not compressed as well.
+func WithMaxCompressedSize(n int64) DecoderOption {
+	return func(o *decoderOpts) {
+		o.maxCompressedSize = n
+	}
+}
+
+// WithMaxDecompressedSize fails a decode whose input, once gunzipped, is more
+// than n bytes with wxx.ErrDecompressedTooLarge. This is the limit that stops a
+// decompression bomb: a few kilobytes of gzip can hold gigabytes of XML.
+func WithMaxDecompressedSize(n int64) DecoderOption {
+	return func(o *decoderOpts) {
+		o.maxDecompressedSize = n
+	}
+}
+
+// WithMaxTiles fails a decode of a map with more than n tiles with
+// wxx.ErrTooManyTiles. The tilesWide x tilesHigh the file states is checked
+// before any tile is allocated, and so are rows past tilesWide.
+func WithMaxTiles(n int) DecoderOption {
+	return func(o *decoderOpts) {
+		o.maxTiles = n
+	}
+}
+
+// WithMaxElements fails a decode of a document with more than n elements with
+// wxx.ErrTooManyElements.
+func WithMaxElements(n int) DecoderOption {
+	return func(o *decoderOpts) {
+		o.maxElements = n
+	}
+}
+
+// WithMaxDepth fails a decode of a document that nests elements more than n
+// deep with wxx.ErrNestingTooDeep. <map> is at depth 1.
+func WithMaxDepth(n int) DecoderOption {
+	return func(o *decoderOpts) {
+		o.maxDepth = n
+	}
+}
+
+// WithDefaultLimits sets every decode limit to its Default value, which is the
+// set to use on a file from someone else. ReadFile uses it by default. A limit
+// set by a later option replaces the default, and 0 removes it.
+//
+// A Decoder has no limits otherwise: it reads whatever it is given, and a
+// crafted file can make it allocate as much memory as the file claims to need.
+func WithDefaultLimits() DecoderOption {
+	return func(o *decoderOpts) {
+		o.maxCompressedSize = DefaultMaxCompressedSize
+		o.maxDecompressedSize = DefaultMaxDecompressedSize
+		o.maxTiles = DefaultMaxTiles
+		o.maxElements = DefaultMaxElements
+		o.maxDepth = DefaultMaxDepth
+	}
+}
+
+// sizeLimiter fails with sentinel once more than max bytes have been read
+// through it.
+type sizeLimiter struct {
+	r        io.Reader
+	max      int64
+	read     int64
+	sentinel error
+}
+
+func (l *sizeLimiter) Read(p []byte) (int, error) {
+	if l.read > l.max {
+		return 0, l.exceeded()
+	}
+	// read no further than the byte that goes over
+	if left := l.max - l.read + 1; int64(len(p)) > left {
+		p = p[:left]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.max {
+		return n, l.exceeded()
+	}
+	return n, err
+}
+
+func (l *sizeLimiter) exceeded() error {
+	return fmt.Errorf("%w: more than %d bytes", l.sentinel, l.max)
+}
+
+// isLimit reports whether err is a decode limit being exceeded. A stage the
+// error passes up through does not tag it as a failure of its own.
+func isLimit(err error) bool {
+	return errors.Is(err, wxx.ErrCompressedTooLarge) || errors.Is(err, wxx.ErrDecompressedTooLarge) ||
+		errors.Is(err, wxx.ErrTooManyElements) || errors.Is(err, wxx.ErrNestingTooDeep)
+}
+
+// The states of elementLimiter's reading of the markup.
+const (
+	lexText        = iota
+	lexOpen        // after '<'
+	lexStartTag    // in a start tag, outside an attribute value
+	lexAttrValue   // in a quoted attribute value
+	lexEndTag      // in an end tag
+	lexBang        // after "<!"
+	lexBangDash    // after "<!-"
+	lexComment     // in a comment, looking for "-->"
+	lexCDATA       // in a CDATA section, looking for "]]>"
+	lexPI          // in a processing instruction, looking for "?>"
+	lexDirective   // in a directive such as <!DOCTYPE ...>
+	lexDirectiveLT // after '<' in a directive, looking for "!--"
+	lexDirectiveComment
+)
+
+// elementLimiter fails the stream with wxx.ErrTooManyElements or
+// wxx.ErrNestingTooDeep once the document it carries opens more elements, or
+// nests them deeper, than it allows. It counts as the bytes pass, so the parser
+// above it has built nothing from the elements past the limit.
+//
+// It reads only as much of the markup as it takes to know where elements start
+// and end: comments, CDATA sections, processing instructions, directives and
+// quoted attribute values are skipped the way encoding/xml skips them, so what
+// looks like a tag inside one is not counted. It does not check that the XML is
+// well formed; the parser does that.
+//
+// A decode that is traced counts elements with it as well, limits or no: with
+// record set it keeps the offset of each start tag it passes, for
+// sectionElements.
+type elementLimiter struct {
+	r                     io.Reader
+	maxElements, maxDepth int
+	elements, depth       int
+
+	record bool
+	offset int64   // the bytes scanned before the current read
+	starts []int64 // the offsets of the '<' of start tags not yet claimed
+
+	state int
+	quote byte // the quote the attribute value or directive string opened with
+	match int  // the bytes of the terminator or "!--" matched so far
+	slash bool // the start tag's last byte was '/'
+	nest  int  // the '<' open in a directive
+}
+
+func (l *elementLimiter) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	lerr := l.scan(p[:n])
+	l.offset += int64(n)
+	if lerr != nil {
+		return n, lerr
+	}
+	return n, err
+}
+
+// sectionElements returns the offset of the first start tag at or after start
+// and the number of start tags from start up to end, and forgets them and any
+// before them. end must not be past the bytes read so far, and each call must
+// start where the last one ended or later.
+func (l *elementLimiter) sectionElements(start, end int64) (first int64, n int) {
+	i := 0
+	for i < len(l.starts) && l.starts[i] < start {
+		i++
+	}
+	j := i
+	for j < len(l.starts) && l.starts[j] < end {
+		j++
+	}
+	first = start
+	if j > i {
+		first = l.starts[i]
+	}
+	l.starts = l.starts[j:]
+	return first, j - i
+}
+
+// scan moves the lexer over b, and returns an error at the element that
+// exceeds a limit.
+func (l *elementLimiter) scan(b []byte) error {
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch l.state {
+		case lexText:
+			j := bytes.IndexByte(b[i:], '<')
+			if j == -1 {
+				return nil
+			}
+			i += j
+			l.state = lexOpen
+		case lexOpen:
+			switch c {
+			case '/':
+				l.depth--
+				l.state = lexEndTag
+			case '!':
+				l.state = lexBang
+			case '?':
+				l.state, l.match = lexPI, 0
+			default:
+				l.elements++
+				l.depth++
+				if l.record {
+					l.starts = append(l.starts, l.offset+int64(i)-1)
+				}
+				if l.maxElements > 0 && l.elements > l.maxElements {
+					return fmt.Errorf("%w: more than %d", wxx.ErrTooManyElements, l.maxElements)
+				}
+				if l.maxDepth > 0 && l.depth > l.maxDepth {
+					return fmt.Errorf("%w: more than %d deep", wxx.ErrNestingTooDeep, l.maxDepth)
+				}
+				l.state, l.slash = lexStartTag, false
+			}
+		case lexStartTag:
+			switch c {
+			case '"', '\'':
+				l.state, l.quote = lexAttrValue, c
+			case '>':
+				if l.slash {
+					l.depth--
+				}
+				l.state = lexText
+			}
+			l.slash = c == '/'
+		case lexAttrValue:
+			if c == l.quote {
+				l.state = lexStartTag
+			}
+		case lexEndTag:
+			if c == '>' {
+				l.state = lexText
+			}
+		case lexBang:
+			switch c {
+			case '-':
+				l.state = lexBangDash
+			case '[':
+				l.state, l.match = lexCDATA, 0
+			default:
+				l.state, l.quote, l.nest = lexDirective, 0, 0
+			}
+		case lexBangDash:
+			l.state, l.match = lexComment, 0
+		case lexComment:
+			if l.ends(c, "-->") {
+				l.state = lexText
+			}
+		case lexCDATA:
+			if l.ends(c, "]]>") {
+				l.state = lexText
+			}
+		case lexPI:
+			if l.ends(c, "?>") {
+				l.state = lexText
+			}
+		case lexDirective:
+			switch {
+			case l.quote == 0 && c == '>' && l.nest == 0:
+				l.state = lexText
+			case c == l.quote:
+				l.quote = 0
+			case l.quote != 0:
+			case c == '"' || c == '\'':
+				l.quote = c
+			case c == '>':
+				l.nest--
+			case c == '<':
+				l.state, l.match = lexDirectiveLT, 0
+			}
+		case lexDirectiveLT:
+			if c == "!--"[l.match] {
+				if l.match++; l.match == len("!--") {
+					l.state, l.match = lexDirectiveComment, 0
+				}
+				continue
+			}
+			// a '<' that does not open a comment nests; c is read again as
+			// part of the directive
+			l.nest++
+			l.state = lexDirective
+			i--
+		case lexDirectiveComment:
+			if l.ends(c, "-->") {
+				l.state = lexDirective
+			}
+		}
+	}
+	return nil
+}
+
+// ends moves the match of term along by c, and reports whether c completes it.
+func (l *elementLimiter) ends(c byte, term string) bool {
+	switch {
+	case c == term[l.match]:
+		l.match++
+	case c == term[0] && term[0] == term[1] && l.match == 2:
+		// "--->": the last two dashes still begin the terminator
+	case c == term[0]:
+		l.match = 1
+	default:
+		l.match = 0
+	}
+	if l.match == len(term) {
+		l.match = 0
+		return true
+	}
+	return false
+}
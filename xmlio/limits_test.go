@@ -0,0 +1,202 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// decodeWith decodes data, auto-detecting its container, with opts.
+func decodeWith(data []byte, opts ...xmlio.DecoderOption) (*wxx.Map_t, error) {
+	return xmlio.NewDecoder(append([]xmlio.DecoderOption{xmlio.WithAutoDetect()}, opts...)...).Decode(bytes.NewReader(data))
+}
+
+// countElements returns the number of elements in the XML doc and the deepest
+// they nest, as encoding/xml reads them.
+func countElements(t *testing.T, doc []byte) (elements, depth int) {
+	t.Helper()
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	for open := 0; ; {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return elements, depth
+		} else if err != nil {
+			t.Fatalf("count elements: %v", err)
+		}
+		switch tok.(type) {
+		case xml.StartElement:
+			elements, open = elements+1, open+1
+			depth = max(depth, open)
+		case xml.EndElement:
+			open--
+		}
+	}
+}
+
+// TestDefaultLimitsReadEveryFixture asserts that the defaults, which ReadFile
+// uses, are no obstacle to a real map.
+func TestDefaultLimitsReadEveryFixture(t *testing.T) {
+	for _, path := range []string{classicFixture, sample2025_206, populatedFixture} {
+		if _, err := xmlio.ReadFile(path); err != nil {
+			t.Errorf("ReadFile(%s): %v", path, err)
+		}
+	}
+}
+
+// TestSizeLimits asserts that the input and the gunzipped input are each
+// bounded, and that exceeding one is reported as that and not as a read or
+// gunzip failure.
+func TestSizeLimits(t *testing.T) {
+	data, err := os.ReadFile(sample2025_206)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeWith(data, xmlio.WithMaxCompressedSize(int64(len(data)))); err != nil {
+		t.Errorf("a limit of the file's size: %v", err)
+	}
+	_, err = decodeWith(data, xmlio.WithMaxCompressedSize(int64(len(data)-1)))
+	if !errors.Is(err, wxx.ErrCompressedTooLarge) || errors.Is(err, wxx.ErrRawReadFailed) {
+		t.Errorf("compressed limit: error = %v, want only %v", err, wxx.ErrCompressedTooLarge)
+	}
+	_, err = decodeWith(data, xmlio.WithMaxDecompressedSize(int64(len(data))))
+	if !errors.Is(err, wxx.ErrDecompressedTooLarge) || errors.Is(err, wxx.ErrGUnZipFailed) {
+		t.Errorf("decompressed limit: error = %v, want only %v", err, wxx.ErrDecompressedTooLarge)
+	}
+}
+
+// TestTileLimit asserts that the tiles a file states are checked before they
+// are allocated, and that rows past tilesWide count as well.
+func TestTileLimit(t *testing.T) {
+	data, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeWith(data, xmlio.WithMaxTiles(13*11)); err != nil {
+		t.Errorf("a limit of the map's 13 x 11 tiles: %v", err)
+	}
+	if _, err := decodeWith(data, xmlio.WithMaxTiles(13*11-1)); !errors.Is(err, wxx.ErrTooManyTiles) {
+		t.Errorf("a limit under the map's tiles: error = %v, want %v", err, wxx.ErrTooManyTiles)
+	}
+
+	// a handful of rows claiming two billion tiles apiece is refused outright
+	huge := bytes.Replace(data, []byte(`tilesHigh="11"`), []byte(`tilesHigh="2000000000"`), 1)
+	if _, err := decodeWith(huge, xmlio.WithDefaultLimits()); !errors.Is(err, wxx.ErrTooManyTiles) {
+		t.Errorf("tilesHigh 2000000000: error = %v, want %v", err, wxx.ErrTooManyTiles)
+	}
+
+	// 13 rows where the header states 10 add three columns the limit has to see
+	narrow := bytes.Replace(data, []byte(`tilesWide="13"`), []byte(`tilesWide="10"`), 1)
+	if _, err := decodeWith(narrow, xmlio.WithMaxTiles(10*11)); !errors.Is(err, wxx.ErrTooManyTiles) {
+		t.Errorf("rows past tilesWide: error = %v, want %v", err, wxx.ErrTooManyTiles)
+	}
+
+	// a negative size is never allocated, with or without limits
+	negative := bytes.Replace(data, []byte(`tilesHigh="11"`), []byte(`tilesHigh="-1"`), 1)
+	if _, err := decodeWith(negative); !errors.Is(err, wxx.ErrInvalidTileGrid) {
+		t.Errorf("tilesHigh -1: error = %v, want %v", err, wxx.ErrInvalidTileGrid)
+	}
+}
+
+// oneTileRow returns the populated fixture as a gzipped map of tilesHigh tiles
+// in one column, of which its single <tilerow> writes one.
+func oneTileRow(t *testing.T, tilesHigh int) []byte {
+	t.Helper()
+	data, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := string(bytes.Replace(data, []byte(`tilesWide="13" tilesHigh="11"`), []byte(fmt.Sprintf(`tilesWide="1" tilesHigh="%d"`, tilesHigh)), 1))
+	start := strings.Index(doc, "<tilerow>\n")
+	line := strings.Index(doc[start+len("<tilerow>\n"):], "\n") + start + len("<tilerow>\n") + 1
+	end := strings.Index(doc, "</tiles>")
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(doc[:line] + "</tilerow>\n" + doc[end:]))
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// TestTileLimitOneRow asserts that a tiny file stating a huge grid is refused
+// by the default limits, and that a row is allocated for the lines it holds
+// rather than for the tilesHigh the file states.
+func TestTileLimitOneRow(t *testing.T) {
+	if _, err := decodeWith(oneTileRow(t, 15_000_000), xmlio.WithDefaultLimits()); !errors.Is(err, wxx.ErrTooManyTiles) {
+		t.Errorf("1 x 15000000 tiles: error = %v, want %v", err, wxx.ErrTooManyTiles)
+	}
+
+	// the grid's million 24-byte cells are allocated; a million 128-byte
+	// tiles for the row must not be
+	const tilesHigh = 1_000_000
+	data := oneTileRow(t, tilesHigh)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	m, err := decodeWith(data, xmlio.WithDefaultLimits(), xmlio.WithDecoderConcurrency(1))
+	runtime.ReadMemStats(&after)
+	if err != nil {
+		t.Fatalf("1 x %d tiles: %v", tilesHigh, err)
+	}
+	if _, high := m.Tiles.Size(); high != tilesHigh {
+		t.Errorf("1 x %d tiles: the grid is %d high", tilesHigh, high)
+	}
+	if got, limit := after.TotalAlloc-before.TotalAlloc, uint64(tilesHigh*(24+24)); got > limit {
+		t.Errorf("1 x %d tiles: %d MiB allocated, want at most %d MiB", tilesHigh, got>>20, limit>>20)
+	}
+}
+
+// TestElementLimits asserts that the element count and depth limits are exact,
+// and that markup inside comments, CDATA sections and directives is not
+// counted, however the input is split into reads.
+func TestElementLimits(t *testing.T) {
+	data, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	elements, depth := countElements(t, data)
+
+	// fake markup the parser skips, after the <map> start tag
+	end := bytes.Index(data, []byte("<gridandnumbering"))
+	hidden := strings.Join([]string{
+		`<!-- <a><b/> -->`,
+		`<![CDATA[ <c></c> ]]]]>`,
+		`<?pi <d> ?>`,
+		`<!DOCTYPE x [ <!-- " --> <!ENTITY y "<e>"> ]>`,
+	}, "\n") + "\n"
+	masked := append(append(bytes.Clone(data[:end]), hidden...), data[end:]...)
+
+	for name, doc := range map[string][]byte{"fixture": data, "masked": masked} {
+		for _, split := range []bool{false, true} {
+			decode := func(opts ...xmlio.DecoderOption) error {
+				var r io.Reader = bytes.NewReader(doc)
+				if split {
+					r = iotest.OneByteReader(r)
+				}
+				_, err := xmlio.NewDecoder(append([]xmlio.DecoderOption{xmlio.WithAutoDetect()}, opts...)...).Decode(r)
+				return err
+			}
+			if err := decode(xmlio.WithMaxElements(elements), xmlio.WithMaxDepth(depth)); err != nil {
+				t.Errorf("%s, one byte at a time %v: limits of %d elements, %d deep: %v", name, split, elements, depth, err)
+			}
+			if err := decode(xmlio.WithMaxElements(elements - 1)); !errors.Is(err, wxx.ErrTooManyElements) {
+				t.Errorf("%s, one byte at a time %v: %d elements: error = %v, want %v", name, split, elements-1, err, wxx.ErrTooManyElements)
+			}
+			if err := decode(xmlio.WithMaxDepth(depth - 1)); !errors.Is(err, wxx.ErrNestingTooDeep) {
+				t.Errorf("%s, one byte at a time %v: %d deep: error = %v, want %v", name, split, depth-1, err, wxx.ErrNestingTooDeep)
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/hexg"
+	"github.com/maloquacious/wxx/xmlio/internal/xmlstream"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Summary is what Peek reads from the top of a map file: what wrote it and the
+// shape of its grid. Each field holds what a Decode of the same file would set
+// on the Map_t, named in its comment.
+type Summary struct {
+	// Version is the file's version identity (MetaData.Version).
+	Version wxx.Version_t
+
+	// Release and Schema are map/@release and map/@schema as the file states
+	// them (MetaData.Worldographer). A classic file states neither, and both are
+	// "". map/@version is Version.App.Raw.
+	Release string
+	Schema  string
+
+	HexOrientation  string             // "COLUMNS" or "ROWS" (HexOrientation)
+	GridOrientation hexg.Orientation_e // OddQ for COLUMNS, OddR for ROWS (GridOrientation)
+	Projection      wxx.Projection_e   // FLAT or ICOSAHEDRAL (MapProjection)
+	LastViewLevel   string             // map/@lastViewLevel (LastViewLevel)
+
+	// ViewLevel, TilesWide and TilesHigh are the attributes of <tiles> (Tiles).
+	// They are zero for a map without one.
+	ViewLevel string
+	TilesWide int
+	TilesHigh int
+}
+
+// Peek reads the identity and grid dimensions of the map r holds without
+// decoding it. It reads the <map> attributes, skips the elements ahead of
+// <tiles>, and stops at the <tiles> opening tag: no tile row, feature, label or
+// note is parsed, so a map of any size is summarized in about the time it takes
+// to read its first few kilobytes.
+//
+// The container is detected as WithAutoDetect detects it, and the map must be
+// one Decode would dispatch to a codec. An orientation or projection Decode
+// would reject is rejected here too. Peek stops reading part of the way
+// through r, so it does not verify a gzip checksum, and a fault after <tiles>
+// is not found.
+func Peek(r io.Reader) (Summary, error) {
+	br := bufio.NewReader(&stageReader{r: r, sentinel: wxx.ErrRawReadFailed})
+	var src io.Reader = br
+	magic, err := peek(br, 2)
+	if err != nil {
+		return Summary{}, err
+	}
+	if len(magic) >= 2 && magic[0] == 0x1F && magic[1] == 0x8B {
+		gzr, err := gzip.NewReader(br)
+		if err != nil {
+			return Summary{}, errors.Join(wxx.ErrGZipNewReaderFailed, err)
+		}
+		defer func() {
+			_ = gzr.Close() // ignore errors closing this reader
+		}()
+		src = &stageReader{r: gzr, sentinel: wxx.ErrGUnZipFailed}
+	}
+
+	br = bufio.NewReader(src)
+	src = br
+	head, err := peek(br, 4)
+	if err != nil {
+		return Summary{}, err
+	}
+	switch encoding, hasBOM := sniffEncoding(head); encoding {
+	case encodingUTF16BE, encodingUTF16LE:
+		endianness, bomPolicy := unicode.BigEndian, unicode.IgnoreBOM
+		if encoding == encodingUTF16LE {
+			endianness = unicode.LittleEndian
+		}
+		if hasBOM {
+			bomPolicy = unicode.ExpectBOM
+		}
+		src = &stageReader{r: transform.NewReader(src, unicode.UTF16(endianness, bomPolicy).NewDecoder()), sentinel: wxx.ErrInvalidUTF16}
+	default:
+		if hasBOM {
+			if _, err := br.Discard(len(utf8BOM)); err != nil {
+				return Summary{}, err
+			}
+		}
+	}
+
+	br = bufio.NewReaderSize(src, mapElementReadAhead)
+	heading, err := sniffXMLHeader(br)
+	if err != nil {
+		return Summary{}, err
+	}
+	s, err := peekMap(xml.NewDecoder(br))
+	if err != nil {
+		return Summary{}, asDecodeError(err, bytes.Count(heading, []byte("\n")))
+	}
+	return s, nil
+}
+
+// peekMap reads the <map> start tag and the elements after it from d, through
+// the <tiles> start tag, and returns what they say.
+func peekMap(d *xml.Decoder) (Summary, error) {
+	root, err := xmlstream.Root(d)
+	if err == nil {
+		err = xmlstream.ExpectRoot(root, "map")
+	}
+	if err != nil {
+		line, column := d.InputPos()
+		return Summary{}, xmlstream.Locate(err, "", line, column)
+	}
+	at := xmlstream.InputPos(d)
+	var attrs struct {
+		Release        string `xml:"release,attr"`
+		Version        string `xml:"version,attr"`
+		Schema         string `xml:"schema,attr"`
+		LastViewLevel  string `xml:"lastViewLevel,attr"`
+		HexOrientation string `xml:"hexOrientation,attr"`
+		MapProjection  string `xml:"mapProjection,attr"`
+	}
+	if err := xmlstream.DecodeAttrs(root, &attrs); err != nil {
+		return Summary{}, at.Locate(err, "map")
+	}
+	s := Summary{
+		Release:        attrs.Release,
+		Schema:         attrs.Schema,
+		HexOrientation: attrs.HexOrientation,
+		LastViewLevel:  attrs.LastViewLevel,
+	}
+
+	// the identity is checked the way Decode checks it to pick a codec
+	switch attrs.Release {
+	case "2025":
+		if _, _, err := decoderFor(attrs.Schema); err != nil {
+			return Summary{}, errors.Join(err, fmt.Errorf("map: release %q: version %q", attrs.Release, attrs.Version))
+		}
+		schema, _ := wxx.ParseDotted(attrs.Schema) // decoderFor has parsed it
+		s.Version = wxx.Version_t{App: dottedOrRaw(attrs.Version), Schema: &schema}
+	case "":
+		if strings.HasPrefix(attrs.Version, "1.") {
+			s.Version = wxx.Version_t{App: dottedOrRaw(attrs.Version)}
+		}
+	}
+	if s.Version.App.Raw == "" {
+		return Summary{}, errors.Join(wxx.ErrUnsupportedMapMetadata, fmt.Errorf("map: release %q: version %q: schema %q", attrs.Release, attrs.Version, attrs.Schema))
+	}
+
+	switch attrs.HexOrientation {
+	case "COLUMNS":
+		s.GridOrientation = hexg.OddQ
+	case "ROWS":
+		s.GridOrientation = hexg.OddR
+	default:
+		err := &xmlstream.Error{Field: "hexOrientation", Err: fmt.Errorf("%w: %q: unknown orientation", wxx.ErrInvalidHexOrientation, attrs.HexOrientation)}
+		return Summary{}, at.Locate(err, "map")
+	}
+	switch attrs.MapProjection {
+	case "FLAT":
+		s.Projection = wxx.FLAT
+	case "ICOSAHEDRAL":
+		s.Projection = wxx.ICOSAHEDRAL
+	default:
+		err := &xmlstream.Error{Field: "mapProjection", Err: fmt.Errorf("%w: %q: unknown projection", wxx.ErrInvalidMapProjection, attrs.MapProjection)}
+		return Summary{}, at.Locate(err, "map")
+	}
+
+	// skip the children of <map> up to <tiles>; a map without one ends first
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return Summary{}, xmlstream.InputPos(d).Locate(err, "map")
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "tiles" {
+				if err := d.Skip(); err != nil {
+					return Summary{}, xmlstream.InputPos(d).Locate(err, "map/"+t.Name.Local)
+				}
+				continue
+			}
+			var tiles struct {
+				ViewLevel string `xml:"viewLevel,attr"`
+				TilesWide int    `xml:"tilesWide,attr"`
+				TilesHigh int    `xml:"tilesHigh,attr"`
+			}
+			if err := xmlstream.DecodeAttrs(t, &tiles); err != nil {
+				return Summary{}, xmlstream.InputPos(d).Locate(err, "map/tiles")
+			}
+			s.ViewLevel, s.TilesWide, s.TilesHigh = tiles.ViewLevel, tiles.TilesWide, tiles.TilesHigh
+			return s, nil
+		case xml.EndElement:
+			return s, nil
+		}
+	}
+}
+
+// dottedOrRaw parses an on-disk dotted version, or keeps its bytes in Raw with
+// zero components when it does not parse, as the codecs record map/@version.
+func dottedOrRaw(s string) wxx.Dotted {
+	d, err := wxx.ParseDotted(s)
+	if err != nil {
+		return wxx.Dotted{Raw: s}
+	}
+	return d
+}
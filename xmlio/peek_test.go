@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// TestPeekMatchesDecode asserts that Peek reports, for every fixture, what a
+// full decode of it sets on the Map_t.
+func TestPeekMatchesDecode(t *testing.T) {
+	paths, err := filepath.Glob(classicInputDir + "*.wxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range append(paths, populatedFixture) {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			m := loadMap(t, path)
+			fp, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer fp.Close()
+			got, err := xmlio.Peek(fp)
+			if err != nil {
+				t.Fatalf("Peek: %v", err)
+			}
+			want := xmlio.Summary{
+				Version:         m.MetaData.Version,
+				Release:         m.MetaData.Worldographer.Release,
+				Schema:          m.MetaData.Worldographer.Schema,
+				HexOrientation:  m.HexOrientation,
+				GridOrientation: m.GridOrientation,
+				Projection:      m.MapProjection,
+				LastViewLevel:   m.LastViewLevel,
+				ViewLevel:       m.Tiles.ViewLevel,
+				TilesWide:       m.Tiles.TilesWide,
+				TilesHigh:       m.Tiles.TilesHigh,
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Peek = %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestPeekDetectsContainers peeks at every container variant of a fixture.
+func TestPeekDetectsContainers(t *testing.T) {
+	for _, tc := range containerVariants(t, sample2025_206) {
+		s, err := xmlio.Peek(bytes.NewReader(tc.data))
+		if err != nil {
+			t.Errorf("%s: Peek: %v", tc.name, err)
+			continue
+		}
+		if s.Version.App.Raw != "2.06" || s.TilesWide != 13 || s.TilesHigh != 11 {
+			t.Errorf("%s: Peek = app %q, %d x %d, want app 2.06, 13 x 11", tc.name, s.Version.App.Raw, s.TilesWide, s.TilesHigh)
+		}
+	}
+}
+
+// TestPeekStopsAtTiles asserts that Peek reads nothing past the <tiles> start
+// tag: a document cut off right after it, which Decode rejects, is summarized.
+func TestPeekStopsAtTiles(t *testing.T) {
+	data, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := bytes.Index(data, []byte("<tiles "))
+	end := start + bytes.IndexByte(data[start:], '>') + 1
+	cut := data[:end]
+	if _, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(bytes.NewReader(cut)); err == nil {
+		t.Fatal("Decode accepted a document cut off after <tiles>")
+	}
+	s, err := xmlio.Peek(bytes.NewReader(cut))
+	if err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+	if s.ViewLevel != "WORLD" || s.TilesWide != 13 || s.TilesHigh != 11 {
+		t.Errorf("Peek = %q, %d x %d, want WORLD, 13 x 11", s.ViewLevel, s.TilesWide, s.TilesHigh)
+	}
+}
+
+// TestPeekRejects asserts that Peek fails, with the sentinel Decode uses, on a
+// map Decode would not read.
+func TestPeekRejects(t *testing.T) {
+	data, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		old, new string
+		want     error
+	}{
+		{"release", `release="2025"`, `release="2031"`, wxx.ErrUnsupportedMapMetadata},
+		{"orientation", `hexOrientation="COLUMNS"`, `hexOrientation="DIAGONAL"`, wxx.ErrInvalidHexOrientation},
+		{"projection", `mapProjection="FLAT"`, `mapProjection="ROUND"`, wxx.ErrInvalidMapProjection},
+	} {
+		bad := strings.Replace(string(data), tc.old, tc.new, 1)
+		if _, err := xmlio.Peek(strings.NewReader(bad)); !errors.Is(err, tc.want) {
+			t.Errorf("%s: Peek error = %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,517 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/maloquacious/wxx"
+)
+
+// This file implements WithPreserveFormatting. It works on the XML text rather
+// than in the codecs: the decoded map is encoded once more, straight away, and
+// that canonical document is set against the file token by token. Where the two
+// spell a token differently, both spellings are kept on the map (wxx.Form_t), and
+// an encode that produces the canonical spelling again writes the file's instead.
+//
+// Comparing the encoder's output with itself, rather than comparing the model
+// with a copy of itself, is what makes "unchanged" safe to decide: the file's
+// token is only written where the encoder, given the map as it is now, writes
+// exactly what it wrote for the map as it was read. It also means the codecs know
+// nothing about any of it.
+
+// pieceKind is what an XML token is, for the key of its form.
+type pieceKind byte
+
+const (
+	pieceStart pieceKind = '<' // a start tag, or a whole empty-element tag
+	pieceEnd   pieceKind = '>' // an end tag; empty after an empty-element tag
+	pieceText  pieceKind = '#' // character data, CDATA, comments, and the like
+)
+
+// piece is one token of a document, as the document spells it.
+type piece struct {
+	key  string // element path and kind, "map[1]/tiles[1]/tilerow[2]<"
+	kind pieceKind
+	name string // element name, for tags
+	raw  string
+	end  int // index of the matching end tag, for start tags
+
+	// parent is the index of the start tag of the element the piece is in, -1
+	// outside the root
+	parent int
+}
+
+// splitPieces cuts doc into its tokens. Every byte of doc is in exactly one
+// piece, so joining the raw text of the pieces gives doc back.
+//
+// Keys number every element among its same-named siblings, and every run of
+// text tokens from the tag before it, so a token's key is the same in any two
+// documents that agree on the structure around it.
+func splitPieces(doc []byte) ([]piece, error) {
+	type frame struct {
+		path  string
+		names map[string]int
+		start int // index of the start tag
+	}
+	stack := []*frame{{names: map[string]int{}, start: -1}}
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	var pieces []piece
+	// tag is the key of the last tag, and texts counts the text tokens since
+	tag, texts := "", 0
+	for start := int64(0); ; {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		end := d.InputOffset()
+		raw := string(doc[start:end])
+		start = end
+
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			parent := top.start
+			top.names[t.Name.Local]++
+			path := fmt.Sprintf("%s/%s[%d]", top.path, t.Name.Local, top.names[t.Name.Local])
+			stack = append(stack, &frame{path: path, names: map[string]int{}, start: len(pieces)})
+			tag, texts = path[1:]+string(pieceStart), 0
+			pieces = append(pieces, piece{key: tag, kind: pieceStart, name: t.Name.Local, raw: raw, parent: parent})
+		case xml.EndElement:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("%w: unexpected </%s>", wxx.ErrInvalidXML, t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+			tag, texts = top.path[1:]+string(pieceEnd), 0
+			pieces[top.start].end = len(pieces)
+			pieces = append(pieces, piece{key: tag, kind: pieceEnd, name: t.Name.Local, raw: raw, parent: pieces[top.start].parent})
+		default:
+			texts++
+			pieces = append(pieces, piece{key: textKey(tag, texts), kind: pieceText, raw: raw, parent: top.start})
+		}
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("%w: <%s> is not closed", wxx.ErrInvalidXML, stack[len(stack)-1].path[1:])
+	}
+	return pieces, nil
+}
+
+// span returns the text of the element whose start tag is pieces[i], tags
+// included.
+func span(pieces []piece, i int) string {
+	var sb strings.Builder
+	for _, p := range pieces[i : pieces[i].end+1] {
+		sb.WriteString(p.raw)
+	}
+	return sb.String()
+}
+
+// elementKey is the key of the form for a whole element whose start tag's key
+// is start.
+func elementKey(start string) string {
+	return strings.TrimSuffix(start, string(pieceStart)) + "{"
+}
+
+// textKey is the key of the n'th text token after the tag whose key is tag.
+func textKey(tag string, n int) string {
+	return fmt.Sprintf("%s%c%d", tag, pieceText, n)
+}
+
+// recordFormatting returns the forms for a file whose XML is original and whose
+// decoded map the encoder writes as canonical. Neither document has an XML
+// declaration.
+//
+// Most forms are for a single token. A token of original that canonical has no
+// counterpart for is recorded only when it is whitespace between tags, which
+// the encoder lays out differently: applyFormatting puts it back, and takes out
+// whitespace the encoder writes that the file did not have. Anything else
+// missing is content the encoder drops, and the map no longer holds it to be
+// written.
+//
+// Text is only recorded in elements both documents give the same children.
+// Where the encoder drops a child, the file's text around it does not add up to
+// the element's character data, which the map holds whole and the encoder
+// writes in one piece; writing the file's pieces would lose what was between.
+//
+// Where tokens alone cannot give the file back -- an element whose character
+// data the file splits around its children and the encoder writes in one piece
+// -- the whole element is recorded, provided both documents agree on which
+// children it has. The root is never recorded whole; its text is whitespace,
+// and a form for it would hold the map twice.
+func recordFormatting(original, canonical []byte) (map[string]wxx.Form_t, error) {
+	op, err := splitPieces(original)
+	if err != nil {
+		return nil, err
+	}
+	cp, err := splitPieces(canonical)
+	if err != nil {
+		return nil, err
+	}
+	canon, orig := indexPieces(cp), indexPieces(op)
+	// agreed reports whether the element whose start tag has the key start has
+	// the same children in both documents; "" is outside the root
+	agreed := func(start string) bool {
+		i, ok1 := orig[start]
+		c, ok2 := canon[start]
+		return start == "" || (ok1 && ok2 && sameChildren(op, i, cp, c))
+	}
+	parentKey := func(pieces []piece, p piece) string {
+		if p.parent == -1 {
+			return ""
+		}
+		return pieces[p.parent].key
+	}
+	forms := map[string]wxx.Form_t{}
+	for _, p := range op {
+		if p.kind == pieceText && !agreed(parentKey(op, p)) {
+			continue
+		}
+		if i, ok := canon[p.key]; ok && cp[i].raw != p.raw {
+			forms[p.key] = wxx.Form_t{Canonical: cp[i].raw, Original: p.raw}
+		} else if !ok && p.kind == pieceText && strings.TrimSpace(p.raw) == "" {
+			forms[p.key] = wxx.Form_t{Original: p.raw}
+		}
+	}
+	for _, p := range cp {
+		if _, ok := orig[p.key]; !ok && p.kind == pieceText && strings.TrimSpace(p.raw) == "" && agreed(parentKey(cp, p)) {
+			forms[p.key] = wxx.Form_t{Canonical: p.raw}
+		}
+	}
+
+	// each pass records the innermost elements that still come out differently;
+	// their parents are looked at again once they are right
+	for {
+		rebuilt, err := applyFormatting(canonical, forms)
+		if err != nil {
+			return nil, err
+		} else if bytes.Equal(rebuilt, original) {
+			return forms, nil
+		}
+		rp, err := splitPieces(rebuilt)
+		if err != nil {
+			return nil, err
+		}
+		again := indexPieces(rp)
+		recorded := false
+		for i, p := range op {
+			if p.kind != pieceStart || !strings.Contains(p.key, "/") {
+				continue
+			}
+			r, ok := again[p.key]
+			if !ok || span(rp, r) == span(op, i) || !sameChildren(op, i, rp, r) {
+				continue
+			}
+			if c, ok := canon[p.key]; ok && differingChildren(op, i, rp, again) == 0 {
+				forms[elementKey(p.key)] = wxx.Form_t{Canonical: span(cp, c), Original: span(op, i)}
+				recorded = true
+			}
+		}
+		if !recorded {
+			return forms, nil
+		}
+	}
+}
+
+// indexPieces maps each key in pieces to the index of its piece.
+func indexPieces(pieces []piece) map[string]int {
+	index := make(map[string]int, len(pieces))
+	for i, p := range pieces {
+		index[p.key] = i
+	}
+	return index
+}
+
+// children returns the indexes of the start tags of the children of the
+// element whose start tag is pieces[i].
+func children(pieces []piece, i int) []int {
+	var kids []int
+	for j := i + 1; j < pieces[i].end; j++ {
+		if pieces[j].kind == pieceStart {
+			kids = append(kids, j)
+			j = pieces[j].end
+		}
+	}
+	return kids
+}
+
+// sameChildren reports whether the elements at a[i] and b[j] have the same
+// children, by key.
+func sameChildren(a []piece, i int, b []piece, j int) bool {
+	ka, kb := children(a, i), children(b, j)
+	if len(ka) != len(kb) {
+		return false
+	}
+	for n := range ka {
+		if a[ka[n]].key != b[kb[n]].key {
+			return false
+		}
+	}
+	return true
+}
+
+// differingChildren counts the children of the element at a[i] whose text is
+// not the same in b, where index locates them.
+func differingChildren(a []piece, i int, b []piece, index map[string]int) int {
+	n := 0
+	for _, k := range children(a, i) {
+		if j, ok := index[a[k].key]; !ok || span(a, k) != span(b, j) {
+			n++
+		}
+	}
+	return n
+}
+
+// dropRepaired removes the forms inside each element a lenient decode repaired
+// a value in. The file's spelling of a repaired value is the bad value, and
+// writing it back would undo the repair.
+func dropRepaired(forms map[string]wxx.Form_t, warnings []DecodeWarning) {
+	for _, w := range warnings {
+		// warning paths number only the elements that repeat; keys number all
+		var sb strings.Builder
+		for i, segment := range strings.Split(w.Path, "/") {
+			if i > 0 {
+				sb.WriteByte('/')
+			}
+			sb.WriteString(segment)
+			if !strings.HasSuffix(segment, "]") {
+				sb.WriteString("[1]")
+			}
+		}
+		// the prefix must end where the element's name does: tilerow[2] is not
+		// tilerow[20]
+		prefix := sb.String()
+		for key := range forms {
+			if strings.HasPrefix(key, prefix) && strings.ContainsRune("<>/{", rune(key[len(prefix)])) {
+				delete(forms, key)
+			}
+		}
+	}
+}
+
+// applyFormatting rewrites doc, the encoder's XML for a map, with the file's
+// spelling of every token the map has not changed. A start tag or text token
+// that did change keeps the file's spelling of the attributes, lines, and
+// tab-separated fields within it that did not.
+func applyFormatting(doc []byte, forms map[string]wxx.Form_t) ([]byte, error) {
+	pieces, err := splitPieces(doc)
+	if err != nil {
+		return nil, err
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(doc)))
+	// selfClosed records, for each open element, whether its start tag was
+	// written as an empty-element tag, which its end tag must then agree with
+	var selfClosed []bool
+	// tag is the key of the last tag written, and texts counts the text tokens
+	// written since; insert writes the whitespace the file had after them
+	tag, texts := "", 0
+	insert := func() {
+		for n := texts + 1; ; n++ {
+			f, ok := forms[textKey(tag, n)]
+			if !ok || f.Canonical != "" {
+				return
+			}
+			out.WriteString(f.Original)
+		}
+	}
+	for i := 0; i < len(pieces); i++ {
+		p := pieces[i]
+		if p.kind == pieceText {
+			texts++
+		} else {
+			insert()
+			tag, texts = p.key, 0
+		}
+		if p.kind == pieceStart {
+			if f, ok := forms[elementKey(p.key)]; ok && span(pieces, i) == f.Canonical {
+				out.WriteString(f.Original)
+				i = p.end
+				tag = pieces[i].key
+				continue
+			}
+		}
+		s := p.raw
+		f, ok := forms[p.key]
+		if ok && s == f.Canonical {
+			s = f.Original
+		} else if ok {
+			switch p.kind {
+			case pieceStart:
+				s = mergeTag(f, s)
+			case pieceText:
+				s = mergeText(f, s)
+			}
+		}
+		switch p.kind {
+		case pieceStart:
+			empty := strings.HasSuffix(s, "/>")
+			if empty && !(i+1 < len(pieces) && pieces[i+1].kind == pieceEnd) {
+				// the file's element was empty and this one is not
+				s, empty = p.raw, strings.HasSuffix(p.raw, "/>")
+			}
+			selfClosed = append(selfClosed, empty)
+		case pieceEnd:
+			empty := selfClosed[len(selfClosed)-1]
+			selfClosed = selfClosed[:len(selfClosed)-1]
+			if empty {
+				s = ""
+			} else if s == "" {
+				s = "</" + p.name + ">"
+			}
+		}
+		out.WriteString(s)
+	}
+	insert()
+	return out.Bytes(), nil
+}
+
+// rawAttr is one attribute of a start tag as the tag spells it.
+type rawAttr struct {
+	lead  string // the whitespace before it
+	name  string
+	value string // between the quotes, entities unexpanded
+	text  string // name, equals sign, and quoted value
+}
+
+// parseTag splits a start tag into its name, its attributes, and its tail, the
+// whitespace and ">" or "/>" after the last attribute. ok is false for text that
+// is not a start tag this can take apart.
+func parseTag(tag string) (name string, attrs []rawAttr, tail string, ok bool) {
+	if !strings.HasPrefix(tag, "<") {
+		return "", nil, "", false
+	}
+	isSpace := func(c byte) bool { return c == ' ' || c == '\t' || c == '\r' || c == '\n' }
+	i := 1
+	for i < len(tag) && !isSpace(tag[i]) && tag[i] != '>' && tag[i] != '/' {
+		i++
+	}
+	name = tag[1:i]
+	for {
+		j := i
+		for j < len(tag) && isSpace(tag[j]) {
+			j++
+		}
+		if j == len(tag) {
+			return "", nil, "", false
+		} else if tag[j] == '>' || tag[j] == '/' {
+			return name, attrs, tag[i:], true
+		}
+		eq := strings.IndexByte(tag[j:], '=')
+		if eq == -1 {
+			return "", nil, "", false
+		}
+		k := j + eq + 1
+		for k < len(tag) && isSpace(tag[k]) {
+			k++
+		}
+		if k == len(tag) || (tag[k] != '"' && tag[k] != '\'') {
+			return "", nil, "", false
+		}
+		closing := strings.IndexByte(tag[k+1:], tag[k])
+		if closing == -1 {
+			return "", nil, "", false
+		}
+		end := k + 1 + closing + 1
+		attrs = append(attrs, rawAttr{
+			lead:  tag[i:j],
+			name:  strings.TrimRight(tag[j:j+eq], " \t\r\n"),
+			value: tag[k+1 : end-1],
+			text:  tag[j:end],
+		})
+		i = end
+	}
+}
+
+// mergeTag returns the start tag out, which differs from the encoder's spelling
+// of the decoded map, spelled as the file spelled it as far as it can be: in the
+// file's attribute order and spacing, with the file's spelling of each value the
+// encoder still writes the same. Attributes the file did not have follow, as out
+// spells them.
+func mergeTag(f wxx.Form_t, out string) string {
+	oname, oattrs, otail, ok1 := parseTag(f.Original)
+	cname, cattrs, _, ok2 := parseTag(f.Canonical)
+	name, attrs, tail, ok3 := parseTag(out)
+	if !ok1 || !ok2 || !ok3 || oname != name || cname != name {
+		return out
+	}
+	canonical := map[string]string{}
+	for _, a := range cattrs {
+		canonical[a.name] = a.value
+	}
+	current := map[string]rawAttr{}
+	for _, a := range attrs {
+		current[a.name] = a
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<" + name)
+	written := map[string]bool{}
+	for _, a := range oattrs {
+		c, ok := current[a.name]
+		if !ok {
+			continue // removed since the file was read
+		}
+		written[a.name] = true
+		sb.WriteString(a.lead)
+		if v, ok := canonical[a.name]; ok && v == c.value {
+			sb.WriteString(a.text)
+		} else {
+			sb.WriteString(c.text)
+		}
+	}
+	for _, a := range attrs {
+		if !written[a.name] {
+			sb.WriteString(a.lead + a.text)
+		}
+	}
+	if strings.HasSuffix(otail, "/>") == strings.HasSuffix(tail, "/>") {
+		tail = otail
+	}
+	sb.WriteString(tail)
+	return sb.String()
+}
+
+// mergeText returns the text out, which differs from the encoder's spelling of
+// the decoded map, with the file's spelling of every line the encoder still
+// writes the same, and within a changed line, of every tab-separated field. A
+// <tilerow> with one tile edited keeps the spelling of the others, and of the
+// unedited values of that tile. Text whose lines no longer line up with the
+// file's is written as out spells it.
+func mergeText(f wxx.Form_t, out string) string {
+	olines := strings.SplitAfter(f.Original, "\n")
+	clines := strings.SplitAfter(f.Canonical, "\n")
+	lines := strings.SplitAfter(out, "\n")
+	if len(olines) != len(lines) || len(clines) != len(lines) {
+		return out
+	}
+	var sb strings.Builder
+	for i, line := range lines {
+		if line == clines[i] {
+			sb.WriteString(olines[i])
+			continue
+		}
+		ofields := strings.Split(olines[i], "\t")
+		cfields := strings.Split(clines[i], "\t")
+		fields := strings.Split(line, "\t")
+		if len(ofields) != len(fields) || len(cfields) != len(fields) {
+			sb.WriteString(line)
+			continue
+		}
+		for j, field := range fields {
+			if j > 0 {
+				sb.WriteByte('\t')
+			}
+			if field == cfields[j] {
+				sb.WriteString(ofields[j])
+			} else {
+				sb.WriteString(field)
+			}
+		}
+	}
+	return sb.String()
+}
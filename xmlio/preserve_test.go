@@ -0,0 +1,192 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// preserveSources returns the fixtures, classic and W2025, as UTF-8 XML,
+// unmodified.
+func preserveSources(t *testing.T) map[string]string {
+	t.Helper()
+	sources := map[string]string{}
+	fixtures, err := filepath.Glob("../testdata/*.wxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range fixtures {
+		sources[filepath.Base(path)] = `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + string(unwrapWXX(t, path))
+	}
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	sources[filepath.Base(populatedFixture)] = string(raw)
+	return sources
+}
+
+// preserveRoundTrip decodes doc with WithPreserveFormatting, lets edit change
+// the map, and encodes it to the release it came from. It returns the document
+// as the decoder read it and as the encoder wrote it, both without the XML
+// declaration.
+func preserveRoundTrip(t *testing.T, doc string, edit func(m *wxx.Map_t)) (read, written string) {
+	t.Helper()
+	var dd xmlio.DecoderDiagnostics
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithPreserveFormatting(), xmlio.WithDecoderDiagnostics(&dd)).Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if m.Formatting == nil {
+		t.Fatal("decode left Map_t.Formatting nil")
+	}
+	if edit != nil {
+		edit(m)
+	}
+	var ed xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&ed)).Encode(&bytes.Buffer{}, m); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	return string(dd.XMLData), string(ed.Utf8Encoded)
+}
+
+// TestPreserveFormattingRoundTrip asserts that an unedited map is written back
+// byte for byte, and that without the forms it would not have been: the
+// fixtures are spelled the way Worldographer spells them, not the way the
+// encoder does.
+func TestPreserveFormattingRoundTrip(t *testing.T) {
+	for name, doc := range preserveSources(t) {
+		t.Run(name, func(t *testing.T) {
+			read, written := preserveRoundTrip(t, doc, nil)
+			if written != read {
+				n := commonPrefix(read, written)
+				t.Fatalf("output differs at byte %d: got %q, want %q", n, excerptAt(written, n), excerptAt(read, n))
+			}
+			_, canonical := preserveRoundTrip(t, doc, func(m *wxx.Map_t) { m.Formatting = nil })
+			if canonical == read {
+				t.Error("output without Formatting is identical too; the test proves nothing")
+			}
+		})
+	}
+}
+
+// TestPreserveFormattingEditedAttribute edits one attribute of a tag whose
+// other attributes the encoder spells differently ("-1" for "-1.0", doubled
+// spaces), and asserts that the edit is the only change in the document.
+func TestPreserveFormattingEditedAttribute(t *testing.T) {
+	doc := preserveSources(t)[filepath.Base(populatedFixture)]
+	read, written := preserveRoundTrip(t, doc, func(m *wxx.Map_t) {
+		m.MapKey.TitleText = "Legend"
+	})
+	want := strings.Replace(read, `titleText="Map Key"`, `titleText="Legend"`, 1)
+	if want == read {
+		t.Fatal(`fixture has no titleText="Map Key"`)
+	}
+	if written != want {
+		n := commonPrefix(want, written)
+		t.Fatalf("output differs at byte %d: got %q, want %q", n, excerptAt(written, n), excerptAt(want, n))
+	}
+}
+
+// TestPreserveFormattingKeepsRepairs asserts that a value a lenient decode
+// repaired is written as repaired. The file's spelling of it is the bad value.
+func TestPreserveFormattingKeepsRepairs(t *testing.T) {
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	lines := strings.Split(string(raw), "\n")
+	lines[31] = "0\t1\t0\t0\t0\t101\t0\t0\t0\t0\t0" // tilerow 2, line 2: brick out of range
+	doc := strings.Join(lines, "\n")
+
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithLenientDecode(), xmlio.WithPreserveFormatting()).Decode(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var ed xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&ed)).Encode(&bytes.Buffer{}, m); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if strings.Contains(string(ed.Utf8Encoded), lines[31]) {
+		t.Errorf("output has the repaired line as the file spelled it, %q", lines[31])
+	}
+	if !strings.Contains(string(ed.Utf8Encoded), "0\t1\t0\t0\t0\t100\t0\t0\t0\t0\t0") {
+		t.Error("output does not have the repaired brick, 100")
+	}
+}
+
+// TestPreserveFormattingDecodesTheSame asserts that writing a fixture with its
+// forms gives, group by group, either the map the file held or the map the
+// encoder writes without them -- the file's value where the encoder alters one,
+// never a mixture. The classic fixtures matter most here: their encoder drops
+// whole elements, and the forms must not bring back half of one.
+func TestPreserveFormattingDecodesTheSame(t *testing.T) {
+	fixtures, err := filepath.Glob("../testdata/*.wxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range append(fixtures, populatedFixture) {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read %s: %v", path, err)
+			}
+			plain, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			preserved, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithPreserveFormatting()).Decode(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("decode with WithPreserveFormatting: %v", err)
+			}
+			app := plain.MetaData.Version.App.Raw
+			var plainOut, preservedOut bytes.Buffer
+			if err := xmlio.NewEncoder(app).Encode(&plainOut, plain); err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			if err := xmlio.NewEncoder(app).Encode(&preservedOut, preserved); err != nil {
+				t.Fatalf("encode with forms: %v", err)
+			}
+			a, err := xmlio.NewDecoder().Decode(&plainOut)
+			if err != nil {
+				t.Fatalf("re-decode: %v", err)
+			}
+			b, err := xmlio.NewDecoder().Decode(&preservedOut)
+			if err != nil {
+				t.Fatalf("re-decode of the output with forms: %v", err)
+			}
+			normalizeVolatile(plain)
+			normalizeVolatile(a)
+			normalizeVolatile(b)
+			read, encoded, got := mapGroups(plain), mapGroups(a), mapGroups(b)
+			for i, g := range got {
+				if !reflect.DeepEqual(g.value, encoded[i].value) && !reflect.DeepEqual(g.value, read[i].value) {
+					path, _ := firstDiff(g.name, reflect.ValueOf(encoded[i].value), reflect.ValueOf(g.value))
+					t.Errorf("group %q is neither as read nor as encoded, at %s", g.name, path)
+				}
+			}
+		})
+	}
+}
+
+// commonPrefix returns the length of the longest common prefix of a and b.
+func commonPrefix(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
+
+// excerptAt returns up to 60 bytes of s starting at n.
+func excerptAt(s string, n int) string {
+	return s[n:min(n+60, len(s))]
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -34,8 +35,8 @@ import (
 
 const classicInputDir = "../testdata/"
 
-// rowsFixture decodes but cannot be re-encoded: classic ROWS encode is a
-// documented hard-error (encode.go encodeTiles asserts orientation != ROWS).
+// rowsFixture is the one classic fixture with ROWS orientation. It decodes
+// and re-encodes like the others; TestRoundTrip2017Rows checks its grid.
 const rowsFixture = "2017-1.77-1.0-rows-blank.wxx"
 
 // classicFixtures are the eight classic 2017 fixtures under testdata/.
@@ -194,8 +195,8 @@ func equalStringSet(a, b map[string]bool) bool {
 
 // classicRoundTrip decodes a classic fixture (capturing the input UTF-8 XML in
 // diagnostics), re-encodes it (capturing the output UTF-8 XML), and returns the
-// element/attribute-set loss between input and output. If encode hard-errors
-// (the ROWS case), it returns a nil loss set and the encode error.
+// element/attribute-set loss between input and output. An encode error is
+// returned with a nil loss set.
 func classicRoundTrip(t *testing.T, fixture string) (loss []string, encodeErr error) {
 	t.Helper()
 	path := classicInputDir + fixture
@@ -245,13 +246,6 @@ func TestRoundTrip2017LossInventory(t *testing.T) {
 		t.Run(fixture, func(t *testing.T) {
 			loss, encErr := classicRoundTrip(t, fixture)
 
-			if fixture == rowsFixture {
-				if encErr == nil {
-					t.Fatalf("%s: expected encode hard-error (classic ROWS), got nil", fixture)
-				}
-				t.Logf("%s: round-trip not possible -- encode hard-errors: %v", fixture, encErr)
-				return
-			}
 			if encErr != nil {
 				t.Fatalf("%s: unexpected encode error: %v", fixture, encErr)
 			}
@@ -266,10 +260,13 @@ func TestRoundTrip2017LossInventory(t *testing.T) {
 	}
 }
 
-// TestRoundTrip2017RowsHardError is the focused ROWS subtest: the ROWS fixture
-// must DECODE successfully but its re-encode must return a non-nil error
-// (classic ROWS encode is intentionally unimplemented -- COVERAGE.md).
-func TestRoundTrip2017RowsHardError(t *testing.T) {
+// TestRoundTrip2017Rows is the focused ROWS check. The loss inventory works at
+// the element/attribute-set level, which cannot see tile data at all, so this
+// test proves the grid itself survives: the decode -> encode -> decode tiles,
+// orientation, and dimensions equal the first decode's, and the <tiles> element the encoder writes is the
+// one the file holds, byte for byte. A transposition in either direction would
+// fail both.
+func TestRoundTrip2017Rows(t *testing.T) {
 	path := classicInputDir + rowsFixture
 	f, err := os.Open(path)
 	if err != nil {
@@ -277,23 +274,56 @@ func TestRoundTrip2017RowsHardError(t *testing.T) {
 	}
 	defer f.Close()
 
-	m, err := xmlio.NewDecoder().Decode(f)
+	var d xmlio.DecoderDiagnostics
+	m1, err := xmlio.NewDecoder(xmlio.WithDecoderDiagnostics(&d)).Decode(f)
 	if err != nil {
-		t.Fatalf("decode %s: want success, got %v", rowsFixture, err)
+		t.Fatalf("decode %s: %v", rowsFixture, err)
 	}
-	if m.Tiles == nil {
-		t.Fatalf("decode %s: nil Tiles", rowsFixture)
-	}
-	if got := m.HexOrientation; got != "ROWS" {
+	if got := m1.HexOrientation; got != "ROWS" {
 		t.Fatalf("decode %s: HexOrientation = %q, want ROWS", rowsFixture, got)
 	}
+	if m1.Tiles.TilesWide == m1.Tiles.TilesHigh {
+		t.Fatalf("decode %s: %d x %d grid is square, so it cannot show a transposition", rowsFixture, m1.Tiles.TilesWide, m1.Tiles.TilesHigh)
+	}
 
+	var e xmlio.EncoderDiagnostics
 	var buf bytes.Buffer
-	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw).Encode(&buf, m); err == nil {
-		t.Fatalf("encode %s: want non-nil error (classic ROWS is a hard-error), got nil", rowsFixture)
+	if err := xmlio.NewEncoder(m1.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&e)).Encode(&buf, m1); err != nil {
+		t.Fatalf("encode %s: %v", rowsFixture, err)
+	}
+	if got, want := tilesElement(t, e.Utf8Encoded), tilesElement(t, d.Converted); got != want {
+		t.Errorf("encode %s: <tiles> differs from the file\n--- got ---\n%s\n--- want ---\n%s", rowsFixture, head([]byte(got), 600), head([]byte(want), 600))
+	}
+
+	m2, err := xmlio.NewDecoder().Decode(&buf)
+	if err != nil {
+		t.Fatalf("re-decode %s: %v", rowsFixture, err)
+	}
+	// the rest of the map loses what every classic fixture loses; that is the
+	// inventory's business, so only the grid is compared here
+	if path, ok := firstDiff("Tiles", reflect.ValueOf(m1.Tiles), reflect.ValueOf(m2.Tiles)); ok {
+		t.Errorf("re-decode %s: tiles differ at %s", rowsFixture, path)
+	}
+	if m2.HexOrientation != m1.HexOrientation || m2.GridOrientation != m1.GridOrientation {
+		t.Errorf("re-decode %s: orientation = %q/%v, want %q/%v", rowsFixture, m2.HexOrientation, m2.GridOrientation, m1.HexOrientation, m1.GridOrientation)
+	}
+	if m2.RowsHigh != m1.RowsHigh || m2.ColumnsWide != m1.ColumnsWide {
+		t.Errorf("re-decode %s: %d rows x %d columns, want %d x %d", rowsFixture, m2.RowsHigh, m2.ColumnsWide, m1.RowsHigh, m1.ColumnsWide)
 	}
 }
 
+// tilesElement returns the <tiles> element of a UTF-8 map document, from its
+// start tag through its end tag.
+func tilesElement(t *testing.T, doc []byte) string {
+	t.Helper()
+	s := string(doc)
+	start, end := strings.Index(s, "<tiles "), strings.Index(s, "</tiles>")
+	if start == -1 || end < start {
+		t.Fatalf("no <tiles> element in %d bytes", len(doc))
+	}
+	return s[start : end+len("</tiles>")]
+}
+
 // assertLossSet compares the observed loss set to the expected set, failing
 // with an explicit list of unexpected additions and removals so a maintainer
 // can update the inventory deliberately.
@@ -347,68 +377,17 @@ func assertLossSet(t *testing.T, fixture string, want, got []string) {
 // "Round-trip loss inventory (executable)" section in
 // xmlio/internal/v0_77/COVERAGE.md; keep the two in sync.
 //
-// Shared across every classic fixture (blank, columns, import, merge):
-//   - <informations>/<information> lore tree dropped (encode.go:438-442 emits
-//     an empty <informations> wrapper). The nesting depth listed per fixture is
-//     the deepest <information> chain that fixture actually carries.
-//   - configuration <text-config>/<labelstyle> dropped (encode.go:483-507:
-//     encodeLabelStyle is a commented-out no-op; the <text-config> wrapper is
-//     still emitted, empty).
-//
-// Observed on a subset only:
-//   - map/mapkey @viewlevel altered "null" -> "WORLD": encode.go:253-258 emits
-//     a hardcoded constant <mapkey> block. The samples happen to match that
-//     block on every other attribute, so viewlevel is the only OBSERVED
-//     alteration (the full constant-block override is latent-by-code). The
-//     1.74 and columns-blank fixtures already carry viewlevel="WORLD", so they
-//     show no mapkey drift at all.
+// Every classic fixture round-trips without loss today. The map key, the lore
+// tree and the label styles, which the encoder used to drop or write as a
+// constant, are written from the map now, so the inventory is empty; it is kept
+// per fixture so that a loss that comes back is one the harness names.
 var classicRoundTripExpect = map[string][]string{
-	"blank-2017-1.73-1.0.wxx": {
-		"attr-altered\tmap/mapkey\tviewlevel",
-		"element-dropped\tmap/configuration/text-config/labelstyle",
-		"element-dropped\tmap/informations/information",
-		"element-dropped\tmap/informations/information/information",
-		"element-dropped\tmap/informations/information/information/information",
-	},
-	"blank-2017-1.74-1.0.wxx": {
-		"element-dropped\tmap/configuration/text-config/labelstyle",
-		"element-dropped\tmap/informations/information",
-		"element-dropped\tmap/informations/information/information",
-	},
-	"blank-2017-1.77-1.0.wxx": {
-		"attr-altered\tmap/mapkey\tviewlevel",
-		"element-dropped\tmap/configuration/text-config/labelstyle",
-		"element-dropped\tmap/informations/information",
-		"element-dropped\tmap/informations/information/information",
-		"element-dropped\tmap/informations/information/information/information",
-	},
-	"2017-1.77-1.0-columns-blank.wxx": {
-		"element-dropped\tmap/configuration/text-config/labelstyle",
-		"element-dropped\tmap/informations/information",
-		"element-dropped\tmap/informations/information/information",
-	},
-	"2017-1.77-1.0-import.wxx": {
-		"attr-altered\tmap/mapkey\tviewlevel",
-		"element-dropped\tmap/configuration/text-config/labelstyle",
-		"element-dropped\tmap/informations/information",
-		"element-dropped\tmap/informations/information/information",
-		"element-dropped\tmap/informations/information/information/information",
-	},
-	"2017-1.77-1.0-merge-01.wxx": {
-		"attr-altered\tmap/mapkey\tviewlevel",
-		"element-dropped\tmap/configuration/text-config/labelstyle",
-		"element-dropped\tmap/informations/information",
-		"element-dropped\tmap/informations/information/information",
-		"element-dropped\tmap/informations/information/information/information",
-	},
-	"2017-1.77-1.0-merge-02.wxx": {
-		"attr-altered\tmap/mapkey\tviewlevel",
-		"element-dropped\tmap/configuration/text-config/labelstyle",
-		"element-dropped\tmap/informations/information",
-		"element-dropped\tmap/informations/information/information",
-		"element-dropped\tmap/informations/information/information/information",
-	},
-	// rowsFixture has no loss set: it decodes but re-encode hard-errors, so the
-	// round trip is impossible. Asserted separately (see the ROWS handling in
-	// TestRoundTrip2017LossInventory and TestRoundTrip2017RowsHardError).
+	"blank-2017-1.73-1.0.wxx":         {},
+	"blank-2017-1.74-1.0.wxx":         {},
+	"blank-2017-1.77-1.0.wxx":         {},
+	"2017-1.77-1.0-columns-blank.wxx": {},
+	"2017-1.77-1.0-import.wxx":        {},
+	"2017-1.77-1.0-merge-01.wxx":      {},
+	"2017-1.77-1.0-merge-02.wxx":      {},
+	rowsFixture:                       {},
 }
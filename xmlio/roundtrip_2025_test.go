@@ -5,6 +5,7 @@ package xmlio_test
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"strings"
@@ -181,9 +182,9 @@ func TestW2025DecodePopulated(t *testing.T) {
 	if got, want := len(m.Features), 2; got != want {
 		t.Fatalf("len(Features) = %d, want %d", got, want)
 	}
-	// The opaque-black feature color folds to nil (decodeRgba contract).
-	if m.Features[0].Color != nil {
-		t.Errorf("Features[0].Color = %+v, want nil (opaque black folds to nil)", m.Features[0].Color)
+	// The opaque-black feature color is an explicit color, not "no color".
+	if c := m.Features[0].Color; c == nil || *c != (wxx.RGBA_t{A: 1, Raw: "0.0,0.0,0.0,1.0"}) {
+		t.Errorf("Features[0].Color = %+v, want explicit opaque black", c)
 	}
 	// The labelless feature must decode with a nil Label.
 	if m.Features[1].Label != nil {
@@ -191,6 +192,38 @@ func TestW2025DecodePopulated(t *testing.T) {
 	}
 }
 
+// TestW2025Informations asserts that the lore tree is kept to the fixture's
+// third level of <information>, and that the encoder writes its HTML as CDATA,
+// as the file did, rather than escaped.
+func TestW2025Informations(t *testing.T) {
+	m := decodeFixture(t, populatedFixture)
+
+	var deepest *wxx.InformationDetail_t
+	for _, info := range m.Informations.Informations {
+		for _, d := range info.Details {
+			for _, dd := range d.Details {
+				if dd.Title == "Egon" {
+					deepest = dd
+				}
+			}
+		}
+	}
+	if deepest == nil {
+		t.Fatal("Egon, a god of the pantheon and a third-level <information>, was not decoded")
+	}
+	if !deepest.IsCDATA {
+		t.Error("Egon's text was a CDATA section, and IsCDATA is false")
+	}
+
+	xmlBytes, err := v1_06.Encode(m, m.MetaData.Version.App.Raw)
+	if err != nil {
+		t.Fatalf("v1_06.Encode: %v", err)
+	}
+	if want := "<![CDATA[<h2>Egon</h2>"; !strings.Contains(string(xmlBytes), want) {
+		t.Errorf("the encoded map does not have %q", want)
+	}
+}
+
 // TestW2025PopulatedRoundTrip drives the in-memory XML codec over the populated
 // fixture: decode -> encode -> decode, then compares the two Map_t values group
 // by group. Any encoder that drops content (shapes, notes) surfaces as a
@@ -321,32 +354,40 @@ func normalizeVolatile(m *wxx.Map_t) {
 // names exactly which part of the model lost fidelity.
 func compareGroups(t *testing.T, a, b *wxx.Map_t) {
 	t.Helper()
-	groups := []struct {
-		name string
-		x, y any
-	}{
-		{"MetaData", a.MetaData, b.MetaData},
-		{"map-attributes", mapAttrs(a), mapAttrs(b)},
-		{"GridAndNumbering", a.GridAndNumbering, b.GridAndNumbering},
-		{"TerrainMap", a.TerrainMap, b.TerrainMap},
-		{"MapLayers", a.MapLayers, b.MapLayers},
-		{"Tiles", a.Tiles, b.Tiles},
-		{"MapKey", a.MapKey, b.MapKey},
-		{"Features", a.Features, b.Features},
-		{"Labels", a.Labels, b.Labels},
-		{"Shapes", a.Shapes, b.Shapes},
-		{"Notes", a.Notes, b.Notes},
-		{"Informations", a.Informations, b.Informations},
-		{"Configuration", a.Configuration, b.Configuration},
-	}
-	for _, g := range groups {
-		if !reflect.DeepEqual(g.x, g.y) {
-			path, _ := firstDiff(g.name, reflect.ValueOf(g.x), reflect.ValueOf(g.y))
+	ga, gb := mapGroups(a), mapGroups(b)
+	for i, g := range ga {
+		if !reflect.DeepEqual(g.value, gb[i].value) {
+			path, _ := firstDiff(g.name, reflect.ValueOf(g.value), reflect.ValueOf(gb[i].value))
 			t.Errorf("group %q differs after round-trip at %s", g.name, path)
 		}
 	}
 }
 
+// mapGroup is one top-level element group of a Map_t.
+type mapGroup struct {
+	name  string
+	value any
+}
+
+// mapGroups returns the groups compareGroups compares, in document order.
+func mapGroups(m *wxx.Map_t) []mapGroup {
+	return []mapGroup{
+		{"MetaData", m.MetaData},
+		{"map-attributes", mapAttrs(m)},
+		{"GridAndNumbering", m.GridAndNumbering},
+		{"TerrainMap", m.TerrainMap},
+		{"MapLayers", m.MapLayers},
+		{"Tiles", m.Tiles},
+		{"MapKey", m.MapKey},
+		{"Features", m.Features},
+		{"Labels", m.Labels},
+		{"Shapes", m.Shapes},
+		{"Notes", m.Notes},
+		{"Informations", m.Informations},
+		{"Configuration", m.Configuration},
+	}
+}
+
 // firstDiff walks two values in lockstep and returns a path string describing
 // the first place they differ, so a round-trip failure names the exact field
 // (e.g. "MapKey.Viewlevel: null vs WORLD") instead of dumping whole structs.
@@ -431,3 +472,37 @@ func head(b []byte, n int) string {
 	}
 	return string(b[:n]) + "…"
 }
+
+// TestW2025ExtraTerrainRoundTrip decodes the layers fixture's <extraTerrain>
+// into the model and requires the encoder to write the element back byte for
+// byte, now that it is rebuilt from typed fields rather than echoed verbatim.
+func TestW2025ExtraTerrainRoundTrip(t *testing.T) {
+	m, err := decodeFile(t, sample2025_206Layers)
+	if err != nil {
+		t.Fatalf("decode %s: %v", sample2025_206Layers, err)
+	}
+	if m.ExtraTerrain == nil || len(m.ExtraTerrain.MapLayers) != 1 || len(m.ExtraTerrain.MapLayers[0].Terrain) != 1 {
+		t.Fatalf("ExtraTerrain = %+v, want one layer holding one hex", m.ExtraTerrain)
+	}
+	layer := m.ExtraTerrain.MapLayers[0]
+	want := wxx.TerrainAndLocation_t{Terrain: "Classic/Flat Dead Soil", Elevation: 1000, Resources: "Z", X: 225, Y: 150}
+	if layer.Name != "Terrain Layer" || *layer.Terrain[0] != want {
+		t.Errorf("layer %q terrain = %+v, want layer %q terrain %+v", layer.Name, *layer.Terrain[0], "Terrain Layer", want)
+	}
+
+	var d xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder(m.MetaData.Version.App.Raw, xmlio.WithEncoderDiagnostics(&d)).Encode(io.Discard, m); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	element := func(data []byte) string {
+		s := string(data)
+		i, j := strings.Index(s, "<extraTerrain>"), strings.Index(s, "</extraTerrain>")
+		if i == -1 || j == -1 {
+			t.Fatalf("no <extraTerrain> element in %d bytes", len(data))
+		}
+		return s[i : j+len("</extraTerrain>")]
+	}
+	if got, want := element(d.Utf8Encoded), element(unwrapWXX(t, sample2025_206Layers)); got != want {
+		t.Errorf("encoded <extraTerrain> =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -54,21 +54,14 @@ func newRowsMap() *wxx.Map_t {
 		List: []*wxx.Terrain_t{{Index: 0, Label: "Blank"}, {Index: 1, Label: "Water"}},
 	}
 
-	m.Tiles = &wxx.Tiles_t{
-		ViewLevel: "WORLD",
-		TilesWide: tilesWide,
-		TilesHigh: tilesHigh,
-	}
+	m.Tiles = wxx.NewTiles(tilesWide, tilesHigh, hexg.OddR)
+	m.Tiles.ViewLevel = "WORLD"
 	// Row orientation: RowsHigh = TilesWide, ColumnsWide = TilesHigh (mirrors decode).
 	m.RowsHigh = tilesWide
 	m.ColumnsWide = tilesHigh
 	for x := 0; x < tilesWide; x++ {
-		col := make([]*wxx.Tile_t, tilesHigh)
 		for y := 0; y < tilesHigh; y++ {
-			t := &wxx.Tile_t{
-				Row:       x,
-				Column:    y,
-				Coords:    hexg.NewOddRCoord(y, x).ToCube(),
+			t := wxx.Tile_t{
 				Terrain:   10*x + y, // distinct, position-sensitive
 				Elevation: float64(100*x + y),
 				IsIcy:     (x+y)%2 == 0,
@@ -80,9 +73,8 @@ func newRowsMap() *wxx.Map_t {
 				t.Resources.Brick = 3
 				t.Resources.Crops = 4
 			}
-			col[y] = t
+			m.Tiles.SetTile(x, y, t)
 		}
-		m.Tiles.Tiles = append(m.Tiles.Tiles, col)
 	}
 	return m
 }
@@ -124,7 +116,7 @@ func TestW2025RowsRoundTrip(t *testing.T) {
 	// would swap values between cells and trip these checks.
 	for x := 0; x < m1.Tiles.TilesWide; x++ {
 		for y := 0; y < m1.Tiles.TilesHigh; y++ {
-			a, b := m1.Tiles.Tiles[x][y], m2.Tiles.Tiles[x][y]
+			a, b := m1.Tiles.Tile(x, y), m2.Tiles.Tile(x, y)
 			if a.Terrain != b.Terrain {
 				t.Errorf("Tiles[%d][%d].Terrain = %d, want %d", x, y, b.Terrain, a.Terrain)
 			}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// restated returns the populated fixture stating schema instead of "1.06", with
+// a new attribute on its first feature, as a build writing that schema might.
+func restated(t *testing.T, schema string) string {
+	t.Helper()
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	doc := string(raw)
+	for old, new := range map[string]string{
+		`schema="1.06"`: `schema="` + schema + `"`,
+		"<feature ":     `<feature glint="0.5" `,
+	} {
+		if !strings.Contains(doc, old) {
+			t.Fatalf("%s has no %s", populatedFixture, old)
+		}
+		doc = strings.Replace(doc, old, new, 1)
+	}
+	return doc
+}
+
+// TestDecodeNewerMinorSchema asserts that a file stating a minor schema no
+// codec declares is read by the nearest older codec, and that the decode says
+// so: the schema it fell back to, and what the codec did not consume.
+func TestDecodeNewerMinorSchema(t *testing.T) {
+	var dd xmlio.DecoderDiagnostics
+	m, err := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithDecoderDiagnostics(&dd)).Decode(strings.NewReader(restated(t, "1.07")))
+	if err != nil {
+		t.Fatalf("decode schema 1.07: %v", err)
+	}
+	if dd.FallbackSchema != "1.06" {
+		t.Errorf("FallbackSchema = %q, want %q", dd.FallbackSchema, "1.06")
+	}
+	if got := m.MetaData.Version.Schema.Raw; got != "1.07" {
+		t.Errorf("MetaData.Version.Schema = %q, want the file's %q", got, "1.07")
+	}
+	if len(dd.UnknownContent) != 1 {
+		t.Fatalf("UnknownContent = %v, want the one attribute added", dd.UnknownContent)
+	}
+	u := dd.UnknownContent[0]
+	if u.Path != "map/features/feature/@glint" || u.Field != "Map_t.Features[].Extras" || u.Count != 1 || !strings.Contains(u.Detail, `"0.5"`) {
+		t.Errorf("UnknownContent[0] = %+v", u)
+	}
+
+	// the extra came from a 1.07 file, and schema 1.06 does not define it
+	if x := m.Features[0].Extras; x == nil || x.Schema != "1.07" {
+		t.Fatalf("Features[0].Extras = %+v, want extras read against schema 1.07", x)
+	}
+	var ed xmlio.EncoderDiagnostics
+	if err := xmlio.NewEncoder("2.06", xmlio.WithEncoderDiagnostics(&ed)).Encode(&bytes.Buffer{}, m); err != nil {
+		t.Fatalf("encode to 2.06: %v", err)
+	}
+	if bytes.Contains(ed.Utf8Encoded, []byte("glint=")) {
+		t.Error("schema 1.06 output has the attribute read from a schema 1.07 file")
+	}
+	if len(ed.Dropped) != 1 || ed.Dropped[0].Path != "map/features/feature/@glint" {
+		t.Errorf("Dropped = %v, want the attribute read from the schema 1.07 file", ed.Dropped)
+	}
+}
+
+// TestDecodeUnsupportedSchema asserts that the fallback does not reach across a
+// major schema or below the oldest codec, and that a schema that is not a dotted
+// version is rejected rather than read by some codec.
+func TestDecodeUnsupportedSchema(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		want   error
+	}{
+		{"2.00", wxx.ErrUnsupportedSchemaVersion},
+		{"1.05", wxx.ErrUnsupportedSchemaVersion},
+		{"0.77", wxx.ErrUnsupportedSchemaVersion},
+		{"1.x", wxx.ErrInvalidMapMetadata},
+	} {
+		t.Run(tc.schema, func(t *testing.T) {
+			_, err := xmlio.NewDecoder(xmlio.WithAutoDetect()).Decode(strings.NewReader(restated(t, tc.schema)))
+			if !errors.Is(err, tc.want) {
+				t.Errorf("decode schema %s: err = %v, want %v", tc.schema, err, tc.want)
+			}
+		})
+	}
+}
+
+// TestDecodeStrictSchema asserts that WithStrictSchema fails a decode for each
+// of the two things it is there to catch, and passes one with neither.
+func TestDecodeStrictSchema(t *testing.T) {
+	strict := xmlio.NewDecoder(xmlio.WithAutoDetect(), xmlio.WithStrictSchema())
+
+	if _, err := strict.Decode(strings.NewReader(restated(t, "1.07"))); !errors.Is(err, wxx.ErrNewerSchema) {
+		t.Errorf("schema 1.07: err = %v, want %v", err, wxx.ErrNewerSchema)
+	}
+
+	_, err := strict.Decode(strings.NewReader(restated(t, "1.06")))
+	if !errors.Is(err, wxx.ErrUnknownContent) {
+		t.Errorf("schema 1.06 with an unknown attribute: err = %v, want %v", err, wxx.ErrUnknownContent)
+	} else if !strings.Contains(err.Error(), "map/features/feature/@glint") {
+		t.Errorf("error %q does not name the unknown attribute", err)
+	}
+
+	raw, err := os.ReadFile(populatedFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", populatedFixture, err)
+	}
+	if _, err := strict.Decode(bytes.NewReader(raw)); err != nil {
+		t.Errorf("%s: %v; the fixture holds nothing the codec does not know", populatedFixture, err)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+	"github.com/maloquacious/wxx/xmlio/internal/v0_77"
+	"github.com/maloquacious/wxx/xmlio/internal/v1_06"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// unwrapWXX undoes the .wxx container by hand, the way the decoder did before it
+// streamed: the whole file is read, gunzipped, converted from UTF-16BE, and
+// stripped of its XML declaration, each into a fresh buffer.
+func unwrapWXX(t *testing.T, path string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	gzr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("gunzip %s: %v", path, err)
+	}
+	data, err := io.ReadAll(gzr)
+	if err != nil {
+		t.Fatalf("gunzip %s: %v", path, err)
+	}
+	data, err = io.ReadAll(transform.NewReader(bytes.NewReader(data), unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM).NewDecoder()))
+	if err != nil {
+		t.Fatalf("utf-16 %s: %v", path, err)
+	}
+	i := bytes.Index(data, []byte("<map "))
+	if i == -1 {
+		t.Fatalf("%s: no <map> element", path)
+	}
+	return data[i:]
+}
+
+// TestStreamingDecodeMatchesUnmarshal is the equivalence proof for the
+// streaming decoder. Every .wxx fixture is decoded twice: through the public
+// Decoder, which streams, and through the codec's Decode, which unmarshals the
+// fully buffered document. The two Map_t values must agree group by group.
+func TestStreamingDecodeMatchesUnmarshal(t *testing.T) {
+	fixtures, err := filepath.Glob("../testdata/*.wxx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no .wxx fixtures found")
+	}
+	for _, path := range fixtures {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			streamed, err := decodeFile(t, path)
+			if err != nil {
+				t.Fatalf("stream decode %s: %v", path, err)
+			}
+
+			var unmarshalled *wxx.Map_t
+			if streamed.MetaData.Worldographer.Release == "2025" {
+				unmarshalled, err = v1_06.Decode(unwrapWXX(t, path))
+			} else {
+				unmarshalled, err = v0_77.Decode(unwrapWXX(t, path))
+			}
+			if err != nil {
+				t.Fatalf("unmarshal decode %s: %v", path, err)
+			}
+
+			normalizeVolatile(streamed)
+			normalizeVolatile(unmarshalled)
+			compareGroups(t, unmarshalled, streamed)
+			assertSameMap(t, unmarshalled, streamed)
+		})
+	}
+
+	// the populated fixture is plain UTF-8 XML, so it goes to the codec directly
+	t.Run(filepath.Base(populatedFixture), func(t *testing.T) {
+		raw, err := os.ReadFile(populatedFixture)
+		if err != nil {
+			t.Fatalf("read %s: %v", populatedFixture, err)
+		}
+		unmarshalled, err := v1_06.Decode(raw)
+		if err != nil {
+			t.Fatalf("v1_06.Decode: %v", err)
+		}
+		streamed, err := v1_06.DecodeReader(bytes.NewReader(raw), nil)
+		if err != nil {
+			t.Fatalf("v1_06.DecodeReader: %v", err)
+		}
+		normalizeVolatile(streamed)
+		normalizeVolatile(unmarshalled)
+		compareGroups(t, unmarshalled, streamed)
+		assertSameMap(t, unmarshalled, streamed)
+	})
+}
+
+// assertSameMap compares the whole Map_t, which catches the fields that no
+// compareGroups group names.
+func assertSameMap(t *testing.T, want, got *wxx.Map_t) {
+	t.Helper()
+	if path, ok := firstDiff("Map_t", reflect.ValueOf(want), reflect.ValueOf(got)); ok {
+		t.Errorf("streamed map differs at %s", path)
+	}
+}
+
+// TestStreamingDecodeChecksGzipTrailer asserts that the streaming decoder still
+// reads the input to its end. The codec stops at </map>, before the gzip
+// trailer; a corrupt checksum there must fail the decode as it did when the
+// input was read whole.
+func TestStreamingDecodeChecksGzipTrailer(t *testing.T) {
+	raw, err := os.ReadFile(classicFixture)
+	if err != nil {
+		t.Fatalf("read %s: %v", classicFixture, err)
+	}
+	// the trailer is CRC-32 then ISIZE, four bytes each
+	raw[len(raw)-8] ^= 0xff
+
+	_, err = xmlio.NewDecoder().Decode(bytes.NewReader(raw))
+	if !errors.Is(err, wxx.ErrGUnZipFailed) {
+		t.Fatalf("decode with corrupt CRC: error = %v, want it to wrap %v", err, wxx.ErrGUnZipFailed)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Tracer is told how each stage of a decode or an encode went: how many bytes
+// it read and wrote, how many elements it saw and how long it took. It is the
+// light alternative to DecoderDiagnostics and EncoderDiagnostics, which copy
+// what each stage produced: a Tracer is given counts, never content, so it can
+// watch maps that are not the caller's to keep.
+//
+// Trace is called on the goroutine that called Decode or Encode, and one event
+// at a time.
+type Tracer interface {
+	Trace(ev TraceEvent_t)
+}
+
+// TracerFunc is a function that is a Tracer.
+type TracerFunc func(ev TraceEvent_t)
+
+// Trace calls f(ev).
+func (f TracerFunc) Trace(ev TraceEvent_t) {
+	f(ev)
+}
+
+// TraceEvent_t is what a Tracer is told about one stage.
+type TraceEvent_t struct {
+	Stage Stage_e
+
+	// Section is the element name of a child of <map>, for StageSection.
+	Section string
+
+	// Detail is the application version, map/@version, that the file states
+	// or the encoder writes, for StageDispatch, StageDecode and StageEncode.
+	Detail string
+
+	BytesIn  int64 // the bytes the stage read
+	BytesOut int64 // the bytes the stage produced
+	Elements int   // the XML elements the stage read or wrote, where it knows
+
+	// Duration is the time the stage took. For read, gunzip and utf16 it is the
+	// time spent in the stage's own reads, without the stages below it; the
+	// other stages of a decode include the reads they caused.
+	Duration time.Duration
+}
+
+// Stage_e names a stage of the decode or encode pipeline.
+type Stage_e int
+
+const (
+	UnknownStage Stage_e = iota
+
+	// The stages of a decode. read, gunzip and utf16 are readers stacked on one
+	// another and run together as the input streams through; they report their
+	// totals once the decode is done, whether or not it succeeded. The others
+	// report as they finish, in the order here.
+	StageHeader   // the XML header was checked and consumed
+	StageDispatch // the <map> element was read and a codec chosen
+	StageSection  // one child of <map> was read; one event per child
+	StageDecode   // the codec read the document and built the map
+	StagePreserve // WithPreserveFormatting recorded the file's spelling
+	StageRead     // the input was read
+	StageGunzip   // the input was uncompressed
+	StageUTF16    // UTF-16 was converted to UTF-8, or back on an encode
+
+	// The stages of an encode, which report in this order: encode, utf16, gzip
+	// and write.
+	StageEncode // the codec wrote the XML and it was checked
+	StageGzip   // the output was compressed
+	StageWrite  // the output was written
+)
+
+// String implements the fmt.Stringer interface
+func (s Stage_e) String() string {
+	switch s {
+	case UnknownStage:
+		return "unknown"
+	case StageHeader:
+		return "header"
+	case StageDispatch:
+		return "dispatch"
+	case StageSection:
+		return "section"
+	case StageDecode:
+		return "decode"
+	case StagePreserve:
+		return "preserve"
+	case StageRead:
+		return "read"
+	case StageGunzip:
+		return "gunzip"
+	case StageUTF16:
+		return "utf16"
+	case StageEncode:
+		return "encode"
+	case StageGzip:
+		return "gzip"
+	case StageWrite:
+		return "write"
+	default:
+		return fmt.Sprintf("Stage_e(%d)", int(s))
+	}
+}
+
+// WithDecoderTracer reports each stage of the decode to t. See Tracer.
+//
+// It is not WithTracer, shared with the Encoder, for the reason given on
+// WithDecoderConcurrency; see WithEncoderTracer for the Encoder's.
+func WithDecoderTracer(t Tracer) DecoderOption {
+	return func(o *decoderOpts) {
+		o.tracer = t
+	}
+}
+
+// WithEncoderTracer reports each stage of the encode to t. See Tracer.
+func WithEncoderTracer(t Tracer) EncoderOption {
+	return func(o *encoderOpts) {
+		o.tracer = t
+	}
+}
+
+// NewSlogTracer returns a Tracer that logs each event to l at level, as the
+// message "xmlio: " and the stage, with the event's fields as attributes.
+// Section and detail are left out when they are empty.
+func NewSlogTracer(l *slog.Logger, level slog.Level) Tracer {
+	return TracerFunc(func(ev TraceEvent_t) {
+		if !l.Enabled(context.Background(), level) {
+			return
+		}
+		attrs := make([]slog.Attr, 0, 6)
+		if ev.Section != "" {
+			attrs = append(attrs, slog.String("section", ev.Section))
+		}
+		if ev.Detail != "" {
+			attrs = append(attrs, slog.String("detail", ev.Detail))
+		}
+		attrs = append(attrs,
+			slog.Int64("bytes_in", ev.BytesIn),
+			slog.Int64("bytes_out", ev.BytesOut),
+			slog.Int("elements", ev.Elements),
+			slog.Duration("duration", ev.Duration),
+		)
+		l.LogAttrs(context.Background(), level, "xmlio: "+ev.Stage.String(), attrs...)
+	})
+}
+
+// traceReader counts the bytes read through it and the time spent reading
+// them, for a stage of a traced decode.
+type traceReader struct {
+	r    io.Reader
+	n    int64
+	took time.Duration
+}
+
+func (t *traceReader) Read(p []byte) (int, error) {
+	at := time.Now()
+	n, err := t.r.Read(p)
+	t.took += time.Since(at)
+	t.n += int64(n)
+	return n, err
+}
+
+// countElements returns the number of elements doc opens, counted as the
+// elementLimiter counts them.
+func countElements(doc []byte) int {
+	var l elementLimiter
+	_ = l.scan(doc)
+	return l.elements
+}
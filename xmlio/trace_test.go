@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// TestDecodeTracer decodes a .wxx file with a tracer and asserts that every
+// stage reports, in order, and that the counts agree with each other: each
+// stage reads what the one below it produced, and the elements of the sections
+// are the elements of the document, less <map>.
+func TestDecodeTracer(t *testing.T) {
+	raw, err := os.ReadFile(sample2025_206)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []xmlio.TraceEvent_t
+	tracer := xmlio.TracerFunc(func(ev xmlio.TraceEvent_t) { events = append(events, ev) })
+	m, err := xmlio.NewDecoder(xmlio.WithDecoderTracer(tracer)).Decode(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("Decode(%s): %v", sample2025_206, err)
+	}
+
+	var stages []xmlio.Stage_e
+	byStage := map[xmlio.Stage_e]xmlio.TraceEvent_t{}
+	sections, elements := map[string]int{}, 0
+	for _, ev := range events {
+		if ev.Stage == xmlio.StageSection {
+			sections[ev.Section] = ev.Elements
+			elements += ev.Elements
+			if ev.BytesIn <= 0 {
+				t.Errorf("section %s: BytesIn = %d, want > 0", ev.Section, ev.BytesIn)
+			}
+			if len(stages) != 0 && stages[len(stages)-1] == xmlio.StageSection {
+				continue
+			}
+		}
+		stages = append(stages, ev.Stage)
+		byStage[ev.Stage] = ev
+	}
+	want := []xmlio.Stage_e{xmlio.StageHeader, xmlio.StageDispatch, xmlio.StageSection, xmlio.StageDecode, xmlio.StageRead, xmlio.StageGunzip, xmlio.StageUTF16}
+	if !reflect.DeepEqual(stages, want) {
+		t.Fatalf("stages = %v, want %v", stages, want)
+	}
+
+	if got := byStage[xmlio.StageRead].BytesOut; got != int64(len(raw)) {
+		t.Errorf("read: BytesOut = %d, want the file's %d", got, len(raw))
+	}
+	for _, s := range []struct{ stage, below xmlio.Stage_e }{{xmlio.StageGunzip, xmlio.StageRead}, {xmlio.StageUTF16, xmlio.StageGunzip}} {
+		if got, want := byStage[s.stage].BytesIn, byStage[s.below].BytesOut; got != want {
+			t.Errorf("%s: BytesIn = %d, want %s's BytesOut %d", s.stage, got, s.below, want)
+		}
+	}
+	if got := byStage[xmlio.StageDispatch].Detail; got != "2.06" {
+		t.Errorf("dispatch: Detail = %q, want %q", got, "2.06")
+	}
+	decode := byStage[xmlio.StageDecode]
+	if elements+1 != decode.Elements {
+		t.Errorf("sections hold %d elements, want the decode's %d less <map>", elements, decode.Elements)
+	}
+	// <tiles> holds a <tilerow> per column of a COLUMNS map
+	if got, want := sections["tiles"], 1+m.Tiles.TilesWide; got != want {
+		t.Errorf("tiles: Elements = %d, want %d", got, want)
+	}
+}
+
+// TestEncodeTracer asserts that an encode reports each stage, in order, and that
+// the write reports the bytes the writer was given.
+func TestEncodeTracer(t *testing.T) {
+	m := loadMap(t, sample2025_206)
+	var events []xmlio.TraceEvent_t
+	tracer := xmlio.TracerFunc(func(ev xmlio.TraceEvent_t) { events = append(events, ev) })
+	var buf bytes.Buffer
+	if err := xmlio.NewEncoder("2.06", xmlio.WithEncoderTracer(tracer)).Encode(&buf, m); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	var stages []xmlio.Stage_e
+	for _, ev := range events {
+		stages = append(stages, ev.Stage)
+	}
+	want := []xmlio.Stage_e{xmlio.StageEncode, xmlio.StageUTF16, xmlio.StageGzip, xmlio.StageWrite}
+	if !reflect.DeepEqual(stages, want) {
+		t.Fatalf("stages = %v, want %v", stages, want)
+	}
+	if events[0].Elements == 0 || events[0].Detail != "2.06" {
+		t.Errorf("encode: Elements = %d, Detail = %q", events[0].Elements, events[0].Detail)
+	}
+	if got := events[3].BytesOut; got != int64(buf.Len()) {
+		t.Errorf("write: BytesOut = %d, want %d", got, buf.Len())
+	}
+}
+
+// TestSlogTracer asserts that the slog adapter logs each stage as a message with
+// the event's counts as attributes.
+func TestSlogTracer(t *testing.T) {
+	var logs bytes.Buffer
+	tracer := xmlio.NewSlogTracer(slog.New(slog.NewTextHandler(&logs, nil)), slog.LevelInfo)
+	if _, err := xmlio.ReadFile(sample2025_206, xmlio.WithDecoderTracer(tracer)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`msg="xmlio: section" section=tiles bytes_in=`, `msg="xmlio: dispatch" detail=2.06 `, `msg="xmlio: gunzip" bytes_in=10387 `} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("log has no %q:\n%s", want, logs.String())
+		}
+	}
+}
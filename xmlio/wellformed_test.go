@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Michael D Henderson. All rights reserved.
+
+package xmlio_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/maloquacious/wxx"
+	"github.com/maloquacious/wxx/xmlio"
+)
+
+// awkward holds everything a string in a map can hold that XML cannot take as
+// it is: markup, both quotes, the three white-space characters a parser
+// normalizes, a C1 control, and the line separator XML 1.1 reads as a break.
+const awkward = "a \"b\" & 'c' <d> ]]> e\tf\ng\r\nh \u0085 i   j \x7f"
+
+// TestEncodeEscapesMapStrings asserts that strings holding markup and awkward
+// characters are written so that they read back exactly, to both targets: a
+// note that contains "]]>", label and note attributes and text, a terrain name,
+// and information text.
+func TestEncodeEscapesMapStrings(t *testing.T) {
+	for _, app := range []string{"2.06", classicTarget} {
+		m := loadMap(t, populatedFixture)
+		if len(m.Notes) == 0 || len(m.Features) == 0 || m.Features[0].Label == nil || m.Informations == nil || len(m.Informations.Informations) == 0 {
+			t.Fatalf("%s: the fixture has no notes, feature label or informations", populatedFixture)
+		}
+		m.Notes[0].Title = awkward
+		m.Notes[0].NoteText = "<p>" + awkward + "</p>]]>]]]>"
+		m.Features[0].Label.FontFace = awkward
+		m.Features[0].Label.InnerText = awkward
+		m.Informations.Informations[0].InnerText = awkward
+		terrain := m.TerrainMap.List[0]
+		delete(m.TerrainMap.Data, terrain.Label)
+		terrain.Label = "Rocks & <Hills>"
+		m.TerrainMap.Data[terrain.Label] = terrain.Index
+
+		var buf bytes.Buffer
+		if err := xmlio.NewEncoder(app).Encode(&buf, m); err != nil {
+			t.Fatalf("encode to %s: %v", app, err)
+		}
+		got, err := decodeWith(buf.Bytes())
+		if err != nil {
+			t.Fatalf("decode the %s encode: %v", app, err)
+		}
+		if label := got.Features[0].Label; label.FontFace != awkward || label.InnerText != awkward {
+			t.Errorf("%s: label = %q, %q, want %q", app, label.FontFace, label.InnerText, awkward)
+		}
+		if got.TerrainMap.Data["Rocks & <Hills>"] != terrain.Index {
+			t.Errorf("%s: terrain map = %v, want %q at %d", app, got.TerrainMap.Data, terrain.Label, terrain.Index)
+		}
+		if app == classicTarget {
+			continue // classic writes no notes and no informations
+		}
+		if got.Notes[0].Title != m.Notes[0].Title || got.Notes[0].NoteText != m.Notes[0].NoteText {
+			t.Errorf("%s: note = %q, %q, want %q, %q", app, got.Notes[0].Title, got.Notes[0].NoteText, m.Notes[0].Title, m.Notes[0].NoteText)
+		}
+		if text := got.Informations.Informations[0].InnerText; text != awkward {
+			t.Errorf("%s: information text = %q, want %q", app, text, awkward)
+		}
+	}
+}
+
+// TestEncodeRejectsInvalidCharacters asserts that a string holding a character
+// XML cannot carry at all fails the encode, and that the writer gets nothing.
+func TestEncodeRejectsInvalidCharacters(t *testing.T) {
+	for _, tc := range []struct {
+		name, text string
+	}{
+		{"control", "a\x01b"},
+		{"lone surrogate", "a\xed\xa0\x80b"},
+		{"invalid UTF-8", "a\xffb"},
+	} {
+		for _, app := range []string{"2.06", classicTarget} {
+			m := loadMap(t, populatedFixture)
+			m.Features[0].Label.InnerText = tc.text
+			var buf bytes.Buffer
+			err := xmlio.NewEncoder(app).Encode(&buf, m)
+			if !errors.Is(err, wxx.ErrMalformedOutput) || !errors.Is(err, wxx.ErrInvalidXMLCharacter) {
+				t.Errorf("%s, %s: error = %v, want %v and %v", tc.name, app, err, wxx.ErrMalformedOutput, wxx.ErrInvalidXMLCharacter)
+			}
+			if buf.Len() != 0 {
+				t.Errorf("%s, %s: the writer got %d bytes", tc.name, app, buf.Len())
+			}
+			if _, err := xmlio.MarshalXML(m, app); !errors.Is(err, wxx.ErrMalformedOutput) {
+				t.Errorf("%s, %s: MarshalXML error = %v, want %v", tc.name, app, err, wxx.ErrMalformedOutput)
+			}
+		}
+	}
+}
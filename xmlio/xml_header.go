@@ -2,6 +2,13 @@
 
 package xmlio
 
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/maloquacious/wxx"
+)
+
 var (
 	// table of XML headers that we can accept
 	xmlHeaders = []struct {
@@ -35,3 +42,46 @@ func utf16XMLHeader(xmlVersion string) (string, bool) {
 	}
 	return "", false
 }
+
+// knownXMLHeader returns the index in xmlHeaders of the declaration br opens
+// with, or -1 when it opens with none of them. Nothing is consumed.
+func knownXMLHeader(br *bufio.Reader) int {
+	for i, header := range xmlHeaders {
+		if heading, err := br.Peek(len(header.heading)); err == nil && string(heading) == header.heading {
+			return i
+		}
+	}
+	return -1
+}
+
+// sniffXMLHeader consumes the XML declaration br opens with, if there is one,
+// and returns it. A declaration from xmlHeaders is consumed exactly as the
+// table spells it; any other well-formed one is consumed through its "?>" and
+// the whitespace after it. A missing declaration is not an error.
+func sniffXMLHeader(br *bufio.Reader) ([]byte, error) {
+	if prefix, err := peek(br, len("<?xml")); err != nil {
+		return nil, err
+	} else if !bytes.HasPrefix(prefix, []byte("<?xml")) {
+		return nil, nil
+	}
+	if i := knownXMLHeader(br); i != -1 {
+		heading := []byte(xmlHeaders[i].heading)
+		_, err := br.Discard(len(heading))
+		return heading, err
+	}
+	data, err := peek(br, br.Size())
+	if err != nil {
+		return nil, err
+	}
+	end := bytes.Index(data, []byte("?>"))
+	if end == -1 {
+		return nil, wxx.ErrInvalidXMLHeader
+	}
+	end += len("?>")
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t' || data[end] == '\r' || data[end] == '\n') {
+		end++
+	}
+	heading := bdup(data[:end])
+	_, err = br.Discard(end)
+	return heading, err
+}